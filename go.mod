@@ -13,9 +13,7 @@ require (
 	golang.org/x/net v0.44.0
 	golang.org/x/sync v0.17.0
 	golang.org/x/term v0.35.0
+	golang.org/x/text v0.29.0
 )
 
-require (
-	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/text v0.29.0 // indirect
-)
+require golang.org/x/sys v0.36.0 // indirect
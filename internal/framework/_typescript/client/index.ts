@@ -49,6 +49,12 @@ export {
 	getRouterData,
 	type ClientLoaderAwaitedServerData,
 } from "./src/river_ctx/river_ctx.ts";
+export {
+	makeTypedPrefetch,
+	prefetchRouteAssets,
+	type RiverRoutePrefetchEntry,
+	type RiverRoutePrefetchMap,
+} from "./src/route_prefetch.ts";
 export { __applyScrollState } from "./src/scroll_state_manager.ts";
 export { route } from "./src/static_route_defs/route_def_helpers.ts";
 export {
@@ -21,6 +21,7 @@ import {
 } from "./events.ts";
 import { HistoryManager } from "./history/history.ts";
 import type { historyInstance } from "./history/npm_history_types.ts";
+import { LoaderDataCache } from "./loader_data_cache.ts";
 import {
 	effectuateRedirectDataResult,
 	getBuildIDFromResponse,
@@ -33,6 +34,7 @@ import {
 	type ClientLoaderAwaitedServerData,
 	type GetRouteDataOutput,
 } from "./river_ctx/river_ctx.ts";
+import { ensureRouteManifestShards } from "./route_manifest.ts";
 import {
 	__applyScrollState,
 	type ScrollState,
@@ -353,6 +355,15 @@ class NavigationStateManager {
 			__riverClientGlobal.get("patternToWaitFnMap") || {};
 
 		const url = new URL(targetUrl);
+
+		// If the route manifest is split, patterns for the current and
+		// target locations may not be registered yet
+		if (
+			!ensureRouteManifestShards(window.location.pathname, url.pathname)
+		) {
+			return { canSkip: false };
+		}
+
 		const matchResult = findNestedMatches(patternRegistry, url.pathname);
 		if (!matchResult) {
 			return { canSkip: false };
@@ -598,6 +609,10 @@ class NavigationStateManager {
 				}
 			}
 
+			// Keyed before adding River's own query params
+			const loaderDataCacheKey = url.pathname + url.search;
+			const cachedLoaderData = LoaderDataCache.get(loaderDataCacheKey);
+
 			url.searchParams.set(
 				"river_json",
 				__riverClientGlobal.get("buildID") || "1",
@@ -616,7 +631,19 @@ class NavigationStateManager {
 				url,
 				isPrefetch: props.navigationType === "prefetch",
 				redirectCount: props.redirectCount,
+				requestInit: cachedLoaderData
+					? { headers: { "If-None-Match": cachedLoaderData.etag } }
+					: undefined,
 			}).then(async (result) => {
+				// Unchanged loader data -- reuse what we already have
+				if (
+					result.response?.status === 304 &&
+					cachedLoaderData &&
+					!result.redirectData?.status
+				) {
+					const json = structuredClone(cachedLoaderData.json);
+					return { ...result, json };
+				}
 				// Read the response body once and return both the original result and parsed JSON
 				if (
 					result.response &&
@@ -624,6 +651,12 @@ class NavigationStateManager {
 					!result.redirectData?.status
 				) {
 					const json = await result.response.json();
+					const etag = result.response.headers.get("ETag");
+					if (etag) {
+						LoaderDataCache.set(loaderDataCacheKey, etag, json);
+					} else {
+						LoaderDataCache.delete(loaderDataCacheKey);
+					}
 					return { ...result, json };
 				}
 				return { ...result, json: undefined };
@@ -655,7 +688,12 @@ class NavigationStateManager {
 									response,
 									json,
 								}): ClientLoaderAwaitedServerData<any, any> => {
-									if (!response || !response.ok || !json) {
+									if (
+										!response ||
+										(!response.ok &&
+											response.status !== 304) ||
+										!json
+									) {
 										return {
 											matchedPatterns: [],
 											loaderData: undefined,
@@ -1004,7 +1042,12 @@ class NavigationStateManager {
 			// Auto-revalidate for mutations
 			const isGET = getIsGETRequest(requestInit);
 			const redirected = redirectData?.status === "did";
-			if (!isGET && !redirected && options?.revalidate !== false) {
+			if (
+				!isGET &&
+				!redirected &&
+				options?.revalidate !== false &&
+				shouldRevalidateForResponse(response)
+			) {
 				await revalidate();
 			}
 
@@ -1153,6 +1196,20 @@ export function getLastTriggeredNavOrRevalidateTimestampMS(): number {
 	return lastTriggeredNavOrRevalidateTimestampMS;
 }
 
+// If the action declared the revalidation tags it affects, only
+// revalidate when the current page's loaders declared one of them.
+function shouldRevalidateForResponse(response: Response | undefined): boolean {
+	const header = response?.headers.get("X-River-Revalidate-Tags");
+	if (header === null || header === undefined) {
+		return true;
+	}
+	const currentTags = __riverClientGlobal.get("revalidationTags") || [];
+	return header
+		.split(",")
+		.map((tag) => tag.trim())
+		.some((tag) => tag !== "" && currentTags.includes(tag));
+}
+
 export async function revalidate() {
 	await navigationStateManager.navigate({
 		href: window.location.href,
@@ -1,7 +1,4 @@
-import {
-	createPatternRegistry,
-	registerPattern,
-} from "river.now/kit/matcher/register";
+import { createPatternRegistry } from "river.now/kit/matcher/register";
 import { setupClientLoaders } from "./client_loaders.ts";
 import { ComponentLoader } from "./component_loader.ts";
 import { defaultErrorBoundary } from "./error_boundary.ts";
@@ -14,6 +11,7 @@ import {
 	type RiverClientGlobal,
 	type RouteErrorComponent,
 } from "./river_ctx/river_ctx.ts";
+import { initRouteManifest } from "./route_manifest.ts";
 import { scrollStateManager } from "./scroll_state_manager.ts";
 
 export async function initClient(options: {
@@ -64,23 +62,7 @@ export async function initClient(options: {
 	});
 	__riverClientGlobal.set("patternRegistry", patternRegistry);
 
-	const manifestURL = __riverClientGlobal.get("routeManifestURL");
-	if (manifestURL) {
-		fetch(manifestURL)
-			.then((response) => response.json())
-			.then((manifest) => {
-				__riverClientGlobal.set("routeManifest", manifest);
-
-				// Register all patterns from manifest into the existing registry
-				for (const pattern of Object.keys(manifest)) {
-					registerPattern(patternRegistry, pattern);
-				}
-			})
-			.catch((error) => {
-				// This is no biggie -- it's a progressive enhancement
-				console.warn("Failed to load route manifest:", error);
-			});
-	}
+	initRouteManifest();
 
 	// Set options
 	if (options.defaultErrorBoundary) {
@@ -0,0 +1,36 @@
+import type { GetRouteDataOutput } from "./river_ctx/river_ctx.ts";
+
+// Route data JSON responses are remembered by URL along with their
+// ETag (only sent when every matched loader declares a data version via
+// SetLoaderDataVersion on the server). Subsequent fetches of the same
+// URL send If-None-Match, and a 304 reuses the remembered JSON.
+
+const MAX_ENTRIES = 50;
+
+export type LoaderDataCacheEntry = {
+	etag: string;
+	json: GetRouteDataOutput;
+};
+
+const entries = new Map<string, LoaderDataCacheEntry>();
+
+export class LoaderDataCache {
+	// Callers must not mutate the returned JSON (clone it before use).
+	static get(key: string): LoaderDataCacheEntry | undefined {
+		return entries.get(key);
+	}
+
+	static set(key: string, etag: string, json: GetRouteDataOutput): void {
+		// Re-insert so that Map iteration order tracks recency
+		entries.delete(key);
+		entries.set(key, { etag, json: structuredClone(json) });
+		if (entries.size > MAX_ENTRIES) {
+			const oldestKey = entries.keys().next().value;
+			if (oldestKey !== undefined) entries.delete(oldestKey);
+		}
+	}
+
+	static delete(key: string): void {
+		entries.delete(key);
+	}
+}
@@ -54,6 +54,7 @@ async function __reRenderAppInner(props: RerenderAppProps): Promise<void> {
 		"importURLs",
 		"exportKeys",
 		"hasRootData",
+		"revalidationTags",
 		"params",
 		"splatValues",
 	] as const;
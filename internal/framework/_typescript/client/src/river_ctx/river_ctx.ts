@@ -28,6 +28,8 @@ type shared = {
 	exportKeys: Array<string>;
 	errorExportKeys: string[];
 	hasRootData: boolean;
+	// Tags declared by the matched server loaders (deduplicated)
+	revalidationTags?: Array<string> | null;
 
 	params: Record<string, string>;
 	splatValues: Array<string>;
@@ -76,6 +78,11 @@ export type RiverClientGlobal = shared & {
 	riverAppConfig: RiverAppConfig;
 	// SSR'd
 	routeManifestURL: string;
+	// SSR'd (null unless the route manifest is split), keyed by static
+	// top-level path segment
+	routeManifestShardURLs: Record<string, string> | null;
+	// SSR'd (empty in dev)
+	prefetchMapURL: string;
 	// Fetched at startup -- fine because progressive enhancement
 	// and not needed until any given route's second navigation
 	// anyway
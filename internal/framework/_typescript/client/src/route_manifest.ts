@@ -0,0 +1,95 @@
+import { registerPattern } from "river.now/kit/matcher/register";
+import { __riverClientGlobal } from "./river_ctx/river_ctx.ts";
+
+const requestedManifestURLs = new Set<string>();
+const loadedManifestURLs = new Set<string>();
+
+function loadRouteManifest(url: string): void {
+	if (requestedManifestURLs.has(url)) {
+		return;
+	}
+	requestedManifestURLs.add(url);
+
+	fetch(url)
+		.then((response) => response.json())
+		.then((manifest: Record<string, number>) => {
+			__riverClientGlobal.set("routeManifest", {
+				...__riverClientGlobal.get("routeManifest"),
+				...manifest,
+			});
+
+			// Register all patterns from manifest into the existing registry
+			const patternRegistry = __riverClientGlobal.get("patternRegistry");
+			for (const pattern of Object.keys(manifest)) {
+				registerPattern(patternRegistry, pattern);
+			}
+
+			loadedManifestURLs.add(url);
+		})
+		.catch((error) => {
+			// This is no biggie -- it's a progressive enhancement
+			console.warn("Failed to load route manifest:", error);
+			requestedManifestURLs.delete(url);
+		});
+}
+
+function getShardURLs(pathname: string): Array<string> {
+	const shardURLs = __riverClientGlobal.get("routeManifestShardURLs");
+	if (!shardURLs) {
+		return [];
+	}
+	const firstSegment = pathname.split("/")[1] ?? "";
+	if (!firstSegment) {
+		return [];
+	}
+	const urls: Array<string> = [];
+	const rawURL = shardURLs[firstSegment];
+	if (rawURL) {
+		urls.push(rawURL);
+	}
+	try {
+		const decodedURL = shardURLs[decodeURIComponent(firstSegment)];
+		if (decodedURL && decodedURL !== rawURL) {
+			urls.push(decodedURL);
+		}
+	} catch {
+		// Malformed escape sequence -- nothing more to look up
+	}
+	return urls;
+}
+
+/**
+ * Loads the (base) route manifest, plus, if the manifest is split into
+ * shards, the shard for the current location.
+ */
+export function initRouteManifest(): void {
+	const manifestURL = __riverClientGlobal.get("routeManifestURL");
+	if (!manifestURL) {
+		return;
+	}
+	loadRouteManifest(manifestURL);
+	for (const url of getShardURLs(window.location.pathname)) {
+		loadRouteManifest(url);
+	}
+}
+
+/**
+ * Reports whether every route manifest shard needed to reason about
+ * the given pathnames has loaded, kicking off loads for any that have
+ * not (so that later navigations can use them). Always true if the
+ * manifest is not split.
+ */
+export function ensureRouteManifestShards(
+	...pathnames: Array<string>
+): boolean {
+	let allLoaded = true;
+	for (const pathname of pathnames) {
+		for (const url of getShardURLs(pathname)) {
+			if (!loadedManifestURLs.has(url)) {
+				allLoaded = false;
+				loadRouteManifest(url);
+			}
+		}
+	}
+	return allLoaded;
+}
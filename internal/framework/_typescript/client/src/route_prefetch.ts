@@ -0,0 +1,67 @@
+import { AssetManager } from "./asset_manager.ts";
+import type {
+	ExtractApp,
+	RiverAppConfig,
+	RiverLoaderPattern,
+} from "./river_app_helpers/river_app_helpers.ts";
+import { __riverClientGlobal } from "./river_ctx/river_ctx.ts";
+
+export type RiverRoutePrefetchEntry = {
+	deps: Array<string>;
+	css: Array<string>;
+	hasLoader: boolean;
+};
+
+export type RiverRoutePrefetchMap = Record<string, RiverRoutePrefetchEntry>;
+
+let prefetchMapPromise: Promise<RiverRoutePrefetchMap> | undefined;
+
+function getPrefetchMap(url: string): Promise<RiverRoutePrefetchMap> {
+	if (!prefetchMapPromise) {
+		prefetchMapPromise = fetch(url)
+			.then((response) => response.json())
+			.catch((error) => {
+				// This is no biggie -- it's a progressive enhancement
+				console.warn("Failed to load prefetch map:", error);
+				prefetchMapPromise = undefined;
+				return {};
+			});
+	}
+	return prefetchMapPromise;
+}
+
+/**
+ * Warms the network cache with the JS and CSS a route pattern needs
+ * (e.g., on link hover), without running any loaders. Resolves to
+ * false if no prefetch data is available for the pattern (always the
+ * case in dev, as the prefetch map is only generated for prod builds).
+ */
+export async function prefetchRouteAssets(pattern: string): Promise<boolean> {
+	const url = __riverClientGlobal.get("prefetchMapURL");
+	if (!url) {
+		return false;
+	}
+	const entry = (await getPrefetchMap(url))[pattern];
+	if (!entry) {
+		return false;
+	}
+	for (const dep of entry.deps) {
+		AssetManager.preloadModule(dep);
+	}
+	await Promise.all(
+		entry.css.map((bundle) =>
+			AssetManager.preloadCSS(bundle).catch(() => {}),
+		),
+	);
+	return true;
+}
+
+export function makeTypedPrefetch<C extends RiverAppConfig>(_: C) {
+	type App = ExtractApp<C>;
+
+	return function typedPrefetch(
+		pattern: RiverLoaderPattern<App>,
+	): Promise<boolean> {
+		return prefetchRouteAssets(pattern);
+	};
+}
@@ -0,0 +1,102 @@
+package river
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/river-now/river/kit/viteutil"
+)
+
+// warnUnusedCSS cross-references the CSS bundles emitted by Vite against
+// the CSS River will actually serve for each route (per DepToCSSBundleMap
+// and each path's Deps), and logs a warning for (i) CSS bundles that no
+// route references (orphaned), and (ii) routes whose dependency chunks
+// import CSS that is never served alongside them. Must be called after
+// pf has been fully populated by toPathsFile_StageTwo.
+func (h *River) warnUnusedCSS(viteManifest viteutil.Manifest, pf *PathsFile) {
+	for _, problem := range findUnusedCSS(viteManifest, pf) {
+		h.Wave.BuildWarn(fmt.Sprintf("unused CSS: %s", problem))
+	}
+}
+
+func findUnusedCSS(viteManifest viteutil.Manifest, pf *PathsFile) []string {
+	chunkCSS := make(map[string][]string, len(viteManifest))
+	dynamicOnly := make(map[string]struct{})
+	for _, chunk := range viteManifest {
+		if len(chunk.CSS) == 0 {
+			continue
+		}
+		cleanKey := path.Base(chunk.File)
+		for _, cssFile := range chunk.CSS {
+			chunkCSS[cleanKey] = append(chunkCSS[cleanKey], path.Base(cssFile))
+		}
+		// Vite loads the CSS of dynamically imported chunks itself, so it
+		// is not expected to show up in any route's CSS bundles.
+		if chunk.IsDynamicEntry {
+			for _, cssFile := range chunk.CSS {
+				dynamicOnly[path.Base(cssFile)] = struct{}{}
+			}
+		}
+	}
+
+	var problems []string
+	servedAnywhere := make(map[string]struct{})
+
+	// mirrors getDeps + getCSSBundles
+	resolve := func(deps []string) (imported, served []string) {
+		all := make([]string, 0, len(pf.ClientEntryDeps)+len(deps)+1)
+		all = append(all, pf.ClientEntryOut)
+		all = append(all, pf.ClientEntryDeps...)
+		all = append(all, deps...)
+		for _, dep := range all {
+			for _, cssFile := range chunkCSS[dep] {
+				if !slices.Contains(imported, cssFile) {
+					imported = append(imported, cssFile)
+				}
+			}
+			if x, exists := pf.DepToCSSBundleMap[dep]; exists {
+				servedAnywhere[x] = struct{}{}
+				if !slices.Contains(served, x) {
+					served = append(served, x)
+				}
+			}
+		}
+		return imported, served
+	}
+
+	resolve(nil)
+
+	for pattern, p := range pf.Paths {
+		imported, served := resolve(p.Deps)
+		for _, cssFile := range imported {
+			if !slices.Contains(served, cssFile) {
+				problems = append(problems, fmt.Sprintf(
+					"route %q imports CSS bundle %q, but it is never served with the route", pattern, cssFile,
+				))
+			}
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, cssFiles := range chunkCSS {
+		for _, cssFile := range cssFiles {
+			if _, ok := seen[cssFile]; ok {
+				continue
+			}
+			seen[cssFile] = struct{}{}
+			if _, ok := servedAnywhere[cssFile]; ok {
+				continue
+			}
+			if _, ok := dynamicOnly[cssFile]; ok {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf(
+				"CSS bundle %q is not referenced by any route (orphaned)", cssFile,
+			))
+		}
+	}
+
+	slices.Sort(problems)
+	return problems
+}
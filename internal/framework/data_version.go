@@ -0,0 +1,87 @@
+package river
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/river-now/river/kit/mux"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// LOADER DATA VERSIONS (304 NOT MODIFIED)
+/////////////////////////////////////////////////////////////////////
+
+// Carried on each loader's response proxy, and stripped before the
+// merged proxy is applied to the response.
+const loaderDataVersionHeaderKey = "X-River-Loader-Data-Version"
+
+// SetLoaderDataVersion declares a version (e.g., a row's updated-at
+// timestamp or a content hash) for the data returned by the loader.
+// When every loader matched by a client navigation declares a version,
+// River sends an ETag (derived from those versions, the matched
+// patterns, the URL path, and the build ID) with the JSON response, and
+// answers subsequent navigations whose If-None-Match matches with a 304,
+// so that the client can reuse the loader data it already has.
+//
+// The version must change whenever anything the loader sends to the
+// client changes, including head elements it adds. Default head elements
+// are not taken into account, so they should not vary with loader data.
+// Initial document (HTML) requests are unaffected.
+func SetLoaderDataVersion(rd *LoaderReqData, version string) {
+	rd.ResponseProxy().SetHeader(loaderDataVersionHeaderKey, version)
+}
+
+// Returns an empty string unless every loader that ran declared a
+// version (and at least one loader ran, so that routes without loaders
+// never opt in implicitly).
+func (h *River) getLoaderDataETag(
+	r *http.Request,
+	matchedPatterns []string,
+	tasksResults *mux.NestedTasksResults,
+) string {
+	hash := sha256.New()
+	writeField := func(s string) {
+		hash.Write([]byte(s))
+		hash.Write([]byte{0})
+	}
+	writeField(h._buildID)
+	writeField(r.URL.Path)
+	var hasVersions bool
+	for i, pattern := range matchedPatterns {
+		writeField(pattern)
+		if !tasksResults.GetHasTaskHandler(i) {
+			continue
+		}
+		version := tasksResults.ResponseProxies[i].GetHeader(loaderDataVersionHeaderKey)
+		if version == "" {
+			return ""
+		}
+		writeField(version)
+		hasVersions = true
+	}
+	if !hasVersions {
+		return ""
+	}
+	return `W/"` + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// Reports whether the If-None-Match request header matches the given
+// ETag, using weak comparison (per RFC 9110 for If-None-Match).
+func ifNoneMatchMatches(r *http.Request, etag string) bool {
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	target := strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package river
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/river-now/river/kit/cryptoutil"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// DEV BUILD CACHE
+/////////////////////////////////////////////////////////////////////
+
+// Dev builds run in a fresh process for every change (via the dev build
+// hook), so anything they reuse from a previous build is persisted to
+// disk, keyed on a hash of its inputs.
+
+const riverDevRouteCallsCacheFileName = "river_dev_route_calls_cache.json"
+
+// Bump whenever extractRouteCallsFromCode changes what it extracts, so
+// that caches written by older versions are ignored.
+const devRouteCallsCacheVersion = 1
+
+type devRouteCallsCache struct {
+	Version       int         `json:"version"`
+	RouteDefsHash string      `json:"routeDefsHash"`
+	RouteCalls    []RouteCall `json:"routeCalls"`
+}
+
+func (h *River) getDevRouteCallsCachePath() string {
+	return filepath.Join(h.Wave.GetStaticPrivateOutDir(), "river_out", riverDevRouteCallsCacheFileName)
+}
+
+// Extracts the route calls from the client route defs file. In dev, if
+// the file is unchanged since the previous build, the route calls that
+// build extracted are reused instead, skipping the (comparatively slow)
+// transform and parse, which matters for large route tables.
+func (h *River) extractRouteCallsCached(clientRouteDefsFile string) ([]RouteCall, error) {
+	if !h._isDev {
+		return extractRouteCallsFromFile(clientRouteDefsFile)
+	}
+
+	code, err := os.ReadFile(clientRouteDefsFile)
+	if err != nil {
+		Log.Error(fmt.Sprintf("error reading client route defs file: %s", err))
+		return nil, err
+	}
+	hash := base64.RawURLEncoding.EncodeToString(cryptoutil.Sha256Hash(code))
+
+	cachePath := h.getDevRouteCallsCachePath()
+	if cacheJSON, err := os.ReadFile(cachePath); err == nil {
+		var cache devRouteCallsCache
+		if json.Unmarshal(cacheJSON, &cache) == nil &&
+			cache.Version == devRouteCallsCacheVersion &&
+			cache.RouteDefsHash == hash {
+			return cache.RouteCalls, nil
+		}
+	}
+
+	routeCalls, err := extractRouteCallsFromCode(code)
+	if err != nil {
+		// already logged internally in extractRouteCallsFromCode
+		return nil, err
+	}
+
+	// The cache is only an optimization, so failing to write it is not
+	// an error (the next build just extracts the route calls again).
+	cacheJSON, err := json.Marshal(devRouteCallsCache{
+		Version:       devRouteCallsCacheVersion,
+		RouteDefsHash: hash,
+		RouteCalls:    routeCalls,
+	})
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(cachePath), os.ModePerm); err == nil {
+			err = os.WriteFile(cachePath, cacheJSON, os.ModePerm)
+		}
+	}
+	if err != nil {
+		Log.Warn(fmt.Sprintf("error writing dev route calls cache: %s", err))
+	}
+
+	return routeCalls, nil
+}
+
+// Writes content to target unless target already holds exactly that
+// content, so that file watchers (e.g., Vite's, for the TS gen output)
+// don't see a change when nothing changed. Reports whether it wrote.
+func writeFileIfChanged(target string, content []byte) (bool, error) {
+	if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, content) {
+		return false, nil
+	}
+	if err := os.WriteFile(target, content, os.ModePerm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,60 @@
+package river
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/river-now/river/kit/response"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// DOCUMENT RESPONSE HEADERS
+/////////////////////////////////////////////////////////////////////
+
+// Carried (as "Key: value" entries) on each loader's response proxy, and
+// stripped before the merged proxy is applied to the response.
+const documentHeadersHeaderKey = "X-River-Document-Headers"
+
+// SetDocumentHeader sets a header (e.g., Cache-Control or Vary) on the
+// initial document (HTML) response for the route, leaving client
+// navigation (JSON) responses unaffected. When several matched loaders
+// set the same header, the most deeply nested loader wins. Document
+// headers are not applied to error or redirect responses, nor in dev
+// mode, and they take precedence over River's default Cache-Control.
+//
+// Documents carrying document headers also get an ETag derived from the
+// build ID and the rendered HTML, and requests whose If-None-Match
+// matches are answered with a 304. As the build ID changes with every
+// deploy, caches that revalidate never get a pre-deploy document back
+// (to be safe with shared caches, keep max-age / s-maxage short or purge
+// on deploy).
+func SetDocumentHeader(rd *LoaderReqData, key, value string) {
+	rd.ResponseProxy().AddHeader(
+		documentHeadersHeaderKey, http.CanonicalHeaderKey(key)+": "+value,
+	)
+}
+
+// Later entries (i.e., from more deeply nested loaders) win.
+func getDocumentHeaders(proxy *response.Proxy) http.Header {
+	entries := proxy.GetHeaders(documentHeadersHeaderKey)
+	if len(entries) == 0 {
+		return nil
+	}
+	headers := make(http.Header, len(entries))
+	for _, entry := range entries {
+		if key, value, ok := strings.Cut(entry, ": "); ok && key != "" {
+			headers.Set(key, value)
+		}
+	}
+	return headers
+}
+
+func (h *River) getDocumentETag(html []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(h._buildID))
+	hash.Write([]byte{0})
+	hash.Write(html)
+	return `W/"` + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
@@ -0,0 +1,147 @@
+package river
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// FILE-SYSTEM ROUTES
+/////////////////////////////////////////////////////////////////////
+
+const (
+	fsRouteLayoutFileName = "_route"
+	fsRouteParamPrefix    = "$"
+)
+
+var fsRouteModuleExts = map[string]struct{}{
+	".tsx": {}, ".ts": {}, ".jsx": {}, ".js": {},
+}
+
+// extractFSRouteCalls walks routesDir and derives a RouteCall for each
+// route module found, using the following conventions (relative to
+// routesDir, extension stripped):
+//   - "foo/bar"       -> "/foo/bar"
+//   - "foo/_route"    -> "/foo" (and "_route" at the root -> "/")
+//   - "foo/_index"    -> "/foo/_index" (explicit index segment)
+//   - "users/$id"     -> "/users/:id" (using the dynamic param rune)
+//   - "files/$"       -> "/files/*" (using the splat segment rune)
+//
+// Declaration files and test files are skipped. Two modules mapping to
+// the same pattern (e.g., "foo.tsx" and "foo/_route.tsx") is an error.
+// Unlike route calls
+// parsed from the client route defs file, module paths here are
+// already relative to the current working directory.
+func extractFSRouteCalls(routesDir string, dynamicRune, splatRune rune) ([]RouteCall, error) {
+	var routeCalls []RouteCall
+
+	err := filepath.WalkDir(routesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		ext := filepath.Ext(p)
+		if _, ok := fsRouteModuleExts[ext]; !ok {
+			return nil
+		}
+		base := strings.TrimSuffix(filepath.Base(p), ext)
+		if strings.HasSuffix(base, ".d") ||
+			strings.HasSuffix(base, ".test") ||
+			strings.HasSuffix(base, ".spec") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(routesDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		routeCalls = append(routeCalls, RouteCall{
+			Pattern: fsRoutePathToPattern(strings.TrimSuffix(rel, ext), dynamicRune, splatRune),
+			Module:  filepath.ToSlash(filepath.Join(routesDir, rel)),
+			Key:     "default",
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(routeCalls, func(a, b RouteCall) int {
+		return strings.Compare(a.Pattern, b.Pattern)
+	})
+
+	for i := 1; i < len(routeCalls); i++ {
+		if prev, cur := routeCalls[i-1], routeCalls[i]; prev.Pattern == cur.Pattern {
+			return nil, fmt.Errorf(
+				"file-system routes %s and %s both map to pattern %s",
+				prev.Module, cur.Module, cur.Pattern,
+			)
+		}
+	}
+
+	return routeCalls, nil
+}
+
+func fsRoutePathToPattern(relPathWithoutExt string, dynamicRune, splatRune rune) string {
+	segments := strings.Split(relPathWithoutExt, "/")
+	if segments[len(segments)-1] == fsRouteLayoutFileName {
+		segments = segments[:len(segments)-1]
+	}
+	for i, segment := range segments {
+		switch {
+		case segment == fsRouteParamPrefix:
+			segments[i] = string(splatRune)
+		case strings.HasPrefix(segment, fsRouteParamPrefix):
+			segments[i] = string(dynamicRune) + strings.TrimPrefix(segment, fsRouteParamPrefix)
+		}
+	}
+	return path.Join("/", strings.Join(segments, "/"))
+}
+
+// getFSRouteCalls returns the file-system route calls whose patterns
+// were not already explicitly defined in the client route defs file.
+// Explicit definitions always win.
+func (h *River) getFSRouteCalls(explicitRouteCalls []RouteCall) ([]RouteCall, error) {
+	routesDir := h.Wave.GetRiverClientRoutesDir()
+	if routesDir == "" {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(routesDir); err != nil {
+		return nil, err
+	}
+
+	fsRouteCalls, err := extractFSRouteCalls(
+		routesDir,
+		h.LoadersRouter().NestedRouter.GetDynamicParamPrefixRune(),
+		h.LoadersRouter().NestedRouter.GetSplatSegmentRune(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	explicit := make(map[string]struct{}, len(explicitRouteCalls))
+	for _, routeCall := range explicitRouteCalls {
+		explicit[routeCall.Pattern] = struct{}{}
+	}
+
+	var routeCalls []RouteCall
+	for _, routeCall := range fsRouteCalls {
+		if _, ok := explicit[routeCall.Pattern]; ok {
+			continue
+		}
+		routeCalls = append(routeCalls, routeCall)
+	}
+
+	return routeCalls, nil
+}
@@ -0,0 +1,108 @@
+package river
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFSRoutePathToPattern(t *testing.T) {
+	tests := []struct {
+		relPath string
+		want    string
+	}{
+		{"_route", "/"},
+		{"about", "/about"},
+		{"foo/bar", "/foo/bar"},
+		{"foo/_route", "/foo"},
+		{"foo/_index", "/foo/_index"},
+		{"users/$id", "/users/:id"},
+		{"users/$id/posts/$postID", "/users/:id/posts/:postID"},
+		{"files/$", "/files/*"},
+		{"$", "/*"},
+		{"users/$id/_route", "/users/:id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.relPath, func(t *testing.T) {
+			if got := fsRoutePathToPattern(tt.relPath, ':', '*'); got != tt.want {
+				t.Errorf("fsRoutePathToPattern(%q) = %q, want %q", tt.relPath, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("CustomRunes", func(t *testing.T) {
+		if got := fsRoutePathToPattern("users/$id/$", '@', '~'); got != "/users/@id/~" {
+			t.Errorf("got %q, want %q", got, "/users/@id/~")
+		}
+	})
+}
+
+func TestExtractFSRouteCalls(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"_route.tsx",
+		"about.tsx",
+		"users/$id.tsx",
+		"users/types.d.ts",
+		"users/$id.test.tsx",
+		"styles.css",
+	} {
+		p := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	routeCalls, err := extractFSRouteCalls(dir, ':', '*')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RouteCall{
+		{Pattern: "/", Module: filepath.ToSlash(filepath.Join(dir, "_route.tsx")), Key: "default"},
+		{Pattern: "/about", Module: filepath.ToSlash(filepath.Join(dir, "about.tsx")), Key: "default"},
+		{Pattern: "/users/:id", Module: filepath.ToSlash(filepath.Join(dir, "users/$id.tsx")), Key: "default"},
+	}
+	if len(routeCalls) != len(want) {
+		t.Fatalf("got %d route calls, want %d: %+v", len(routeCalls), len(want), routeCalls)
+	}
+	for i := range want {
+		if routeCalls[i] != want[i] {
+			t.Errorf("route call %d = %+v, want %+v", i, routeCalls[i], want[i])
+		}
+	}
+}
+
+func TestExtractFSRouteCallsDuplicatePatterns(t *testing.T) {
+	for _, files := range [][2]string{
+		{"foo.tsx", "foo/_route.tsx"},
+		{"a/$id.tsx", "a/$id/_route.tsx"},
+	} {
+		t.Run(files[0], func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range files {
+				p := filepath.Join(dir, file)
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := extractFSRouteCalls(dir, ':', '*')
+			if err == nil {
+				t.Fatal("expected an error for duplicate patterns")
+			}
+			for _, file := range files {
+				if !strings.Contains(err.Error(), file) {
+					t.Errorf("expected error to name %s, got %v", file, err)
+				}
+			}
+		})
+	}
+}
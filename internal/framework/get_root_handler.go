@@ -67,6 +67,8 @@ func (h *River) GetLoadersHandler(nestedRouter *mux.NestedRouter) mux.TasksCtxRe
 			ViteDevURL:   uiRouteData.state_2_final.ViteDevURL,
 		}
 
+		useDocumentHeaders := !isJSON && !h._isDev && len(uiRouteData.documentHeaders) > 0
+
 		currentCacheControlHeader := w.Header().Get("Cache-Control")
 
 		if currentCacheControlHeader == "" {
@@ -75,6 +77,14 @@ func (h *River) GetLoadersHandler(nestedRouter *mux.NestedRouter) mux.TasksCtxRe
 		}
 
 		if isJSON {
+			if etag := uiRouteData.loaderDataETag; etag != "" {
+				res.SetHeader("ETag", etag)
+				if ifNoneMatchMatches(r, etag) {
+					res.NotModified()
+					return
+				}
+			}
+
 			jsonBytes, err := json.Marshal(routeData)
 			if err != nil {
 				Log.Error(fmt.Sprintf("Error marshalling JSON: %v\n", err))
@@ -82,6 +92,10 @@ func (h *River) GetLoadersHandler(nestedRouter *mux.NestedRouter) mux.TasksCtxRe
 				return
 			}
 
+			if status := uiRouteData.successStatus; status != 0 {
+				res.SetHeader("Content-Type", "application/json")
+				res.SetStatus(status)
+			}
 			res.JSONBytes(jsonBytes)
 			return
 		}
@@ -141,7 +155,7 @@ func (h *River) GetLoadersHandler(nestedRouter *mux.NestedRouter) mux.TasksCtxRe
 			rootTemplateData["RiverBodyScripts"] = template.HTML(
 				fmt.Sprintf(
 					`<script type="module" src="%s%s"></script>`,
-					h.Wave.GetPublicPathPrefix(), h._clientEntryOut,
+					h.Wave.GetPublicAssetsURLPrefix(), h._clientEntryOut,
 				),
 			)
 		} else {
@@ -168,8 +182,28 @@ func (h *River) GetLoadersHandler(nestedRouter *mux.NestedRouter) mux.TasksCtxRe
 		if err != nil {
 			Log.Error(fmt.Sprintf("Error executing template: %v\n", err))
 			res.InternalServerError()
+			return
 		}
 
+		// Document headers (e.g., a public Cache-Control) are only applied
+		// once the document has rendered, so that error responses above
+		// never carry them.
+		if useDocumentHeaders {
+			for key, values := range uiRouteData.documentHeaders {
+				w.Header()[key] = values
+			}
+			etag := h.getDocumentETag(buf.Bytes())
+			res.SetHeader("ETag", etag)
+			if ifNoneMatchMatches(r, etag) {
+				res.NotModified()
+				return
+			}
+		}
+
+		if status := uiRouteData.successStatus; status != 0 {
+			res.SetHeader("Content-Type", "text/html")
+			res.SetStatus(status)
+		}
 		res.HTMLBytes(buf.Bytes())
 	})
 
@@ -188,6 +188,63 @@ func (h *Actions) SupportedMethods() map[string]bool {
 type BuildOptions struct {
 	AdHocTypes  []*AdHocType
 	ExtraTSCode string
+
+	// Error shapes that specific actions may return. For each registered
+	// action, River generates `QueryResult<P>` / `MutationResult<P>`
+	// discriminated unions of the form `{ ok: true; data: Output } |
+	// { ok: false; error: Error }` so client error handling can be
+	// type-safe. Actions without a registered error type get `unknown`.
+	ActionErrorTypes []*ActionErrorType
+
+	// If set, River also emits runtime validation schemas (exported as
+	// `riverSchemas`) for each loader output and each action input and
+	// output. Opt-in, as it grows your client bundle.
+	RuntimeSchemas *RuntimeSchemaOptions
+
+	// If true, the build fails when the client route defs and the Go
+	// routers have drifted apart, i.e., when a client route has neither
+	// a loader nor an action registered for its pattern, or when a loader
+	// has no client route component and is not listed in
+	// PassThroughPatterns. Each offending pattern is logged.
+	StrictRouteChecks bool
+	// Loader patterns that intentionally have no client route component.
+	// Only consulted when StrictRouteChecks is true.
+	PassThroughPatterns []string
+
+	// If set, production builds write a sitemap.xml listing every static
+	// (non-dynamic, non-splat) pattern. Dynamic and splat patterns are
+	// included only if SitemapOptions.ExpandDynamicPattern is set. Serve
+	// it at "/sitemap.xml" by adding River.ServeSitemap as a global
+	// middleware, and point crawlers to it with a robots.txt line of the
+	// form "Sitemap: https://example.com/sitemap.xml".
+	Sitemap *SitemapOptions
+
+	// If true, the route manifest (which the client uses to skip server
+	// round trips on navigations that only need client loaders) is split
+	// into one file per static top-level path segment, plus a base file
+	// for the root, the index, and dynamic or splat top-level patterns.
+	// The client then fetches only the shards its navigations touch,
+	// rather than the whole route table up front. Worth enabling only for
+	// very large apps; defaults to a single file.
+	SplitRouteManifest bool
+}
+
+type ActionErrorType struct {
+	// Defaults to "POST".
+	Method  string
+	Pattern string
+	// An instance of the Go type that the action's errors serialize to.
+	TypeInstance any
+	// Optional. Same semantics as AdHocType.TSTypeName.
+	TSTypeName string
+}
+
+type RuntimeSchemaOptions struct {
+	// Either "zod" or "valibot".
+	Library string
+	// Optional. Defaults to the library's package name (e.g., "zod").
+	// Useful for things like "zod/v4" or a re-exporting local module.
+	ImportPath string
 }
 
 func (h *River) Build(o ...BuildOptions) {
@@ -195,12 +252,15 @@ func (h *River) Build(o ...BuildOptions) {
 	if len(o) > 0 {
 		opts = o[0]
 	}
-	h.Wave.BuildWaveWithHook(func(isDev bool) error {
-		return h.buildInner(&buildInnerOptions{
-			isDev:        isDev,
-			buildOptions: &opts,
-		})
-	})
+	h.Wave.BuildWaveWithHooks(
+		func(isDev bool) error {
+			return h.buildInner(&buildInnerOptions{
+				isDev:        isDev,
+				buildOptions: &opts,
+			})
+		},
+		func() error { return h.regenerateTypes(&opts) },
+	)
 }
 
 type Route[I any, O any] = mux.Route[I, O]
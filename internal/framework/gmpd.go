@@ -45,6 +45,8 @@ type ui_data_core struct {
 	ExportKeys      []string `json:"exportKeys,omitempty"`
 	HasRootData     bool     `json:"hasRootData,omitempty"`
 
+	RevalidationTags []string `json:"revalidationTags,omitempty"`
+
 	Params      mux.Params  `json:"params,omitempty"`
 	SplatValues SplatValues `json:"splatValues,omitempty"`
 
@@ -61,6 +63,9 @@ type ui_data_all struct {
 	notFound         bool
 	didRedirect      bool
 	didErr           bool
+	loaderDataETag   string // empty unless every loader declared a data version
+	documentHeaders  http.Header
+	successStatus    int // written by the handler, after all headers are set
 	ui_data_core     *ui_data_core
 	stage_1_head_els []*htmlutil.Element
 	state_2_final    *ui_data_stage_2
@@ -145,8 +150,23 @@ func (h *River) get_ui_data_stage_1(
 		hasRootData = true
 	}
 
+	var documentHeaders http.Header
+	var successStatus int
+
+	// Loader headers and cookies are applied here, for both document and
+	// JSON responses. A success status, however, is handed off to the
+	// handler, as writing it now would commit the response before the
+	// handler's own headers (Cache-Control, ETag, etc.) are set.
 	_merged_response_proxy := response.MergeProxyResponses(_tasks_results.ResponseProxies...)
 	if _merged_response_proxy != nil {
+		documentHeaders = getDocumentHeaders(_merged_response_proxy)
+		_merged_response_proxy.DelHeader(documentHeadersHeaderKey)
+		_merged_response_proxy.DelHeader(loaderDataVersionHeaderKey)
+		_merged_response_proxy.DelHeader(loaderRevalidationTagsHeaderKey)
+		if !_merged_response_proxy.IsError() && !_merged_response_proxy.IsRedirect() {
+			successStatus, _ = _merged_response_proxy.GetStatus()
+			_merged_response_proxy.SetStatus(0)
+		}
 		_merged_response_proxy.ApplyToResponseWriter(w, r)
 
 		if _merged_response_proxy.IsError() {
@@ -225,6 +245,8 @@ func (h *River) get_ui_data_stage_1(
 				ExportKeys:      _cachedItemSubset.ExportKeys[:cutIdx],
 				HasRootData:     hasRootData,
 
+				RevalidationTags: getLoaderRevalidationTags(_tasks_results, cutIdx),
+
 				Params:      _match_results.Params,
 				SplatValues: _match_results.SplatValues,
 
@@ -232,6 +254,7 @@ func (h *River) get_ui_data_stage_1(
 			},
 
 			stage_1_head_els: headEls,
+			successStatus:    successStatus,
 		}
 
 		return ui_data
@@ -254,6 +277,8 @@ func (h *River) get_ui_data_stage_1(
 			ExportKeys:      _cachedItemSubset.ExportKeys,
 			HasRootData:     hasRootData,
 
+			RevalidationTags: getLoaderRevalidationTags(_tasks_results, numberOfLoaders),
+
 			Params:      _match_results.Params,
 			SplatValues: _match_results.SplatValues,
 
@@ -261,6 +286,9 @@ func (h *River) get_ui_data_stage_1(
 		},
 
 		stage_1_head_els: headEls,
+		loaderDataETag:   h.getLoaderDataETag(r, matchedPatterns, _tasks_results),
+		documentHeaders:  documentHeaders,
+		successStatus:    successStatus,
 	}
 
 	return ui_data
@@ -315,7 +343,7 @@ func (h *River) getUIRouteData(
 	hb = append(hb, defaultHeadElsRaw...)
 	hb = append(hb, uiRoutesData.stage_1_head_els...)
 
-	publicPathPrefix := h.Wave.GetPublicPathPrefix()
+	publicPathPrefix := h.Wave.GetPublicAssetsURLPrefix()
 
 	// For client transitions (JSON), AssetManager injects
 	// modulepreload links before head els get rendered,
@@ -355,7 +383,10 @@ func (h *River) getUIRouteData(
 	headEls := headElsInstance.ToSortedAndPreEscapedHeadEls(hb)
 
 	ui_data := &ui_data_all{
-		ui_data_core: uiRoutesData.ui_data_core,
+		ui_data_core:    uiRoutesData.ui_data_core,
+		loaderDataETag:  uiRoutesData.loaderDataETag,
+		documentHeaders: uiRoutesData.documentHeaders,
+		successStatus:   uiRoutesData.successStatus,
 
 		state_2_final: &ui_data_stage_2{
 			SortedAndPreEscapedHeadEls: headEls,
@@ -0,0 +1,75 @@
+package river
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/river-now/river/kit/cryptoutil"
+	"github.com/river-now/river/kit/mux"
+)
+
+// RoutePrefetchEntry describes the assets a client route needs, so the
+// client can warm the network cache (e.g., on link hover) before
+// navigating to it. Deps and CSS are public file names, as with the
+// "deps" and "cssBundles" of a navigation response.
+type RoutePrefetchEntry struct {
+	Deps      []string `json:"deps"`
+	CSS       []string `json:"css"`
+	HasLoader bool     `json:"hasLoader"`
+}
+
+// generatePrefetchMap maps each client route pattern to its prefetch
+// entry. Output file names only exist after Vite has run, so this must
+// be called after toPathsFile_StageTwo has populated each path's Deps.
+// Deps shared with the client entry are omitted, as the client always
+// has those already.
+func (h *River) generatePrefetchMap(pf *PathsFile, nestedRouter *mux.NestedRouter) map[string]*RoutePrefetchEntry {
+	alreadyLoaded := make(map[string]struct{}, len(pf.ClientEntryDeps)+1)
+	alreadyLoaded[pf.ClientEntryOut] = struct{}{}
+	for _, dep := range pf.ClientEntryDeps {
+		alreadyLoaded[dep] = struct{}{}
+	}
+
+	prefetchMap := make(map[string]*RoutePrefetchEntry, len(pf.Paths))
+	for pattern, p := range pf.Paths {
+		entry := &RoutePrefetchEntry{
+			Deps:      []string{},
+			CSS:       []string{},
+			HasLoader: nestedRouter.HasTaskHandler(pattern),
+		}
+		for _, dep := range p.Deps {
+			if _, ok := alreadyLoaded[dep]; ok {
+				continue
+			}
+			entry.Deps = append(entry.Deps, dep)
+			if css, ok := pf.DepToCSSBundleMap[dep]; ok {
+				entry.CSS = append(entry.CSS, css)
+			}
+		}
+		prefetchMap[pattern] = entry
+	}
+	return prefetchMap
+}
+
+func (h *River) writePrefetchMapToDisk(prefetchMap map[string]*RoutePrefetchEntry) (string, error) {
+	prefetchMapJSON, err := json.Marshal(prefetchMap)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling prefetch map: %w", err)
+	}
+
+	// Hash the content to create a stable filename
+	hash := cryptoutil.Sha256Hash(prefetchMapJSON)
+	hashStr := base64.RawURLEncoding.EncodeToString(hash[:8])
+	filename := fmt.Sprintf(riverPrefetchMapPrefix+"%s.json", hashStr)
+
+	// Write to static public dir so it's served automatically
+	outPath := filepath.Join(h.Wave.GetStaticPublicOutDir(), filename)
+	if err := os.WriteFile(outPath, prefetchMapJSON, 0644); err != nil {
+		return "", fmt.Errorf("error writing prefetch map: %w", err)
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,71 @@
+package river
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/river-now/river/kit/mux"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// REVALIDATION TAGS
+/////////////////////////////////////////////////////////////////////
+
+// Carried on each loader's response proxy, and stripped before the
+// merged proxy is applied to the response.
+const loaderRevalidationTagsHeaderKey = "X-River-Loader-Revalidation-Tags"
+
+// Set on action responses and read by the client.
+const RiverRevalidateTagsHeaderKey = "X-River-Revalidate-Tags"
+
+// SetLoaderRevalidationTags declares tags (e.g., "user" or "post:123")
+// describing the data returned by the loader. The tags of all matched
+// loaders are sent to the client along with the loader data, and
+// actions can use RevalidateTags to have the client revalidate the
+// current page only if it depends on one of the given tags.
+func SetLoaderRevalidationTags(rd *LoaderReqData, tags ...string) {
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			rd.ResponseProxy().AddHeader(loaderRevalidationTagsHeaderKey, tag)
+		}
+	}
+}
+
+// RevalidateTags tells the client which loader revalidation tags (see
+// SetLoaderRevalidationTags) the action affects. After the action
+// succeeds, the client automatically revalidates the current page only
+// if any of its loaders declared one of these tags. Calling it with no
+// tags means the action affects no loader data, so the client skips
+// revalidation. Actions that never call it keep the default behavior of
+// revalidating after every successful non-GET submission.
+//
+// Tags must not contain commas.
+func RevalidateTags[I any](rd *ActionReqData[I], tags ...string) {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	rd.ResponseProxy().SetHeader(RiverRevalidateTagsHeaderKey, strings.Join(cleaned, ","))
+}
+
+// Returns the deduplicated tags declared by the loaders at indices up
+// to (but excluding) cutIdx, in order of first appearance.
+func getLoaderRevalidationTags(tasksResults *mux.NestedTasksResults, cutIdx int) []string {
+	var tags []string
+	for i, proxy := range tasksResults.ResponseProxies {
+		if i >= cutIdx {
+			break
+		}
+		if proxy == nil {
+			continue
+		}
+		for _, tag := range proxy.GetHeaders(loaderRevalidationTagsHeaderKey) {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -17,10 +17,12 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	esbuild "github.com/evanw/esbuild/pkg/api"
 	"github.com/river-now/river/kit/cryptoutil"
 	"github.com/river-now/river/kit/id"
+	"github.com/river-now/river/kit/matcher"
 	"github.com/river-now/river/kit/mux"
 	"github.com/river-now/river/kit/stringsutil"
 	"github.com/river-now/river/kit/tsgen"
@@ -33,6 +35,7 @@ const (
 	riverOutPrefix                 = "river_out_"
 	riverVitePrehashedFilePrefix   = riverOutPrefix + "vite_"
 	riverRouteManifestPrefix       = riverOutPrefix + "river_internal_route_manifest_"
+	riverPrefetchMapPrefix         = riverOutPrefix + "river_internal_prefetch_map_"
 	RiverPathsStageOneJSONFileName = "river_paths_stage_1.json"
 	RiverPathsStageTwoJSONFileName = "river_paths_stage_2.json"
 )
@@ -45,10 +48,14 @@ type PathsFile struct {
 	Paths             map[string]*Path `json:"paths"`
 	RouteManifestFile string           `json:"routeManifestFile"`
 
+	// both stages, only if BuildOptions.SplitRouteManifest is true
+	RouteManifestShards map[string]string `json:"routeManifestShards,omitempty"`
+
 	// stage two only
 	ClientEntryOut    string            `json:"clientEntryOut,omitempty"`
 	ClientEntryDeps   []string          `json:"clientEntryDeps,omitempty"`
 	DepToCSSBundleMap map[string]string `json:"depToCSSBundleMap,omitempty"`
+	PrefetchMapFile   string            `json:"prefetchMapFile,omitempty"`
 }
 
 func (h *River) writePathsToDisk_StageOne() error {
@@ -63,11 +70,12 @@ func (h *River) writePathsToDisk_StageOne() error {
 	}
 
 	pathsAsJSON, err := json.MarshalIndent(PathsFile{
-		Stage:             "one",
-		Paths:             h._paths,
-		ClientEntrySrc:    h.Wave.GetRiverClientEntry(),
-		BuildID:           h._buildID,
-		RouteManifestFile: h._routeManifestFile,
+		Stage:               "one",
+		Paths:               h._paths,
+		ClientEntrySrc:      h.Wave.GetRiverClientEntry(),
+		BuildID:             h._buildID,
+		RouteManifestFile:   h._routeManifestFile,
+		RouteManifestShards: h._routeShardFiles,
 	}, "", "\t")
 	if err != nil {
 		return err
@@ -156,7 +164,9 @@ func (h *River) toRollupOptions(entrypoints []string, fileMap map[string]string)
 		path.Join("**", h.Wave.GetPrivateStaticDir()+"/**/*"),
 		path.Join("**", h.Wave.GetConfigFile()),
 		path.Join("**", h.Wave.GetRiverTSGenOutPath()),
-		path.Join("**", h.Wave.GetRiverClientRouteDefsFile()),
+	}
+	if clientRouteDefsFile := h.Wave.GetRiverClientRouteDefsFile(); clientRouteDefsFile != "" {
+		ignoredList = append(ignoredList, path.Join("**", clientRouteDefsFile))
 	}
 
 	mapAsJSON, err := json.MarshalIndent(fileMap, "", "\t") // No initial indent
@@ -167,7 +177,7 @@ func (h *River) toRollupOptions(entrypoints []string, fileMap map[string]string)
 	var buf bytes.Buffer
 	err = vitePluginTemplate.Execute(&buf, map[string]any{
 		"Entrypoints":              entrypoints,
-		"PublicPathPrefix":         h.Wave.GetPublicPathPrefix(),
+		"PublicPathPrefix":         h.Wave.GetPublicAssetsURLPrefix(),
 		"StaticPublicAssetMapJSON": template.HTML(mapAsJSON),
 		"FuncName":                 h.Wave.GetRiverBuildtimePublicURLFuncName(),
 		"IgnoredPatterns":          ignoredList,
@@ -207,7 +217,10 @@ func (h *River) handleViteConfigHelper(extraTS string) error {
 		return err
 	}
 
-	if err = os.WriteFile(target, []byte(rollupOptions), os.ModePerm); err != nil {
+	// Rewriting identical content would still make Vite reload every
+	// module importing it, so unchanged output (e.g., after a change to
+	// a route's module that left its types alone) is left untouched.
+	if _, err = writeFileIfChanged(target, []byte(rollupOptions)); err != nil {
 		Log.Error(fmt.Sprintf("HandleEntrypoints: error writing entrypoints to disk: %s", err))
 		return err
 	}
@@ -409,6 +422,51 @@ func extractRouteCalls(code string) ([]RouteCall, error) {
 	return routes, nil
 }
 
+// extractRouteCallsFromFile reads, transpiles, and parses the client
+// route defs file. Module paths are returned as written in the file
+// (i.e., relative to the file itself).
+func extractRouteCallsFromFile(clientRouteDefsFile string) ([]RouteCall, error) {
+	code, err := os.ReadFile(clientRouteDefsFile)
+	if err != nil {
+		Log.Error(fmt.Sprintf("error reading client route defs file: %s", err))
+		return nil, err
+	}
+	return extractRouteCallsFromCode(code)
+}
+
+func extractRouteCallsFromCode(code []byte) ([]RouteCall, error) {
+	// First, transpile and minify the routes file to ensure consistent import format.
+	// This output is only parsed (never shipped), so its target is fixed.
+	minifyResult := esbuild.Transform(string(code), esbuild.TransformOptions{
+		Format:            esbuild.FormatESModule,
+		Platform:          esbuild.PlatformNode,
+		MinifyWhitespace:  true,
+		MinifySyntax:      true,
+		MinifyIdentifiers: false,
+		Loader:            esbuild.LoaderTSX,
+		Target:            esbuild.ES2020,
+	})
+	if len(minifyResult.Errors) > 0 {
+		for _, msg := range minifyResult.Errors {
+			Log.Error(fmt.Sprintf("esbuild error: %s", msg.Text))
+		}
+		return nil, fmt.Errorf("esbuild errors occurred during transform")
+	}
+	minifiedCode := string(minifyResult.Code)
+
+	// Apply the import transformation to the minified code
+	transformedCode := importRegex.ReplaceAllString(minifiedCode, "$1")
+
+	// Extract route calls from the transformed code
+	routeCalls, err := extractRouteCalls(transformedCode)
+	if err != nil {
+		Log.Error(fmt.Sprintf("error extracting route calls: %s", err))
+		return nil, err
+	}
+
+	return routeCalls, nil
+}
+
 func (h *River) buildInner(opts *buildInnerOptions) error {
 	a := time.Now()
 
@@ -429,66 +487,151 @@ func (h *River) buildInner(opts *buildInnerOptions) error {
 		Log.Info("START building River (PROD)")
 	}
 
-	clientRouteDefsFile := h.Wave.GetRiverClientRouteDefsFile()
+	numRoutes, err := h.loadPaths(opts.buildOptions)
+	if err != nil {
+		// already logged internally in loadPaths
+		return err
+	}
 
-	code, err := os.ReadFile(clientRouteDefsFile)
+	// Remove all files in StaticPublicOutDir starting with riverChunkPrefix or riverEntryPrefix.
+	err = h.cleanStaticPublicOutDir()
 	if err != nil {
-		Log.Error(fmt.Sprintf("error reading client route defs file: %s", err))
+		Log.Error(fmt.Sprintf("error cleaning static public out dir: %s", err))
 		return err
 	}
 
-	// First, transpile and minify the routes file to ensure consistent import format
-	minifyResult := esbuild.Transform(string(code), esbuild.TransformOptions{
-		Format:            esbuild.FormatESModule,
-		Platform:          esbuild.PlatformNode,
-		MinifyWhitespace:  true,
-		MinifySyntax:      true,
-		MinifyIdentifiers: false,
-		Loader:            esbuild.LoaderTSX,
-		Target:            esbuild.ES2020,
-	})
-	if len(minifyResult.Errors) > 0 {
-		for _, msg := range minifyResult.Errors {
-			Log.Error(fmt.Sprintf("esbuild error: %s", msg.Text))
+	manifest := h.generateRouteManifest(h.LoadersRouter().NestedRouter)
+	var manifestShardFiles map[string]string
+	if opts.buildOptions.SplitRouteManifest {
+		var shards map[string]map[string]int
+		manifest, shards = splitRouteManifest(manifest, h.LoadersRouter().NestedRouter)
+		manifestShardFiles = make(map[string]string, len(shards))
+		for segment, shard := range shards {
+			shardFile, err := h.writeRouteManifestToDisk(shard)
+			if err != nil {
+				Log.Error(fmt.Sprintf("error writing route manifest shard: %s", err))
+				return err
+			}
+			manifestShardFiles[segment] = shardFile
 		}
-		return fmt.Errorf("esbuild errors occurred during transform")
 	}
-	minifiedCode := string(minifyResult.Code)
+	manifestFile, err := h.writeRouteManifestToDisk(manifest)
+	if err != nil {
+		Log.Error(fmt.Sprintf("error writing route manifest: %s", err))
+		return err
+	}
+	h._routeManifestFile = manifestFile
+	h._routeShardFiles = manifestShardFiles
 
-	// Apply the import transformation to the minified code
-	transformedCode := importRegex.ReplaceAllString(minifiedCode, "$1")
+	if err = h.writePathsToDisk_StageOne(); err != nil {
+		Log.Error(fmt.Sprintf("error writing paths to disk: %s", err))
+		return err
+	}
 
-	// Extract route calls from the transformed code
-	routeCalls, err := extractRouteCalls(transformedCode)
-	if err != nil {
-		Log.Error(fmt.Sprintf("error extracting route calls: %s", err))
+	if err = h.writeTSGenOutput(opts.buildOptions); err != nil {
+		// already logged internally in writeTSGenOutput
 		return err
 	}
 
-	h._paths = make(map[string]*Path)
+	if !h._isDev && opts.buildOptions.Sitemap != nil {
+		if err := h.writeSitemapToDisk(opts.buildOptions.Sitemap); err != nil {
+			Log.Error(fmt.Sprintf("error writing sitemap: %s", err))
+			return err
+		}
+	}
 
-	routesDir := filepath.Dir(clientRouteDefsFile)
-	for _, routeCall := range routeCalls {
-		// The module path is now a raw string literal, so we need to unquote it if needed
-		unquotedModule := routeCall.Module
+	if !h._isDev {
+		if err := h.Wave.ViteProdBuildWave(); err != nil {
+			Log.Error(fmt.Sprintf("error running vite prod build: %s", err))
+			return err
+		}
+
+		if err := h.postViteProdBuild(); err != nil {
+			Log.Error(fmt.Sprintf("error running post vite prod build: %s", err))
+			return err
+		}
+	}
 
-		resolvedModulePath, err := filepath.Rel(".", filepath.Join(routesDir, unquotedModule))
+	Log.Info("DONE building River",
+		"buildID", h._buildID,
+		"routes found", numRoutes,
+		"duration", time.Since(a),
+	)
+
+	return nil
+}
+
+// Only regenerates the TS gen output file (e.g., after a change to a Go
+// file defining loader or action types), skipping everything else the
+// build does. Run via the dev build hook for watched files with
+// OnlyRegenerateTypes set.
+func (h *River) regenerateTypes(buildOptions *BuildOptions) error {
+	a := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := h.loadPaths(buildOptions); err != nil {
+		// already logged internally in loadPaths
+		return err
+	}
+	if err := h.writeTSGenOutput(buildOptions); err != nil {
+		// already logged internally in writeTSGenOutput
+		return err
+	}
+
+	Log.Info("DONE regenerating River types", "duration", time.Since(a))
+
+	return nil
+}
+
+// Reads the client route defs (and client routes dir) into h._paths,
+// adding pass-through entries for server-only routes. Returns the number
+// of client routes found.
+func (h *River) loadPaths(buildOptions *BuildOptions) (int, error) {
+	clientRouteDefsFile := h.Wave.GetRiverClientRouteDefsFile()
+
+	var routeCalls []RouteCall
+	if clientRouteDefsFile != "" {
+		var err error
+		routeCalls, err = h.extractRouteCallsCached(clientRouteDefsFile)
 		if err != nil {
-			Log.Warn(fmt.Sprintf("could not make module path relative: %s", err))
-			resolvedModulePath = unquotedModule
+			// already logged internally in extractRouteCallsCached
+			return 0, err
+		}
+		routesDir := filepath.Dir(clientRouteDefsFile)
+		for i, routeCall := range routeCalls {
+			resolvedModulePath, err := filepath.Rel(".", filepath.Join(routesDir, routeCall.Module))
+			if err != nil {
+				h.Wave.BuildWarn(fmt.Sprintf("could not make module path relative: %s", err))
+				resolvedModulePath = routeCall.Module
+			}
+			routeCalls[i].Module = filepath.ToSlash(resolvedModulePath)
 		}
-		modulePath := filepath.ToSlash(resolvedModulePath)
+	}
+
+	fsRouteCalls, err := h.getFSRouteCalls(routeCalls)
+	if err != nil {
+		Log.Error(fmt.Sprintf("error reading client routes dir: %s", err))
+		return 0, err
+	}
+	routeCalls = append(routeCalls, fsRouteCalls...)
+
+	h._paths = make(map[string]*Path)
+
+	for _, routeCall := range routeCalls {
+		modulePath := routeCall.Module
 
 		// Check if the module file exists on disk
 		if _, err := os.Stat(modulePath); err != nil {
 			if os.IsNotExist(err) {
 				errMsg := fmt.Sprintf("Component module does not exist: %s (pattern: %s). Did you specify the correct file extension?", modulePath, routeCall.Pattern)
 				Log.Error(errMsg)
-				return errors.New(errMsg)
+				return 0, errors.New(errMsg)
 			}
 			errMsg := fmt.Sprintf("Error accessing component module %s: %v", modulePath, err)
 			Log.Error(errMsg)
-			return errors.New(errMsg)
+			return 0, errors.New(errMsg)
 		}
 
 		h._paths[routeCall.Pattern] = &Path{
@@ -499,6 +642,13 @@ func (h *River) buildInner(opts *buildInnerOptions) error {
 		}
 	}
 
+	if buildOptions.StrictRouteChecks {
+		if err := h.checkRouteDrift(buildOptions.PassThroughPatterns); err != nil {
+			// already logged internally in checkRouteDrift
+			return 0, err
+		}
+	}
+
 	allServerRoutes := h.LoadersRouter().NestedRouter.AllRoutes()
 	for pattern := range allServerRoutes {
 		if _, hasClientRoute := h._paths[pattern]; !hasClientRoute {
@@ -512,31 +662,19 @@ func (h *River) buildInner(opts *buildInnerOptions) error {
 		}
 	}
 
-	// Remove all files in StaticPublicOutDir starting with riverChunkPrefix or riverEntryPrefix.
-	err = cleanStaticPublicOutDir(h.Wave.GetStaticPublicOutDir())
-	if err != nil {
-		Log.Error(fmt.Sprintf("error cleaning static public out dir: %s", err))
-		return err
-	}
-
-	manifest := h.generateRouteManifest(h.LoadersRouter().NestedRouter)
-	manifestFile, err := h.writeRouteManifestToDisk(manifest)
-	if err != nil {
-		Log.Error(fmt.Sprintf("error writing route manifest: %s", err))
-		return err
-	}
-	h._routeManifestFile = manifestFile
-
-	if err = h.writePathsToDisk_StageOne(); err != nil {
-		Log.Error(fmt.Sprintf("error writing paths to disk: %s", err))
-		return err
-	}
+	return len(routeCalls), nil
+}
 
+// Generates the TypeScript types (and Vite config helper) and writes them
+// to the configured TS gen output path. Requires h._paths to be loaded.
+func (h *River) writeTSGenOutput(buildOptions *BuildOptions) error {
 	tsgenOutput, err := h.generateTypeScript(&tsGenOptions{
-		LoadersRouter: h.LoadersRouter().NestedRouter,
-		ActionsRouter: h.ActionsRouter().Router,
-		AdHocTypes:    opts.buildOptions.AdHocTypes,
-		ExtraTSCode:   opts.buildOptions.ExtraTSCode,
+		LoadersRouter:    h.LoadersRouter().NestedRouter,
+		ActionsRouter:    h.ActionsRouter().Router,
+		AdHocTypes:       buildOptions.AdHocTypes,
+		ExtraTSCode:      buildOptions.ExtraTSCode,
+		ActionErrorTypes: buildOptions.ActionErrorTypes,
+		RuntimeSchemas:   buildOptions.RuntimeSchemas,
 	})
 	if err != nil {
 		Log.Error(fmt.Sprintf("error generating TypeScript: %s", err))
@@ -548,24 +686,6 @@ func (h *River) buildInner(opts *buildInnerOptions) error {
 		return err
 	}
 
-	if !h._isDev {
-		if err := h.Wave.ViteProdBuildWave(); err != nil {
-			Log.Error(fmt.Sprintf("error running vite prod build: %s", err))
-			return err
-		}
-
-		if err := h.postViteProdBuild(); err != nil {
-			Log.Error(fmt.Sprintf("error running post vite prod build: %s", err))
-			return err
-		}
-	}
-
-	Log.Info("DONE building River",
-		"buildID", h._buildID,
-		"routes found", len(routeCalls),
-		"duration", time.Since(a),
-	)
-
 	return nil
 }
 
@@ -580,11 +700,12 @@ func (h *River) getViteDevURL() string {
 /////// CLEAN STATIC PUBLIC OUT DIR
 /////////////////////////////////////////////////////////////////////
 
-func cleanStaticPublicOutDir(staticPublicOutDir string) error {
+func (h *River) cleanStaticPublicOutDir() error {
+	staticPublicOutDir := h.Wave.GetStaticPublicOutDir()
 	fileInfo, err := os.Stat(staticPublicOutDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			Log.Warn(fmt.Sprintf("static public out dir does not exist: %s", staticPublicOutDir))
+			h.Wave.BuildWarn(fmt.Sprintf("static public out dir does not exist: %s", staticPublicOutDir))
 			return nil
 		}
 		return err
@@ -610,7 +731,8 @@ func cleanStaticPublicOutDir(staticPublicOutDir string) error {
 			return err
 		}
 		if strings.HasPrefix(filepath.Base(path), riverVitePrehashedFilePrefix) ||
-			strings.HasPrefix(filepath.Base(path), riverRouteManifestPrefix) {
+			strings.HasPrefix(filepath.Base(path), riverRouteManifestPrefix) ||
+			strings.HasPrefix(filepath.Base(path), riverPrefetchMapPrefix) {
 			err = os.Remove(path)
 			if err != nil {
 				return err
@@ -654,7 +776,7 @@ func (h *River) toPathsFile_StageTwo() (*PathsFile, error) {
 	riverClientEntryDeps := []string{}
 	depToCSSBundleMap := make(map[string]string)
 
-	viteManifest, err := viteutil.ReadManifest(h.Wave.GetViteManifestLocation())
+	viteManifest, err := h.readViteManifest(os.DirFS(h.Wave.GetStaticPrivateOutDir()))
 	if err != nil {
 		Log.Error(fmt.Sprintf("error reading vite manifest: %s", err))
 		return nil, err
@@ -707,14 +829,26 @@ func (h *River) toPathsFile_StageTwo() (*PathsFile, error) {
 	htmlContentHash := cryptoutil.Sha256Hash(htmlTemplateContent)
 
 	pf := &PathsFile{
-		Stage:             "two",
-		DepToCSSBundleMap: depToCSSBundleMap,
-		Paths:             h._paths,
-		ClientEntrySrc:    h.Wave.GetRiverClientEntry(),
-		ClientEntryOut:    riverClientEntryOut,
-		ClientEntryDeps:   riverClientEntryDeps,
-		RouteManifestFile: h._routeManifestFile,
+		Stage:               "two",
+		DepToCSSBundleMap:   depToCSSBundleMap,
+		Paths:               h._paths,
+		ClientEntrySrc:      h.Wave.GetRiverClientEntry(),
+		ClientEntryOut:      riverClientEntryOut,
+		ClientEntryDeps:     riverClientEntryDeps,
+		RouteManifestFile:   h._routeManifestFile,
+		RouteManifestShards: h._routeShardFiles,
+	}
+
+	h.warnUnusedCSS(viteManifest, pf)
+
+	prefetchMapFile, err := h.writePrefetchMapToDisk(
+		h.generatePrefetchMap(pf, h.LoadersRouter().NestedRouter),
+	)
+	if err != nil {
+		Log.Error(fmt.Sprintf("error writing prefetch map: %s", err))
+		return nil, err
 	}
+	pf.PrefetchMapFile = prefetchMapFile
 
 	asJSON, err := json.Marshal(pf)
 	if err != nil {
@@ -741,6 +875,31 @@ func (h *River) toPathsFile_StageTwo() (*PathsFile, error) {
 	return pf, nil
 }
 
+// readViteManifest reads the Vite manifest from the provided FS, which
+// must be rooted at the static private out dir (on disk at build time,
+// or the potentially embedded private FS at runtime).
+func (h *River) readViteManifest(privateFS fs.FS) (viteutil.Manifest, error) {
+	rel, err := filepath.Rel(h.Wave.GetStaticPrivateOutDir(), h.Wave.GetViteManifestLocation())
+	if err != nil {
+		return nil, err
+	}
+	return viteutil.ReadManifestFS(privateFS, filepath.ToSlash(rel))
+}
+
+// GetViteManifest reads the Vite manifest from River's private FS. This
+// works with embedded filesystems (e.g., single-binary deploys), as it
+// does not assume the dist directory exists on disk at runtime. Must be
+// called after Init.
+func (h *River) GetViteManifest() (viteutil.Manifest, error) {
+	h.mu.RLock()
+	privateFS := h._privateFS
+	h.mu.RUnlock()
+	if privateFS == nil {
+		return nil, errors.New("private FS not initialized; did you call Init?")
+	}
+	return h.readViteManifest(privateFS)
+}
+
 func (h *River) writeRouteManifestToDisk(manifest map[string]int) (string, error) {
 	manifestJSON, err := json.Marshal(manifest)
 	if err != nil {
@@ -774,3 +933,48 @@ func (h *River) generateRouteManifest(nestedRouter *mux.NestedRouter) map[string
 
 	return manifest
 }
+
+// splitRouteManifest splits a route manifest into one shard per static
+// top-level segment (e.g., "/users" and "/users/:id" both land in the
+// "users" shard) plus a base manifest holding everything else (the root
+// layout, the index, and dynamic or splat top-level patterns), which the
+// client always loads. Because every pattern that could match a path
+// starting with a given static segment is in either the base manifest or
+// that segment's shard, the client only ever needs those two.
+func splitRouteManifest(
+	manifest map[string]int, nestedRouter *mux.NestedRouter,
+) (map[string]int, map[string]map[string]int) {
+	base := make(map[string]int)
+	shards := make(map[string]map[string]int)
+
+	for pattern, hasServerLoader := range manifest {
+		segment := routeManifestShardKey(pattern, nestedRouter)
+		if segment == "" {
+			base[pattern] = hasServerLoader
+			continue
+		}
+		if shards[segment] == nil {
+			shards[segment] = make(map[string]int)
+		}
+		shards[segment][pattern] = hasServerLoader
+	}
+
+	return base, shards
+}
+
+// Returns an empty string for patterns that belong in the base manifest.
+func routeManifestShardKey(pattern string, nestedRouter *mux.NestedRouter) string {
+	segments := matcher.ParseSegments(pattern)
+	if len(segments) == 0 {
+		return ""
+	}
+	first := segments[0]
+	if first == "" || first == nestedRouter.GetExplicitIndexSegment() {
+		return ""
+	}
+	firstRune, _ := utf8.DecodeRuneInString(first)
+	if firstRune == nestedRouter.GetDynamicParamPrefixRune() || firstRune == nestedRouter.GetSplatSegmentRune() {
+		return ""
+	}
+	return first
+}
@@ -0,0 +1,76 @@
+package river
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/river-now/river/kit/mux"
+)
+
+func TestRouteManifestShardKey(t *testing.T) {
+	nestedRouter := mux.NewNestedRouter(&mux.NestedOptions{ExplicitIndexSegment: "_index"})
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"/_index", ""},
+		{"/:id", ""},
+		{"/*", ""},
+		{"/about", "about"},
+		{"/users/_index", "users"},
+		{"/users/:id", "users"},
+		{"/users/*", "users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := routeManifestShardKey(tt.pattern, nestedRouter); got != tt.want {
+				t.Errorf("routeManifestShardKey(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitRouteManifest(t *testing.T) {
+	nestedRouter := mux.NewNestedRouter(&mux.NestedOptions{ExplicitIndexSegment: "_index"})
+	manifest := map[string]int{
+		"":              0,
+		"/_index":       1,
+		"/:slug":        1,
+		"/about":        0,
+		"/users":        1,
+		"/users/_index": 0,
+		"/users/:id":    1,
+		"/docs/*":       0,
+	}
+
+	base, shards := splitRouteManifest(manifest, nestedRouter)
+
+	wantBase := map[string]int{"": 0, "/_index": 1, "/:slug": 1}
+	if !maps.Equal(base, wantBase) {
+		t.Errorf("base = %v, want %v", base, wantBase)
+	}
+	wantShards := map[string]map[string]int{
+		"about": {"/about": 0},
+		"users": {"/users": 1, "/users/_index": 0, "/users/:id": 1},
+		"docs":  {"/docs/*": 0},
+	}
+	if !maps.EqualFunc(shards, wantShards, maps.Equal) {
+		t.Errorf("shards = %v, want %v", shards, wantShards)
+	}
+
+	// Every pattern lands in exactly one place
+	merged := maps.Clone(base)
+	for _, shard := range shards {
+		for pattern, hasServerLoader := range shard {
+			if _, dup := merged[pattern]; dup {
+				t.Errorf("pattern %q appears more than once", pattern)
+			}
+			merged[pattern] = hasServerLoader
+		}
+	}
+	if !maps.Equal(merged, manifest) {
+		t.Errorf("merged = %v, want %v", merged, manifest)
+	}
+}
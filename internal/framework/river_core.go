@@ -85,6 +85,8 @@ type River struct {
 	_rootTemplate      *template.Template
 	_privateFS         fs.FS
 	_routeManifestFile string
+	_routeShardFiles   map[string]string
+	_prefetchMapFile   string
 	_serverAddr        string
 }
 
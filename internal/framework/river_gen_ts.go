@@ -2,9 +2,12 @@ package river
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/river-now/river/kit/genericsutil"
 	"github.com/river-now/river/kit/matcher"
 	"github.com/river-now/river/kit/mux"
 	"github.com/river-now/river/kit/rpc"
@@ -14,10 +17,12 @@ import (
 type AdHocType = rpc.AdHocType
 
 type tsGenOptions struct {
-	LoadersRouter *mux.NestedRouter
-	ActionsRouter *mux.Router
-	AdHocTypes    []*AdHocType
-	ExtraTSCode   string
+	LoadersRouter    *mux.NestedRouter
+	ActionsRouter    *mux.Router
+	AdHocTypes       []*AdHocType
+	ExtraTSCode      string
+	ActionErrorTypes []*ActionErrorType
+	RuntimeSchemas   *RuntimeSchemaOptions
 }
 
 var base = rpc.BaseOptions{
@@ -106,6 +111,15 @@ func (h *River) generateTypeScript(opts *tsGenOptions) (string, error) {
 		seen[path.OriginalPattern] = struct{}{}
 	}
 
+	actionErrorTypes := make(map[string]*ActionErrorType, len(opts.ActionErrorTypes))
+	for _, t := range opts.ActionErrorTypes {
+		method := t.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		actionErrorTypes[method+" "+t.Pattern] = t
+	}
+
 	for _, action := range allActions {
 		method, pattern := action.Method(), action.OriginalPattern()
 		_, isQuery := queryMethods[method]
@@ -138,6 +152,12 @@ func (h *River) generateTypeScript(opts *tsGenOptions) (string, error) {
 				"phantomInputType":  {TypeInstance: action.I()},
 				"phantomOutputType": {TypeInstance: action.O()},
 			}
+			if t, ok := actionErrorTypes[method+" "+pattern]; ok {
+				item.PhantomTypes["phantomErrorType"] = AdHocType{
+					TypeInstance: t.TypeInstance,
+					TSTypeName:   t.TSTypeName,
+				}
+			}
 		}
 		collection = append(collection, item)
 	}
@@ -183,6 +203,7 @@ import type {
 	RiverQueryOutput,
 	RiverQueryPattern,
 	RiverQueryProps,
+	RiverRoutePrefetchEntry,
 } from "river.now/client";
 import type { RiverRouteProps } from "river.now/%s";
 
@@ -190,6 +211,15 @@ export type QueryPattern = RiverQueryPattern<RiverApp>;
 export type QueryProps<P extends QueryPattern> = RiverQueryProps<RiverApp, P>;
 export type QueryInput<P extends QueryPattern> = RiverQueryInput<RiverApp, P>;
 export type QueryOutput<P extends QueryPattern> = RiverQueryOutput<RiverApp, P>;
+export type QueryError<P extends QueryPattern> = Extract<
+	(typeof routes)[number],
+	{ _type: "query"; pattern: P }
+> extends { phantomErrorType: infer E }
+	? E
+	: unknown;
+export type QueryResult<P extends QueryPattern> =
+	| { ok: true; data: QueryOutput<P> }
+	| { ok: false; error: QueryError<P> };
 
 export type MutationPattern = RiverMutationPattern<RiverApp>;
 export type MutationProps<P extends MutationPattern> = RiverMutationProps<
@@ -204,9 +234,24 @@ export type MutationOutput<P extends MutationPattern> = RiverMutationOutput<
 	RiverApp,
 	P
 >;
+export type MutationError<P extends MutationPattern> = Extract<
+	(typeof routes)[number],
+	{ _type: "mutation"; pattern: P }
+> extends { phantomErrorType: infer E }
+	? E
+	: unknown;
+export type MutationResult<P extends MutationPattern> =
+	| { ok: true; data: MutationOutput<P> }
+	| { ok: false; error: MutationError<P> };
 
 export type RouteProps<P extends RiverLoaderPattern<RiverApp>> =
 	RiverRouteProps<RiverApp, P>;
+
+// Shape of the prefetch map River writes alongside prod builds (see
+// makeTypedPrefetch from "river.now/client").
+export type RoutePrefetchMap = {
+	[P in RiverLoaderPattern<RiverApp>]?: RiverRoutePrefetchEntry;
+};
 `,
 		opts.ActionsRouter.MountRoot(),
 		string(actionsDynamicRune),
@@ -217,6 +262,15 @@ export type RouteProps<P extends RiverLoaderPattern<RiverApp>> =
 		uiVariant,
 	))
 
+	if opts.RuntimeSchemas != nil {
+		schemasTS, err := generateRuntimeSchemas(opts.RuntimeSchemas, allLoaders, allActions)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("\n")
+		sb.WriteString(schemasTS)
+	}
+
 	if opts.ExtraTSCode != "" {
 		sb.WriteString("\n")
 		sb.WriteString(opts.ExtraTSCode)
@@ -244,3 +298,64 @@ func extractDynamicParamsFromPattern(pattern string, dynamicRune rune) []string
 func isSplat(pattern string, splatRune rune) bool {
 	return strings.HasSuffix(pattern, "/"+string(splatRune))
 }
+
+func generateRuntimeSchemas(
+	o *RuntimeSchemaOptions, allLoaders map[string]mux.AnyNestedRoute, allActions []mux.AnyRoute,
+) (string, error) {
+	b, err := tsgen.NewSchemaBuilder(tsgen.SchemaLibrary(o.Library), o.ImportPath)
+	if err != nil {
+		return "", err
+	}
+
+	loaderPatterns := slices.Sorted(maps.Keys(allLoaders))
+	var loaders strings.Builder
+	for _, pattern := range loaderPatterns {
+		loader := allLoaders[pattern]
+		if loader == nil {
+			continue
+		}
+		fmt.Fprintf(&loaders, "\t\t%q: { output: %s },\n", pattern, b.Schema(loader.O()))
+	}
+
+	// Queries are keyed by pattern. Mutations are keyed by method, then
+	// pattern, since the same pattern may be registered under several
+	// mutation methods.
+	var queries strings.Builder
+	mutationsByMethod := make(map[string]*strings.Builder)
+	for _, action := range allActions {
+		method, pattern := action.Method(), action.OriginalPattern()
+		var target *strings.Builder
+		switch _, isMutation := mutationMethods[method]; {
+		case isMutation:
+			if mutationsByMethod[method] == nil {
+				mutationsByMethod[method] = &strings.Builder{}
+			}
+			target = mutationsByMethod[method]
+		case method == http.MethodGet:
+			target = &queries
+		default:
+			continue
+		}
+		input := "undefined"
+		if !genericsutil.IsNone(action.I()) {
+			input = b.Schema(action.I())
+		}
+		fmt.Fprintf(target, "\t\t%q: { input: %s, output: %s },\n", pattern, input, b.Schema(action.O()))
+	}
+
+	var mutations strings.Builder
+	for _, method := range slices.Sorted(maps.Keys(mutationsByMethod)) {
+		fmt.Fprintf(&mutations, "\t\t%s: {\n", method)
+		mutations.WriteString(strings.ReplaceAll(mutationsByMethod[method].String(), "\t\t", "\t\t\t"))
+		mutations.WriteString("\t\t},\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.Definitions())
+	sb.WriteString("\nexport const riverSchemas = {\n")
+	sb.WriteString("\tloaders: {\n" + loaders.String() + "\t},\n")
+	sb.WriteString("\tqueries: {\n" + queries.String() + "\t},\n")
+	sb.WriteString("\tmutations: {\n" + mutations.String() + "\t},\n")
+	sb.WriteString("} as const;\n")
+	return sb.String(), nil
+}
@@ -86,7 +86,12 @@ func (h *River) initInner(isDev bool) error {
 		h._depToCSSBundleMap = make(map[string]string)
 	}
 	h._routeManifestFile = pathsFile.RouteManifestFile
-	tmpl, err := template.ParseFS(h._privateFS, h.Wave.GetRiverHTMLTemplateLocation())
+	h._routeShardFiles = pathsFile.RouteManifestShards
+	h._prefetchMapFile = pathsFile.PrefetchMapFile
+	templateLocation := h.Wave.GetRiverHTMLTemplateLocation()
+	tmpl, err := template.New(path.Base(templateLocation)).
+		Funcs(h.Wave.PublicURLFuncMap()).
+		ParseFS(h._privateFS, templateLocation)
 	if err != nil {
 		return fmt.Errorf("error parsing root template: %w", err)
 	}
@@ -0,0 +1,59 @@
+package river
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// checkRouteDrift compares the client-defined paths against the loaders
+// and actions routers. Must be called after h._paths has been populated
+// from the client route defs, but before pass-through paths are added.
+func (h *River) checkRouteDrift(passThroughPatterns []string) error {
+	loaders := h.LoadersRouter().NestedRouter
+	actionPatterns := make(map[string]struct{})
+	for _, action := range h.ActionsRouter().AllRoutes() {
+		actionPatterns[action.OriginalPattern()] = struct{}{}
+	}
+	intentionalPassThroughs := make(map[string]struct{}, len(passThroughPatterns))
+	for _, p := range passThroughPatterns {
+		intentionalPassThroughs[p] = struct{}{}
+	}
+
+	var problems []string
+
+	for pattern := range h._paths {
+		if loaders.HasTaskHandler(pattern) {
+			continue
+		}
+		if _, hasAction := actionPatterns[pattern]; hasAction {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf(
+			"client route %q has no matching loader or action (possibly a dead route)", pattern,
+		))
+	}
+
+	for pattern := range loaders.AllRoutes() {
+		if _, hasClientRoute := h._paths[pattern]; hasClientRoute {
+			continue
+		}
+		if _, isIntentional := intentionalPassThroughs[pattern]; isIntentional {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf(
+			"loader %q has no client route component and is not listed in PassThroughPatterns", pattern,
+		))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	slices.Sort(problems)
+	for _, problem := range problems {
+		Log.Error(fmt.Sprintf("route drift: %s", problem))
+	}
+
+	return fmt.Errorf("strict route checks failed (%d problems):\n\t%s", len(problems), strings.Join(problems, "\n\t"))
+}
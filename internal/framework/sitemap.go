@@ -0,0 +1,185 @@
+package river
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// SITEMAP
+/////////////////////////////////////////////////////////////////////
+
+const (
+	sitemapFileName = "sitemap.xml"
+	sitemapURLPath  = "/" + sitemapFileName
+)
+
+type SitemapOptions struct {
+	// Required. The absolute origin (and optional base path) that URL
+	// paths are joined onto (e.g., "https://example.com").
+	BaseURL string
+	// Optional. Called once for each dynamic or splat pattern (e.g.,
+	// "/users/:id"), returning the concrete URL paths to include for it
+	// (e.g., "/users/1", "/users/2"). If nil, such patterns are omitted.
+	ExpandDynamicPattern func(pattern string) ([]string, error)
+	// Optional. Patterns (as registered) to leave out of the sitemap.
+	ExcludePatterns []string
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// writeSitemapToDisk writes a sitemap.xml (to be served by ServeSitemap)
+// to the river_out dir in the static private out dir, covering every
+// known pattern. Must be called after h._paths has been
+// fully populated (including pass-through paths).
+func (h *River) writeSitemapToDisk(opts *SitemapOptions) error {
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	if baseURL == "" {
+		return errors.New("sitemap: BaseURL is required")
+	}
+
+	nestedRouter := h.LoadersRouter().NestedRouter
+	dynamicRune := nestedRouter.GetDynamicParamPrefixRune()
+	splatRune := nestedRouter.GetSplatSegmentRune()
+	explicitIndexSegment := nestedRouter.GetExplicitIndexSegment()
+
+	excluded := make(map[string]struct{}, len(opts.ExcludePatterns))
+	for _, p := range opts.ExcludePatterns {
+		excluded[p] = struct{}{}
+	}
+
+	patterns := make([]string, 0, len(h._paths))
+	for pattern := range h._paths {
+		if _, isExcluded := excluded[pattern]; !isExcluded {
+			patterns = append(patterns, pattern)
+		}
+	}
+	slices.Sort(patterns)
+
+	seen := make(map[string]struct{}, len(patterns))
+	var urlPaths []string
+	addURLPath := func(urlPath string) {
+		if _, ok := seen[urlPath]; ok {
+			return
+		}
+		seen[urlPath] = struct{}{}
+		urlPaths = append(urlPaths, urlPath)
+	}
+
+	for _, pattern := range patterns {
+		if !isSitemapDynamicPattern(pattern, dynamicRune, splatRune) {
+			addURLPath(sitemapPatternToURLPath(pattern, explicitIndexSegment))
+			continue
+		}
+		if opts.ExpandDynamicPattern == nil {
+			continue
+		}
+		expanded, err := opts.ExpandDynamicPattern(pattern)
+		if err != nil {
+			return fmt.Errorf("sitemap: error expanding pattern %q: %w", pattern, err)
+		}
+		for _, urlPath := range expanded {
+			addURLPath("/" + strings.TrimLeft(urlPath, "/"))
+		}
+	}
+
+	slices.Sort(urlPaths)
+
+	urlSet := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  make([]sitemapURL, 0, len(urlPaths)),
+	}
+	for _, urlPath := range urlPaths {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: baseURL + urlPath})
+	}
+
+	sitemapXML, err := xml.MarshalIndent(urlSet, "", "\t")
+	if err != nil {
+		return fmt.Errorf("sitemap: error marshalling XML: %w", err)
+	}
+
+	outPath := filepath.Join(h.Wave.GetStaticPrivateOutDir(), "river_out", sitemapFileName)
+	if err := os.MkdirAll(filepath.Dir(outPath), os.ModePerm); err != nil {
+		return fmt.Errorf("sitemap: error making out dir: %w", err)
+	}
+	content := append([]byte(xml.Header), sitemapXML...)
+	if err := os.WriteFile(outPath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("sitemap: error writing %s: %w", outPath, err)
+	}
+
+	Log.Info("Wrote sitemap", "path", outPath, "urls", len(urlPaths))
+
+	return nil
+}
+
+// ServeSitemap returns a middleware that serves the sitemap written by
+// the production build (see BuildOptions.Sitemap) at "/sitemap.xml".
+// Per the sitemap protocol, a sitemap may only list URLs under its own
+// location, so it is served from the site root (not from the public
+// assets prefix or origin). Requests fall through to the next handler
+// when no sitemap was built (e.g., in dev).
+func (h *River) ServeSitemap() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			isGetOrHead := r.Method == http.MethodGet || r.Method == http.MethodHead
+			if !isGetOrHead || r.URL.Path != sitemapURLPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.mu.RLock()
+			privateFS := h._privateFS
+			h.mu.RUnlock()
+			if privateFS == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			content, err := fs.ReadFile(privateFS, path.Join("river_out", sitemapFileName))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write(content)
+		})
+	}
+}
+
+func isSitemapDynamicPattern(pattern string, dynamicRune, splatRune rune) bool {
+	for segment := range strings.SplitSeq(pattern, "/") {
+		if segment == string(splatRune) || strings.HasPrefix(segment, string(dynamicRune)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Index routes render at their parent's URL, so "/foo/_index" (or
+// "/foo/" when not using an explicit index segment) maps to "/foo".
+func sitemapPatternToURLPath(pattern, explicitIndexSegment string) string {
+	if explicitIndexSegment != "" {
+		pattern = strings.TrimSuffix(pattern, "/"+explicitIndexSegment)
+	}
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if pattern == "" {
+		return "/"
+	}
+	return pattern
+}
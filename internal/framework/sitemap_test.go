@@ -0,0 +1,103 @@
+package river
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIsSitemapDynamicPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"/", false},
+		{"/about", false},
+		{"/users/_index", false},
+		{"/users/:id", true},
+		{"/users/:id/posts", true},
+		{"/files/*", true},
+		{"/*", true},
+		{"/a:b", false},
+		{"/files/*.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := isSitemapDynamicPattern(tt.pattern, ':', '*'); got != tt.want {
+				t.Errorf("isSitemapDynamicPattern(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSitemapPatternToURLPath(t *testing.T) {
+	tests := []struct {
+		pattern              string
+		explicitIndexSegment string
+		want                 string
+	}{
+		{"", "", "/"},
+		{"/", "", "/"},
+		{"/about", "", "/about"},
+		{"/users/", "", "/users"},
+		{"/_index", "_index", "/"},
+		{"/users/_index", "_index", "/users"},
+		{"/users/_index", "", "/users/_index"},
+		{"/users/_indexes", "_index", "/users/_indexes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.explicitIndexSegment, func(t *testing.T) {
+			if got := sitemapPatternToURLPath(tt.pattern, tt.explicitIndexSegment); got != tt.want {
+				t.Errorf("sitemapPatternToURLPath(%q, %q) = %q, want %q", tt.pattern, tt.explicitIndexSegment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeSitemap(t *testing.T) {
+	const sitemap = `<?xml version="1.0" encoding="UTF-8"?><urlset></urlset>`
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	serve := func(h *River, method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeSitemap()(next).ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	withSitemap := &River{_privateFS: fstest.MapFS{
+		"river_out/sitemap.xml": &fstest.MapFile{Data: []byte(sitemap)},
+	}}
+
+	t.Run("ServesFromSiteRoot", func(t *testing.T) {
+		w := serve(withSitemap, http.MethodGet, "/sitemap.xml")
+		if w.Code != http.StatusOK || w.Body.String() != sitemap {
+			t.Errorf("expected sitemap, got %d %q", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+			t.Errorf("unexpected Content-Type %q", ct)
+		}
+	})
+
+	t.Run("PassesThroughOtherRequests", func(t *testing.T) {
+		for _, tc := range []struct{ method, target string }{
+			{http.MethodGet, "/public/sitemap.xml"},
+			{http.MethodPost, "/sitemap.xml"},
+			{http.MethodGet, "/"},
+		} {
+			if w := serve(withSitemap, tc.method, tc.target); w.Code != http.StatusTeapot {
+				t.Errorf("%s %s: expected pass-through, got %d", tc.method, tc.target, w.Code)
+			}
+		}
+	})
+
+	t.Run("PassesThroughWithoutSitemap", func(t *testing.T) {
+		h := &River{_privateFS: fstest.MapFS{}}
+		if w := serve(h, http.MethodGet, "/sitemap.xml"); w.Code != http.StatusTeapot {
+			t.Errorf("expected pass-through, got %d", w.Code)
+		}
+	})
+}
@@ -19,6 +19,9 @@ type SSRInnerHTMLInput struct {
 	PublicPathPrefix string
 	DeploymentID     string
 	RouteManifestURL string
+	PrefetchMapURL   string
+
+	RouteManifestShardURLs map[string]string
 
 	*ui_data_core
 
@@ -45,12 +48,15 @@ x.loadersData = {{.LoadersData}};
 x.importURLs = {{.ImportURLs}};
 x.exportKeys = {{.ExportKeys}};
 x.hasRootData = {{.HasRootData}};
+x.revalidationTags = {{.RevalidationTags}};
 x.params = {{.Params}};
 x.splatValues = {{.SplatValues}};
 x.deps = {{.Deps}};
 x.cssBundles = {{.CSSBundles}};
 x.deploymentID = {{.DeploymentID}};
 x.routeManifestURL = {{.RouteManifestURL}};
+x.routeManifestShardURLs = {{.RouteManifestShardURLs}};
+x.prefetchMapURL = {{.PrefetchMapURL}};
 </script>`
 
 var ssrInnerTmpl = template.Must(template.New("ssr").Parse(ssrInnerHTMLTmplStr))
@@ -60,6 +66,29 @@ type GetSSRInnerHTMLOutput struct {
 	Sha256Hash string
 }
 
+// Empty in dev, as the prefetch map is only generated for prod builds.
+// Like the route manifest, it is kept same-origin.
+func (h *River) getPrefetchMapURL() string {
+	if h._prefetchMapFile == "" {
+		return ""
+	}
+	return path.Join(h.Wave.GetPublicPathPrefix(), h._prefetchMapFile)
+}
+
+// Nil unless the route manifest is split (see
+// BuildOptions.SplitRouteManifest). Kept same-origin, like the base
+// route manifest.
+func (h *River) getRouteManifestShardURLs() map[string]string {
+	if len(h._routeShardFiles) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(h._routeShardFiles))
+	for segment, file := range h._routeShardFiles {
+		urls[segment] = path.Join(h.Wave.GetPublicPathPrefix(), file)
+	}
+	return urls
+}
+
 func (h *River) getSSRInnerHTML(routeData *final_ui_data) (*GetSSRInnerHTMLOutput, error) {
 	var htmlBuilder strings.Builder
 
@@ -69,11 +98,16 @@ func (h *River) getSSRInnerHTML(routeData *final_ui_data) (*GetSSRInnerHTMLOutpu
 		IsDev:            h._isDev,
 		ViteDevURL:       routeData.ViteDevURL,
 		BuildID:          h._buildID,
-		PublicPathPrefix: h.Wave.GetPublicPathPrefix(),
+		PublicPathPrefix: h.Wave.GetPublicAssetsURLPrefix(),
+		// Kept same-origin (even if using a PublicAssetsOrigin),
+		// as the client fetches it directly.
 		RouteManifestURL: path.Join(
 			h.Wave.GetPublicPathPrefix(),
 			h._routeManifestFile,
 		),
+		PrefetchMapURL: h.getPrefetchMapURL(),
+
+		RouteManifestShardURLs: h.getRouteManifestShardURLs(),
 
 		ui_data_core: routeData.ui_data_core,
 
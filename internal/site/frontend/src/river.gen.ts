@@ -99,6 +99,7 @@ import type {
 	RiverQueryOutput,
 	RiverQueryPattern,
 	RiverQueryProps,
+	RiverRoutePrefetchEntry,
 } from "river.now/client";
 import type { RiverRouteProps } from "river.now/solid";
 
@@ -124,6 +125,12 @@ export type MutationOutput<P extends MutationPattern> = RiverMutationOutput<
 export type RouteProps<P extends RiverLoaderPattern<RiverApp>> =
 	RiverRouteProps<RiverApp, P>;
 
+// Shape of the prefetch map River writes alongside prod builds (see
+// makeTypedPrefetch from "river.now/client").
+export type RoutePrefetchMap = {
+	[P in RiverLoaderPattern<RiverApp>]?: RiverRoutePrefetchEntry;
+};
+
 /////////////////////////////////////////////////////////////////////
 /////// River Vite Config:
 /////////////////////////////////////////////////////////////////////
@@ -15,6 +15,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ const nonceSize = 16 // Size, in bytes, of the random nonce used in the CSRF tok
 type payload struct {
 	Nonce         []byte `json:"n"`
 	ExpiresAtUnix int64  `json:"e"`
+	IssuedAtUnix  int64  `json:"i,omitempty"`
 	SessionID     string `json:"s,omitempty"`
 }
 
@@ -53,14 +55,37 @@ type ProtectorConfig struct {
 	// Defaults to "csrf_token".
 	CookieName string
 	HeaderName string // Defaults to "X-CSRF-Token"
+	// Additional header names from which submitted tokens are accepted (e.g.,
+	// a legacy header while clients migrate to HeaderName). HeaderName remains
+	// the primary name; these are only consulted during validation.
+	AcceptHeaderNames []string
+	// Optional callback invoked whenever a request is blocked, before the
+	// 403 is written (e.g., to record metrics or feed a rate limiter). It
+	// runs synchronously on the request goroutine, so keep it cheap.
+	OnReject func(r *http.Request, reason RejectReason)
 }
 
+// RejectReason describes why Protector.Middleware blocked a request.
+type RejectReason string
+
+const (
+	RejectReasonBadOrigin       RejectReason = "bad_origin"       // Origin/Referer not allowed or malformed
+	RejectReasonCookieMissing   RejectReason = "cookie_missing"   // No token cookie on the request
+	RejectReasonCookieEmpty     RejectReason = "cookie_empty"     // Token cookie present but empty
+	RejectReasonInvalidToken    RejectReason = "invalid_token"    // Token cookie could not be decrypted
+	RejectReasonExpired         RejectReason = "expired"          // Token cookie invalid or expired
+	RejectReasonTokenMissing    RejectReason = "token_missing"    // No token in the request headers
+	RejectReasonTokenMismatch   RejectReason = "token_mismatch"   // Submitted token does not match cookie
+	RejectReasonSessionMismatch RejectReason = "session_mismatch" // Token bound to a different session
+)
+
 type Protector struct {
 	cfg                   ProtectorConfig
 	isDev                 bool
 	cookie                *cookies.SecureCookie[payload]
 	allowedOrigins        map[string]bool
 	hasOriginRestrictions bool
+	headerNames           []string // HeaderName first, then AcceptHeaderNames
 }
 
 func NewProtector(cfg ProtectorConfig) *Protector {
@@ -105,12 +130,20 @@ func NewProtector(cfg ProtectorConfig) *Protector {
 		normalized[normalizedOrigin] = true
 	}
 
+	headerNames := []string{http.CanonicalHeaderKey(cfg.HeaderName)}
+	for _, name := range cfg.AcceptHeaderNames {
+		if name = http.CanonicalHeaderKey(name); name != "" && !slices.Contains(headerNames, name) {
+			headerNames = append(headerNames, name)
+		}
+	}
+
 	return &Protector{
 		cfg:                   cfg,
 		isDev:                 isDev,
 		cookie:                cookie,
 		allowedOrigins:        normalized,
 		hasOriginRestrictions: len(normalized) > 0,
+		headerNames:           headerNames,
 	}
 }
 
@@ -131,8 +164,11 @@ func (p *Protector) Middleware(next http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 			return
 		}
-		err, shouldSelfHeal := p.applyCSRFProtection(r)
+		err, reason, shouldSelfHeal := p.applyCSRFProtection(r)
 		if err != nil {
+			if p.cfg.OnReject != nil {
+				p.cfg.OnReject(r, reason)
+			}
 			rp := response.NewProxy()
 			if shouldSelfHeal {
 				if err := p.CycleTokenWithProxy(rp, p.cfg.GetSessionID(r)); err != nil {
@@ -169,6 +205,42 @@ func (p *Protector) CycleTokenWithWriter(w http.ResponseWriter, r *http.Request,
 	return nil
 }
 
+// TokenInfo describes the CSRF token currently held by a client.
+type TokenInfo struct {
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	SessionID string // Empty if the token is not bound to a session
+}
+
+// TokenInfo decodes the CSRF token cookie on the request and returns its
+// metadata, so that clients can proactively refresh a token before it
+// expires rather than guessing the TTL. Returns an error if the cookie is
+// missing, cannot be decrypted, or has expired. It does not check the
+// token's session binding against the current session.
+func (p *Protector) TokenInfo(r *http.Request) (*TokenInfo, error) {
+	cookie, err := r.Cookie(p.cookie.Name())
+	if err != nil || cookie.Value == "" {
+		return nil, errors.New("csrf: token cookie missing")
+	}
+	payload, err := p.cookie.Get(r)
+	if err != nil {
+		return nil, fmt.Errorf("csrf: invalid token: %w", err)
+	}
+	if !payload.isValid() {
+		return nil, errors.New("csrf: token invalid or expired")
+	}
+	expiresAt := time.Unix(payload.ExpiresAtUnix, 0)
+	issuedAt := time.Unix(payload.IssuedAtUnix, 0)
+	if payload.IssuedAtUnix == 0 { // Tokens issued before IssuedAtUnix was recorded
+		issuedAt = expiresAt.Add(-p.cfg.TokenTTL)
+	}
+	return &TokenInfo{
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		SessionID: payload.SessionID,
+	}, nil
+}
+
 func (p *Protector) issueCSRFTokenIfNeeded(rp *response.Proxy, r *http.Request) error {
 	payload, err := p.cookie.Get(r)
 	if err == nil && payload.isValid() {
@@ -180,36 +252,41 @@ func (p *Protector) issueCSRFTokenIfNeeded(rp *response.Proxy, r *http.Request)
 	return p.CycleTokenWithProxy(rp, p.cfg.GetSessionID(r))
 }
 
-func (p *Protector) applyCSRFProtection(r *http.Request) (err error, shouldSelfheal bool) {
+func (p *Protector) applyCSRFProtection(r *http.Request) (err error, reason RejectReason, shouldSelfheal bool) {
 	if err := p.validateOrigin(r); err != nil {
-		return fmt.Errorf("origin validation failed: %w", err), false
+		return fmt.Errorf("origin validation failed: %w", err), RejectReasonBadOrigin, false
 	}
 	cookie, err := r.Cookie(p.cookie.Name())
 	if err != nil {
-		return errors.New("csrf token cookie missing"), true
+		return errors.New("csrf token cookie missing"), RejectReasonCookieMissing, true
 	}
 	if cookie.Value == "" {
-		return errors.New("csrf token cookie empty"), false
+		return errors.New("csrf token cookie empty"), RejectReasonCookieEmpty, false
 	}
 	payload, err := p.cookie.Get(r)
 	if err != nil {
-		return fmt.Errorf("invalid csrf token: %w", err), true
+		return fmt.Errorf("invalid csrf token: %w", err), RejectReasonInvalidToken, true
 	}
 	if !payload.isValid() {
-		return errors.New("csrf token invalid or expired"), true
+		return errors.New("csrf token invalid or expired"), RejectReasonExpired, true
+	}
+	var submittedValue string
+	for _, name := range p.headerNames {
+		if submittedValue = r.Header.Get(name); submittedValue != "" {
+			break
+		}
 	}
-	submittedValue := r.Header.Get(p.cfg.HeaderName)
 	if submittedValue == "" {
-		return errors.New("csrf token missing from request"), false
+		return errors.New("csrf token missing from request"), RejectReasonTokenMissing, false
 	}
 	if subtle.ConstantTimeCompare([]byte(submittedValue), []byte(cookie.Value)) != 1 {
-		return errors.New("csrf token mismatch"), false
+		return errors.New("csrf token mismatch"), RejectReasonTokenMismatch, false
 	}
 	currentSessionID := p.cfg.GetSessionID(r)
 	if subtle.ConstantTimeCompare([]byte(payload.SessionID), []byte(currentSessionID)) != 1 {
-		return errors.New("csrf token session mismatch"), true
+		return errors.New("csrf token session mismatch"), RejectReasonSessionMismatch, true
 	}
-	return nil, false
+	return nil, "", false
 }
 
 func (p *Protector) validateOrigin(r *http.Request) error {
@@ -242,9 +319,11 @@ func (p *Protector) newCSRFCookie(sessionID string) (*http.Cookie, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate secure random bytes: %w", err)
 	}
+	now := time.Now()
 	payload := payload{
 		Nonce:         nonce,
-		ExpiresAtUnix: time.Now().Add(p.cfg.TokenTTL).Unix(),
+		ExpiresAtUnix: now.Add(p.cfg.TokenTTL).Unix(),
+		IssuedAtUnix:  now.Unix(),
 		SessionID:     sessionID,
 	}
 	return p.cookie.New(payload)
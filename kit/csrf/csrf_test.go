@@ -212,6 +212,8 @@ func TestMiddleware_GETRequest(t *testing.T) {
 
 func TestMiddleware_POSTRequest(t *testing.T) {
 	p := createTestProtector(t, []string{"https://example.com"})
+	var gotReason RejectReason
+	p.cfg.OnReject = func(r *http.Request, reason RejectReason) { gotReason = reason }
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -239,6 +241,7 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 		origin     string
 		referer    string
 		wantStatus int
+		wantReason RejectReason
 	}{
 		{
 			name:       "valid POST with token and origin",
@@ -262,6 +265,7 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 			token:      token,
 			origin:     "https://example.com",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonCookieMissing,
 		},
 		{
 			name:       "POST without token header",
@@ -269,6 +273,7 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 			cookie:     cookie,
 			origin:     "https://example.com",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonTokenMissing,
 		},
 		{
 			name:       "POST with wrong token",
@@ -277,6 +282,7 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 			token:      "wrong-token",
 			origin:     "https://example.com",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonTokenMismatch,
 		},
 		{
 			name:       "POST with wrong origin",
@@ -285,6 +291,7 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 			token:      token,
 			origin:     "https://evil.com",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonBadOrigin,
 		},
 		{
 			name:       "POST with wrong referer",
@@ -293,6 +300,7 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 			token:      token,
 			referer:    "https://evil.com/page",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonBadOrigin,
 		},
 		{
 			name:       "PUT request",
@@ -336,12 +344,16 @@ func TestMiddleware_POSTRequest(t *testing.T) {
 				req.Header.Set("Referer", tt.referer)
 			}
 
+			gotReason = ""
 			rr := httptest.NewRecorder()
 			p.Middleware(handler).ServeHTTP(rr, req)
 
 			if rr.Code != tt.wantStatus {
 				t.Errorf("Expected status %d, got %d", tt.wantStatus, rr.Code)
 			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Expected reject reason %q, got %q", tt.wantReason, gotReason)
+			}
 		})
 	}
 }
@@ -425,6 +437,66 @@ func TestCycleTokenWithProxy(t *testing.T) {
 	}
 }
 
+func TestTokenInfo(t *testing.T) {
+	p := createTestProtector(t, nil)
+
+	t.Run("valid token", func(t *testing.T) {
+		rp := response.NewProxy()
+		before := time.Now().Add(-time.Second)
+		if err := p.CycleTokenWithProxy(rp, "session-abc"); err != nil {
+			t.Fatalf("CycleTokenWithProxy failed: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		rp.ApplyToResponseWriter(rr, req)
+		req.AddCookie(extractCSRFCookie(rr, p.cookie.Name()))
+
+		info, err := p.TokenInfo(req)
+		if err != nil {
+			t.Fatalf("TokenInfo failed: %v", err)
+		}
+		if info.SessionID != "session-abc" {
+			t.Errorf("Expected session ID %q, got %q", "session-abc", info.SessionID)
+		}
+		if info.IssuedAt.Before(before) || info.IssuedAt.After(time.Now()) {
+			t.Errorf("Unexpected IssuedAt: %v", info.IssuedAt)
+		}
+		if got := info.ExpiresAt.Sub(info.IssuedAt); got != p.cfg.TokenTTL {
+			t.Errorf("Expected ExpiresAt - IssuedAt to equal TokenTTL (%v), got %v", p.cfg.TokenTTL, got)
+		}
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if _, err := p.TokenInfo(req); err == nil {
+			t.Error("Expected error for missing cookie")
+		}
+	})
+
+	t.Run("invalid cookie", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(&http.Cookie{Name: p.cookie.Name(), Value: "SGVsbG8="})
+		if _, err := p.TokenInfo(req); err == nil {
+			t.Error("Expected error for invalid cookie")
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		cookie, err := p.cookie.New(payload{
+			Nonce:         []byte("0123456789abcdef"),
+			ExpiresAtUnix: time.Now().Add(-time.Minute).Unix(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create cookie: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(cookie)
+		if _, err := p.TokenInfo(req); err == nil {
+			t.Error("Expected error for expired token")
+		}
+	})
+}
+
 func TestCycleTokenWithWriter(t *testing.T) {
 	p := createTestProtector(t, nil)
 
@@ -910,9 +982,68 @@ func TestCustomHeaderName(t *testing.T) {
 	}
 }
 
+// TestAcceptHeaderNames tests accepting tokens from additional header names
+func TestAcceptHeaderNames(t *testing.T) {
+	p := NewProtector(ProtectorConfig{
+		CookieManager:     createTestCookieManager(t),
+		GetSessionID:      func(r *http.Request) string { return "" },
+		HeaderName:        "X-New-CSRF-Token",
+		AcceptHeaderNames: []string{"x-legacy-csrf-token", "X-New-CSRF-Token"},
+	})
+
+	if len(p.headerNames) != 2 || p.headerNames[0] != "X-New-Csrf-Token" {
+		t.Fatalf("Expected primary header first and duplicates removed, got %v", p.headerNames)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	getReq := httptest.NewRequest("GET", "/", nil)
+	getRR := httptest.NewRecorder()
+	p.Middleware(handler).ServeHTTP(getRR, getReq)
+
+	cookie := extractCSRFCookie(getRR, p.cookie.Name())
+	token := extractTokenFromCookie(cookie)
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"primary header", map[string]string{"X-New-CSRF-Token": token}, http.StatusOK},
+		{"legacy header", map[string]string{"X-Legacy-CSRF-Token": token}, http.StatusOK},
+		{"unlisted header", map[string]string{"X-CSRF-Token": token}, http.StatusForbidden},
+		{
+			"primary header takes precedence",
+			map[string]string{"X-New-CSRF-Token": "wrong", "X-Legacy-CSRF-Token": token},
+			http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			req.AddCookie(cookie)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			rr := httptest.NewRecorder()
+			p.Middleware(handler).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
+
 // TestInvalidTokenPayload tests handling of corrupted tokens
 func TestInvalidTokenPayload(t *testing.T) {
 	p := createTestProtector(t, nil)
+	var gotReason RejectReason
+	p.cfg.OnReject = func(r *http.Request, reason RejectReason) { gotReason = reason }
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -922,26 +1053,31 @@ func TestInvalidTokenPayload(t *testing.T) {
 		name       string
 		tokenValue string
 		wantStatus int
+		wantReason RejectReason
 	}{
 		{
 			name:       "empty token",
 			tokenValue: "",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonCookieEmpty,
 		},
 		{
 			name:       "invalid base64",
 			tokenValue: "not-valid-base64!@#$",
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonInvalidToken,
 		},
 		{
 			name:       "truncated token",
 			tokenValue: "SGVsbG8=", // Valid base64 but not a valid encrypted payload
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonInvalidToken,
 		},
 		{
 			name:       "random data",
 			tokenValue: base64.StdEncoding.EncodeToString([]byte("random data that's not encrypted")),
 			wantStatus: http.StatusForbidden,
+			wantReason: RejectReasonInvalidToken,
 		},
 	}
 
@@ -954,12 +1090,16 @@ func TestInvalidTokenPayload(t *testing.T) {
 			})
 			req.Header.Set(p.cfg.HeaderName, tt.tokenValue)
 
+			gotReason = ""
 			rr := httptest.NewRecorder()
 			p.Middleware(handler).ServeHTTP(rr, req)
 
 			if rr.Code != tt.wantStatus {
 				t.Errorf("Expected status %d for %s, got %d", tt.wantStatus, tt.name, rr.Code)
 			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Expected reject reason %q for %s, got %q", tt.wantReason, tt.name, gotReason)
+			}
 		})
 	}
 }
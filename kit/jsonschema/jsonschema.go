@@ -36,11 +36,14 @@ type Def struct {
 	AllOf               []any
 	Items               Entry
 	Enum                []string
+	// Other types also accepted (e.g., TypeString for a number that may
+	// instead be given as a string to be parsed later).
+	AltTypes []string
 }
 
 type Entry struct {
 	Schema      string   `json:"$schema,omitempty"`
-	Type        string   `json:"type"`
+	Type        any      `json:"type"` // a string, or a []string of accepted types
 	Description string   `json:"description,omitempty"`
 	Default     any      `json:"default,omitempty"`
 	Required    []string `json:"required,omitempty"`
@@ -57,8 +60,12 @@ type IfThen struct {
 }
 
 func ToJSONSchema(sd Def) Entry {
+	var typ any = sd.Type
+	if len(sd.AltTypes) > 0 {
+		typ = append([]string{sd.Type}, sd.AltTypes...)
+	}
 	x := Entry{
-		Type:        sd.Type,
+		Type:        typ,
 		Description: sd.descStr(),
 		Required:    sd.RequiredChildren,
 		Default:     sd.Default,
@@ -67,7 +74,7 @@ func ToJSONSchema(sd Def) Entry {
 		Properties:  sd.Properties,
 		Enum:        sd.Enum,
 	}
-	if sd.Items.Type != "" {
+	if sd.Items.Type != nil && sd.Items.Type != "" {
 		x.Items = sd.Items
 	}
 	return x
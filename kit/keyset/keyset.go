@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/river-now/river/kit/bytesutil"
 	"github.com/river-now/river/kit/cryptoutil"
@@ -102,6 +103,45 @@ func Attempt[R any](ks *Keyset, f func(cryptoutil.Key32) (R, error)) (R, error)
 	return zeroR, errors.Join(errs...)
 }
 
+/////////////////////////////////////////////////////////////////////
+/////// SIGN / VERIFY
+/////////////////////////////////////////////////////////////////////
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// Sign returns an HMAC-SHA-256 signature of msg using the first
+// (latest) key in the keyset. Useful for signed URLs, cookies, and
+// similar. Prefer signing with a purpose-specific keyset derived via
+// HKDF rather than with a root keyset directly.
+func (ks *Keyset) Sign(msg []byte) ([]byte, error) {
+	first, err := ks.First()
+	if err != nil {
+		return nil, err
+	}
+	return cryptoutil.HmacSha256(msg, first[:])
+}
+
+// Verify reports whether sig is a valid HMAC-SHA-256 signature of msg
+// under any key in the keyset (tried latest-first), so signatures made
+// before a key rotation remain valid as long as the prior key is still
+// in the keyset. Comparisons are constant-time.
+func (ks *Keyset) Verify(msg, sig []byte) bool {
+	if ks == nil {
+		return false
+	}
+	_, err := Attempt(ks, func(k cryptoutil.Key32) (bool, error) {
+		ok, err := cryptoutil.ValidateHmacSha256(msg, k[:], sig)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, errInvalidSignature
+		}
+		return true, nil
+	})
+	return err == nil
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// HKDF
 /////////////////////////////////////////////////////////////////////
@@ -133,11 +173,7 @@ func LoadRootKeyset(latestFirstEnvVarNames ...string) (*Keyset, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error loading root secrets: %w", err)
 	}
-	keyset, err := RootSecretsToRootKeyset(rootSecrets)
-	if err != nil {
-		return nil, fmt.Errorf("error converting root secrets to keyset: %w", err)
-	}
-	return keyset, nil
+	return rootSecretsToRootKeysetWrapped(rootSecrets)
 }
 
 // RootSecretsToRootKeyset converts a slice of base64-encoded root
@@ -187,6 +223,85 @@ func LoadRootSecrets(latestFirstEnvVarNames ...string) (RootSecrets, error) {
 	return rootSecrets, nil
 }
 
+// Pass in a latest-first slice of file paths, each containing a single
+// base64-encoded 32-byte root secret (surrounding whitespace, such as a
+// trailing newline, is ignored). Useful when secrets are mounted as files
+// (e.g., Docker or Kubernetes secrets).
+// Example: LoadRootSecretsFromFiles("/run/secrets/current", "/run/secrets/previous")
+func LoadRootSecretsFromFiles(latestFirstFilePaths ...string) (RootSecrets, error) {
+	if len(latestFirstFilePaths) == 0 {
+		return nil, fmt.Errorf("at least 1 file path is required")
+	}
+	rootSecrets := make(RootSecrets, 0, len(latestFirstFilePaths))
+	for i, filePath := range latestFirstFilePaths {
+		if filePath == "" {
+			return nil, fmt.Errorf("file path at index %d is empty", i)
+		}
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading secret file %s: %w", filePath, err)
+		}
+		secret := strings.TrimSpace(string(contents))
+		if secret == "" {
+			return nil, fmt.Errorf("secret file %s is empty", filePath)
+		}
+		rootSecrets = append(rootSecrets, RootSecret(secret))
+	}
+	return rootSecrets, nil
+}
+
+// Pass in a function returning a latest-first slice of base64-encoded
+// 32-byte root secrets. Use this to wire up secret managers (e.g., AWS
+// Secrets Manager or GCP Secret Manager).
+func LoadRootSecretsFrom(loader func() ([]RootSecret, error)) (RootSecrets, error) {
+	if loader == nil {
+		return nil, fmt.Errorf("root secrets loader is nil")
+	}
+	secrets, err := loader()
+	if err != nil {
+		return nil, fmt.Errorf("error running root secrets loader: %w", err)
+	}
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("root secrets loader returned no secrets")
+	}
+	rootSecrets := make(RootSecrets, 0, len(secrets))
+	for i, secret := range secrets {
+		if secret == "" {
+			return nil, fmt.Errorf("secret at index %d is empty", i)
+		}
+		rootSecrets = append(rootSecrets, secret)
+	}
+	return rootSecrets, nil
+}
+
+// Like LoadRootKeyset, but reads the root secrets from files.
+// See LoadRootSecretsFromFiles for details.
+func LoadRootKeysetFromFiles(latestFirstFilePaths ...string) (*Keyset, error) {
+	rootSecrets, err := LoadRootSecretsFromFiles(latestFirstFilePaths...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading root secrets: %w", err)
+	}
+	return rootSecretsToRootKeysetWrapped(rootSecrets)
+}
+
+// Like LoadRootKeyset, but gets the root secrets from the provided
+// loader. See LoadRootSecretsFrom for details.
+func LoadRootKeysetFrom(loader func() ([]RootSecret, error)) (*Keyset, error) {
+	rootSecrets, err := LoadRootSecretsFrom(loader)
+	if err != nil {
+		return nil, fmt.Errorf("error loading root secrets: %w", err)
+	}
+	return rootSecretsToRootKeysetWrapped(rootSecrets)
+}
+
+func rootSecretsToRootKeysetWrapped(rootSecrets RootSecrets) (*Keyset, error) {
+	keyset, err := RootSecretsToRootKeyset(rootSecrets)
+	if err != nil {
+		return nil, fmt.Errorf("error converting root secrets to keyset: %w", err)
+	}
+	return keyset, nil
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// APP KEYSET
 /////////////////////////////////////////////////////////////////////
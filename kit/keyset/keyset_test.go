@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -236,6 +237,79 @@ func TestAttempt(t *testing.T) {
 	}
 }
 
+func TestKeyset_SignVerify(t *testing.T) {
+	randomKey32 := func(t *testing.T) cryptoutil.Key32 {
+		b, err := cryptoutil.RandomBytes(32)
+		if err != nil {
+			t.Fatalf("RandomBytes failed: %v", err)
+		}
+		k32, _ := cryptoutil.ToKey32(b)
+		return k32
+	}
+	oldKey, newKey := randomKey32(t), randomKey32(t)
+	oldKeyset := &Keyset{uks: UnwrappedKeyset{oldKey}}
+	rotatedKeyset := &Keyset{uks: UnwrappedKeyset{newKey, oldKey}}
+	msg := []byte("/download?file=report.pdf&exp=1700000000")
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		sig, err := rotatedKeyset.Sign(msg)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		if len(sig) != 32 {
+			t.Errorf("Expected 32-byte signature, got %d bytes", len(sig))
+		}
+		if !rotatedKeyset.Verify(msg, sig) {
+			t.Error("Expected signature to verify")
+		}
+	})
+
+	t.Run("SignsWithFirstKey", func(t *testing.T) {
+		sig, _ := rotatedKeyset.Sign(msg)
+		if oldKeyset.Verify(msg, sig) {
+			t.Error("Signature from the latest key should not verify under only the old key")
+		}
+	})
+
+	t.Run("VerifiesAfterRotation", func(t *testing.T) {
+		sig, err := oldKeyset.Sign(msg)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		if !rotatedKeyset.Verify(msg, sig) {
+			t.Error("Signature from the prior key should verify after rotation")
+		}
+	})
+
+	t.Run("RejectsTampering", func(t *testing.T) {
+		sig, _ := rotatedKeyset.Sign(msg)
+		if rotatedKeyset.Verify([]byte("tampered"), sig) {
+			t.Error("Expected tampered message to fail verification")
+		}
+		badSig := bytes.Clone(sig)
+		badSig[0] ^= 0xFF
+		if rotatedKeyset.Verify(msg, badSig) {
+			t.Error("Expected tampered signature to fail verification")
+		}
+		if rotatedKeyset.Verify(msg, sig[:16]) {
+			t.Error("Expected truncated signature to fail verification")
+		}
+	})
+
+	t.Run("NilOrEmptyKeyset", func(t *testing.T) {
+		var nilKeyset *Keyset
+		if _, err := nilKeyset.Sign(msg); err == nil {
+			t.Error("Expected error signing with nil keyset")
+		}
+		if nilKeyset.Verify(msg, make([]byte, 32)) {
+			t.Error("Expected nil keyset to never verify")
+		}
+		if (&Keyset{}).Verify(msg, make([]byte, 32)) {
+			t.Error("Expected empty keyset to never verify")
+		}
+	})
+}
+
 func TestKeyset_HKDF(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -471,6 +545,135 @@ func TestLoadRootKeyset(t *testing.T) {
 	}
 }
 
+func TestLoadRootSecretsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, contents string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	valid1 := writeFile("valid1", generateTestSecret()+"\n")
+	valid2 := writeFile("valid2", "  "+generateTestSecret()+"\r\n")
+	empty := writeFile("empty", " \n")
+	invalid := writeFile("invalid", "invalid-base64!")
+
+	tests := []struct {
+		name         string
+		paths        []string
+		wantErr      bool
+		wantLen      int
+		wantKeyErr   bool
+		wantErrMatch string
+	}{
+		{name: "no paths", paths: []string{}, wantErr: true},
+		{name: "single valid file", paths: []string{valid1}, wantLen: 1},
+		{name: "multiple valid files", paths: []string{valid1, valid2}, wantLen: 2},
+		{name: "empty path", paths: []string{""}, wantErr: true},
+		{name: "non-existent file", paths: []string{filepath.Join(dir, "nope")}, wantErr: true},
+		{name: "whitespace-only file", paths: []string{empty}, wantErr: true, wantErrMatch: "is empty"},
+		{name: "invalid base64", paths: []string{invalid}, wantLen: 1, wantKeyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets, err := LoadRootSecretsFromFiles(tt.paths...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadRootSecretsFromFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrMatch != "" && !strings.Contains(err.Error(), tt.wantErrMatch) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErrMatch, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(secrets) != tt.wantLen {
+				t.Errorf("expected %d secrets, got %d", tt.wantLen, len(secrets))
+			}
+			for _, s := range secrets {
+				if s != strings.TrimSpace(s) {
+					t.Errorf("expected secret to be trimmed, got %q", s)
+				}
+			}
+			keyset, err := LoadRootKeysetFromFiles(tt.paths...)
+			if (err != nil) != tt.wantKeyErr {
+				t.Fatalf("LoadRootKeysetFromFiles() error = %v, wantKeyErr %v", err, tt.wantKeyErr)
+			}
+			if !tt.wantKeyErr && len(keyset.Unwrap()) != tt.wantLen {
+				t.Errorf("expected %d keys, got %d", tt.wantLen, len(keyset.Unwrap()))
+			}
+		})
+	}
+}
+
+func TestLoadRootSecretsFrom(t *testing.T) {
+	loaderErr := errors.New("provider unavailable")
+	shortSecret := base64.StdEncoding.EncodeToString(make([]byte, 16))
+
+	tests := []struct {
+		name       string
+		loader     func() ([]RootSecret, error)
+		wantErr    bool
+		wantLen    int
+		wantKeyErr bool
+	}{
+		{name: "nil loader", loader: nil, wantErr: true},
+		{
+			name:    "loader error",
+			loader:  func() ([]RootSecret, error) { return nil, loaderErr },
+			wantErr: true,
+		},
+		{
+			name:    "no secrets",
+			loader:  func() ([]RootSecret, error) { return nil, nil },
+			wantErr: true,
+		},
+		{
+			name:    "empty secret",
+			loader:  func() ([]RootSecret, error) { return []RootSecret{generateTestSecret(), ""}, nil },
+			wantErr: true,
+		},
+		{
+			name:    "valid secrets",
+			loader:  func() ([]RootSecret, error) { return []RootSecret{generateTestSecret(), generateTestSecret()}, nil },
+			wantLen: 2,
+		},
+		{
+			name:       "wrong length secret",
+			loader:     func() ([]RootSecret, error) { return []RootSecret{shortSecret}, nil },
+			wantLen:    1,
+			wantKeyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets, err := LoadRootSecretsFrom(tt.loader)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadRootSecretsFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(secrets) != tt.wantLen {
+				t.Errorf("expected %d secrets, got %d", tt.wantLen, len(secrets))
+			}
+			_, err = LoadRootKeysetFrom(tt.loader)
+			if (err != nil) != tt.wantKeyErr {
+				t.Fatalf("LoadRootKeysetFrom() error = %v, wantKeyErr %v", err, tt.wantKeyErr)
+			}
+		})
+	}
+
+	t.Run("loader error is wrapped", func(t *testing.T) {
+		_, err := LoadRootKeysetFrom(func() ([]RootSecret, error) { return nil, loaderErr })
+		if !errors.Is(err, loaderErr) {
+			t.Errorf("expected error to wrap loader error, got %v", err)
+		}
+	})
+}
+
 func TestMustAppKeyset(t *testing.T) {
 	// Setup test environment variable
 	os.Setenv("TEST_APP_SECRET", generateTestSecret())
@@ -0,0 +1,59 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// PARAM CONSTRAINTS
+/////////////////////////////////////////////////////////////////////
+
+// ParamConstraint reports whether a captured dynamic param segment is
+// acceptable. A dynamic segment may declare a constraint in parentheses
+// after its name, either as the name of a constraint registered via
+// Options.ParamConstraints (e.g., ":id(int)") or as an inline regex
+// (e.g., ":id(\d+)" or ":lang(en|fr)"), which must match the entire
+// segment. Segments failing their constraint do not match, so matching
+// falls through to other patterns. Params still carry the raw value.
+type ParamConstraint func(segment string) bool
+
+type paramConstraint struct {
+	expr  string
+	check ParamConstraint
+}
+
+// Splits a dynamic segment's name (sans prefix rune) into the param name
+// and its constraint expression, if any.
+func splitParamConstraint(pattern, nameWithConstraint string) (string, string) {
+	openIdx := strings.IndexByte(nameWithConstraint, '(')
+	if openIdx == -1 {
+		return nameWithConstraint, ""
+	}
+	if !strings.HasSuffix(nameWithConstraint, ")") || openIdx == len(nameWithConstraint)-2 {
+		panic(fmt.Sprintf(
+			"Error with pattern '%s'. Param constraints must be non-empty and wrapped in parentheses at the end of the segment (e.g., ':id(\\d+)').",
+			pattern,
+		))
+	}
+	return nameWithConstraint[:openIdx], nameWithConstraint[openIdx+1 : len(nameWithConstraint)-1]
+}
+
+func (m *Matcher) resolveParamConstraint(pattern, expr string) *paramConstraint {
+	if check, ok := m.paramConstraints[expr]; ok {
+		return &paramConstraint{expr: expr, check: check}
+	}
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		panic(fmt.Sprintf(
+			"Error with pattern '%s'. Param constraint '%s' is neither a registered constraint name nor a valid regex: %v",
+			pattern, expr, err,
+		))
+	}
+	return &paramConstraint{expr: expr, check: re.MatchString}
+}
+
+func (c *paramConstraint) allows(segment string) bool {
+	return c == nil || c.check(segment)
+}
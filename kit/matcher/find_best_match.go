@@ -29,7 +29,7 @@ func (m *Matcher) FindBestMatch(realPath string) (*BestMatch, bool) {
 		params := make(Params, best.numberOfDynamicParamSegs)
 		for i, seg := range best.normalizedSegments {
 			if seg.segType == segTypes.dynamic {
-				params[seg.normalizedVal[1:]] = segments[i]
+				params[seg.paramName] = segments[i]
 			}
 		}
 		best.Params = params
@@ -85,8 +85,12 @@ func (m *Matcher) dfsBest(
 		switch child.nodeType {
 		case nodeDynamic:
 			// Don't match empty segments to dynamic parameters
-			if segments[depth] != "" {
-				m.dfsBest(child, segments, depth+1, score+scoreDynamic, best, bestScore, foundMatch, checkTrailingSlash)
+			if segments[depth] != "" && child.constraint.allows(segments[depth]) {
+				childScore := score + scoreDynamic
+				if child.constraint != nil {
+					childScore += scoreConstraintBonus
+				}
+				m.dfsBest(child, segments, depth+1, childScore, best, bestScore, foundMatch, checkTrailingSlash)
 			}
 
 		case nodeSplat:
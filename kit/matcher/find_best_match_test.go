@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"strconv"
 	"testing"
 )
 
@@ -283,6 +284,61 @@ func getTestCases() []testCase {
 			wantParams:        nil,
 			wantSplatSegments: nil,
 		},
+
+		// param constraints
+		{
+			name:        "regex constraint satisfied",
+			patterns:    []string{`/users/:id(\d+)`},
+			path:        "/users/123",
+			wantPattern: `/users/:id(\d+)`,
+			wantParams:  Params{"id": "123"},
+		},
+		{
+			name:        "regex constraint unsatisfied",
+			patterns:    []string{`/users/:id(\d+)`},
+			path:        "/users/abc",
+			wantPattern: NOT_FOUND,
+		},
+		{
+			name:        "regex constraint must match entire segment",
+			patterns:    []string{`/users/:id(\d+)`},
+			path:        "/users/123abc",
+			wantPattern: NOT_FOUND,
+		},
+		{
+			name:        "constrained param beats unconstrained param",
+			patterns:    []string{"/users/:slug", `/users/:id(\d+)`},
+			path:        "/users/123",
+			wantPattern: `/users/:id(\d+)`,
+			wantParams:  Params{"id": "123"},
+		},
+		{
+			name:        "unsatisfied constraint falls through to unconstrained param",
+			patterns:    []string{`/users/:id(\d+)`, "/users/:slug"},
+			path:        "/users/bob",
+			wantPattern: "/users/:slug",
+			wantParams:  Params{"slug": "bob"},
+		},
+		{
+			name:        "static beats constrained param",
+			patterns:    []string{`/users/:id(\d+)`, "/users/123"},
+			path:        "/users/123",
+			wantPattern: "/users/123",
+		},
+		{
+			name:              "unsatisfied constraint falls through to splat",
+			patterns:          []string{`/files/:lang(en|fr)/readme`, "/files/*"},
+			path:              "/files/de/readme",
+			wantPattern:       "/files/*",
+			wantSplatSegments: []string{"de", "readme"},
+		},
+		{
+			name:        "enum constraint satisfied",
+			patterns:    []string{`/files/:lang(en|fr)/readme`, "/files/*"},
+			path:        "/files/fr/readme",
+			wantPattern: `/files/:lang(en|fr)/readme`,
+			wantParams:  Params{"lang": "fr"},
+		},
 	}
 }
 
@@ -359,6 +415,58 @@ func TestFindBestMatchAdditionalScenarios(t *testing.T) {
 	}
 }
 
+func TestParamConstraints(t *testing.T) {
+	t.Run("named constraint", func(t *testing.T) {
+		m := New(&Options{
+			Quiet: true,
+			ParamConstraints: map[string]ParamConstraint{
+				"even": func(segment string) bool {
+					n, err := strconv.Atoi(segment)
+					return err == nil && n%2 == 0
+				},
+			},
+		})
+		m.RegisterPattern("/n/:num(even)")
+		m.RegisterPattern("/n/:other")
+
+		match, ok := m.FindBestMatch("/n/42")
+		if !ok || match.normalizedPattern != "/n/:num(even)" || match.Params["num"] != "42" {
+			t.Errorf("expected even constraint to match /n/42, got %v", match)
+		}
+		match, ok = m.FindBestMatch("/n/43")
+		if !ok || match.normalizedPattern != "/n/:other" || match.Params["other"] != "43" {
+			t.Errorf("expected /n/43 to fall through to /n/:other, got %v", match)
+		}
+	})
+
+	t.Run("nested matches respect constraints", func(t *testing.T) {
+		m := New(&Options{Quiet: true})
+		m.RegisterPattern("")
+		m.RegisterPattern(`/users/:id(\d+)`)
+
+		results, ok := m.FindNestedMatches("/users/123")
+		if !ok || results.Params["id"] != "123" {
+			t.Errorf("expected nested match with id 123, got %v", results)
+		}
+		if _, ok := m.FindNestedMatches("/users/abc"); ok {
+			t.Errorf("expected no nested match for /users/abc")
+		}
+	})
+
+	t.Run("invalid constraints panic", func(t *testing.T) {
+		for _, pattern := range []string{`/users/:id([)`, "/users/:id(", "/users/:id()"} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("expected panic for pattern %q", pattern)
+					}
+				}()
+				New(&Options{Quiet: true}).RegisterPattern(pattern)
+			}()
+		}
+	})
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// BENCHMARKS
 /////////////////////////////////////////////////////////////////////
@@ -203,6 +203,9 @@ func (m *Matcher) dfsNestedMatches(
 	for _, child := range node.dynChildren {
 		switch child.nodeType {
 		case nodeDynamic:
+			if !child.constraint.allows(seg) {
+				continue
+			}
 			// Backtracking pattern for dynamic
 			oldVal, hadVal := params[child.paramName]
 			params[child.paramName] = seg
@@ -27,6 +27,8 @@ type Matcher struct {
 	slashIndexSegment         string
 	usingExplicitIndexSegment bool
 
+	paramConstraints map[string]ParamConstraint
+
 	quiet bool
 }
 
@@ -63,6 +65,10 @@ type Options struct {
 	ExplicitIndexSegment string
 
 	Quiet bool // Optional. Defaults to false. Set to true if you want to quash warnings.
+
+	// Optional. Named constraints that dynamic param segments may reference
+	// by name, e.g., ":id(int)". Names take precedence over inline regexes.
+	ParamConstraints map[string]ParamConstraint
 }
 
 func New(opts *Options) *Matcher {
@@ -78,6 +84,7 @@ func New(opts *Options) *Matcher {
 	instance.dynamicParamPrefixRune = mungedOpts.DynamicParamPrefixRune
 	instance.splatSegmentRune = mungedOpts.SplatSegmentRune
 	instance.quiet = mungedOpts.Quiet
+	instance.paramConstraints = mungedOpts.ParamConstraints
 
 	instance.slashIndexSegment = "/" + instance.explicitIndexSegment
 	instance.usingExplicitIndexSegment = instance.explicitIndexSegment != ""
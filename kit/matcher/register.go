@@ -12,8 +12,11 @@ const (
 	nodeStatic       uint8 = 0
 	nodeDynamic      uint8 = 1
 	nodeSplat        uint8 = 2
-	scoreStaticMatch       = 2
-	scoreDynamic           = 1
+	scoreStaticMatch       = 64
+	scoreDynamic           = 32
+	// Added to scoreDynamic for constrained params, so that they beat
+	// unconstrained params, but never static segments.
+	scoreConstraintBonus = 1
 )
 
 type RegisteredPattern struct {
@@ -92,6 +95,8 @@ func JoinPatterns(rp *RegisteredPattern, pattern string) string {
 type segment struct {
 	normalizedVal string
 	segType       segType
+	paramName     string           // dynamic segments only
+	constraint    *paramConstraint // dynamic segments only (nil if unconstrained)
 }
 
 var segTypes = struct {
@@ -149,11 +154,19 @@ func (m *Matcher) NormalizePattern(originalPattern string) *RegisteredPattern {
 
 	for _, seg := range rawSegments {
 		normalizedVal := seg
+		var paramName string
+		var constraint *paramConstraint
 
 		segType := m.getSegmentTypeAssumeNormalized(seg)
 		if segType == segTypes.dynamic {
 			numberOfDynamicParamSegs++
-			normalizedVal = ":" + seg[1:]
+			var expr string
+			paramName, expr = splitParamConstraint(originalPattern, seg[1:])
+			normalizedVal = ":" + paramName
+			if expr != "" {
+				constraint = m.resolveParamConstraint(originalPattern, expr)
+				normalizedVal += "(" + expr + ")"
+			}
 		}
 		if segType == segTypes.splat {
 			normalizedVal = "*"
@@ -162,6 +175,8 @@ func (m *Matcher) NormalizePattern(originalPattern string) *RegisteredPattern {
 		segments = append(segments, &segment{
 			normalizedVal: normalizedVal,
 			segType:       segType,
+			paramName:     paramName,
+			constraint:    constraint,
 		})
 	}
 
@@ -222,10 +237,13 @@ func (m *Matcher) RegisterPattern(originalPattern string) *RegisteredPattern {
 	var nodeScore int
 
 	for i, segment := range _normalized.normalizedSegments {
-		child := current.findOrCreateChild(segment.normalizedVal)
+		child := current.findOrCreateChild(segment)
 		switch {
 		case segment.segType == segTypes.dynamic:
 			nodeScore += scoreDynamic
+			if segment.constraint != nil {
+				nodeScore += scoreConstraintBonus
+			}
 		case segment.segType != segTypes.splat:
 			nodeScore += scoreStaticMatch
 		}
@@ -274,40 +292,54 @@ type segmentNode struct {
 	children    map[string]*segmentNode
 	dynChildren []*segmentNode
 	paramName   string
+	constraint  *paramConstraint
 	finalScore  int
 }
 
 // findOrCreateChild finds or creates a child node for a segment
-func (n *segmentNode) findOrCreateChild(segment string) *segmentNode {
-	if segment == "*" || (len(segment) > 0 && rune(segment[0]) == ':') {
+func (n *segmentNode) findOrCreateChild(seg *segment) *segmentNode {
+	if seg.segType == segTypes.splat || seg.segType == segTypes.dynamic {
 		for _, child := range n.dynChildren {
-			if child.paramName == segment[1:] {
+			if child.key() == seg.normalizedVal {
 				return child
 			}
 		}
-		return n.addDynamicChild(segment)
+		return n.addDynamicChild(seg)
 	}
 
 	if n.children == nil {
 		n.children = make(map[string]*segmentNode)
 	}
-	if child, exists := n.children[segment]; exists {
+	if child, exists := n.children[seg.normalizedVal]; exists {
 		return child
 	}
 	child := &segmentNode{nodeType: nodeStatic}
-	n.children[segment] = child
+	n.children[seg.normalizedVal] = child
 	return child
 }
 
 // addDynamicChild creates a new dynamic or splat child node
-func (n *segmentNode) addDynamicChild(segment string) *segmentNode {
+func (n *segmentNode) addDynamicChild(seg *segment) *segmentNode {
 	child := &segmentNode{}
-	if segment == "*" {
+	if seg.segType == segTypes.splat {
 		child.nodeType = nodeSplat
 	} else {
 		child.nodeType = nodeDynamic
-		child.paramName = segment[1:]
+		child.paramName = seg.paramName
+		child.constraint = seg.constraint
 	}
 	n.dynChildren = append(n.dynChildren, child)
 	return child
 }
+
+// key returns the normalized segment value of a dynamic or splat node
+func (n *segmentNode) key() string {
+	switch {
+	case n.nodeType == nodeSplat:
+		return "*"
+	case n.constraint != nil:
+		return ":" + n.paramName + "(" + n.constraint.expr + ")"
+	default:
+		return ":" + n.paramName
+	}
+}
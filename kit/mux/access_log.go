@@ -0,0 +1,117 @@
+package mux
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/river-now/river/kit/contextutil"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// ACCESS LOG
+/////////////////////////////////////////////////////////////////////
+
+var accessLogStore = contextutil.NewStore[*accessLogEntry]("__river_kit_mux_access_log")
+
+type accessLogEntry struct {
+	pattern           string
+	headFellBackToGet bool
+	requestID         string
+}
+
+// AccessLogMiddleware returns a middleware that logs one line per request
+// with the method, path, matched pattern, status, bytes written, duration,
+// and request ID (if Options.RequestID is set). If logger is nil, the mux
+// package logger is used.
+//
+// Wrap your router with it (e.g., AccessLogMiddleware(nil)(router))
+// rather than registering it via SetGlobalHTTPMiddleware, so that the
+// logged status reflects everything the router writes, including
+// response proxy statuses set by task middlewares and not-found
+// responses. HEAD requests served by a GET handler are logged with the
+// bytes actually sent on the wire (i.e., zero body bytes).
+func AccessLogMiddleware(logger *slog.Logger) HTTPMiddleware {
+	if logger == nil {
+		logger = muxLog
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &accessLogEntry{}
+			alw := &accessLogResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(alw, accessLogStore.GetRequestWithContext(r, entry))
+			status := alw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"pattern", entry.pattern,
+				"status", status,
+				"bytes", alw.bytes,
+				"duration", time.Since(start),
+			}
+			if entry.requestID != "" {
+				attrs = append(attrs, "request_id", entry.requestID)
+			}
+			if entry.headFellBackToGet {
+				attrs = append(attrs, "head_as_get", true)
+			}
+			logger.Info("request", attrs...)
+		})
+	}
+}
+
+// Called by the router once a request has been matched, so that an
+// outer AccessLogMiddleware (if any) can see the matched pattern.
+func recordAccessLogMatch(r *http.Request, best *findBestOutput) {
+	if entry := accessLogStore.GetValueFromContext(r.Context()); entry != nil {
+		entry.pattern = best.match.OriginalPattern()
+		entry.headFellBackToGet = best.headFellBackToGet
+	}
+}
+
+// Called by the router once a request ID has been assigned (see
+// Options.RequestID).
+func recordAccessLogRequestID(r *http.Request, requestID string) {
+	if entry := accessLogStore.GetValueFromContext(r.Context()); entry != nil {
+		entry.requestID = requestID
+	}
+}
+
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *accessLogResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *accessLogResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += n
+	return n, err
+}
+
+func (w *accessLogResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+func (w *accessLogResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
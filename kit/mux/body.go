@@ -0,0 +1,77 @@
+package mux
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/river-now/river/kit/contextutil"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// REQUEST BODY BUFFERING
+/////////////////////////////////////////////////////////////////////
+
+// Used when Options.BufferRequestBody is true and
+// Options.MaxRequestBodyBytes is not set.
+const DefaultMaxBufferedRequestBodyBytes int64 = 10 << 20 // 10 MB
+
+var rawBodyStore = contextutil.NewStore[[]byte]("__river_kit_mux_raw_body")
+
+// GetRawBody returns the request body as read by the router when
+// Options.BufferRequestBody is true (e.g., for verifying a signature over
+// the exact bytes sent), regardless of whether ParseInput or any
+// middleware has already consumed r.Body. Prefer it over r.Body in task
+// middlewares, as they run in parallel and share the same r.Body. Returns
+// nil if the body was not buffered.
+func GetRawBody(r *http.Request) []byte {
+	return rawBodyStore.GetValueFromContext(r.Context())
+}
+
+// bufferedBody is the re-seekable r.Body of requests whose bodies were
+// buffered by the router.
+type bufferedBody struct {
+	*bytes.Reader
+}
+
+func (bufferedBody) Close() error { return nil }
+
+// Applies Options.MaxRequestBodyBytes and, if Options.BufferRequestBody
+// is true, reads the body into memory. On failure, the error response has
+// already been written and ok is false.
+func (rt *Router) prepareRequestBody(w http.ResponseWriter, r *http.Request) (_ *http.Request, ok bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		if rt.bufferRequestBody {
+			r = rawBodyStore.GetRequestWithContext(r, []byte{})
+		}
+		return r, true
+	}
+	if rt.maxRequestBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, rt.maxRequestBodyBytes)
+	}
+	if !rt.bufferRequestBody {
+		return r, true
+	}
+	raw, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		}
+		return r, false
+	}
+	r = rawBodyStore.GetRequestWithContext(r, raw)
+	r.Body = bufferedBody{bytes.NewReader(raw)}
+	return r, true
+}
+
+// Rewinds a buffered body (e.g., after ParseInput has consumed it), so
+// that middlewares and handlers can read it again from the start.
+func rewindBody(r *http.Request) {
+	if body, ok := r.Body.(bufferedBody); ok {
+		body.Seek(0, io.SeekStart)
+	}
+}
@@ -0,0 +1,76 @@
+package mux
+
+import (
+	"net/http"
+	"time"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// CONCURRENCY LIMIT
+/////////////////////////////////////////////////////////////////////
+
+type ConcurrencyLimitOptions struct {
+	// If true, requests arriving while the limit is reached wait for a
+	// slot (until MaxWait elapses or the request is cancelled) instead of
+	// being rejected immediately.
+	Queue bool
+	// Optional. Only applies if Queue is true. If zero, queued requests
+	// wait for as long as their context allows.
+	MaxWait time.Duration
+}
+
+// ConcurrencyLimitMiddleware returns a middleware that allows at most max
+// requests through to the next handler at once. Requests over the limit
+// are handled by onLimit (or, if onLimit is nil, answered with a 503),
+// either immediately or, if opts.Queue is set, once they give up waiting
+// for a slot. Queued requests whose context is cancelled while waiting
+// are dropped without a response. If max is less than 1, it is set to 1.
+//
+// Each call returns an independent limiter, so register the same returned
+// middleware wherever requests should share a limit (e.g., globally via
+// SetGlobalHTTPMiddleware, or per method or pattern).
+func ConcurrencyLimitMiddleware(max int, onLimit http.Handler, opts ...*ConcurrencyLimitOptions) HTTPMiddleware {
+	if max < 1 {
+		max = 1
+	}
+	if onLimit == nil {
+		onLimit = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		})
+	}
+	var o ConcurrencyLimitOptions
+	if len(opts) > 0 && opts[0] != nil {
+		o = *opts[0]
+	}
+
+	sem := make(chan struct{}, max)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+			default:
+				if !o.Queue {
+					onLimit.ServeHTTP(w, r)
+					return
+				}
+				var timeout <-chan time.Time
+				if o.MaxWait > 0 {
+					timer := time.NewTimer(o.MaxWait)
+					defer timer.Stop()
+					timeout = timer.C
+				}
+				select {
+				case sem <- struct{}{}:
+				case <-timeout:
+					onLimit.ServeHTTP(w, r)
+					return
+				case <-r.Context().Done():
+					return
+				}
+			}
+			defer func() { <-sem }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
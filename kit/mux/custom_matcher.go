@@ -0,0 +1,57 @@
+package mux
+
+import (
+	"net/http"
+
+	"github.com/river-now/river/kit/matcher"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// CUSTOM MATCHERS
+/////////////////////////////////////////////////////////////////////
+
+// Matcher is the pattern matching strategy used by a Router, one
+// instance per HTTP method. *matcher.Matcher (the default) implements
+// it. Custom implementations (see Options.MatcherFactory) should return,
+// from FindBestMatch, a BestMatch whose OriginalPattern is one they were
+// given via RegisterPattern, as that is how the router looks up the
+// matched route (a match for any other pattern is treated as not
+// found). The simplest way to build RegisteredPattern and BestMatch
+// values is to delegate to one or more built-in matchers.
+type Matcher interface {
+	RegisterPattern(originalPattern string) *matcher.RegisteredPattern
+	FindBestMatch(realPath string) (*matcher.BestMatch, bool)
+}
+
+// RequestMatcher is an optional interface for Matchers that need more
+// than the path to pick a route (e.g., host-based routing). If a
+// Router's Matcher implements it, FindBestMatchForRequest is called
+// instead of FindBestMatch, with the request and the path to match
+// (relative to the router's mount root, if any).
+type RequestMatcher interface {
+	FindBestMatchForRequest(r *http.Request, realPath string) (*matcher.BestMatch, bool)
+}
+
+func defaultMatcherFactory(opts *matcher.Options) Matcher {
+	return matcher.New(opts)
+}
+
+// Finds the best match among the method's registered routes, ignoring
+// any match (from a custom Matcher) for a pattern that was never
+// registered.
+func (mm *methodMatcher) findBestMatch(r *http.Request, realPath string) (*matcher.BestMatch, bool) {
+	var match *matcher.BestMatch
+	var found bool
+	if rm, ok := mm.matcher.(RequestMatcher); ok {
+		match, found = rm.FindBestMatchForRequest(r, realPath)
+	} else {
+		match, found = mm.matcher.FindBestMatch(realPath)
+	}
+	if !found || match == nil {
+		return nil, false
+	}
+	if _, ok := mm.routes[match.OriginalPattern()]; !ok {
+		return nil, false
+	}
+	return match, true
+}
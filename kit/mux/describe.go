@@ -0,0 +1,189 @@
+package mux
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/river-now/river/kit/reflectutil"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// ROUTE INTROSPECTION
+/////////////////////////////////////////////////////////////////////
+
+// RouteDescription is a serializable description of a registered route,
+// structured enough to generate API docs (e.g., an OpenAPI document)
+// from downstream.
+type RouteDescription struct {
+	Method      string   `json:"method"`
+	Pattern     string   `json:"pattern"`
+	Path        string   `json:"path"` // Pattern joined onto the mount root
+	HandlerType string   `json:"handlerType"`
+	Tags        []string `json:"tags,omitempty"`
+	// True for pure HTTP handlers, whose input and output are unknown to
+	// the router (Input and Output are nil).
+	Opaque bool        `json:"opaque,omitempty"`
+	Input  *TypeSchema `json:"input,omitempty"`
+	Output *TypeSchema `json:"output,omitempty"`
+}
+
+// TypeSchema is a JSON-Schema-like description of a Go type, as it is
+// marshalled to (or unmarshalled from) JSON. Struct fields are walked the
+// same way the TypeScript type generation walks them (JSON field names,
+// omitempty/omitzero/pointer optionality, flattened untagged embedded
+// structs). Recursive references to a struct type already being described
+// are emitted as a Ref to its Go type name.
+type TypeSchema struct {
+	// One of "object", "array", "string", "integer", "number", "boolean",
+	// or empty (any value).
+	Type                 string                 `json:"type,omitempty"`
+	GoType               string                 `json:"goType,omitempty"`
+	Format               string                 `json:"format,omitempty"` // e.g., "date-time"
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Properties           map[string]*TypeSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *TypeSchema            `json:"items,omitempty"`
+	AdditionalProperties *TypeSchema            `json:"additionalProperties,omitempty"`
+	Ref                  string                 `json:"ref,omitempty"`
+}
+
+// Describe returns a description of every route registered on the
+// router, in registration order. Task routes report the schemas of their
+// input and output types, and pure HTTP handlers are reported as opaque.
+func (rt *Router) Describe() []RouteDescription {
+	descriptions := make([]RouteDescription, 0, len(rt.allRoutes))
+	for _, route := range rt.allRoutes {
+		d := RouteDescription{
+			Method:      route.Method(),
+			Pattern:     route.OriginalPattern(),
+			Path:        rt.MountRoot(route.OriginalPattern()),
+			HandlerType: route.getHandlerType(),
+			Tags:        route.Tags(),
+		}
+		if d.HandlerType == "http" {
+			d.Opaque = true
+		} else {
+			d.Input = describeType(reflect.TypeOf(route.IPtr()).Elem())
+			d.Output = describeType(reflect.TypeOf(route.OPtr()).Elem())
+		}
+		descriptions = append(descriptions, d)
+	}
+	return descriptions
+}
+
+const describeMaxEmbedDepth = 32 // guards against pathological embedding chains
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+)
+
+func describeType(t reflect.Type) *TypeSchema {
+	return (&typeDescriber{visiting: make(map[reflect.Type]bool)}).describe(t)
+}
+
+type typeDescriber struct {
+	visiting map[reflect.Type]bool
+}
+
+func (td *typeDescriber) describe(t reflect.Type) *TypeSchema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+	s := td.describeNonPtr(t)
+	s.Nullable = s.Nullable || nullable
+	return s
+}
+
+func (td *typeDescriber) describeNonPtr(t reflect.Type) *TypeSchema {
+	s := &TypeSchema{GoType: t.String()}
+
+	switch {
+	case t == timeType:
+		s.Type, s.Format = "string", "date-time"
+		return s
+	case t == rawMessageType:
+		return s
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom JSON encodings can't be known without running them
+		return s
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		s.Type = "string"
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			s.Type, s.Format = "string", "byte" // base64, per encoding/json
+			break
+		}
+		s.Type = "array"
+		s.Nullable = t.Kind() == reflect.Slice
+		s.Items = td.describe(t.Elem())
+	case reflect.Map:
+		s.Type = "object"
+		s.Nullable = true
+		s.AdditionalProperties = td.describe(t.Elem())
+	case reflect.Struct:
+		if td.visiting[t] {
+			return &TypeSchema{Ref: t.String()}
+		}
+		td.visiting[t] = true
+		defer delete(td.visiting, t)
+		s.Type = "object"
+		s.Properties = make(map[string]*TypeSchema)
+		td.collectFields(t, s, 0)
+	}
+	return s
+}
+
+// Direct fields are collected before flattened embedded ones, so that
+// shallower fields win name conflicts, as with encoding/json.
+func (td *typeDescriber) collectFields(t reflect.Type, s *TypeSchema, depth int) {
+	var embedded []reflect.Type
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if reflectutil.IsJSONFieldOmitted(field) {
+			continue
+		}
+		if ft, _, ok := reflectutil.GetJSONFlattenedStruct(field); ok {
+			embedded = append(embedded, ft)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := reflectutil.GetJSONFieldName(field)
+		if _, exists := s.Properties[name]; name == "" || exists {
+			continue
+		}
+		s.Properties[name] = td.describe(field.Type)
+		if !reflectutil.IsJSONFieldOptional(field) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	if depth < describeMaxEmbedDepth {
+		for _, ft := range embedded {
+			td.collectFields(ft, s, depth+1)
+		}
+	}
+}
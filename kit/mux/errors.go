@@ -0,0 +1,154 @@
+package mux
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/river-now/river/kit/validate"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// ERROR RESPONSES
+/////////////////////////////////////////////////////////////////////
+
+// ErrorHandler writes the response for an error the router encountered
+// while parsing input or running task middlewares or task handlers.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// ErrorResponseBody is the JSON body DefaultErrorHandler writes for
+// clients that accept JSON.
+type ErrorResponseBody struct {
+	Error string `json:"error"`
+	// Only present for validation errors (possibly empty, for validation
+	// errors carrying no rule failures) and for HTTPErrors with Details.
+	Details []ErrorResponseDetail `json:"details,omitempty"`
+}
+
+type ErrorResponseDetail struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// HTTPError lets task handlers and task middlewares short-circuit with a
+// specific status (e.g., 404, 409, or 422) by returning it (or an error
+// wrapping it) as their error. DefaultErrorHandler responds with Status
+// and Message (defaulting to the status text), plus any Details in the
+// JSON body. Task handler HTTPErrors with a status below 500 are
+// expected outcomes and so are not logged.
+type HTTPError struct {
+	Status  int // Defaults to 500 if not a 4xx or 5xx status.
+	Message string
+	Details []ErrorResponseDetail
+}
+
+func (e HTTPError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.status())
+	}
+	return fmt.Sprintf("%d: %s", e.status(), msg)
+}
+
+func (e HTTPError) status() int {
+	if e.Status < 400 || e.Status > 599 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
+
+// AsHTTPError reports whether err is (or wraps) an HTTPError, whether
+// returned by value or by pointer.
+func AsHTTPError(err error) (HTTPError, bool) {
+	var ptr *HTTPError
+	if errors.As(err, &ptr) && ptr != nil {
+		return *ptr, true
+	}
+	var val HTTPError
+	if errors.As(err, &val) {
+		return val, true
+	}
+	return HTTPError{}, false
+}
+
+// DefaultErrorHandler responds with a 400 and the error message for
+// validation errors (per validate.IsValidationError), with the status,
+// message, and details of an HTTPError (per AsHTTPError), and with a
+// generic 500 otherwise. Clients whose Accept header includes a JSON media type
+// get an ErrorResponseBody; all others get plain text. It is exported so
+// that custom ErrorHandlers can delegate to it.
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	isValidationErr := validate.IsValidationError(err)
+	httpErr, isHTTPErr := AsHTTPError(err)
+
+	status := http.StatusInternalServerError
+	msg := http.StatusText(status)
+	var details []ErrorResponseDetail
+	switch {
+	case isValidationErr:
+		status = http.StatusBadRequest
+		msg = err.Error()
+		details = validationErrorDetails(err)
+	case isHTTPErr:
+		status = httpErr.status()
+		msg = httpErr.Message
+		if msg == "" {
+			msg = http.StatusText(status)
+		}
+		details = httpErr.Details
+	}
+
+	if !acceptsJSON(r) {
+		http.Error(w, msg, status)
+		return
+	}
+
+	body := ErrorResponseBody{Error: msg, Details: details}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (rt *Router) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if rt.errorHandler != nil {
+		rt.errorHandler(w, r, err)
+		return
+	}
+	DefaultErrorHandler(w, r, err)
+}
+
+func validationErrorDetails(err error) []ErrorResponseDetail {
+	details := []ErrorResponseDetail{}
+	var validationErr *validate.ValidationError
+	if !errors.As(err, &validationErr) {
+		return details
+	}
+	for _, ruleErr := range validationErr.RuleErrors() {
+		details = append(details, ErrorResponseDetail{
+			Field:   ruleErr.Label,
+			Code:    ruleErr.Code,
+			Message: ruleErr.Message,
+		})
+	}
+	return details
+}
+
+func acceptsJSON(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package mux
+
+import "net/http"
+
+/////////////////////////////////////////////////////////////////////
+/////// HEAD SHORT-CIRCUIT
+/////////////////////////////////////////////////////////////////////
+
+// ShortCircuitHEAD changes how HEAD requests that fall back to this GET
+// route are served. By default, the full GET handler (and its middleware)
+// runs and its body is discarded, so that HEAD responses carry the same
+// status and headers as GET. With ShortCircuitHEAD, the router instead
+// responds with a 200 and the given headers, without running the handler
+// or any middleware, which is cheaper for expensive handlers.
+//
+// The trade-off is correctness: the response reflects nothing the handler
+// (or middleware) would have done, so headers such as Content-Length,
+// ETag, or Cache-Control are absent unless included in headers, and the
+// status is always 200, even where GET would fail (e.g., auth, 404s for
+// unknown resources). Only use it where that is acceptable. A dedicated
+// HEAD route, which takes precedence over the fallback, remains the way to
+// serve HEAD with custom logic. Returns the route for chaining. Call it at
+// registration time, before serving any requests.
+func (route *Route[I, O]) ShortCircuitHEAD(headers http.Header) *Route[I, O] {
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	route.headHeaders = headers
+	return route
+}
+
+func (route *Route[I, O]) getHeadHeaders() http.Header { return route.headHeaders }
+
+func serveShortCircuitHEAD(w http.ResponseWriter, headers http.Header) {
+	for k, values := range headers {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,117 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/river-now/river/kit/response"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// INVOKE (TEST HARNESS)
+/////////////////////////////////////////////////////////////////////
+
+var (
+	ErrInvokeNoMatch      = errors.New("mux: no route matched")
+	ErrInvokeNotTaskRoute = errors.New("mux: matched route is not a task handler route")
+)
+
+type InvokeResult[O any] struct {
+	// The handler's output. For handlers returning a StatusData, O is the
+	// wrapped data type. Zero if a task middleware short-circuited with an
+	// error or redirect.
+	Data O
+	// The status that ServeHTTP would have sent: the StatusData status if
+	// any, else the merged proxy status if any, else 200.
+	Status int
+	// The task middleware proxies merged with the task handler's proxy.
+	ResponseProxy *response.Proxy
+}
+
+// Invoke runs the task handler route matching method and path with the
+// given input, executing the same task middleware and TasksCtx pipeline
+// as ServeHTTP, but without parsing a request body or serializing a
+// response. Input must be of the route's input type (or nil for its
+// zero value). It is primarily intended for unit tests.
+//
+// HTTP middlewares are not run, as there is no response writer to wrap.
+// An error is returned if no route matches, if the matched route is not
+// a task handler route, if the input or output types do not match the
+// route, or if a task middleware or the task handler returns an error.
+func Invoke[O any](rt *Router, method, path string, input any) (*InvokeResult[O], error) {
+	r, err := http.NewRequestWithContext(context.Background(), method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return InvokeRequest[O](rt, r, input)
+}
+
+// InvokeRequest is like Invoke, but uses the provided request (e.g., to
+// set headers or cookies that task middlewares depend on). The request
+// body is ignored in favor of input.
+func InvokeRequest[O any](rt *Router, r *http.Request, input any) (*InvokeResult[O], error) {
+	best := rt.matchRequest(r)
+	if !best.didMatch {
+		return nil, fmt.Errorf("%w: %s %s", ErrInvokeNoMatch, r.Method, r.URL.Path)
+	}
+	match := best.match
+	mm := best.methodMatcher
+	route := mm.routes[match.OriginalPattern()]
+	if route.getHandlerType() != "task" {
+		return nil, fmt.Errorf("%w: %s %s", ErrInvokeNotTaskRoute, route.Method(), route.OriginalPattern())
+	}
+
+	r, tasksCtx := prepareTasksRequest(r, route, match, GetRequestID(r))
+	reqData, err := route.newReqDataWithInput(r, tasksCtx, match, input)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &InvokeResult[O]{}
+
+	var proxies []*response.Proxy
+	if collected := rt.gatherAllTaskMiddlewares(mm, route); len(collected) > 0 {
+		merged, err := runTaskMws(r, tasksCtx, reqData, route, collected)
+		if err != nil {
+			return nil, err
+		}
+		if merged.IsError() || merged.IsRedirect() {
+			res.ResponseProxy = merged
+			res.Status, _ = merged.GetStatus()
+			return res, nil
+		}
+		handOffSuccessStatus(merged, reqData)
+		proxies = append(proxies, merged)
+	}
+
+	data, explicitStatus, err := runTaskHandler(route, reqData)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		typed, ok := data.(O)
+		if !ok {
+			return nil, fmt.Errorf(
+				"mux: output of type %T does not match requested type %T", data, res.Data,
+			)
+		}
+		res.Data = typed
+	}
+
+	res.ResponseProxy = response.MergeProxyResponses(append(proxies, reqData.ResponseProxy())...)
+
+	switch proxyStatus, _ := res.ResponseProxy.GetStatus(); {
+	case res.ResponseProxy.IsError() || res.ResponseProxy.IsRedirect():
+		res.Status = proxyStatus
+	case explicitStatus != 0:
+		res.Status = explicitStatus
+	case proxyStatus != 0:
+		res.Status = proxyStatus
+	default:
+		res.Status = http.StatusOK
+	}
+
+	return res, nil
+}
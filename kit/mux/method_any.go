@@ -0,0 +1,47 @@
+package mux
+
+import "net/http"
+
+/////////////////////////////////////////////////////////////////////
+/////// ANY METHOD
+/////////////////////////////////////////////////////////////////////
+
+// MethodAny is the method under which RegisterTaskHandlerAny and
+// RegisterHandlerAny register routes. Pass it to SetMethodLevelTaskMiddleware,
+// SetMethodLevelHTTPMiddleware, or the ByPattern middleware setters to
+// target those routes. Method-level middleware set for specific methods
+// does not run for them.
+const MethodAny = "*"
+
+// RegisterTaskHandlerAny registers taskHandler for requests of every
+// method. Routes registered for a request's specific method always take
+// precedence: ANY routes are only consulted as a last resort, when no
+// route for the method (nor, for HEAD requests, for GET) matches the
+// path, even if the ANY pattern is more specific. HEAD requests matched
+// by an ANY route are passed through as is (the handler sees r.Method),
+// rather than being treated as GET.
+func RegisterTaskHandlerAny[I any, O any](
+	router *Router, pattern string, taskHandler *TaskHandler[I, O],
+) *Route[I, O] {
+	return RegisterTaskHandler(router, MethodAny, pattern, taskHandler)
+}
+
+// RegisterHandlerAny is the http.Handler equivalent of
+// RegisterTaskHandlerAny.
+func RegisterHandlerAny(router *Router, pattern string, httpHandler http.Handler) *Route[any, any] {
+	return RegisterHandler(router, MethodAny, pattern, httpHandler)
+}
+
+// Returns the best ANY route match for realPath, if any, and whether any
+// ANY routes are registered at all.
+func (rt *Router) findAnyMethodMatch(r *http.Request, realPath string) (out *findBestOutput, hasAny bool) {
+	anyMatcher, ok := rt.methodToMatcherMap[MethodAny]
+	if !ok || len(anyMatcher.routes) == 0 {
+		return nil, false
+	}
+	match, found := anyMatcher.findBestMatch(r, realPath)
+	if !found {
+		return nil, true
+	}
+	return &findBestOutput{methodMatcher: anyMatcher, match: match, didMatch: true}, true
+}
@@ -0,0 +1,33 @@
+package mux
+
+/////////////////////////////////////////////////////////////////////
+/////// METRICS
+/////////////////////////////////////////////////////////////////////
+
+// Metrics receives a call for every request the router serves,
+// distinguishing requests whose method has no registered routes at all
+// (IncNoMethod) from those whose method has routes but whose path matched
+// none of them (IncNoMatch), which are otherwise indistinguishable 404s.
+// Implementations must be safe for concurrent use and should be cheap
+// (e.g., incrementing counters), as they run inline on every request.
+type Metrics interface {
+	IncNoMethod()
+	IncNoMatch()
+	// pattern is the matched route's original pattern, which has bounded
+	// cardinality, so it is safe to use as a metrics label.
+	IncMatched(pattern string)
+}
+
+// Unmatched HEAD requests fall back to GET routes, and every method falls
+// back to ANY routes (see RegisterHandlerAny), so a request only counts as
+// IncNoMethod if there are no ANY routes either.
+func (rt *Router) recordMetrics(best *findBestOutput) {
+	switch {
+	case best.didMatch:
+		rt.metrics.IncMatched(best.match.OriginalPattern())
+	case best.noMethod:
+		rt.metrics.IncNoMethod()
+	default:
+		rt.metrics.IncNoMatch()
+	}
+}
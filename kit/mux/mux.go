@@ -1,11 +1,18 @@
 package mux
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"path"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/river-now/river/kit/colorlog"
 	"github.com/river-now/river/kit/contextutil"
@@ -34,8 +41,9 @@ var (
 // NOTES:
 // Order of registration of handlers does not matter. Order of middleware
 // registration DOES matter. For traditional middleware, it will run sequentially,
-// first to last. For task middleware, they will run with maximum parallelism, but
-// their response proxies will be merged according to the rules of response.Proxy.
+// first to last. For task middleware, they will run with maximum parallelism (unless
+// marked as sequential; see SequentialPhase), but their response proxies will be
+// merged according to the rules of response.Proxy.
 
 type (
 	None                      = genericsutil.None
@@ -56,6 +64,17 @@ type MiddlewareOptions struct {
 	// Return true if the middleware should be run for this request.
 	// If nil, the middleware will always run.
 	If func(r *http.Request) bool
+
+	// Like If, but also receives the matched route, so that the
+	// middleware can be gated on route metadata such as tags (see
+	// Route.Tag). If both If and IfRoute are set, both must return true
+	// for the middleware to run.
+	IfRoute func(r *http.Request, route AnyRoute) bool
+
+	// Task middleware only. If set, the middleware runs on its own,
+	// before or after the parallel batch, rather than as part of it. See
+	// SequentialPhase.
+	Sequential SequentialPhase
 }
 
 type (
@@ -66,14 +85,25 @@ type (
 )
 
 type Router struct {
-	parseInput         func(r *http.Request, iPtr any) error
-	httpMws            []httpMiddlewareWithOptions
-	taskMws            []taskMiddlewareWithOptions
-	methodToMatcherMap map[string]*methodMatcher
-	matcherOpts        *matcher.Options
-	notFoundHandler    http.Handler
-	mountRoot          string
-	allRoutes          []AnyRoute
+	parseInput          func(r *http.Request, iPtr any) error
+	allowEmptyBody      bool
+	httpMws             []httpMiddlewareWithOptions
+	taskMws             []taskMiddlewareWithOptions
+	methodToMatcherMap  map[string]*methodMatcher
+	matcherOpts         *matcher.Options
+	matcherFactory      func(*matcher.Options) Matcher
+	notFoundHandler     *unmatchedHandler
+	methodFallbacks     map[string]*unmatchedHandler
+	injectNotFoundCtx   bool
+	errorHandler        ErrorHandler
+	jsonEncoder         func(w io.Writer) response.JSONEncoder
+	bufferRequestBody   bool
+	maxRequestBodyBytes int64
+	requestIDConfig     *RequestIDConfig
+	slowRequestAfter    time.Duration
+	metrics             Metrics
+	mountRoot           string
+	allRoutes           []AnyRoute
 }
 
 func (rt *Router) AllRoutes() []AnyRoute {
@@ -127,6 +157,56 @@ type Options struct {
 	// and mutate the input ptr to the desired value (this is what will ultimately
 	// be returned by c.Input()).
 	ParseInput func(r *http.Request, inputPtr any) error
+	// Optional. If true, when ParseInput returns an error wrapping io.EOF
+	// (as JSON decoding an empty request body does), the error is ignored
+	// and the task handler receives the zero value of its input type.
+	// Note that any validation your ParseInput would have run on a
+	// successfully decoded input does not run on that zero value.
+	AllowEmptyBody bool
+	// Optional. Writes the response when input parsing, a task middleware,
+	// or a task handler fails. Defaults to DefaultErrorHandler, which
+	// responds with JSON or plain text according to the Accept header.
+	ErrorHandler ErrorHandler
+	// Optional. If true, the router reads the body of each matched request
+	// into memory before running any middleware, so that it can be read more
+	// than once: r.Body is re-seekable (and is rewound after ParseInput
+	// runs), and GetRawBody returns the raw bytes. Buffering is bounded by
+	// MaxRequestBodyBytes (or DefaultMaxBufferedRequestBodyBytes if unset).
+	BufferRequestBody bool
+	// Optional. If greater than zero, request bodies larger than this are
+	// rejected with a 413 (when buffering) or fail to read (otherwise).
+	MaxRequestBodyBytes int64
+	// Optional. If set, every request gets an ID (see RequestIDConfig),
+	// which is echoed in a response header, available to handlers and
+	// middlewares via GetRequestID, and logged by AccessLogMiddleware.
+	RequestID *RequestIDConfig
+	// Optional. If true, the global not-found handler always receives a
+	// fresh TasksCtx (available via GetTasksCtx), e.g., so an SPA fallback
+	// can run loaders for a catch-all shell. Not-found handlers that
+	// implement TasksCtxRequirer get one regardless of this setting.
+	InjectTasksCtxForNotFound bool
+	// Optional. If greater than zero, any request whose end-to-end
+	// handling by the router (including middlewares and applying response
+	// proxies) takes longer than this is logged as a warning, with its
+	// method, path, matched pattern, status, duration, and request ID (if
+	// Options.RequestID is set). This is independent of any timeout: the
+	// request is never interrupted.
+	SlowRequestThreshold time.Duration
+	// Optional. If set, the router reports every request it serves as
+	// matched (with its pattern), as having no routes for its method, or
+	// as having no route matching its path. See Metrics.
+	Metrics Metrics
+	// Optional. Creates the encoder used to write task handler responses
+	// (e.g., to disable HTML escaping for non-browser APIs, set
+	// indentation, or stream large values without buffering them).
+	// Defaults to json.NewEncoder.
+	JSONEncoder func(w io.Writer) response.JSONEncoder
+	// Optional. Creates the matcher used for each HTTP method's routes,
+	// given the router's matcher options (e.g., to match with a
+	// different strategy, or to wrap the built-in matcher). Defaults to
+	// matcher.New. See Matcher, and RequestMatcher for matching on more
+	// than the path (e.g., the host).
+	MatcherFactory func(*matcher.Options) Matcher
 }
 
 func NewRouter(options ...*Options) *Router {
@@ -153,13 +233,31 @@ func NewRouter(options ...*Options) *Router {
 			mountRootToUse = mountRootToUse + "/"
 		}
 	}
+	matcherFactory := opts.MatcherFactory
+	if matcherFactory == nil {
+		matcherFactory = defaultMatcherFactory
+	}
+	maxRequestBodyBytes := opts.MaxRequestBodyBytes
+	if opts.BufferRequestBody && maxRequestBodyBytes <= 0 {
+		maxRequestBodyBytes = DefaultMaxBufferedRequestBodyBytes
+	}
 	return &Router{
-		parseInput:         opts.ParseInput,
-		methodToMatcherMap: make(map[string]*methodMatcher),
-		matcherOpts:        matcherOpts,
-		mountRoot:          mountRootToUse,
-		httpMws:            emptyHTTPMws,
-		taskMws:            emptyTaskMws,
+		parseInput:          opts.ParseInput,
+		allowEmptyBody:      opts.AllowEmptyBody,
+		errorHandler:        opts.ErrorHandler,
+		jsonEncoder:         opts.JSONEncoder,
+		bufferRequestBody:   opts.BufferRequestBody,
+		maxRequestBodyBytes: maxRequestBodyBytes,
+		requestIDConfig:     resolveRequestIDConfig(opts.RequestID),
+		slowRequestAfter:    opts.SlowRequestThreshold,
+		metrics:             opts.Metrics,
+		injectNotFoundCtx:   opts.InjectTasksCtxForNotFound,
+		methodToMatcherMap:  make(map[string]*methodMatcher),
+		matcherOpts:         matcherOpts,
+		matcherFactory:      matcherFactory,
+		mountRoot:           mountRootToUse,
+		httpMws:             emptyHTTPMws,
+		taskMws:             emptyTaskMws,
 	}
 }
 
@@ -224,8 +322,41 @@ func SetPatternLevelHTTPMiddleware[I any, O any](route *Route[I, O], httpMw HTTP
 	})
 }
 
+// If httpHandler implements TasksCtxRequirer (or the router was created
+// with Options.InjectTasksCtxForNotFound), it receives a fresh TasksCtx.
 func SetGlobalNotFoundHTTPHandler(router *Router, httpHandler http.Handler) {
-	router.notFoundHandler = httpHandler
+	router.notFoundHandler = router.newUnmatchedHandler(httpHandler)
+}
+
+// A handler for requests that match no route (the not-found handler or a
+// method fallback)
+type unmatchedHandler struct {
+	handler       http.Handler
+	needsTasksCtx bool
+}
+
+func (rt *Router) newUnmatchedHandler(httpHandler http.Handler) *unmatchedHandler {
+	return &unmatchedHandler{
+		handler: httpHandler,
+		needsTasksCtx: rt.injectNotFoundCtx ||
+			reflectutil.ImplementsInterface(reflect.TypeOf(httpHandler), HandlerNeedsTasksCtxImplReflectType),
+	}
+}
+
+// SetMethodFallbackHandler sets a handler for requests of the given method
+// that match no registered pattern (including splats), e.g., to serve an
+// SPA shell for any unmatched GET while other methods still get the
+// global not-found handler. It never takes precedence over a registered
+// route, and, as with the not-found handler, no middleware runs for it.
+// A GET fallback also serves unmatched HEAD requests (without a body),
+// unless a HEAD fallback is set. If httpHandler implements
+// TasksCtxRequirer (or the router was created with
+// Options.InjectTasksCtxForNotFound), it receives a fresh TasksCtx.
+func SetMethodFallbackHandler(router *Router, method string, httpHandler http.Handler) {
+	if router.methodFallbacks == nil {
+		router.methodFallbacks = make(map[string]*unmatchedHandler)
+	}
+	router.methodFallbacks[method] = router.newUnmatchedHandler(httpHandler)
 }
 
 type Route[I, O any] struct {
@@ -239,12 +370,16 @@ type Route[I, O any] struct {
 	userHTTPHandler http.Handler
 	taskHandler     tasks.AnyTask
 	needsTasksCtx   bool
+	tags            []string
+	headHeaders     http.Header // see ShortCircuitHEAD
 	compiledHTTP    atomic.Value
 }
 
 type AnyRoute interface {
 	OriginalPattern() string
 	Method() string
+	Tags() []string
+	HasTag(tag string) bool
 	genericsutil.AnyZeroHelper
 	getHandlerType() string
 	getHTTPHandler() http.Handler
@@ -252,7 +387,11 @@ type AnyRoute interface {
 	getHTTPMws() []httpMiddlewareWithOptions
 	getTaskMws() []taskMiddlewareWithOptions
 	getNeedsTasksCtx() bool
+	getHeadHeaders() http.Header
+	addHTTPMw(mw httpMiddlewareWithOptions)
+	addTaskMw(mw taskMiddlewareWithOptions)
 	httpChain(rt *Router, mm *methodMatcher) http.Handler
+	newReqDataWithInput(r *http.Request, tasksCtx *tasks.Ctx, match *matcher.BestMatch, input any) (reqDataMarker, error)
 }
 
 func (route *Route[I, O]) OriginalPattern() string {
@@ -262,6 +401,37 @@ func (route *Route[I, O]) Method() string {
 	return route.method
 }
 
+// Tag attaches arbitrary string metadata to the route, which can be
+// consulted by middleware via MiddlewareOptions.IfRoute. Returns the
+// route for chaining. Tag routes at registration time, before serving
+// any requests.
+func (route *Route[I, O]) Tag(tags ...string) *Route[I, O] {
+	for _, tag := range tags {
+		if !slices.Contains(route.tags, tag) {
+			route.tags = append(route.tags, tag)
+		}
+	}
+	return route
+}
+func (route *Route[I, O]) Tags() []string {
+	return route.tags
+}
+func (route *Route[I, O]) HasTag(tag string) bool {
+	return slices.Contains(route.tags, tag)
+}
+
+// O returns the zero value of the route's output type. If the output
+// type is a StatusData wrapper, the zero value of the wrapped data type
+// is returned instead, so that consumers generating types from routes
+// see the JSON shape that is actually sent over the wire.
+func (route *Route[I, O]) O() any {
+	zero := genericsutil.Zero[O]()
+	if sd, ok := any(zero).(statusDataMarker); ok {
+		return sd.zeroData()
+	}
+	return zero
+}
+
 // TaskHandlers are used for JSON responses only, and they are intended to
 // be particularly convenient for sending JSON. If you need to send a different
 // content type, use a traditional http.Handler instead.
@@ -277,6 +447,22 @@ func RegisterTaskHandler[I any, O any](
 	return route
 }
 
+// RegisterTaskHandlerMethods registers the same task handler under each
+// of the given methods (e.g., "PUT" and "PATCH"), returning one route per
+// method, in the order given (duplicates are skipped). As each method has
+// its own route, attach any pattern-level middleware or tags to each of
+// the returned routes.
+func RegisterTaskHandlerMethods[I any, O any](
+	router *Router, methods []string, pattern string, taskHandler *TaskHandler[I, O],
+) []*Route[I, O] {
+	methods = dedupeMethods(methods)
+	routes := make([]*Route[I, O], 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, RegisterTaskHandler(router, method, pattern, taskHandler))
+	}
+	return routes
+}
+
 func RegisterHandlerFunc(
 	router *Router, method, pattern string, httpHandlerFunc http.HandlerFunc,
 ) *Route[any, any] {
@@ -298,6 +484,29 @@ func RegisterHandler(
 	return route
 }
 
+// RegisterHandlerMethods is the http.Handler equivalent of
+// RegisterTaskHandlerMethods.
+func RegisterHandlerMethods(
+	router *Router, methods []string, pattern string, httpHandler http.Handler,
+) []*Route[any, any] {
+	methods = dedupeMethods(methods)
+	routes := make([]*Route[any, any], 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, RegisterHandler(router, method, pattern, httpHandler))
+	}
+	return routes
+}
+
+func dedupeMethods(methods []string) []string {
+	deduped := make([]string, 0, len(methods))
+	for _, method := range methods {
+		if !slices.Contains(deduped, method) {
+			deduped = append(deduped, method)
+		}
+	}
+	return deduped
+}
+
 func (rd *ReqData[I]) Params() Params                 { return rd.params }
 func (rd *ReqData[I]) SplatValues() []string          { return rd.splatVals }
 func (rd *ReqData[I]) TasksCtx() *tasks.Ctx           { return rd.tasksCtx }
@@ -305,13 +514,92 @@ func (rd *ReqData[I]) Request() *http.Request         { return rd.req }
 func (rd *ReqData[I]) ResponseProxy() *response.Proxy { return rd.responseProxy }
 func (rd *ReqData[I]) Input() I                       { return rd.input }
 
+// StatusData wraps a task handler's output together with an explicit
+// HTTP status code. Construct one with WithStatus and use it as your
+// task handler's output type (e.g., TaskHandler[I, StatusData[O]]) when
+// you want to return something other than a 200 without needing a task
+// middleware, such as a 201 Created from a POST handler.
+//
+// Precedence: an error or redirect status set on the request's response
+// proxy always wins (no JSON is written in that case). Otherwise, a
+// non-zero explicit status takes precedence over any success status
+// set on the response proxy. A zero status means "no preference".
+type StatusData[O any] struct {
+	data   O
+	status int
+}
+
+func WithStatus[O any](data O, status int) StatusData[O] {
+	return StatusData[O]{data: data, status: status}
+}
+
+func (sd StatusData[O]) Data() O     { return sd.data }
+func (sd StatusData[O]) Status() int { return sd.status }
+
+// MarshalJSON encodes only the wrapped data.
+func (sd StatusData[O]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sd.data)
+}
+
+// FromMiddleware returns the output of a task middleware that ran for
+// the request and whose output type is T (e.g., a *User produced by an
+// auth middleware), so that handlers can consume it typed. If more than
+// one such middleware ran, the most specific one wins (pattern-level over
+// method-level over global, and later-registered over earlier within a
+// level). The bool reports whether any such output was found.
+func FromMiddleware[T any, I any](rd *ReqData[I]) (T, bool) {
+	return FromMiddlewareRequest[T](rd.req)
+}
+
+// FromMiddlewareRequest is like FromMiddleware, but for use in HTTP
+// handlers and middlewares (it reads from the request context).
+func FromMiddlewareRequest[T any](r *http.Request) (T, bool) {
+	var zero T
+	if r == nil {
+		return zero, false
+	}
+	rdt := requestStore.GetValueFromContext(r.Context())
+	if rdt == nil {
+		return zero, false
+	}
+	for _, output := range slices.Backward(rdt.mwOutputs) {
+		if typed, ok := output.(T); ok {
+			return typed, true
+		}
+	}
+	return zero, false
+}
+
+// GetTasksCtx returns the request's TasksCtx. For matched routes, it is
+// available to HTTP handlers and middlewares whether or not the route has
+// task middleware (routes on the fast path get one lazily, on first call).
 func GetTasksCtx(r *http.Request) *tasks.Ctx {
 	if rd := requestStore.GetValueFromContext(r.Context()); rd != nil {
-		return rd.tasksCtx
+		return rd.getTasksCtx()
+	}
+	return nil
+}
+
+// GetMatchedRoute returns the route matched by the router serving the
+// request, or nil if none matched (e.g., in a not-found handler). Unlike
+// the raw URL path, the route's pattern has bounded cardinality, so it is
+// suitable for metrics labels.
+func GetMatchedRoute(r *http.Request) AnyRoute {
+	if rd := requestStore.GetValueFromContext(r.Context()); rd != nil {
+		return rd.route
 	}
 	return nil
 }
 
+// GetMatchedPattern returns the original pattern of the matched route
+// (see GetMatchedRoute), or an empty string if none matched.
+func GetMatchedPattern(r *http.Request) string {
+	if route := GetMatchedRoute(r); route != nil {
+		return route.OriginalPattern()
+	}
+	return ""
+}
+
 func GetParam(r *http.Request, key string) string {
 	return GetParams(r)[key]
 }
@@ -331,34 +619,44 @@ func GetSplatValues(r *http.Request) []string {
 }
 
 func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	pathToUse := r.URL.Path
-	if rt.mountRoot != "" && strings.HasPrefix(pathToUse, rt.mountRoot) {
-		pathToUse = "/" + pathToUse[len(rt.mountRoot):]
+	if rt.slowRequestAfter > 0 {
+		rt.serveAndLogIfSlow(w, r)
+		return
+	}
+	rt.serve(w, r)
+}
+
+// Returns the request ID and match (if any), for slow request logging.
+func (rt *Router) serve(w http.ResponseWriter, r *http.Request) (requestID string, best *findBestOutput) {
+	requestID = rt.ensureRequestID(w, r)
+	best = rt.matchRequest(r)
+	if rt.metrics != nil {
+		rt.recordMetrics(best)
 	}
-	best := rt.findBestMatcherAndMatch(r.Method, pathToUse)
 	if !best.didMatch {
-		if rt.notFoundHandler != nil {
-			rt.notFoundHandler.ServeHTTP(w, r)
-		} else {
-			http.NotFound(w, r)
-		}
+		rt.serveNotFound(w, r, requestID)
 		return
 	}
+	recordAccessLogMatch(r, best)
 	match := best.match
 	mm := best.methodMatcher
 	route := mm.routes[match.OriginalPattern()]
-	// Fast path for pure HTTP handlers without task middleware
+	if best.headFellBackToGet && route.getHeadHeaders() != nil {
+		serveShortCircuitHEAD(w, route.getHeadHeaders())
+		return
+	}
+	r, ok := rt.prepareRequestBody(w, r)
+	if !ok {
+		return
+	}
+	// Fast path for pure HTTP handlers without task middleware. The request
+	// data is still always stored, so that GetParams, GetSplatValues, and
+	// GetTasksCtx behave the same on both paths (only the TasksCtx is
+	// deferred until first requested).
 	if route.getHandlerType() == "http" &&
 		!rt.hasAnyTaskMiddleware(mm, route) &&
 		!route.getNeedsTasksCtx() {
-		if len(match.Params) > 0 || len(match.SplatValues) > 0 {
-			rd := &rdTransport{
-				params:    match.Params,
-				splatVals: match.SplatValues,
-				req:       r,
-			}
-			r = requestStore.GetRequestWithContext(r, rd)
-		}
+		r = requestStore.GetRequestWithContext(r, newRDTransport(r, route, match, requestID))
 		handler := route.httpChain(rt, mm)
 		if best.headFellBackToGet {
 			treatGetAsHead(handler, w, r)
@@ -368,25 +666,16 @@ func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Slow path: create TasksCtx and full request data
-	tasksCtx := tasks.NewCtx(r.Context())
-	rd := &rdTransport{
-		params:        match.Params,
-		splatVals:     match.SplatValues,
-		tasksCtx:      tasksCtx,
-		req:           r,
-		responseProxy: response.NewProxy(),
-	}
-	r = requestStore.GetRequestWithContext(r, rd)
+	r, tasksCtx := prepareTasksRequest(r, route, match, requestID)
 	reqGetter := mm.reqDataGetters[match.OriginalPattern()]
 	reqData, err := reqGetter.getReqData(r, tasksCtx, match)
 	if err != nil {
 		if validate.IsValidationError(err) {
 			muxLog.Error("Validation error", "error", err, "pattern", match.OriginalPattern())
-			http.Error(w, err.Error(), http.StatusBadRequest)
 		} else {
 			muxLog.Error("Internal server error", "error", err, "pattern", match.OriginalPattern())
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
+		rt.writeError(w, r, err)
 		return
 	}
 	var finalHandler http.Handler
@@ -401,25 +690,149 @@ func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	} else {
 		handlerWithMW.ServeHTTP(w, r)
 	}
+	return requestID, best
 }
 
 /////////////////////////////////////////////////////////////////////
 /////// PRIVATE API
 /////////////////////////////////////////////////////////////////////
 
+func (rt *Router) matchRequest(r *http.Request) *findBestOutput {
+	pathToUse := r.URL.Path
+	if rt.mountRoot != "" && strings.HasPrefix(pathToUse, rt.mountRoot) {
+		pathToUse = "/" + pathToUse[len(rt.mountRoot):]
+	}
+	return rt.findBestMatcherAndMatch(r, pathToUse)
+}
+
+func (rt *Router) serveNotFound(w http.ResponseWriter, r *http.Request, requestID string) {
+	if fallback, isHeadAsGet := rt.getMethodFallback(r.Method); fallback != nil {
+		r = prepareUnmatchedRequest(r, requestID, fallback.needsTasksCtx)
+		if isHeadAsGet {
+			treatGetAsHead(fallback.handler, w, r)
+		} else {
+			fallback.handler.ServeHTTP(w, r)
+		}
+		return
+	}
+	if rt.notFoundHandler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	rt.notFoundHandler.handler.ServeHTTP(w, prepareUnmatchedRequest(r, requestID, rt.notFoundHandler.needsTasksCtx))
+}
+
+func (rt *Router) getMethodFallback(method string) (fallback *unmatchedHandler, isHeadAsGet bool) {
+	if fallback = rt.methodFallbacks[method]; fallback != nil {
+		return fallback, false
+	}
+	if method == http.MethodHead {
+		if fallback = rt.methodFallbacks[http.MethodGet]; fallback != nil {
+			return fallback, true
+		}
+	}
+	return nil, false
+}
+
+func prepareUnmatchedRequest(r *http.Request, requestID string, needsTasksCtx bool) *http.Request {
+	if needsTasksCtx {
+		r, _ = prepareTasksRequest(r, nil, &matcher.BestMatch{
+			Params:      emptyParams,
+			SplatValues: emptySplatValues,
+		}, requestID)
+	} else if requestID != "" {
+		r = requestStore.GetRequestWithContext(r, &rdTransport{
+			params:    emptyParams,
+			splatVals: emptySplatValues,
+			req:       r,
+			requestID: requestID,
+		})
+	}
+	return r
+}
+
+// Creates a fresh TasksCtx for the request and stores the request-level
+// data (matched route, params, splat values, TasksCtx) in the request
+// context. The route is nil for unmatched requests.
+func prepareTasksRequest(r *http.Request, route AnyRoute, match *matcher.BestMatch, requestID string) (*http.Request, *tasks.Ctx) {
+	rd := newRDTransport(r, route, match, requestID)
+	rd.tasksCtx = tasks.NewCtx(r.Context())
+	rd.responseProxy = response.NewProxy()
+	return requestStore.GetRequestWithContext(r, rd), rd.tasksCtx
+}
+
+// Params and splat values are normalized to their empty (non-nil) forms,
+// so that callers see the same values regardless of the path taken.
+func newRDTransport(r *http.Request, route AnyRoute, match *matcher.BestMatch, requestID string) *rdTransport {
+	rd := &rdTransport{
+		route:     route,
+		requestID: requestID,
+		params:    match.Params,
+		splatVals: match.SplatValues,
+		req:       r,
+	}
+	if rd.params == nil {
+		rd.params = emptyParams
+	}
+	if rd.splatVals == nil {
+		rd.splatVals = emptySplatValues
+	}
+	return rd
+}
+
 type rdTransport struct {
+	route         AnyRoute
 	params        Params
 	splatVals     []string
 	tasksCtx      *tasks.Ctx
+	tasksCtxOnce  sync.Once
 	req           *http.Request
 	responseProxy *response.Proxy
+	requestID     string
+	// Outputs of the task middlewares that run, in registration order (one
+	// slot each, nil until written). runTaskMws writes a slot as soon as
+	// the batch holding its middleware completes: each SequentialBefore
+	// middleware alone, then all NotSequential ones together, then each
+	// SequentialAfter one alone. So a middleware can read the outputs of
+	// earlier batches (but not those of its NotSequential peers), and the
+	// handler can read them all. Slots of middlewares skipped after an
+	// error or redirect stay nil.
+	mwOutputs []any
+}
+
+func (rd *rdTransport) getTasksCtx() *tasks.Ctx {
+	rd.tasksCtxOnce.Do(func() {
+		if rd.tasksCtx == nil && rd.req != nil {
+			rd.tasksCtx = tasks.NewCtx(rd.req.Context())
+		}
+	})
+	return rd.tasksCtx
+}
+
+func (opts *MiddlewareOptions) isConditional() bool {
+	return opts != nil && (opts.If != nil || opts.IfRoute != nil)
+}
+
+func (opts *MiddlewareOptions) shouldRun(r *http.Request, route AnyRoute) bool {
+	if opts == nil {
+		return true
+	}
+	if opts.If != nil && !opts.If(r) {
+		return false
+	}
+	if opts.IfRoute != nil && !opts.IfRoute(r, route) {
+		return false
+	}
+	return true
 }
 
-func applyHTTPMiddlewareWithOptions(mwWithOpts httpMiddlewareWithOptions, handler http.Handler) http.Handler {
-	if mwWithOpts.opts != nil && mwWithOpts.opts.If != nil {
+func applyHTTPMiddlewareWithOptions(
+	mwWithOpts httpMiddlewareWithOptions, handler http.Handler, route AnyRoute,
+) http.Handler {
+	if mwWithOpts.opts.isConditional() {
 		originalHandler := handler
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !mwWithOpts.opts.If(r) {
+			if !mwWithOpts.opts.shouldRun(r, route) {
 				originalHandler.ServeHTTP(w, r)
 			} else {
 				mwWithOpts.mw(originalHandler).ServeHTTP(w, r)
@@ -431,18 +844,19 @@ func applyHTTPMiddlewareWithOptions(mwWithOpts httpMiddlewareWithOptions, handle
 
 func applyHTTPMiddlewares(
 	handler http.Handler,
+	route AnyRoute,
 	routeMws []httpMiddlewareWithOptions,
 	methodMws []httpMiddlewareWithOptions,
 	globalMws []httpMiddlewareWithOptions,
 ) http.Handler { // Apply in reverse order for proper nesting
 	for i := len(routeMws) - 1; i >= 0; i-- { // Pattern-level middlewares (innermost)
-		handler = applyHTTPMiddlewareWithOptions(routeMws[i], handler)
+		handler = applyHTTPMiddlewareWithOptions(routeMws[i], handler, route)
 	}
 	for i := len(methodMws) - 1; i >= 0; i-- { // Method-level middlewares
-		handler = applyHTTPMiddlewareWithOptions(methodMws[i], handler)
+		handler = applyHTTPMiddlewareWithOptions(methodMws[i], handler, route)
 	}
 	for i := len(globalMws) - 1; i >= 0; i-- { // Global middlewares (outermost)
-		handler = applyHTTPMiddlewareWithOptions(globalMws[i], handler)
+		handler = applyHTTPMiddlewareWithOptions(globalMws[i], handler, route)
 	}
 	return handler
 }
@@ -450,10 +864,12 @@ func applyHTTPMiddlewares(
 type middlewareBoundTask struct {
 	taskToRun tasks.AnyTask
 	input     *ReqData[None]
+	output    any
 }
 
 func (m *middlewareBoundTask) Run(ctx *tasks.Ctx) error {
-	_, err := m.taskToRun.RunWithAnyInput(ctx, m.input)
+	output, err := m.taskToRun.RunWithAnyInput(ctx, m.input)
+	m.output = output
 	return err
 }
 
@@ -475,29 +891,53 @@ func (rt *Router) gatherAllTaskMiddlewares(
 func (rt *Router) createTaskFinalHandler(route AnyRoute, reqDataMarker reqDataMarker) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		res := response.New(w)
-		taskHandler := route.getTaskHandler()
-		inputData := reqDataMarker.getUnderlyingReqDataInstance()
-		data, err := taskHandler.RunWithAnyInput(reqDataMarker.TasksCtx(), inputData)
+		res.NewJSONEncoder = rt.jsonEncoder
+		data, explicitStatus, err := runTaskHandler(route, reqDataMarker)
 		if err != nil {
-			muxLog.Error("Error executing task handler", "error", err, "pattern", route.OriginalPattern())
-			res.InternalServerError()
+			if httpErr, ok := AsHTTPError(err); !ok || httpErr.status() >= 500 {
+				muxLog.Error("Error executing task handler", "error", err, "pattern", route.OriginalPattern())
+			}
+			rt.writeError(w, r, err)
 			return
 		}
 		responseProxy := reqDataMarker.ResponseProxy()
-		responseProxy.ApplyToResponseWriter(w, r)
 		if responseProxy.IsError() || responseProxy.IsRedirect() {
+			responseProxy.ApplyToResponseWriter(w, r)
 			return // Don't write JSON after error/redirect
 		}
+		if explicitStatus != 0 {
+			responseProxy.SetStatus(0) // Explicit status wins over proxy success status
+		}
+		responseProxy.ApplyToResponseWriter(w, r)
 		if reflectutil.ExcludingNoneGetIsNilOrUltimatelyPointsToNil(data) {
 			muxLog.Warn(
 				"Do not return nil values from task handlers unless: (i) the underlying type is an empty struct or pointer to an empty struct; or (ii) you are returning an error.",
 				"pattern", route.OriginalPattern(),
 			)
 		}
+		if explicitStatus != 0 {
+			res.SetHeader("Content-Type", "application/json")
+			res.SetStatus(explicitStatus)
+		}
 		res.JSON(data)
 	})
 }
 
+// Runs the route's task handler, unwrapping any StatusData into its data
+// and explicit status (zero if none).
+func runTaskHandler(route AnyRoute, reqDataMarker reqDataMarker) (any, int, error) {
+	inputData := reqDataMarker.getUnderlyingReqDataInstance()
+	data, err := route.getTaskHandler().RunWithAnyInput(reqDataMarker.TasksCtx(), inputData)
+	if err != nil {
+		return nil, 0, err
+	}
+	explicitStatus := 0
+	if sd, ok := data.(statusDataMarker); ok {
+		explicitStatus, data = sd.statusAndData()
+	}
+	return data, explicitStatus, nil
+}
+
 func (rt *Router) runAppropriateMws(
 	tasksCtx *tasks.Ctx,
 	reqDataMarker reqDataMarker,
@@ -509,51 +949,129 @@ func (rt *Router) runAppropriateMws(
 	if routeMarker.getHandlerType() == "http" {
 		handlerWithHTTPMws = finalHandler
 	} else {
-		handlerWithHTTPMws = applyHTTPMiddlewares(finalHandler, routeMarker.getHTTPMws(), methodMatcher.httpMws, rt.httpMws)
+		handlerWithHTTPMws = applyHTTPMiddlewares(finalHandler, routeMarker, routeMarker.getHTTPMws(), methodMatcher.httpMws, rt.httpMws)
 	}
 	collected := rt.gatherAllTaskMiddlewares(methodMatcher, routeMarker)
 	if len(collected) == 0 {
 		return handlerWithHTTPMws
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		boundTasks := make([]tasks.BoundTask, 0, len(collected))
-		reqDataInstances := make([]*ReqData[None], 0, len(collected))
-		for _, taskWithOpts := range collected {
-			if taskWithOpts.opts != nil && taskWithOpts.opts.If != nil && !taskWithOpts.opts.If(r) {
-				continue
-			}
-			rdForMw := &ReqData[None]{
-				params:        reqDataMarker.Params(),
-				splatVals:     reqDataMarker.SplatValues(),
-				tasksCtx:      tasksCtx,
-				input:         None{},
-				req:           r,
-				responseProxy: response.NewProxy(),
-			}
-			reqDataInstances = append(reqDataInstances, rdForMw)
-			boundTasks = append(boundTasks, &middlewareBoundTask{
-				taskToRun: taskWithOpts.mw,
-				input:     rdForMw,
-			})
-		}
-		if err := tasksCtx.RunParallel(boundTasks...); err != nil {
-			muxLog.Error("Error during parallel middleware execution", "error", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		merged, err := runTaskMws(r, tasksCtx, reqDataMarker, routeMarker, collected)
+		if err != nil {
+			muxLog.Error("Error during task middleware execution", "error", err)
+			rt.writeError(w, r, err)
 			return
 		}
-		proxies := make([]*response.Proxy, len(reqDataInstances))
-		for i, rdInst := range reqDataInstances {
-			proxies[i] = rdInst.ResponseProxy()
-		}
-		merged := response.MergeProxyResponses(proxies...)
-		merged.ApplyToResponseWriter(w, r)
 		if merged.IsError() || merged.IsRedirect() {
+			merged.ApplyToResponseWriter(w, r)
 			return
 		}
+		if routeMarker.getHandlerType() == "task" {
+			handOffSuccessStatus(merged, reqDataMarker)
+		}
+		merged.ApplyToResponseWriter(w, r)
 		handlerWithHTTPMws.ServeHTTP(w, r)
 	})
 }
 
+// Runs the applicable task middlewares (in parallel, apart from any
+// sequential ones; see SequentialPhase), each with its own response proxy,
+// and returns the merged proxy.
+func runTaskMws(
+	r *http.Request,
+	tasksCtx *tasks.Ctx,
+	reqDataMarker reqDataMarker,
+	routeMarker AnyRoute,
+	collected []taskMiddlewareWithOptions,
+) (*response.Proxy, error) {
+	var phases [3][]int // indices into mwBoundTasks, by SequentialPhase
+	mwBoundTasks := make([]*middlewareBoundTask, 0, len(collected))
+	reqDataInstances := make([]*ReqData[None], 0, len(collected))
+	for _, taskWithOpts := range collected {
+		if !taskWithOpts.opts.shouldRun(r, routeMarker) {
+			continue
+		}
+		rdForMw := &ReqData[None]{
+			params:        reqDataMarker.Params(),
+			splatVals:     reqDataMarker.SplatValues(),
+			tasksCtx:      tasksCtx,
+			input:         None{},
+			req:           r,
+			responseProxy: response.NewProxy(),
+		}
+		reqDataInstances = append(reqDataInstances, rdForMw)
+		mwBoundTask := &middlewareBoundTask{
+			taskToRun: taskWithOpts.mw,
+			input:     rdForMw,
+		}
+		phase := taskWithOpts.opts.sequentialPhase()
+		phases[phase] = append(phases[phase], len(mwBoundTasks))
+		mwBoundTasks = append(mwBoundTasks, mwBoundTask)
+	}
+	// Outputs are published after each run, so that later phases (and
+	// later sequential middlewares) can read them via FromMiddleware.
+	rdt := requestStore.GetValueFromContext(r.Context())
+	if rdt != nil {
+		rdt.mwOutputs = make([]any, len(mwBoundTasks))
+	}
+	run := func(indices []int) (stop bool, err error) {
+		boundTasks := make([]tasks.BoundTask, len(indices))
+		for i, idx := range indices {
+			boundTasks[i] = mwBoundTasks[idx]
+		}
+		if err := tasksCtx.RunParallel(boundTasks...); err != nil {
+			return true, err
+		}
+		for _, idx := range indices {
+			if rdt != nil {
+				rdt.mwOutputs[idx] = mwBoundTasks[idx].output
+			}
+			if proxy := reqDataInstances[idx].ResponseProxy(); proxy.IsError() || proxy.IsRedirect() {
+				stop = true
+			}
+		}
+		return stop, nil
+	}
+	var batches [][]int
+	for _, idx := range phases[SequentialBefore] {
+		batches = append(batches, []int{idx})
+	}
+	if len(phases[NotSequential]) > 0 {
+		batches = append(batches, phases[NotSequential])
+	}
+	for _, idx := range phases[SequentialAfter] {
+		batches = append(batches, []int{idx})
+	}
+	order := make([]int, 0, len(mwBoundTasks))
+	for _, batch := range batches {
+		order = append(order, batch...)
+		stop, err := run(batch)
+		if err != nil {
+			return nil, err
+		}
+		if stop {
+			break
+		}
+	}
+	proxies := make([]*response.Proxy, len(order))
+	for i, idx := range order {
+		proxies[i] = reqDataInstances[idx].ResponseProxy()
+	}
+	return response.MergeProxyResponses(proxies...), nil
+}
+
+// Hands any success status off to the task handler's own proxy instead
+// of writing it with the middleware proxy, so that an explicit StatusData
+// status returned by the handler can still take precedence.
+func handOffSuccessStatus(merged *response.Proxy, reqDataMarker reqDataMarker) {
+	if status, _ := merged.GetStatus(); status != 0 {
+		if handlerStatus, _ := reqDataMarker.ResponseProxy().GetStatus(); handlerStatus == 0 {
+			reqDataMarker.ResponseProxy().SetStatus(status)
+		}
+		merged.SetStatus(0)
+	}
+}
+
 func newRouteStruct[I any, O any](router *Router, method, originalPattern string) *Route[I, O] {
 	return &Route[I, O]{
 		router: router, method: method, originalPattern: originalPattern,
@@ -565,6 +1083,7 @@ func (rt *Router) registerRoute(route AnyRoute) {
 	methodMatcher := rt.getOrCreateMethodMatcher(route.Method())
 	methodMatcher.matcher.RegisterPattern(route.OriginalPattern())
 	methodMatcher.routes[route.OriginalPattern()] = route
+	methodMatcher.attachPendingPatternMws(route)
 	rt.allRoutes = append(rt.allRoutes, route)
 }
 
@@ -580,8 +1099,12 @@ func createReqDataGetter[I any, O any](route *Route[I, O]) reqDataGetter {
 			inputPtr := route.IPtr()
 			if route.router.parseInput != nil && !genericsutil.IsNone(route.I()) {
 				if err := route.router.parseInput(reqData.Request(), inputPtr); err != nil {
-					return nil, err
+					if !route.router.allowEmptyBody || !errors.Is(err, io.EOF) {
+						return nil, err
+					}
+					inputPtr = route.IPtr() // discard anything partially decoded
 				}
+				rewindBody(r)
 			}
 			reqData.input = *(inputPtr.(*I))
 			return reqData, nil
@@ -594,7 +1117,7 @@ func (rt *Router) getOrCreateMethodMatcher(method string) *methodMatcher {
 		return mm
 	}
 	mm := &methodMatcher{
-		matcher:        matcher.New(rt.matcherOpts),
+		matcher:        rt.matcherFactory(rt.matcherOpts),
 		routes:         make(map[string]AnyRoute),
 		reqDataGetters: make(map[string]reqDataGetter),
 		httpMws:        emptyHTTPMws,
@@ -609,13 +1132,15 @@ type findBestOutput struct {
 	match             *matcher.BestMatch
 	didMatch          bool
 	headFellBackToGet bool
+	noMethod          bool // no routes are registered for the method
 }
 
-func (rt *Router) findBestMatcherAndMatch(method string, realPath string) *findBestOutput {
+func (rt *Router) findBestMatcherAndMatch(r *http.Request, realPath string) *findBestOutput {
+	method := r.Method
 	isHead := method == http.MethodHead
 	if isHead {
 		if headMatcher, ok := rt.methodToMatcherMap[http.MethodHead]; ok {
-			if match, found := headMatcher.matcher.FindBestMatch(realPath); found {
+			if match, found := headMatcher.findBestMatch(r, realPath); found {
 				return &findBestOutput{
 					methodMatcher: headMatcher,
 					match:         match,
@@ -626,19 +1151,22 @@ func (rt *Router) findBestMatcherAndMatch(method string, realPath string) *findB
 		method = http.MethodGet
 	}
 	methodMatcher, ok := rt.methodToMatcherMap[method]
-	if !ok {
-		return &findBestOutput{}
-	}
-	match, ok := methodMatcher.matcher.FindBestMatch(realPath)
-	if !ok {
-		return &findBestOutput{}
+	hasMethod := ok && len(methodMatcher.routes) > 0
+	if hasMethod {
+		if match, found := methodMatcher.findBestMatch(r, realPath); found {
+			return &findBestOutput{
+				methodMatcher:     methodMatcher,
+				match:             match,
+				didMatch:          true,
+				headFellBackToGet: isHead,
+			}
+		}
 	}
-	return &findBestOutput{
-		methodMatcher:     methodMatcher,
-		match:             match,
-		didMatch:          true,
-		headFellBackToGet: isHead,
+	anyOut, hasAny := rt.findAnyMethodMatch(r, realPath)
+	if anyOut != nil {
+		return anyOut
 	}
+	return &findBestOutput{noMethod: !hasMethod && !hasAny}
 }
 
 func (rt *Router) hasAnyTaskMiddleware(methodMatcher *methodMatcher, route AnyRoute) bool {
@@ -658,11 +1186,13 @@ type taskMiddlewareWithOptions struct {
 }
 
 type methodMatcher struct {
-	matcher        *matcher.Matcher
+	matcher        Matcher
 	httpMws        []httpMiddlewareWithOptions
 	taskMws        []taskMiddlewareWithOptions
 	routes         map[string]AnyRoute
 	reqDataGetters map[string]reqDataGetter
+	// See SetPatternLevelHTTPMiddlewareByPattern
+	pendingPatternMws map[string]*pendingPatternMws
 }
 
 func getFirstOpt(opts []*MiddlewareOptions) *MiddlewareOptions {
@@ -682,11 +1212,34 @@ func (r *Route[I, O]) httpChain(rt *Router, mm *methodMatcher) http.Handler {
 	if h, ok := r.compiledHTTP.Load().(http.Handler); ok {
 		return h
 	}
-	h := applyHTTPMiddlewares(r.getHTTPHandler(), r.httpMws, mm.httpMws, rt.httpMws)
+	h := applyHTTPMiddlewares(r.getHTTPHandler(), r, r.httpMws, mm.httpMws, rt.httpMws)
 	r.compiledHTTP.Store(h)
 	return h
 }
 
+func (route *Route[I, O]) newReqDataWithInput(
+	r *http.Request, tasksCtx *tasks.Ctx, match *matcher.BestMatch, input any,
+) (reqDataMarker, error) {
+	var typedInput I
+	if input != nil {
+		var ok bool
+		if typedInput, ok = input.(I); !ok {
+			return nil, fmt.Errorf(
+				"mux: input of type %T does not match input type %s of route %s %s",
+				input, reflect.TypeFor[I](), route.method, route.originalPattern,
+			)
+		}
+	}
+	return &ReqData[I]{
+		params:        match.Params,
+		splatVals:     match.SplatValues,
+		tasksCtx:      tasksCtx,
+		input:         typedInput,
+		req:           r,
+		responseProxy: response.NewProxy(),
+	}, nil
+}
+
 type reqDataMarker interface {
 	getInput() any
 	getUnderlyingReqDataInstance() any
@@ -700,6 +1253,14 @@ type reqDataMarker interface {
 func (rd *ReqData[I]) getInput() any                     { return rd.input }
 func (rd *ReqData[I]) getUnderlyingReqDataInstance() any { return rd }
 
+type statusDataMarker interface {
+	statusAndData() (int, any)
+	zeroData() any
+}
+
+func (sd StatusData[O]) statusAndData() (int, any) { return sd.status, sd.data }
+func (StatusData[O]) zeroData() any                { return genericsutil.Zero[O]() }
+
 type reqDataGetter interface {
 	getReqData(
 		r *http.Request, tasksCtx *tasks.Ctx, match *matcher.BestMatch,
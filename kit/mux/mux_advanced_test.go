@@ -1,14 +1,30 @@
 package mux
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/river-now/river/kit/matcher"
+	"github.com/river-now/river/kit/response"
+	"github.com/river-now/river/kit/tasks"
+	"github.com/river-now/river/kit/validate"
 )
 
 func TestTaskMiddleware_Interactions(t *testing.T) {
@@ -131,10 +147,10 @@ func TestTaskMiddleware_Interactions(t *testing.T) {
 			t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
 		}
 		traces := w.Header().Values("X-Multi-Trace")
-		// Order of AddHeader can be non-deterministic for parallel tasks if underlying map write is not guarded
-		// For testing, we just check presence and count. response.MergeProxyResponses handles combining them.
-		if len(traces) != 3 {
-			t.Errorf("Expected 3 X-Multi-Trace headers, got %d: %v", len(traces), traces)
+		// Proxies are merged in registration order (global, method, pattern),
+		// regardless of the order in which the parallel middlewares finish.
+		if strings.Join(traces, ",") != "MW1,MW2,MW3" {
+			t.Errorf("Expected X-Multi-Trace headers [MW1 MW2 MW3], got %v", traces)
 		}
 	})
 }
@@ -508,6 +524,64 @@ func TestParseInputEdgeCases(t *testing.T) {
 			t.Errorf("Expected mutated input 'original_mutated', got %q", receivedInput.Field)
 		}
 	})
+
+	jsonParseInput := func(req *http.Request, inputPtr any) error {
+		if err := json.NewDecoder(req.Body).Decode(inputPtr); err != nil {
+			return fmt.Errorf("decoding input: %w", err)
+		}
+		return nil
+	}
+
+	t.Run("EmptyBodyErrorsByDefault", func(t *testing.T) {
+		r := NewRouter(&Options{ParseInput: jsonParseInput})
+		RegisterTaskHandler(r, http.MethodPost, "/test", TaskHandlerFromFunc(func(rd *ReqData[MyInput]) (MyOutput, error) {
+			t.Error("Handler should not run when the body is empty and AllowEmptyBody is false")
+			return MyOutput{}, nil
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("EmptyBodyYieldsZeroInputWhenAllowed", func(t *testing.T) {
+		r := NewRouter(&Options{ParseInput: jsonParseInput, AllowEmptyBody: true})
+		var handlerRan bool
+		var receivedInput MyInput
+		RegisterTaskHandler(r, http.MethodPost, "/test", TaskHandlerFromFunc(func(rd *ReqData[MyInput]) (MyOutput, error) {
+			handlerRan = true
+			receivedInput = rd.Input()
+			return MyOutput{OutputField: "ok"}, nil
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status OK, got %d", w.Code)
+		}
+		if !handlerRan {
+			t.Error("Expected handler to run")
+		}
+		if receivedInput != (MyInput{}) {
+			t.Errorf("Expected zero input, got %+v", receivedInput)
+		}
+	})
+
+	t.Run("MalformedBodyStillErrorsWhenAllowed", func(t *testing.T) {
+		r := NewRouter(&Options{ParseInput: jsonParseInput, AllowEmptyBody: true})
+		RegisterTaskHandler(r, http.MethodPost, "/test", TaskHandlerFromFunc(func(rd *ReqData[MyInput]) (MyOutput, error) {
+			t.Error("Handler should not run for a truncated body")
+			return MyOutput{}, nil
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"field":`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
 }
 
 func TestTasksCtxRequirer(t *testing.T) {
@@ -554,11 +628,15 @@ func TestTasksCtxRequirer(t *testing.T) {
 	t.Run("Regular_Handler_Without_TasksCtxRequirer", func(t *testing.T) {
 		router := NewRouter(nil)
 
-		// Regular handler that doesn't implement TasksCtxRequirer
+		// Regular handler that doesn't implement TasksCtxRequirer (takes
+		// the fast path, so its TasksCtx is created lazily)
 		RegisterHandlerFunc(router, http.MethodGet, "/test", func(w http.ResponseWriter, r *http.Request) {
 			tasksCtx := GetTasksCtx(r)
-			if tasksCtx != nil {
-				t.Error("TasksCtx should not be available for regular handlers without middleware")
+			if tasksCtx == nil {
+				t.Error("TasksCtx should be lazily available for regular handlers without middleware")
+			}
+			if GetTasksCtx(r) != tasksCtx {
+				t.Error("Expected the same lazily created TasksCtx on repeated calls")
 			}
 			w.WriteHeader(http.StatusOK)
 		})
@@ -637,6 +715,95 @@ func TestTaskHandlerErrors(t *testing.T) {
 	})
 }
 
+func TestTaskHandlerHTTPErrors(t *testing.T) {
+	var logBuf strings.Builder
+	prevLog := muxLog
+	muxLog = slog.New(slog.NewTextHandler(&logBuf, nil))
+	defer func() { muxLog = prevLog }()
+
+	router := NewRouter(nil)
+	RegisterTaskHandler(router, http.MethodGet, "/status/:code", TaskHandlerFromFunc(func(rd *ReqData[None]) (None, error) {
+		switch rd.Params()["code"] {
+		case "404":
+			return None{}, &HTTPError{Status: http.StatusNotFound}
+		case "409":
+			return None{}, HTTPError{Status: http.StatusConflict, Message: "already exists"}
+		case "422":
+			return None{}, fmt.Errorf("wrapped: %w", &HTTPError{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "invalid widget",
+				Details: []ErrorResponseDetail{{Field: "name", Code: "required", Message: "name is required"}},
+			})
+		case "503":
+			return None{}, &HTTPError{Status: http.StatusServiceUnavailable, Message: "try later"}
+		case "bogus":
+			return None{}, &HTTPError{Status: http.StatusOK}
+		}
+		return None{}, errors.New("plain error")
+	}))
+
+	tests := []struct {
+		code       string
+		wantStatus int
+		wantMsg    string
+		wantLogged bool
+	}{
+		{"404", http.StatusNotFound, "Not Found", false},
+		{"409", http.StatusConflict, "already exists", false},
+		{"422", http.StatusUnprocessableEntity, "invalid widget", false},
+		{"503", http.StatusServiceUnavailable, "try later", true},
+		{"bogus", http.StatusInternalServerError, "Internal Server Error", true},
+		{"plain", http.StatusInternalServerError, "Internal Server Error", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			logBuf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/status/"+tt.code, nil)
+			req.Header.Set("Accept", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var body ErrorResponseBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode body %q: %v", rec.Body.String(), err)
+			}
+			if body.Error != tt.wantMsg {
+				t.Errorf("Expected error %q, got %q", tt.wantMsg, body.Error)
+			}
+			if logged := strings.Contains(logBuf.String(), "Error executing task handler"); logged != tt.wantLogged {
+				t.Errorf("Expected logged=%v, got log %q", tt.wantLogged, logBuf.String())
+			}
+		})
+	}
+
+	t.Run("Details", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/status/422", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var body ErrorResponseBody
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Details) != 1 || body.Details[0].Field != "name" || body.Details[0].Code != "required" {
+			t.Errorf("Unexpected details: %+v", body.Details)
+		}
+	})
+
+	t.Run("PlainText", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status/409", nil))
+		if rec.Code != http.StatusConflict || strings.TrimSpace(rec.Body.String()) != "already exists" {
+			t.Errorf("Expected plain text 409, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+}
+
 func TestTaskMiddlewareErrors(t *testing.T) {
 	t.Run("Task_Middleware_Error_Returns_500", func(t *testing.T) {
 		router := NewRouter(nil)
@@ -886,3 +1053,2068 @@ func TestTaskMiddlewareErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestTaskHandlerWithStatus(t *testing.T) {
+	type created struct {
+		ID string `json:"id"`
+	}
+
+	t.Run("ExplicitStatusIsWritten", func(t *testing.T) {
+		router := NewRouter(nil)
+		handler := TaskHandlerFromFunc(func(rd *ReqData[None]) (StatusData[created], error) {
+			return WithStatus(created{ID: "abc"}, http.StatusCreated), nil
+		})
+		RegisterTaskHandler(router, http.MethodPost, "/items", handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected Content-Type application/json, got %q", ct)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != `{"id":"abc"}` {
+			t.Errorf("Expected unwrapped JSON body, got %q", body)
+		}
+	})
+
+	t.Run("ExplicitStatusBeatsProxySuccessStatus", func(t *testing.T) {
+		router := NewRouter(nil)
+		SetGlobalTaskMiddleware(router, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			rd.ResponseProxy().SetStatus(http.StatusAccepted)
+			rd.ResponseProxy().SetHeader("X-From-MW", "yes")
+			return None{}, nil
+		}))
+		handler := TaskHandlerFromFunc(func(rd *ReqData[None]) (StatusData[created], error) {
+			return WithStatus(created{ID: "abc"}, http.StatusCreated), nil
+		})
+		RegisterTaskHandler(router, http.MethodPost, "/items", handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", rec.Code)
+		}
+		if rec.Header().Get("X-From-MW") != "yes" {
+			t.Error("Expected proxy headers to still be applied")
+		}
+	})
+
+	t.Run("ProxyErrorStatusBeatsExplicitStatus", func(t *testing.T) {
+		router := NewRouter(nil)
+		handler := TaskHandlerFromFunc(func(rd *ReqData[None]) (StatusData[created], error) {
+			rd.ResponseProxy().SetStatus(http.StatusConflict)
+			return WithStatus(created{ID: "abc"}, http.StatusCreated), nil
+		})
+		RegisterTaskHandler(router, http.MethodPost, "/items", handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "abc") {
+			t.Errorf("Expected no JSON body after proxy error, got %q", rec.Body.String())
+		}
+	})
+
+	t.Run("ExplicitErrorStatusStillWritesJSON", func(t *testing.T) {
+		router := NewRouter(nil)
+		handler := TaskHandlerFromFunc(func(rd *ReqData[None]) (StatusData[created], error) {
+			return WithStatus(created{ID: "bad"}, http.StatusUnprocessableEntity), nil
+		})
+		RegisterTaskHandler(router, http.MethodPost, "/items", handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rec.Code)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != `{"id":"bad"}` {
+			t.Errorf("Expected JSON body, got %q", body)
+		}
+	})
+
+	t.Run("RouteOutputTypeIsUnwrapped", func(t *testing.T) {
+		router := NewRouter(nil)
+		handler := TaskHandlerFromFunc(func(rd *ReqData[None]) (StatusData[created], error) {
+			return WithStatus(created{}, 0), nil
+		})
+		route := RegisterTaskHandler(router, http.MethodPost, "/items", handler)
+		if _, ok := route.O().(created); !ok {
+			t.Errorf("Expected route.O() to be the wrapped data type, got %T", route.O())
+		}
+	})
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	router := NewRouter(nil)
+	RegisterHandlerFunc(router, http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	RegisterHandlerFunc(router, http.MethodGet, "/stream", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("Expected the response writer to implement http.Flusher")
+			return
+		}
+		f.Flush()
+	})
+	SetGlobalTaskMiddleware(router, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+		if rd.Request().URL.Query().Get("deny") != "" {
+			rd.ResponseProxy().SetStatus(http.StatusForbidden)
+		}
+		return None{}, nil
+	}))
+	handler := AccessLogMiddleware(logger)(router)
+
+	t.Run("LogsPatternStatusAndBytes", func(t *testing.T) {
+		buf.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/123", nil))
+		line := buf.String()
+		for _, want := range []string{"pattern=/users/:id", "status=200", "bytes=5", "path=/users/123"} {
+			if !strings.Contains(line, want) {
+				t.Errorf("Expected log line to contain %q, got %q", want, line)
+			}
+		}
+	})
+
+	t.Run("LogsProxyErrorStatus", func(t *testing.T) {
+		buf.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/123?deny=1", nil))
+		if !strings.Contains(buf.String(), "status=403") {
+			t.Errorf("Expected status=403 in log line, got %q", buf.String())
+		}
+	})
+
+	t.Run("HeadFallbackLogsZeroBytes", func(t *testing.T) {
+		buf.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/users/123", nil))
+		line := buf.String()
+		for _, want := range []string{"bytes=0", "head_as_get=true", "status=200"} {
+			if !strings.Contains(line, want) {
+				t.Errorf("Expected log line to contain %q, got %q", want, line)
+			}
+		}
+	})
+
+	t.Run("PassesThroughFlush", func(t *testing.T) {
+		buf.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		if !rec.Flushed {
+			t.Error("Expected the wrapped writer to flush the underlying writer")
+		}
+		if !strings.Contains(buf.String(), "status=200") {
+			t.Errorf("Expected status=200 in log line, got %q", buf.String())
+		}
+	})
+
+	t.Run("NotFoundHasEmptyPattern", func(t *testing.T) {
+		buf.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+		line := buf.String()
+		if !strings.Contains(line, "status=404") || !strings.Contains(line, `pattern=""`) {
+			t.Errorf("Expected 404 with empty pattern, got %q", line)
+		}
+	})
+}
+
+func TestSlowRequestThreshold(t *testing.T) {
+	var buf strings.Builder
+	prevLog := muxLog
+	muxLog = slog.New(slog.NewTextHandler(&buf, nil))
+	defer func() { muxLog = prevLog }()
+
+	router := NewRouter(&Options{
+		SlowRequestThreshold: 20 * time.Millisecond,
+		RequestID:            &RequestIDConfig{},
+	})
+	RegisterHandlerFunc(router, http.MethodGet, "/fast", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	})
+	RegisterHandlerFunc(router, http.MethodGet, "/stream", func(w http.ResponseWriter, r *http.Request) {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	})
+	RegisterHandlerFunc(router, http.MethodGet, "/slow/:id", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(40 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	SetGlobalHTTPMiddleware(router, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("slowmw") != "" {
+				time.Sleep(40 * time.Millisecond)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	t.Run("PassesThroughFlush", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		if !rec.Flushed {
+			t.Error("Expected the wrapped writer to flush the underlying writer")
+		}
+	})
+
+	t.Run("FastRequestNotLogged", func(t *testing.T) {
+		buf.Reset()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+		if buf.Len() != 0 {
+			t.Errorf("Expected no log for fast request, got %q", buf.String())
+		}
+	})
+
+	t.Run("SlowHandlerLogged", func(t *testing.T) {
+		buf.Reset()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow/1", nil))
+		line := buf.String()
+		wantID := "request_id=" + rec.Header().Get(DefaultRequestIDHeaderName)
+		for _, want := range []string{"level=WARN", "pattern=/slow/:id", "status=202", "path=/slow/1", wantID} {
+			if !strings.Contains(line, want) {
+				t.Errorf("Expected log line to contain %q, got %q", want, line)
+			}
+		}
+	})
+
+	t.Run("SlowMiddlewareCounted", func(t *testing.T) {
+		buf.Reset()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast?slowmw=1", nil))
+		if !strings.Contains(buf.String(), "pattern=/fast") {
+			t.Errorf("Expected slow middleware to be logged, got %q", buf.String())
+		}
+	})
+}
+
+func TestInvoke(t *testing.T) {
+	type input struct {
+		Name string `json:"name"`
+	}
+	type output struct {
+		Greeting string `json:"greeting"`
+		UserID   string `json:"userID"`
+	}
+
+	newRouter := func() *Router {
+		router := NewRouter(&Options{MountRoot: "/api/"})
+		SetGlobalTaskMiddleware(router, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			if rd.Request().Header.Get("Authorization") == "deny" {
+				rd.ResponseProxy().SetStatus(http.StatusUnauthorized)
+				return None{}, nil
+			}
+			rd.ResponseProxy().SetHeader("X-From-MW", "yes")
+			rd.ResponseProxy().SetStatus(http.StatusAccepted)
+			return None{}, nil
+		}))
+		RegisterTaskHandler(router, http.MethodPost, "/users/:id/greet", TaskHandlerFromFunc(
+			func(rd *ReqData[input]) (output, error) {
+				return output{Greeting: "hello " + rd.Input().Name, UserID: rd.Params()["id"]}, nil
+			},
+		))
+		RegisterTaskHandler(router, http.MethodPost, "/items", TaskHandlerFromFunc(
+			func(rd *ReqData[None]) (StatusData[output], error) {
+				return WithStatus(output{Greeting: "created"}, http.StatusCreated), nil
+			},
+		))
+		RegisterTaskHandler(router, http.MethodGet, "/fail", TaskHandlerFromFunc(
+			func(rd *ReqData[None]) (output, error) {
+				return output{}, errors.New("boom")
+			},
+		))
+		RegisterHandlerFunc(router, http.MethodGet, "/raw", func(w http.ResponseWriter, r *http.Request) {})
+		return router
+	}
+
+	t.Run("RunsMiddlewareAndHandler", func(t *testing.T) {
+		res, err := Invoke[output](newRouter(), http.MethodPost, "/api/users/42/greet", input{Name: "bob"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Data.Greeting != "hello bob" || res.Data.UserID != "42" {
+			t.Errorf("unexpected data: %+v", res.Data)
+		}
+		if res.Status != http.StatusAccepted {
+			t.Errorf("expected status 202, got %d", res.Status)
+		}
+		if res.ResponseProxy.GetHeader("X-From-MW") != "yes" {
+			t.Error("expected middleware proxy header to be merged")
+		}
+	})
+
+	t.Run("ExplicitStatus", func(t *testing.T) {
+		res, err := Invoke[output](newRouter(), http.MethodPost, "/api/items", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Status != http.StatusCreated || res.Data.Greeting != "created" {
+			t.Errorf("unexpected result: status %d, data %+v", res.Status, res.Data)
+		}
+	})
+
+	t.Run("MiddlewareShortCircuit", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/users/42/greet", nil)
+		r.Header.Set("Authorization", "deny")
+		res, err := InvokeRequest[output](newRouter(), r, input{Name: "bob"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Status != http.StatusUnauthorized {
+			t.Errorf("expected status 401, got %d", res.Status)
+		}
+		if res.Data != (output{}) {
+			t.Errorf("expected zero data, got %+v", res.Data)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		router := newRouter()
+		if _, err := Invoke[output](router, http.MethodGet, "/api/nope", nil); !errors.Is(err, ErrInvokeNoMatch) {
+			t.Errorf("expected ErrInvokeNoMatch, got %v", err)
+		}
+		if _, err := Invoke[output](router, http.MethodGet, "/api/raw", nil); !errors.Is(err, ErrInvokeNotTaskRoute) {
+			t.Errorf("expected ErrInvokeNotTaskRoute, got %v", err)
+		}
+		if _, err := Invoke[output](router, http.MethodPost, "/api/users/1/greet", "wrong"); err == nil {
+			t.Error("expected error for mismatched input type")
+		}
+		if _, err := Invoke[string](router, http.MethodPost, "/api/users/1/greet", input{}); err == nil {
+			t.Error("expected error for mismatched output type")
+		}
+		if _, err := Invoke[output](router, http.MethodGet, "/api/fail", nil); err == nil {
+			t.Error("expected handler error to be returned")
+		}
+	})
+}
+
+func TestRegisterMultipleMethods(t *testing.T) {
+	type output struct {
+		Method string `json:"method"`
+	}
+
+	t.Run("TaskHandler", func(t *testing.T) {
+		router := NewRouter(nil)
+		routes := RegisterTaskHandlerMethods(router, []string{http.MethodPut, http.MethodPatch, http.MethodPut}, "/items/:id",
+			TaskHandlerFromFunc(func(rd *ReqData[None]) (output, error) {
+				return output{Method: rd.Request().Method}, nil
+			}),
+		)
+		if len(routes) != 2 {
+			t.Fatalf("expected 2 routes (duplicates skipped), got %d", len(routes))
+		}
+		for _, route := range routes {
+			SetPatternLevelTaskMiddleware(route, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+				rd.ResponseProxy().SetHeader("X-Item-ID", rd.Params()["id"])
+				return None{}, nil
+			}))
+		}
+
+		for _, method := range []string{http.MethodPut, http.MethodPatch} {
+			req := httptest.NewRequest(method, "/items/7", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: expected status 200, got %d", method, rec.Code)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != `{"method":"`+method+`"}` {
+				t.Errorf("%s: unexpected body %q", method, body)
+			}
+			if rec.Header().Get("X-Item-ID") != "7" {
+				t.Errorf("%s: expected pattern-level middleware to run", method)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/items/7", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected unregistered method to 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("HTTPHandlerWithHeadFallback", func(t *testing.T) {
+		router := NewRouter(nil)
+		routes := RegisterHandlerMethods(router, []string{http.MethodGet, http.MethodDelete}, "/files/*",
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Method", r.Method)
+				w.Write([]byte(strings.Join(GetSplatValues(r), "/")))
+			}),
+		)
+		if len(routes) != 2 {
+			t.Fatalf("expected 2 routes, got %d", len(routes))
+		}
+
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			req := httptest.NewRequest(method, "/files/a/b", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Header().Get("X-Method") != method || rec.Body.String() != "a/b" {
+				t.Errorf("%s: unexpected response %q (X-Method %q)", method, rec.Body.String(), rec.Header().Get("X-Method"))
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodHead, "/files/a/b", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+			t.Errorf("expected HEAD to fall back to GET with no body, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestErrorResponses(t *testing.T) {
+	type Input struct {
+		Email string `json:"email"`
+	}
+	parseInput := func(r *http.Request, inputPtr any) error {
+		if err := json.NewDecoder(r.Body).Decode(inputPtr); err != nil {
+			return err
+		}
+		oc := validate.Object(inputPtr)
+		oc.Required("Email")
+		return oc.Error()
+	}
+	newRouter := func(errorHandler ErrorHandler) *Router {
+		r := NewRouter(&Options{ParseInput: parseInput, ErrorHandler: errorHandler})
+		RegisterTaskHandler(r, http.MethodPost, "/validate", TaskHandlerFromFunc(func(rd *ReqData[Input]) (None, error) {
+			return None{}, nil
+		}))
+		RegisterTaskHandler(r, http.MethodPost, "/fail", TaskHandlerFromFunc(func(rd *ReqData[None]) (None, error) {
+			return None{}, errors.New("secret internal detail")
+		}))
+		return r
+	}
+	do := func(r *Router, path, body, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ValidationErrorAsJSON", func(t *testing.T) {
+		w := do(newRouter(nil), "/validate", `{}`, "application/json, text/plain;q=0.9")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type, got %q", ct)
+		}
+		var body ErrorResponseBody
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode body %q: %v", w.Body.String(), err)
+		}
+		if body.Error == "" {
+			t.Error("Expected a non-empty error message")
+		}
+		if len(body.Details) != 1 || body.Details[0].Field != "Email" || body.Details[0].Code != validate.CodeRequired {
+			t.Errorf("Expected a single required detail for Email, got %+v", body.Details)
+		}
+	})
+
+	t.Run("ValidationErrorAsText", func(t *testing.T) {
+		w := do(newRouter(nil), "/validate", `{}`, "")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("Expected plain text content type, got %q", ct)
+		}
+		if !strings.Contains(w.Body.String(), "Email") {
+			t.Errorf("Expected validation message in body, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("InternalErrorAsJSONHasNoDetails", func(t *testing.T) {
+		w := do(newRouter(nil), "/fail", ``, "application/problem+json")
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("Expected status 500, got %d", w.Code)
+		}
+		if strings.Contains(w.Body.String(), "secret internal detail") {
+			t.Errorf("Internal error details leaked: %q", w.Body.String())
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != `{"error":"Internal Server Error"}` {
+			t.Errorf("Unexpected body %q", got)
+		}
+	})
+
+	t.Run("CustomErrorHandler", func(t *testing.T) {
+		var gotErr error
+		r := newRouter(func(w http.ResponseWriter, r *http.Request, err error) {
+			gotErr = err
+			if validate.IsValidationError(err) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			DefaultErrorHandler(w, r, err)
+		})
+		if w := do(r, "/validate", `{}`, "application/json"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", w.Code)
+		}
+		if !validate.IsValidationError(gotErr) {
+			t.Errorf("Expected handler to receive the validation error, got %v", gotErr)
+		}
+		if w := do(r, "/fail", ``, ""); w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		if gotErr == nil || !strings.Contains(gotErr.Error(), "secret internal detail") {
+			t.Errorf("Expected handler to receive the task handler error, got %v", gotErr)
+		}
+	})
+}
+
+func TestFromMiddleware(t *testing.T) {
+	type User struct{ Name string }
+	type Tenant string
+
+	t.Run("TaskHandlerReadsTypedOutputs", func(t *testing.T) {
+		r := NewRouter(nil)
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (*User, error) {
+			return &User{Name: "global"}, nil
+		}))
+		SetMethodLevelTaskMiddleware(r, http.MethodGet, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (Tenant, error) {
+			return Tenant("acme"), nil
+		}))
+		route := RegisterTaskHandler(r, http.MethodGet, "/me", TaskHandlerFromFunc(func(rd *ReqData[None]) (string, error) {
+			user, ok := FromMiddleware[*User](rd)
+			if !ok {
+				return "", errors.New("no user")
+			}
+			tenant, _ := FromMiddleware[Tenant](rd)
+			if _, ok := FromMiddleware[int](rd); ok {
+				return "", errors.New("unexpected int output")
+			}
+			return user.Name + "@" + string(tenant), nil
+		}))
+		SetPatternLevelTaskMiddleware(route, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (*User, error) {
+			return &User{Name: "pattern"}, nil
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var got string
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if got != "pattern@acme" {
+			t.Errorf("Expected most specific middleware output 'pattern@acme', got %q", got)
+		}
+	})
+
+	t.Run("HTTPHandlerReadsTypedOutputs", func(t *testing.T) {
+		r := NewRouter(nil)
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (*User, error) {
+			return &User{Name: "alice"}, nil
+		}))
+		RegisterHandlerFunc(r, http.MethodGet, "/hello", func(w http.ResponseWriter, r *http.Request) {
+			user, ok := FromMiddlewareRequest[*User](r)
+			if !ok {
+				http.Error(w, "no user", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("hello " + user.Name))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Body.String(); got != "hello alice" {
+			t.Errorf("Expected 'hello alice', got %q", got)
+		}
+	})
+
+	t.Run("SkippedMiddlewareProducesNoOutput", func(t *testing.T) {
+		r := NewRouter(nil)
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (*User, error) {
+			return &User{Name: "alice"}, nil
+		}), &MiddlewareOptions{If: func(r *http.Request) bool { return false }})
+		var found bool
+		RegisterHandlerFunc(r, http.MethodGet, "/anon", func(w http.ResponseWriter, r *http.Request) {
+			_, found = FromMiddlewareRequest[*User](r)
+		})
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/anon", nil))
+
+		if found {
+			t.Error("Expected no output from a middleware that did not run")
+		}
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		r := NewRouter(nil)
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (*User, error) {
+			return &User{Name: "invoked"}, nil
+		}))
+		RegisterTaskHandler(r, http.MethodGet, "/me", TaskHandlerFromFunc(func(rd *ReqData[None]) (string, error) {
+			user, _ := FromMiddleware[*User](rd)
+			return user.Name, nil
+		}))
+
+		res, err := Invoke[string](r, http.MethodGet, "/me", nil)
+		if err != nil {
+			t.Fatalf("Invoke failed: %v", err)
+		}
+		if res.Data != "invoked" {
+			t.Errorf("Expected 'invoked', got %q", res.Data)
+		}
+	})
+}
+
+func TestBufferRequestBody(t *testing.T) {
+	type Payload struct{ Message string }
+
+	secret := []byte("shh")
+	sign := func(body []byte) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	jsonParseInput := func(req *http.Request, inputPtr any) error {
+		return json.NewDecoder(req.Body).Decode(inputPtr)
+	}
+
+	newSignedRouter := func(opts *Options) *Router {
+		opts.ParseInput = jsonParseInput
+		r := NewRouter(opts)
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			req := rd.Request()
+			bodyBytes, err := io.ReadAll(req.Body)
+			if err != nil {
+				return None{}, err
+			}
+			if !bytes.Equal(bodyBytes, GetRawBody(req)) {
+				rd.ResponseProxy().SetStatus(http.StatusInternalServerError, "body not re-readable")
+				return None{}, nil
+			}
+			if !hmac.Equal([]byte(sign(bodyBytes)), []byte(req.Header.Get("X-Signature"))) {
+				rd.ResponseProxy().SetStatus(http.StatusUnauthorized, "bad signature")
+			}
+			return None{}, nil
+		}))
+		RegisterTaskHandler(r, http.MethodPost, "/hook", TaskHandlerFromFunc(func(rd *ReqData[Payload]) (string, error) {
+			return "got " + rd.Input().Message, nil
+		}))
+		return r
+	}
+
+	t.Run("SignatureMiddlewareAndTypedHandlerBothReadBody", func(t *testing.T) {
+		r := newSignedRouter(&Options{BufferRequestBody: true})
+		body := `{"Message":"hello"}`
+
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+		req.Header.Set("X-Signature", sign([]byte(body)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var got string
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if got != "got hello" {
+			t.Errorf("Expected 'got hello', got %q", got)
+		}
+	})
+
+	t.Run("BadSignatureRejected", func(t *testing.T) {
+		r := newSignedRouter(&Options{BufferRequestBody: true})
+
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"Message":"hello"}`))
+		req.Header.Set("X-Signature", sign([]byte(`{"Message":"tampered"}`)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("BodyLargerThanMaxRejected", func(t *testing.T) {
+		r := newSignedRouter(&Options{BufferRequestBody: true, MaxRequestBodyBytes: 8})
+		body := `{"Message":"too long"}`
+
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+		req.Header.Set("X-Signature", sign([]byte(body)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", w.Code)
+		}
+	})
+
+	t.Run("HTTPHandlerGetsRawBody", func(t *testing.T) {
+		r := NewRouter(&Options{BufferRequestBody: true})
+		var raw []byte
+		RegisterHandlerFunc(r, http.MethodPost, "/raw", func(w http.ResponseWriter, r *http.Request) {
+			raw = GetRawBody(r)
+		})
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader("abc")))
+
+		if string(raw) != "abc" {
+			t.Errorf("Expected raw body 'abc', got %q", raw)
+		}
+	})
+
+	t.Run("NotBufferedByDefault", func(t *testing.T) {
+		r := NewRouter(nil)
+		var raw []byte
+		RegisterHandlerFunc(r, http.MethodPost, "/raw", func(w http.ResponseWriter, r *http.Request) {
+			raw = GetRawBody(r)
+		})
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader("abc")))
+
+		if raw != nil {
+			t.Errorf("Expected nil raw body, got %q", raw)
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	newRouter := func(cfg *RequestIDConfig) (*Router, *[]string) {
+		r := NewRouter(&Options{RequestID: cfg})
+		var seen []string
+		var mu sync.Mutex
+		record := func(id string) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, id)
+		}
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			record(GetRequestID(rd.Request()))
+			return None{}, nil
+		}))
+		RegisterTaskHandler(r, http.MethodGet, "/task", TaskHandlerFromFunc(func(rd *ReqData[None]) (string, error) {
+			record(GetRequestID(rd.Request()))
+			return "ok", nil
+		}))
+		RegisterHandlerFunc(r, http.MethodGet, "/plain", func(w http.ResponseWriter, r *http.Request) {
+			record(GetRequestID(r))
+		})
+		SetGlobalNotFoundHTTPHandler(r, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			record(GetRequestID(r))
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		return r, &seen
+	}
+
+	t.Run("GeneratedAndPropagated", func(t *testing.T) {
+		r, seen := newRouter(&RequestIDConfig{})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/task", nil))
+
+		requestID := w.Header().Get(DefaultRequestIDHeaderName)
+		if len(requestID) != 20 {
+			t.Fatalf("Expected generated 20-char request ID header, got %q", requestID)
+		}
+		if len(*seen) != 2 || (*seen)[0] != requestID || (*seen)[1] != requestID {
+			t.Errorf("Expected middleware and handler to see %q, got %v", requestID, *seen)
+		}
+	})
+
+	t.Run("AvailableInHTTPAndNotFoundHandlers", func(t *testing.T) {
+		r, seen := newRouter(&RequestIDConfig{HeaderName: "X-Correlation-Id", Generate: func() string { return "fixed" }})
+		for _, path := range []string{"/plain", "/missing"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if got := w.Header().Get("X-Correlation-Id"); got != "fixed" {
+				t.Errorf("%s: expected echoed request ID 'fixed', got %q", path, got)
+			}
+		}
+		// The task middleware and the HTTP handler for /plain, plus the
+		// not-found handler for /missing
+		if !slices.Equal(*seen, []string{"fixed", "fixed", "fixed"}) {
+			t.Errorf("Expected middleware and handlers to see 'fixed', got %v", *seen)
+		}
+	})
+
+	t.Run("IncomingIDOnlyUsedWhenTrusted", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			trust    bool
+			incoming string
+			want     string
+		}{
+			{"Untrusted", false, "upstream-123", "generated"},
+			{"Trusted", true, "upstream-123", "upstream-123"},
+			{"TrustedButMalformed", true, "bad id\n", "generated"},
+			{"TrustedButTooLong", true, strings.Repeat("a", 200), "generated"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r, _ := newRouter(&RequestIDConfig{
+					TrustIncoming: tt.trust,
+					Generate:      func() string { return "generated" },
+				})
+				req := httptest.NewRequest(http.MethodGet, "/plain", nil)
+				req.Header.Set(DefaultRequestIDHeaderName, tt.incoming)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if got := w.Header().Get(DefaultRequestIDHeaderName); got != tt.want {
+					t.Errorf("Expected request ID %q, got %q", tt.want, got)
+				}
+			})
+		}
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		r, seen := newRouter(nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+		if got := w.Header().Get(DefaultRequestIDHeaderName); got != "" {
+			t.Errorf("Expected no request ID header, got %q", got)
+		}
+		if !slices.Equal(*seen, []string{"", ""}) {
+			t.Errorf("Expected empty request IDs, got %v", *seen)
+		}
+	})
+
+	t.Run("LoggedByAccessLogMiddleware", func(t *testing.T) {
+		var buf strings.Builder
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		r, _ := newRouter(&RequestIDConfig{Generate: func() string { return "logged-id" }})
+		AccessLogMiddleware(logger)(r).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+		if !strings.Contains(buf.String(), "request_id=logged-id") {
+			t.Errorf("Expected request_id in log line, got %q", buf.String())
+		}
+	})
+}
+
+func TestNotFoundTasksCtx(t *testing.T) {
+	serve := func(router *Router) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("TasksCtxRequirer_NotFound_Handler_Gets_TasksCtx", func(t *testing.T) {
+		router := NewRouter()
+		SetGlobalNotFoundHTTPHandler(router, TasksCtxRequirerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetTasksCtx(r) == nil {
+				t.Error("TasksCtx should be available for TasksCtxRequirer not-found handler")
+			}
+			if len(GetParams(r)) != 0 || len(GetSplatValues(r)) != 0 {
+				t.Error("Expected empty params and splat values")
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		if rec := serve(router); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Option_Injects_TasksCtx_For_Plain_Handler", func(t *testing.T) {
+		router := NewRouter(&Options{InjectTasksCtxForNotFound: true})
+		var ran bool
+		SetGlobalNotFoundHTTPHandler(router, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tasksCtx := GetTasksCtx(r)
+			if tasksCtx == nil {
+				t.Fatal("TasksCtx should be available when InjectTasksCtxForNotFound is set")
+			}
+			ran = true
+			w.WriteHeader(http.StatusOK)
+		}))
+		if rec := serve(router); rec.Code != http.StatusOK || !ran {
+			t.Errorf("Expected shell handler to run with status 200, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Plain_NotFound_Handler_Has_No_TasksCtx", func(t *testing.T) {
+		router := NewRouter()
+		SetGlobalNotFoundHTTPHandler(router, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetTasksCtx(r) != nil {
+				t.Error("TasksCtx should not be available for regular not-found handlers")
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		serve(router)
+	})
+
+	t.Run("Default_NotFound_Unaffected_By_Option", func(t *testing.T) {
+		router := NewRouter(&Options{InjectTasksCtxForNotFound: true})
+		if rec := serve(router); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
+func TestJSONEncoderOption(t *testing.T) {
+	type out struct {
+		HTML string `json:"html"`
+	}
+	serve := func(router *Router) string {
+		RegisterTaskHandler(router, http.MethodGet, "/data", TaskHandlerFromFunc(func(rd *ReqData[None]) (out, error) {
+			return out{HTML: "<b>&</b>"}, nil
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/data", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected application/json content type, got %q", ct)
+		}
+		return rec.Body.String()
+	}
+
+	t.Run("DefaultEscapesHTML", func(t *testing.T) {
+		got := serve(NewRouter())
+		if got != `{"html":"\u003cb\u003e\u0026\u003c/b\u003e"}`+"\n" {
+			t.Errorf("Unexpected default encoding: %q", got)
+		}
+	})
+
+	t.Run("CustomEncoder", func(t *testing.T) {
+		got := serve(NewRouter(&Options{
+			JSONEncoder: func(w io.Writer) response.JSONEncoder {
+				enc := json.NewEncoder(w)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "\t")
+				return enc
+			},
+		}))
+		if got != "{\n\t\"html\": \"<b>&</b>\"\n}\n" {
+			t.Errorf("Unexpected custom encoding: %q", got)
+		}
+	})
+}
+
+func TestRequestDataConsistentAcrossPaths(t *testing.T) {
+	type observed struct {
+		param      string
+		params     Params
+		splats     []string
+		hasTaskCtx bool
+	}
+
+	setup := func(withTaskMw bool) (*Router, *observed) {
+		r := NewRouter(nil)
+		obs := &observed{}
+		SetGlobalHTTPMiddleware(r, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				obs.param = GetParam(req, "id")
+				obs.params = GetParams(req)
+				obs.splats = GetSplatValues(req)
+				obs.hasTaskCtx = GetTasksCtx(req) != nil
+				next.ServeHTTP(w, req)
+			})
+		})
+		if withTaskMw {
+			SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+				return None{}, nil
+			}))
+		}
+		RegisterHandlerFunc(r, http.MethodGet, "/items/:id", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		RegisterHandlerFunc(r, http.MethodGet, "/static", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		return r, obs
+	}
+
+	for _, path := range []string{"/items/42", "/static"} {
+		var results []observed
+		for _, withTaskMw := range []bool{false, true} {
+			r, obs := setup(withTaskMw)
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected status 200, got %d", path, w.Code)
+			}
+			results = append(results, *obs)
+		}
+		fast, slow := results[0], results[1]
+		if fast.param != slow.param || !maps.Equal(fast.params, slow.params) {
+			t.Errorf("%s: params differ between fast (%v) and slow (%v) paths", path, fast.params, slow.params)
+		}
+		if fast.params == nil || fast.splats == nil {
+			t.Errorf("%s: expected non-nil params and splat values on the fast path", path)
+		}
+		if !slices.Equal(fast.splats, slow.splats) {
+			t.Errorf("%s: splat values differ between fast (%v) and slow (%v) paths", path, fast.splats, slow.splats)
+		}
+		if !fast.hasTaskCtx || !slow.hasTaskCtx {
+			t.Errorf("%s: expected TasksCtx on both paths (fast: %v, slow: %v)", path, fast.hasTaskCtx, slow.hasTaskCtx)
+		}
+	}
+
+	t.Run("InjectTasksCtxMiddlewarePreservesParams", func(t *testing.T) {
+		r := NewRouter(nil)
+		SetGlobalHTTPMiddleware(r, InjectTasksCtxMiddleware)
+		var got string
+		RegisterHandlerFunc(r, http.MethodGet, "/items/:id", func(w http.ResponseWriter, req *http.Request) {
+			got = GetParam(req, "id")
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/7", nil))
+		if got != "7" {
+			t.Errorf("Expected param '7', got %q", got)
+		}
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	type Base struct {
+		ID        string    `json:"id"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	type Node struct {
+		Name     string  `json:"name"`
+		Children []*Node `json:"children,omitempty"`
+	}
+	type Input struct {
+		Query  string `json:"q"`
+		Limit  *int   `json:"limit"`
+		Page   int    `json:"page,omitempty"`
+		Skip   string `json:"-"`
+		secret string
+	}
+	type Output struct {
+		Base
+		ID    int             `json:"id"` // shadows Base.ID
+		Tags  []string        `json:"tags"`
+		Meta  map[string]any  `json:"meta"`
+		Tree  *Node           `json:"tree"`
+		Score float64         `json:"score"`
+		Flags map[string]bool `json:"flags,omitempty"`
+	}
+
+	r := NewRouter(nil)
+	RegisterTaskHandler(r, http.MethodPost, "/search", TaskHandlerFromFunc(func(rd *ReqData[Input]) (Output, error) {
+		return Output{}, nil
+	})).Tag("search")
+	RegisterHandlerFunc(r, http.MethodGet, "/raw", func(w http.ResponseWriter, req *http.Request) {})
+
+	descriptions := r.Describe()
+	if len(descriptions) != 2 {
+		t.Fatalf("Expected 2 route descriptions, got %d", len(descriptions))
+	}
+
+	search, raw := descriptions[0], descriptions[1]
+	if search.Method != http.MethodPost || search.Pattern != "/search" || search.HandlerType != "task" || search.Opaque {
+		t.Errorf("Unexpected task route description: %+v", search)
+	}
+	if !slices.Equal(search.Tags, []string{"search"}) {
+		t.Errorf("Expected tags [search], got %v", search.Tags)
+	}
+	if !raw.Opaque || raw.Input != nil || raw.Output != nil {
+		t.Errorf("Expected HTTP route to be opaque, got %+v", raw)
+	}
+
+	in := search.Input
+	if in.Type != "object" || len(in.Properties) != 3 {
+		t.Fatalf("Expected input object with 3 properties, got %+v", in)
+	}
+	if !slices.Equal(in.Required, []string{"q"}) {
+		t.Errorf("Expected only 'q' to be required, got %v", in.Required)
+	}
+	if limit := in.Properties["limit"]; limit.Type != "integer" || !limit.Nullable {
+		t.Errorf("Expected nullable integer limit, got %+v", limit)
+	}
+
+	out := search.Output
+	if id := out.Properties["id"]; id == nil || id.Type != "integer" {
+		t.Errorf("Expected shallower integer 'id' to win over embedded one, got %+v", id)
+	}
+	if createdAt := out.Properties["createdAt"]; createdAt == nil || createdAt.Format != "date-time" {
+		t.Errorf("Expected embedded createdAt to be flattened as date-time, got %+v", createdAt)
+	}
+	if tags := out.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("Expected string array tags, got %+v", tags)
+	}
+	if meta := out.Properties["meta"]; meta.Type != "object" || meta.AdditionalProperties.Type != "" {
+		t.Errorf("Expected map of any for meta, got %+v", meta)
+	}
+	tree := out.Properties["tree"]
+	if tree.Type != "object" || !tree.Nullable {
+		t.Fatalf("Expected nullable object tree, got %+v", tree)
+	}
+	if child := tree.Properties["children"].Items; child.Ref == "" {
+		t.Errorf("Expected recursive reference for children, got %+v", child)
+	}
+
+	if _, err := json.Marshal(descriptions); err != nil {
+		t.Errorf("Expected descriptions to be serializable: %v", err)
+	}
+}
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	const limit = 2
+
+	newRouter := func(opts *ConcurrencyLimitOptions) (*Router, chan struct{}, chan struct{}) {
+		started := make(chan struct{}, limit+1)
+		release := make(chan struct{})
+		r := NewRouter()
+		SetGlobalHTTPMiddleware(r, ConcurrencyLimitMiddleware(limit, nil, opts))
+		RegisterHandlerFunc(r, http.MethodGet, "/slow", func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		})
+		return r, started, release
+	}
+
+	fill := func(r *Router, started chan struct{}) *sync.WaitGroup {
+		var wg sync.WaitGroup
+		for range limit {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+			}()
+		}
+		for range limit {
+			<-started
+		}
+		return &wg
+	}
+
+	t.Run("RejectsWhenFull", func(t *testing.T) {
+		r, started, release := newRouter(nil)
+		wg := fill(r, started)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503 for request over the limit, got %d", w.Code)
+		}
+
+		close(release)
+		wg.Wait()
+
+		// Slots are freed once in-flight requests finish
+		w = httptest.NewRecorder()
+		go func() { <-started }()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected 200 after slots freed, got %d", w.Code)
+		}
+	})
+
+	t.Run("CustomOnLimit", func(t *testing.T) {
+		started := make(chan struct{}, limit)
+		release := make(chan struct{})
+		r := NewRouter()
+		onLimit := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		SetGlobalHTTPMiddleware(r, ConcurrencyLimitMiddleware(limit, onLimit))
+		RegisterHandlerFunc(r, http.MethodGet, "/slow", func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		})
+		wg := fill(r, started)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected onLimit's 429, got %d", w.Code)
+		}
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("QueuesWhenFull", func(t *testing.T) {
+		r, started, release := newRouter(&ConcurrencyLimitOptions{Queue: true})
+		wg := fill(r, started)
+
+		done := make(chan int, 1)
+		go func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+			done <- w.Code
+		}()
+
+		select {
+		case <-started:
+			t.Fatal("Expected request over the limit to wait for a slot")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		release <- struct{}{} // finish one in-flight request
+		<-started
+		close(release)
+
+		if code := <-done; code != http.StatusOK {
+			t.Errorf("Expected queued request to succeed, got %d", code)
+		}
+		wg.Wait()
+	})
+
+	t.Run("QueueMaxWait", func(t *testing.T) {
+		r, started, release := newRouter(&ConcurrencyLimitOptions{Queue: true, MaxWait: 20 * time.Millisecond})
+		wg := fill(r, started)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503 after MaxWait, got %d", w.Code)
+		}
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("QueueRespectsCancellation", func(t *testing.T) {
+		r, started, release := newRouter(&ConcurrencyLimitOptions{Queue: true})
+		wg := fill(r, started)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected cancelled queued request to return")
+		}
+		select {
+		case <-started:
+			t.Error("Cancelled request should not reach the handler")
+		default:
+		}
+		close(release)
+		wg.Wait()
+	})
+}
+
+func TestMethodFallbackHandler(t *testing.T) {
+	newRouter := func() *Router {
+		r := NewRouter()
+		RegisterHandlerFunc(r, http.MethodGet, "/api/users", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("users"))
+		})
+		RegisterHandlerFunc(r, http.MethodGet, "/docs/*", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("docs"))
+		})
+		RegisterHandlerFunc(r, http.MethodPost, "/api/users", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("created"))
+		})
+		SetMethodFallbackHandler(r, http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Shell", "1")
+			w.Write([]byte("shell"))
+		}))
+		SetGlobalNotFoundHTTPHandler(r, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		}))
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"Exact route wins", http.MethodGet, "/api/users", http.StatusOK, "users"},
+		{"Splat wins", http.MethodGet, "/docs/anything/here", http.StatusOK, "docs"},
+		{"Unmatched GET uses fallback", http.MethodGet, "/some/client/route", http.StatusOK, "shell"},
+		{"Unmatched HEAD uses GET fallback", http.MethodHead, "/some/client/route", http.StatusOK, ""},
+		{"Other method route still matches", http.MethodPost, "/api/users", http.StatusOK, "created"},
+		{"Other method gets not found", http.MethodPost, "/api/nope", http.StatusNotFound, "not found"},
+		{"Method without routes gets not found", http.MethodDelete, "/api/users", http.StatusNotFound, "not found"},
+	}
+
+	r := newRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantCode || w.Body.String() != tt.wantBody {
+				t.Errorf("Expected %d %q, got %d %q", tt.wantCode, tt.wantBody, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("HEAD fallback keeps headers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/x", nil))
+		if w.Header().Get("X-Shell") != "1" {
+			t.Errorf("Expected fallback headers on HEAD, got %v", w.Header())
+		}
+	})
+
+	t.Run("Fallback with TasksCtx", func(t *testing.T) {
+		r := NewRouter()
+		var gotCtx bool
+		SetMethodFallbackHandler(r, http.MethodGet, TasksCtxRequirerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotCtx = GetTasksCtx(r) != nil
+		}))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/anything", nil))
+		if !gotCtx {
+			t.Error("Expected TasksCtx for TasksCtxRequirer fallback")
+		}
+	})
+}
+
+func TestPatternLevelMiddlewareByPattern(t *testing.T) {
+	addHeader := func(val string) HTTPMiddleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Trace", val)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }
+
+	t.Run("Before and after registration", func(t *testing.T) {
+		r := NewRouter()
+		SetPatternLevelHTTPMiddlewareByPattern(r, http.MethodGet, "/users/:id", addHeader("before"))
+		route := RegisterHandlerFunc(r, http.MethodGet, "/users/:id", ok)
+		SetPatternLevelHTTPMiddlewareByPattern(r, http.MethodGet, "/users/:id", addHeader("after"))
+		SetPatternLevelHTTPMiddleware(route, addHeader("direct"))
+		RegisterHandlerFunc(r, http.MethodPost, "/users/:id", ok)
+
+		if err := r.Validate(); err != nil {
+			t.Fatalf("Expected no validation error, got %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		if got := w.Header().Values("X-Trace"); !slices.Equal(got, []string{"before", "after", "direct"}) {
+			t.Errorf("Expected middleware in registration order, got %v", got)
+		}
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/1", nil))
+		if got := w.Header().Values("X-Trace"); len(got) != 0 {
+			t.Errorf("Expected no middleware on other methods, got %v", got)
+		}
+	})
+
+	t.Run("Task middleware", func(t *testing.T) {
+		r := NewRouter()
+		var ran bool
+		SetPatternLevelTaskMiddlewareByPattern(r, http.MethodGet, "/tasks", TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			ran = true
+			return None{}, nil
+		}))
+		RegisterHandlerFunc(r, http.MethodGet, "/tasks", ok)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+		if !ran || w.Body.String() != "ok" {
+			t.Errorf("Expected task middleware to run before handler, ran=%v body=%q", ran, w.Body.String())
+		}
+	})
+
+	t.Run("Unregistered patterns fail validation", func(t *testing.T) {
+		r := NewRouter()
+		RegisterHandlerFunc(r, http.MethodGet, "/users", ok)
+		SetPatternLevelHTTPMiddlewareByPattern(r, http.MethodGet, "/userz", addHeader("x"))
+		SetPatternLevelHTTPMiddlewareByPattern(r, http.MethodDelete, "/users", addHeader("x"))
+
+		err := r.Validate()
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+		for _, want := range []string{"GET /userz", "DELETE /users"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to mention %q, got %v", want, err)
+			}
+		}
+
+		// Methods with only pending middleware still fall through to not found
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestGetMatchedRoute(t *testing.T) {
+	r := NewRouter()
+
+	var mwPattern string
+	SetGlobalHTTPMiddleware(r, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mwPattern = GetMatchedPattern(req)
+			next.ServeHTTP(w, req)
+		})
+	})
+
+	writePattern := func(w http.ResponseWriter, req *http.Request) {
+		route := GetMatchedRoute(req)
+		if route == nil {
+			w.Write([]byte("<nil>"))
+			return
+		}
+		w.Write([]byte(route.Method() + " " + GetMatchedPattern(req)))
+	}
+
+	RegisterHandlerFunc(r, http.MethodGet, "/users/:id", writePattern)
+	slowRoute := RegisterHandlerFunc(r, http.MethodGet, "/orgs/:org/*", writePattern)
+	SetPatternLevelTaskMiddleware(slowRoute, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+		return None{}, nil
+	}))
+	SetGlobalNotFoundHTTPHandler(r, http.HandlerFunc(writePattern))
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"Fast path", "/users/123", "GET /users/:id"},
+		{"Slow path", "/orgs/acme/repos/1", "GET /orgs/:org/*"},
+		{"Not found", "/nope", "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mwPattern = "unset"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Body.String() != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, w.Body.String())
+			}
+			if tt.want != "<nil>" && "GET "+mwPattern != tt.want {
+				t.Errorf("Expected middleware to see pattern for %q, got %q", tt.want, mwPattern)
+			}
+		})
+	}
+
+	if got := GetMatchedPattern(httptest.NewRequest(http.MethodGet, "/", nil)); got != "" {
+		t.Errorf("Expected empty pattern outside the router, got %q", got)
+	}
+}
+
+type testMetrics struct {
+	mu       sync.Mutex
+	noMethod int
+	noMatch  int
+	matched  []string
+}
+
+func (m *testMetrics) IncNoMethod() { m.mu.Lock(); m.noMethod++; m.mu.Unlock() }
+func (m *testMetrics) IncNoMatch()  { m.mu.Lock(); m.noMatch++; m.mu.Unlock() }
+func (m *testMetrics) IncMatched(pattern string) {
+	m.mu.Lock()
+	m.matched = append(m.matched, pattern)
+	m.mu.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &testMetrics{}
+	r := NewRouter(&Options{Metrics: metrics})
+	RegisterHandlerFunc(r, http.MethodGet, "/users/:id", func(w http.ResponseWriter, req *http.Request) {})
+	// Method-level middleware alone does not count as routes for the method
+	SetMethodLevelHTTPMiddleware(r, http.MethodPut, func(next http.Handler) http.Handler { return next })
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/users/123"},
+		{http.MethodHead, "/users/456"}, // falls back to GET
+		{http.MethodGet, "/nope"},
+		{http.MethodHead, "/nope"},
+		{http.MethodPost, "/users/123"},
+		{http.MethodPut, "/users/123"},
+	}
+	for _, req := range requests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	if !slices.Equal(metrics.matched, []string{"/users/:id", "/users/:id"}) {
+		t.Errorf("Expected two matches of /users/:id, got %v", metrics.matched)
+	}
+	if metrics.noMatch != 2 {
+		t.Errorf("Expected 2 no-match requests, got %d", metrics.noMatch)
+	}
+	if metrics.noMethod != 2 {
+		t.Errorf("Expected 2 no-method requests, got %d", metrics.noMethod)
+	}
+
+	// No metrics configured
+	r = NewRouter()
+	RegisterHandlerFunc(r, http.MethodGet, "/", func(w http.ResponseWriter, req *http.Request) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+}
+
+func TestShortCircuitHEAD(t *testing.T) {
+	r := NewRouter()
+
+	var calls int
+	var mwCalls int
+	SetGlobalHTTPMiddleware(r, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mwCalls++
+			next.ServeHTTP(w, req)
+		})
+	})
+	expensive := func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.Header().Set("X-From-Handler", "1")
+		w.Write([]byte("expensive"))
+	}
+
+	RegisterHandlerFunc(r, http.MethodGet, "/default", expensive)
+	RegisterHandlerFunc(r, http.MethodGet, "/short", expensive).
+		ShortCircuitHEAD(http.Header{"Content-Type": {"text/plain"}})
+	RegisterHandlerFunc(r, http.MethodGet, "/dedicated", expensive).ShortCircuitHEAD(nil)
+	RegisterHandlerFunc(r, http.MethodHead, "/dedicated", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Dedicated", "1")
+	})
+
+	t.Run("Default fallback runs the GET handler", func(t *testing.T) {
+		calls, mwCalls = 0, 0
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/default", nil))
+		if calls != 1 || mwCalls != 1 {
+			t.Errorf("Expected handler and middleware to run once, got %d and %d", calls, mwCalls)
+		}
+		if w.Header().Get("X-From-Handler") != "1" || w.Body.Len() != 0 {
+			t.Errorf("Expected handler headers and no body, got %v %q", w.Header(), w.Body.String())
+		}
+	})
+
+	t.Run("Short-circuit skips the handler", func(t *testing.T) {
+		calls, mwCalls = 0, 0
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/short", nil))
+		if calls != 0 || mwCalls != 0 {
+			t.Errorf("Expected handler and middleware not to run, got %d and %d", calls, mwCalls)
+		}
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/plain" {
+			t.Errorf("Expected 200 with configured headers, got %d %v", w.Code, w.Header())
+		}
+		if w.Header().Get("X-From-Handler") != "" || w.Body.Len() != 0 {
+			t.Errorf("Expected no handler headers or body, got %v %q", w.Header(), w.Body.String())
+		}
+	})
+
+	t.Run("GET is unaffected", func(t *testing.T) {
+		calls = 0
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/short", nil))
+		if calls != 1 || w.Body.String() != "expensive" {
+			t.Errorf("Expected GET to run the handler, got %d calls and %q", calls, w.Body.String())
+		}
+	})
+
+	t.Run("Dedicated HEAD route takes precedence", func(t *testing.T) {
+		calls = 0
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/dedicated", nil))
+		if calls != 0 || w.Header().Get("X-Dedicated") != "1" {
+			t.Errorf("Expected the HEAD route to serve, got %d calls and %v", calls, w.Header())
+		}
+	})
+}
+
+func TestRouterUseChaining(t *testing.T) {
+	var order []string
+	httpMw := func(name string) HTTPMiddleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	var taskMwCalled bool
+	taskMw := TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+		taskMwCalled = true
+		return None{}, nil
+	})
+
+	r := NewRouter().
+		UseHTTP(httpMw("first")).
+		UseHTTP(httpMw("second"), &MiddlewareOptions{
+			If: func(req *http.Request) bool { return req.URL.Path != "/skip" },
+		}).
+		UseTask(taskMw)
+
+	RegisterHandlerFunc(r, http.MethodGet, "/", func(w http.ResponseWriter, req *http.Request) {
+		order = append(order, "handler")
+	})
+	RegisterHandlerFunc(r, http.MethodGet, "/skip", func(w http.ResponseWriter, req *http.Request) {
+		order = append(order, "handler")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !slices.Equal(order, []string{"first", "second", "handler"}) {
+		t.Errorf("Expected middleware in registration order, got %v", order)
+	}
+	if !taskMwCalled {
+		t.Error("Expected task middleware to run")
+	}
+
+	order = nil
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/skip", nil))
+	if !slices.Equal(order, []string{"first", "handler"}) {
+		t.Errorf("Expected options to be respected, got %v", order)
+	}
+
+	t.Run("UseTask rejects non-middleware tasks", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected UseTask to panic for a task with the wrong input type")
+			}
+		}()
+		NewRouter().UseTask(tasks.NewTask(func(c *tasks.Ctx, input string) (None, error) {
+			return None{}, nil
+		}))
+	})
+}
+
+// Matches paths case-insensitively by delegating to the built-in matcher
+type caseInsensitiveMatcher struct {
+	*matcher.Matcher
+	registered []string
+}
+
+func (m *caseInsensitiveMatcher) RegisterPattern(originalPattern string) *matcher.RegisteredPattern {
+	m.registered = append(m.registered, originalPattern)
+	return m.Matcher.RegisterPattern(originalPattern)
+}
+
+func (m *caseInsensitiveMatcher) FindBestMatch(realPath string) (*matcher.BestMatch, bool) {
+	return m.Matcher.FindBestMatch(strings.ToLower(realPath))
+}
+
+func TestMatcherFactory(t *testing.T) {
+	var created []*caseInsensitiveMatcher
+	var gotOpts *matcher.Options
+	r := NewRouter(&Options{
+		DynamicParamPrefixRune: '$',
+		MatcherFactory: func(opts *matcher.Options) Matcher {
+			gotOpts = opts
+			m := &caseInsensitiveMatcher{Matcher: matcher.New(opts)}
+			created = append(created, m)
+			return m
+		},
+	})
+	RegisterHandlerFunc(r, http.MethodGet, "/users/$id", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("user " + GetParam(req, "id")))
+	})
+	RegisterHandlerFunc(r, http.MethodPost, "/users", func(w http.ResponseWriter, req *http.Request) {})
+
+	if len(created) != 2 {
+		t.Fatalf("Expected one matcher per method, got %d", len(created))
+	}
+	if gotOpts == nil || gotOpts.DynamicParamPrefixRune != '$' {
+		t.Errorf("Expected the router's matcher options to be passed, got %+v", gotOpts)
+	}
+	if !slices.Equal(created[0].registered, []string{"/users/$id"}) {
+		t.Errorf("Expected /users/$id to be registered, got %v", created[0].registered)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/USERS/abc", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "user abc" {
+		t.Errorf("Expected a case-insensitive match, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// The default matcher stays case-sensitive
+	r = NewRouter()
+	RegisterHandlerFunc(r, http.MethodGet, "/users/:id", func(w http.ResponseWriter, req *http.Request) {})
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/USERS/abc", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 with the default matcher, got %d", rec.Code)
+	}
+}
+
+// Matches against a separate matcher that knows patterns the router
+// never registered
+type ghostMatcher struct {
+	*matcher.Matcher
+	ghost *matcher.Matcher
+}
+
+func (m *ghostMatcher) FindBestMatch(realPath string) (*matcher.BestMatch, bool) {
+	return m.ghost.FindBestMatch(realPath)
+}
+
+func TestMatcherFactoryUnregisteredPattern(t *testing.T) {
+	r := NewRouter(&Options{
+		MatcherFactory: func(opts *matcher.Options) Matcher {
+			ghost := matcher.New(opts)
+			ghost.RegisterPattern("/ghost")
+			return &ghostMatcher{Matcher: matcher.New(opts), ghost: ghost}
+		},
+	})
+	RegisterHandlerFunc(r, http.MethodGet, "/real", func(w http.ResponseWriter, req *http.Request) {})
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, "/ghost", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404 for an unregistered pattern, got %d", method, rec.Code)
+		}
+	}
+}
+
+// Routes by host, with patterns written as /{host}/{path}
+type hostMatcher struct {
+	*matcher.Matcher
+}
+
+func (m *hostMatcher) FindBestMatchForRequest(r *http.Request, realPath string) (*matcher.BestMatch, bool) {
+	return m.Matcher.FindBestMatch("/" + r.Host + realPath)
+}
+
+func TestMatcherFactoryRequestMatcher(t *testing.T) {
+	r := NewRouter(&Options{
+		MatcherFactory: func(opts *matcher.Options) Matcher {
+			return &hostMatcher{Matcher: matcher.New(opts)}
+		},
+	})
+	RegisterHandlerFunc(r, http.MethodGet, "/a.example.com/hello", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("a"))
+	})
+	RegisterHandlerFunc(r, http.MethodGet, "/b.example.com/hello", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("b"))
+	})
+
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.String() != host[:1] {
+			t.Errorf("%s: expected %q, got %d %q", host, host[:1], rec.Code, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	req.Host = "c.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown host, got %d", rec.Code)
+	}
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, int, time.Duration) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func TestRateLimitTaskMiddleware(t *testing.T) {
+	newRouter := func(cfg RateLimitConfig) (*Router, *int) {
+		router := NewRouter(nil)
+		SetGlobalTaskMiddleware(router, RateLimitTaskMiddleware(cfg))
+		var handlerCalls int
+		RegisterHandlerFunc(router, http.MethodGet, "/test", func(w http.ResponseWriter, r *http.Request) {
+			handlerCalls++
+			w.WriteHeader(http.StatusOK)
+		})
+		return router, &handlerCalls
+	}
+	serve := func(router *Router, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Sets_Error_Status_Returns_Nil", func(t *testing.T) {
+		now := time.Unix(1000, 0)
+		store := NewMemoryRateLimitStore()
+		store.now = func() time.Time { return now }
+		router, handlerCalls := newRouter(RateLimitConfig{Limit: 2, Window: time.Minute, Store: store})
+
+		for i := range 2 {
+			if rec := serve(router, "10.0.0.1:1234"); rec.Code != http.StatusOK {
+				t.Fatalf("request %d: expected 200, got %d", i, rec.Code)
+			}
+		}
+		rec := serve(router, "10.0.0.1:5678") // same client, different port
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected 429, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "30" {
+			t.Errorf("Expected Retry-After of 30 seconds, got %q", got)
+		}
+		if *handlerCalls != 2 {
+			t.Errorf("Handler should not be called when rate limited, got %d calls", *handlerCalls)
+		}
+
+		// Other clients have their own budget
+		if rec := serve(router, "10.0.0.2:1234"); rec.Code != http.StatusOK {
+			t.Errorf("Expected 200 for another client, got %d", rec.Code)
+		}
+
+		// Tokens refill over the window
+		now = now.Add(30 * time.Second)
+		if rec := serve(router, "10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Errorf("Expected 200 after refill, got %d", rec.Code)
+		}
+		if rec := serve(router, "10.0.0.1:1234"); rec.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected 429 once the refilled token is used, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Custom_Key_And_Exemptions", func(t *testing.T) {
+		router, _ := newRouter(RateLimitConfig{
+			KeyFn:  func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+			Limit:  1,
+			Window: time.Hour,
+		})
+		req := func(apiKey string) int {
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r.Header.Set("X-API-Key", apiKey)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+			return rec.Code
+		}
+		if req("a") != http.StatusOK || req("a") != http.StatusTooManyRequests || req("b") != http.StatusOK {
+			t.Error("Expected requests to be limited per API key")
+		}
+		if req("") != http.StatusOK || req("") != http.StatusOK {
+			t.Error("Expected requests with an empty key not to be limited")
+		}
+	})
+
+	t.Run("Store_Error_Is_Unexpected", func(t *testing.T) {
+		router, handlerCalls := newRouter(RateLimitConfig{Limit: 1, Window: time.Second, Store: failingRateLimitStore{}})
+		if rec := serve(router, "10.0.0.1:1234"); rec.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", rec.Code)
+		}
+		if *handlerCalls != 0 {
+			t.Error("Handler should not be called when the store fails")
+		}
+	})
+
+	t.Run("Sweeps_Refilled_Buckets", func(t *testing.T) {
+		now := time.Unix(1000, 0)
+		store := NewMemoryRateLimitStore()
+		store.now = func() time.Time { return now }
+		for _, key := range []string{"a", "b", "c"} {
+			store.Take(context.Background(), key, 5, time.Second)
+		}
+		now = now.Add(2 * time.Second)
+		store.Take(context.Background(), "d", 5, time.Second)
+		if len(store.buckets) != 1 {
+			t.Errorf("Expected only the new bucket to remain, got %d buckets", len(store.buckets))
+		}
+	})
+
+	t.Run("Invalid_Config_Panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected a panic for a zero window")
+			}
+		}()
+		RateLimitTaskMiddleware(RateLimitConfig{Limit: 1})
+	})
+}
+
+func TestRegisterAny(t *testing.T) {
+	r := NewRouter()
+
+	tag := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(name + " " + req.Method))
+		}
+	}
+	RegisterHandlerAny(r, "/webhook", tag("any"))
+	RegisterHandlerAny(r, "/files/*", tag("any-splat"))
+	RegisterHandlerFunc(r, http.MethodPost, "/webhook", tag("post"))
+	RegisterHandlerFunc(r, http.MethodGet, "/page", tag("get"))
+	RegisterTaskHandlerAny(r, "/api/echo", TaskHandlerFromFunc(func(rd *ReqData[None]) (string, error) {
+		return rd.Request().Method, nil
+	}))
+
+	tests := []struct {
+		method, path, want string
+		code               int
+	}{
+		{http.MethodGet, "/webhook", "any GET", 200},
+		{http.MethodPut, "/webhook", "any PUT", 200},
+		{http.MethodPost, "/webhook", "post POST", 200},
+		{http.MethodDelete, "/files/a/b", "any-splat DELETE", 200},
+		{http.MethodGet, "/page", "get GET", 200},
+		{http.MethodPatch, "/api/echo", `"PATCH"`, 200},
+		{http.MethodPost, "/page", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || (tt.want != "" && strings.TrimSpace(w.Body.String()) != tt.want) {
+			t.Errorf("%s %s: got %d %q, want %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.code, tt.want)
+		}
+	}
+
+	t.Run("HEAD prefers the GET fallback over ANY", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/page", nil))
+		if w.Code != http.StatusOK || w.Body.Len() != 0 {
+			t.Errorf("Expected a bodiless 200 from the GET route, got %d %q", w.Code, w.Body.String())
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/webhook", nil))
+		if w.Body.String() != "any HEAD" {
+			t.Errorf("Expected the ANY route to see HEAD, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		m := &testMetrics{}
+		r := NewRouter(&Options{Metrics: m})
+		RegisterHandlerAny(r, "/webhook", tag("any"))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/webhook", nil))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/other", nil))
+		if m.noMethod != 0 || m.noMatch != 1 {
+			t.Errorf("Expected an ANY route to count as a route for every method, got %d noMethod and %d noMatch", m.noMethod, m.noMatch)
+		}
+	})
+}
+
+func TestSequentialTaskMiddleware(t *testing.T) {
+	type User struct{ Name string }
+	type Tenant string
+
+	newRouter := func(ranTenant *atomic.Bool) *Router {
+		r := NewRouter(nil)
+		// Registered in reverse of the order they must observe each other,
+		// to show that phases, not registration, order them across phases.
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			user, _ := FromMiddleware[*User](rd)
+			tenant, _ := FromMiddleware[Tenant](rd)
+			if user == nil || tenant == "" {
+				return None{}, errors.New("after middleware ran too early")
+			}
+			rd.ResponseProxy().SetHeader("X-Seen", user.Name+"@"+string(tenant))
+			return None{}, nil
+		}), &MiddlewareOptions{Sequential: SequentialAfter})
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			rd.ResponseProxy().SetHeader("X-Seen", "parallel")
+			return None{}, nil
+		}))
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (*User, error) {
+			if rd.Request().Header.Get("Authorization") == "" {
+				rd.ResponseProxy().SetStatus(http.StatusUnauthorized)
+				return nil, nil
+			}
+			return &User{Name: "alice"}, nil
+		}), &MiddlewareOptions{Sequential: SequentialBefore})
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (Tenant, error) {
+			ranTenant.Store(true)
+			user, ok := FromMiddleware[*User](rd)
+			if !ok {
+				return "", errors.New("tenant middleware ran before auth")
+			}
+			return Tenant(user.Name + "-corp"), nil
+		}), &MiddlewareOptions{Sequential: SequentialBefore})
+		RegisterTaskHandler(r, http.MethodGet, "/me", TaskHandlerFromFunc(func(rd *ReqData[None]) (string, error) {
+			tenant, _ := FromMiddleware[Tenant](rd)
+			return string(tenant), nil
+		}))
+		return r
+	}
+
+	t.Run("Phases run in order", func(t *testing.T) {
+		var ranTenant atomic.Bool
+		r := newRouter(&ranTenant)
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `"alice-corp"` {
+			t.Fatalf("Expected 200 with the tenant, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("X-Seen"); got != "alice@alice-corp" {
+			t.Errorf("Expected the after phase's header to win the merge, got %q", got)
+		}
+	})
+
+	t.Run("Error status skips later middleware", func(t *testing.T) {
+		var ranTenant atomic.Bool
+		r := newRouter(&ranTenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d: %s", w.Code, w.Body.String())
+		}
+		if ranTenant.Load() {
+			t.Errorf("Expected the tenant middleware to be skipped")
+		}
+		if w.Header().Get("X-Seen") != "" {
+			t.Errorf("Expected later phases to be skipped, got X-Seen %q", w.Header().Get("X-Seen"))
+		}
+	})
+}
+
+func TestPathLimitsMiddleware(t *testing.T) {
+	r := NewRouter(nil)
+	RegisterHandlerFunc(r, http.MethodGet, "/*", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	handler := PathLimitsMiddleware(64, 4)(r)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"normal path", "/a/b/c", http.StatusOK},
+		{"at segment limit", "/a/b/c/d", http.StatusOK},
+		{"too long", "/" + strings.Repeat("a", 64), http.StatusRequestURITooLong},
+		{"too many segments", "/a/b/c/d/e", http.StatusBadRequest},
+		{"trailing slash counts as segment", "/a/b/c/d/", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+
+	t.Run("zero disables limits", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/"+strings.Repeat("a/", 100), nil)
+		w := httptest.NewRecorder()
+		PathLimitsMiddleware(0, 0)(r).ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+}
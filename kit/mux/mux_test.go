@@ -386,6 +386,51 @@ func TestHTTPMiddleware(t *testing.T) {
 		}
 	})
 
+	t.Run("Middleware_With_IfRoute_Tags", func(t *testing.T) {
+		r := NewRouter(nil)
+		var httpMwCalled, taskMwCalled bool
+
+		SetGlobalHTTPMiddleware(r, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				httpMwCalled = true
+				next.ServeHTTP(w, req)
+			})
+		}, &MiddlewareOptions{
+			IfRoute: func(req *http.Request, route AnyRoute) bool {
+				return route.HasTag("auth")
+			},
+		})
+		SetGlobalTaskMiddleware(r, TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+			taskMwCalled = true
+			return None{}, nil
+		}), &MiddlewareOptions{
+			IfRoute: func(req *http.Request, route AnyRoute) bool {
+				return route.HasTag("auth")
+			},
+		})
+
+		RegisterHandlerFunc(r, http.MethodGet, "/public", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		route := RegisterHandlerFunc(r, http.MethodGet, "/account", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}).Tag("auth", "account", "auth")
+
+		if len(route.Tags()) != 2 {
+			t.Errorf("Expected duplicate tags to be ignored, got %v", route.Tags())
+		}
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/public", nil))
+		if httpMwCalled || taskMwCalled {
+			t.Error("Middlewares should not run for untagged routes")
+		}
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/account", nil))
+		if !httpMwCalled || !taskMwCalled {
+			t.Error("Middlewares should run for routes tagged 'auth'")
+		}
+	})
+
 	t.Run("Middleware_Short_Circuit", func(t *testing.T) {
 		r := NewRouter(nil)
 		var handlerCalled bool
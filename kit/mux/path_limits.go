@@ -0,0 +1,61 @@
+package mux
+
+import "net/http"
+
+/////////////////////////////////////////////////////////////////////
+/////// PATH LIMITS
+/////////////////////////////////////////////////////////////////////
+
+// PathLimitsMiddleware returns a middleware that rejects requests whose
+// URL path is longer than maxLength bytes (with a 414) or has more than
+// maxSegments slash-separated segments (with a 400), so that pathological
+// paths (e.g., thousands of segments aimed at splat matching) never reach
+// the matcher. A maxLength or maxSegments less than 1 disables that check.
+//
+// A router's own middleware (e.g., via SetGlobalHTTPMiddleware) only runs
+// once a request has been matched, so to reject requests before matching,
+// wrap the router itself (e.g., PathLimitsMiddleware(2048, 64)(router)).
+func PathLimitsMiddleware(maxLength, maxSegments int) HTTPMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if maxLength > 0 && len(path) > maxLength {
+				http.Error(w, http.StatusText(http.StatusRequestURITooLong), http.StatusRequestURITooLong)
+				return
+			}
+			if maxSegments > 0 && countPathSegments(path) > maxSegments {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Counts segments the same way matcher.ParseSegments splits them (empty
+// segments are skipped, except for a trailing slash), without allocating.
+func countPathSegments(path string) int {
+	if path == "" {
+		return 0
+	}
+	count := 0
+	start := 0
+	if path[0] == '/' {
+		start = 1
+	}
+	for i := start; i < len(path); i++ {
+		if path[i] == '/' {
+			if i > start {
+				count++
+			}
+			start = i + 1
+		}
+	}
+	if start < len(path) {
+		count++
+	}
+	if path[len(path)-1] == '/' {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,104 @@
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// PATTERN-LEVEL MIDDLEWARE BY PATTERN
+/////////////////////////////////////////////////////////////////////
+
+type pendingPatternMws struct {
+	httpMws []httpMiddlewareWithOptions
+	taskMws []taskMiddlewareWithOptions
+}
+
+// SetPatternLevelHTTPMiddlewareByPattern is like SetPatternLevelHTTPMiddleware,
+// but takes the route's method and pattern (exactly as registered) instead
+// of the route itself, so that middleware can be wired up before (or
+// independently of) handler registration, e.g., from config. If the route
+// does not exist yet, the middleware is attached once it is registered.
+// Call Router.Validate after registering all routes to catch middleware
+// set for patterns that were never registered.
+func SetPatternLevelHTTPMiddlewareByPattern(
+	router *Router, method, pattern string, httpMw HTTPMiddleware, opts ...*MiddlewareOptions,
+) {
+	mw := httpMiddlewareWithOptions{mw: httpMw, opts: getFirstOpt(opts)}
+	mm := router.getOrCreateMethodMatcher(method)
+	if route, ok := mm.routes[pattern]; ok {
+		route.addHTTPMw(mw)
+		return
+	}
+	pending := mm.getOrCreatePendingPatternMws(pattern)
+	pending.httpMws = append(pending.httpMws, mw)
+}
+
+// SetPatternLevelTaskMiddlewareByPattern is the task middleware equivalent
+// of SetPatternLevelHTTPMiddlewareByPattern.
+func SetPatternLevelTaskMiddlewareByPattern[O any](
+	router *Router, method, pattern string, taskMw *TaskMiddleware[O], opts ...*MiddlewareOptions,
+) {
+	mw := taskMiddlewareWithOptions{mw: taskMw, opts: getFirstOpt(opts)}
+	mm := router.getOrCreateMethodMatcher(method)
+	if route, ok := mm.routes[pattern]; ok {
+		route.addTaskMw(mw)
+		return
+	}
+	pending := mm.getOrCreatePendingPatternMws(pattern)
+	pending.taskMws = append(pending.taskMws, mw)
+}
+
+// Validate reports an error for each method and pattern that had
+// pattern-level middleware set (via SetPatternLevelHTTPMiddlewareByPattern
+// or SetPatternLevelTaskMiddlewareByPattern) but was never registered.
+// Call it once all routes are registered, before serving any requests.
+func (rt *Router) Validate() error {
+	var errs []error
+	for method, mm := range rt.methodToMatcherMap {
+		for pattern := range mm.pendingPatternMws {
+			errs = append(errs, fmt.Errorf(
+				"mux: pattern-level middleware set for unregistered route %s %s", method, pattern,
+			))
+		}
+	}
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+func (mm *methodMatcher) getOrCreatePendingPatternMws(pattern string) *pendingPatternMws {
+	if mm.pendingPatternMws == nil {
+		mm.pendingPatternMws = make(map[string]*pendingPatternMws)
+	}
+	pending, ok := mm.pendingPatternMws[pattern]
+	if !ok {
+		pending = new(pendingPatternMws)
+		mm.pendingPatternMws[pattern] = pending
+	}
+	return pending
+}
+
+// Attaches (in order) any middleware set by pattern before the route was
+// registered.
+func (mm *methodMatcher) attachPendingPatternMws(route AnyRoute) {
+	pending, ok := mm.pendingPatternMws[route.OriginalPattern()]
+	if !ok {
+		return
+	}
+	for _, mw := range pending.httpMws {
+		route.addHTTPMw(mw)
+	}
+	for _, mw := range pending.taskMws {
+		route.addTaskMw(mw)
+	}
+	delete(mm.pendingPatternMws, route.OriginalPattern())
+}
+
+func (route *Route[I, O]) addHTTPMw(mw httpMiddlewareWithOptions) {
+	route.httpMws = append(route.httpMws, mw)
+}
+func (route *Route[I, O]) addTaskMw(mw taskMiddlewareWithOptions) {
+	route.taskMws = append(route.taskMws, mw)
+}
@@ -0,0 +1,167 @@
+package mux
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// RATE LIMIT
+/////////////////////////////////////////////////////////////////////
+
+// RateLimitStore tracks request budgets per key. Implementations backed
+// by a shared store (e.g., Redis) let several instances of an app
+// enforce a single limit. They must be safe for concurrent use.
+type RateLimitStore interface {
+	// Take consumes one request from key's budget of limit requests per
+	// window. If none is left, it reports false, along with how long
+	// until one will be.
+	Take(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+type RateLimitConfig struct {
+	// Optional. Returns the key that requests are limited by. Defaults to
+	// the client IP from r.RemoteAddr, so if your app runs behind a proxy
+	// or load balancer, use a key derived from a trusted forwarding header
+	// (or from the authenticated user) instead. Requests for which it
+	// returns an empty string are not limited.
+	KeyFn func(r *http.Request) string
+	// Required. The number of requests allowed per Window (and the
+	// largest burst allowed after a quiet period).
+	Limit int
+	// Required. The period over which Limit requests are allowed.
+	Window time.Duration
+	// Optional. Defaults to a new MemoryRateLimitStore, which only limits
+	// requests served by the current process.
+	Store RateLimitStore
+}
+
+// RateLimitTaskMiddleware returns a task middleware that limits each key
+// (see RateLimitConfig.KeyFn) to cfg.Limit requests per cfg.Window.
+// Requests over the limit get a 429 with a Retry-After header, and the
+// rest of the chain (including the handler) does not run. Errors from
+// the store are returned as is (and so, by default, answered with a 500).
+// It panics if cfg.Limit or cfg.Window is not positive.
+//
+// Each call returns an independent task middleware, but requests only
+// share a budget if they share a store, so to limit several routes
+// together, either register the same returned middleware for each of
+// them or give each middleware the same Store.
+func RateLimitTaskMiddleware(cfg RateLimitConfig) *TaskMiddleware[None] {
+	if cfg.Limit < 1 {
+		panic("mux: RateLimitConfig.Limit must be positive")
+	}
+	if cfg.Window <= 0 {
+		panic("mux: RateLimitConfig.Window must be positive")
+	}
+	keyFn := cfg.KeyFn
+	if keyFn == nil {
+		keyFn = clientIPFromRemoteAddr
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	return TaskMiddlewareFromFunc(func(rd *ReqData[None]) (None, error) {
+		r := rd.Request()
+		key := keyFn(r)
+		if key == "" {
+			return None{}, nil
+		}
+		allowed, retryAfter, err := store.Take(r.Context(), key, cfg.Limit, cfg.Window)
+		if err != nil {
+			return None{}, err
+		}
+		if !allowed {
+			seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+			rd.ResponseProxy().SetHeader("Retry-After", strconv.Itoa(seconds))
+			rd.ResponseProxy().SetStatus(http.StatusTooManyRequests)
+		}
+		return None{}, nil
+	})
+}
+
+func clientIPFromRemoteAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// MemoryRateLimitStore is an in-process RateLimitStore implementing a
+// token bucket per key: each bucket holds up to limit tokens, and refills
+// continuously at limit tokens per window. Buckets that have fully
+// refilled are periodically dropped, so memory use is bounded by the
+// number of recently active keys.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	limit  int
+	window time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.maybeSweep(now, window)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit), last: now}
+		s.buckets[key] = b
+	} else {
+		b.refill(now)
+	}
+	b.limit, b.window = limit, window
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	missing := 1 - b.tokens
+	return false, time.Duration(missing * float64(window) / float64(limit)), nil
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = min(float64(b.limit), b.tokens+float64(b.limit)*elapsed.Seconds()/b.window.Seconds())
+	b.last = now
+}
+
+// At most once per window, drops buckets that have fully refilled (which
+// are indistinguishable from new ones).
+func (s *MemoryRateLimitStore) maybeSweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit) {
+			delete(s.buckets, key)
+		}
+	}
+}
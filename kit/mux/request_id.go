@@ -0,0 +1,96 @@
+package mux
+
+import (
+	"net/http"
+
+	"github.com/river-now/river/kit/id"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// REQUEST IDS
+/////////////////////////////////////////////////////////////////////
+
+const (
+	DefaultRequestIDHeaderName = "X-Request-Id"
+	requestIDLen               = 20
+	maxIncomingRequestIDLen    = 128
+)
+
+type RequestIDConfig struct {
+	// Header from which incoming IDs are read (if TrustIncoming is true),
+	// and in which the ID is echoed on the response. Defaults to
+	// DefaultRequestIDHeaderName.
+	HeaderName string
+	// If true, a well-formed ID already present on the incoming request
+	// (e.g., one set by a trusted load balancer) is used as is, rather than
+	// a new one being generated. Only enable this if something in front of
+	// your app sets (or strips) the header.
+	TrustIncoming bool
+	// Optional. Defaults to a random 20-character alphanumeric ID.
+	Generate func() string
+}
+
+// GetRequestID returns the ID the router assigned to the request (see
+// Options.RequestID), or an empty string if request IDs are disabled.
+func GetRequestID(r *http.Request) string {
+	if rd := requestStore.GetValueFromContext(r.Context()); rd != nil {
+		return rd.requestID
+	}
+	return ""
+}
+
+// Returns an empty string if request IDs are disabled. Otherwise, sets
+// the response header and records the ID on any outer AccessLogMiddleware.
+func (rt *Router) ensureRequestID(w http.ResponseWriter, r *http.Request) string {
+	cfg := rt.requestIDConfig
+	if cfg == nil {
+		return ""
+	}
+	var requestID string
+	if cfg.TrustIncoming {
+		if incoming := r.Header.Get(cfg.HeaderName); isWellFormedRequestID(incoming) {
+			requestID = incoming
+		}
+	}
+	if requestID == "" {
+		requestID = cfg.Generate()
+	}
+	w.Header().Set(cfg.HeaderName, requestID)
+	recordAccessLogRequestID(r, requestID)
+	return requestID
+}
+
+// Guards against log injection and unbounded lengths from untrusted input.
+func isWellFormedRequestID(s string) bool {
+	if s == "" || len(s) > maxIncomingRequestIDLen {
+		return false
+	}
+	for i := range len(s) {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func resolveRequestIDConfig(cfg *RequestIDConfig) *RequestIDConfig {
+	if cfg == nil {
+		return nil
+	}
+	resolved := *cfg
+	if resolved.HeaderName == "" {
+		resolved.HeaderName = DefaultRequestIDHeaderName
+	}
+	if resolved.Generate == nil {
+		resolved.Generate = generateRequestID
+	}
+	return &resolved
+}
+
+func generateRequestID() string {
+	requestID, err := id.New(requestIDLen)
+	if err != nil {
+		muxLog.Error("Failed to generate request ID", "error", err)
+	}
+	return requestID
+}
@@ -0,0 +1,45 @@
+package mux
+
+/////////////////////////////////////////////////////////////////////
+/////// SEQUENTIAL TASK MIDDLEWARE
+/////////////////////////////////////////////////////////////////////
+
+// SequentialPhase opts a task middleware out of running in parallel with
+// the others (see MiddlewareOptions.Sequential), for middleware that must
+// observe another's effects (e.g., tenant resolution that needs the user
+// produced by an auth middleware, read via FromMiddleware).
+//
+// For each request, the applicable task middlewares run in three phases:
+// first the SequentialBefore ones, one at a time, in registration order
+// (global, then method-level, then pattern-level, and in the order
+// registered within each level); then all NotSequential ones, in parallel;
+// then the SequentialAfter ones, one at a time, in the same order. Each
+// middleware can read the outputs of every middleware from earlier phases
+// (and, when sequential, of earlier middlewares in its own phase) via
+// FromMiddleware. Once any middleware errors, or any response proxy from
+// a completed middleware has an error or redirect status, the remaining
+// middlewares are skipped.
+//
+// Each middleware still has its own response proxy. The proxies of all
+// middlewares that ran are merged as usual (see
+// response.MergeProxyResponses), in phase order (so, for example, a
+// header set by a SequentialAfter middleware replaces one set by an
+// earlier phase), but a middleware never sees another's proxy directly.
+type SequentialPhase int
+
+const (
+	NotSequential SequentialPhase = iota // the default: run in parallel
+	SequentialBefore
+	SequentialAfter
+)
+
+func (opts *MiddlewareOptions) sequentialPhase() SequentialPhase {
+	if opts == nil {
+		return NotSequential
+	}
+	switch opts.Sequential {
+	case SequentialBefore, SequentialAfter:
+		return opts.Sequential
+	}
+	return NotSequential
+}
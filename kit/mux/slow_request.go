@@ -0,0 +1,41 @@
+package mux
+
+import (
+	"net/http"
+	"time"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// SLOW REQUEST LOGGING
+/////////////////////////////////////////////////////////////////////
+
+// See Options.SlowRequestThreshold.
+func (rt *Router) serveAndLogIfSlow(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	sw := &accessLogResponseWriter{ResponseWriter: w}
+	requestID, best := rt.serve(sw, r)
+	duration := time.Since(start)
+	if duration <= rt.slowRequestAfter {
+		return
+	}
+	status := sw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	var pattern string
+	if best != nil && best.didMatch {
+		pattern = best.match.OriginalPattern()
+	}
+	attrs := []any{
+		"method", r.Method,
+		"path", r.URL.Path,
+		"pattern", pattern,
+		"status", status,
+		"duration", duration,
+		"threshold", rt.slowRequestAfter,
+	}
+	if requestID != "" {
+		attrs = append(attrs, "request_id", requestID)
+	}
+	muxLog.Warn("Slow request", attrs...)
+}
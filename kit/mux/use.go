@@ -0,0 +1,48 @@
+package mux
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/river-now/river/kit/tasks"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// CHAINABLE GLOBAL MIDDLEWARE
+/////////////////////////////////////////////////////////////////////
+
+// UseHTTP is a chainable equivalent of SetGlobalHTTPMiddleware, for
+// fluent router setup (e.g., mux.NewRouter().UseHTTP(a).UseHTTP(b)).
+func (rt *Router) UseHTTP(httpMw HTTPMiddleware, opts ...*MiddlewareOptions) *Router {
+	SetGlobalHTTPMiddleware(rt, httpMw, opts...)
+	return rt
+}
+
+// UseTask is a chainable equivalent of SetGlobalTaskMiddleware. As Go
+// methods cannot have type parameters, taskMw is typed as tasks.AnyTask
+// rather than *TaskMiddleware[O], so UseTask instead panics (at setup
+// time) if taskMw is not a task middleware, such as one created via
+// TaskMiddlewareFromFunc. Use SetGlobalTaskMiddleware to have this
+// checked at compile time.
+func (rt *Router) UseTask(taskMw tasks.AnyTask, opts ...*MiddlewareOptions) *Router {
+	if !isTaskMiddleware(taskMw) {
+		panic(fmt.Sprintf("mux: UseTask requires a *TaskMiddleware (got %T)", taskMw))
+	}
+	rt.taskMws = append(rt.taskMws, taskMiddlewareWithOptions{
+		mw:   taskMw,
+		opts: getFirstOpt(opts),
+	})
+	return rt
+}
+
+var taskMiddlewareInputType = reflect.TypeFor[*ReqData[None]]()
+
+// Reports whether taskMw is a *tasks.Task taking *ReqData[None] as input
+func isTaskMiddleware(taskMw tasks.AnyTask) bool {
+	if taskMw == nil {
+		return false
+	}
+	run, ok := reflect.TypeOf(taskMw).MethodByName("Run")
+	// In(0) is the receiver, In(1) the *tasks.Ctx
+	return ok && run.Type.NumIn() == 3 && run.Type.In(2) == taskMiddlewareInputType
+}
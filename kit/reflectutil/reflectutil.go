@@ -76,3 +76,45 @@ func GetJSONFieldName(field reflect.StructField) string {
 	}
 	return field.Name
 }
+
+// IsJSONFieldOmitted reports whether the field's json tag excludes it
+// from JSON entirely ("-" or "-,...").
+func IsJSONFieldOmitted(field reflect.StructField) bool {
+	tag := field.Tag.Get("json")
+	return tag == "-" || strings.HasPrefix(tag, "-,")
+}
+
+// IsJSONFieldOptional reports whether the field may be absent (or null)
+// in JSON, i.e., it is a pointer or is tagged omitempty or omitzero.
+func IsJSONFieldOptional(field reflect.StructField) bool {
+	if field.Type.Kind() == reflect.Ptr {
+		return true
+	}
+	parts := strings.Split(field.Tag.Get("json"), ",")
+	for _, part := range parts[1:] {
+		if part == "omitempty" || part == "omitzero" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetJSONFlattenedStruct returns the struct type of an embedded field
+// whose fields encoding/json flattens into the parent (an untagged or
+// unnamed embedded struct or pointer to struct), and whether it is
+// embedded by pointer. The final return value is false for any other
+// field.
+func GetJSONFlattenedStruct(field reflect.StructField) (reflect.Type, bool, bool) {
+	if !field.Anonymous || strings.Split(field.Tag.Get("json"), ",")[0] != "" {
+		return nil, false, false
+	}
+	t := field.Type
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false, false
+	}
+	return t, isPtr, true
+}
@@ -2,6 +2,7 @@ package response
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
 	"slices"
 
@@ -48,7 +49,11 @@ func (p *Proxy) GetStatus() (int, string) {
 
 /////// HEADERS
 
+// Header keys are canonicalized (as with http.Header), so that operations
+// on "x-foo" and "X-Foo" apply to the same header, including when merging.
+
 func (p *Proxy) SetHeader(key, value string) {
+	key = http.CanonicalHeaderKey(key)
 	p._headerOps[key] = append(
 		p._headerOps[key],
 		headerOp{op: "set", value: value},
@@ -56,12 +61,24 @@ func (p *Proxy) SetHeader(key, value string) {
 }
 
 func (p *Proxy) AddHeader(key, value string) {
+	key = http.CanonicalHeaderKey(key)
 	p._headerOps[key] = append(
 		p._headerOps[key],
 		headerOp{op: "add", value: value},
 	)
 }
 
+// DelHeader removes all values for the key, including those from any
+// earlier SetHeader or AddHeader calls (and, when merged, from earlier
+// proxies).
+func (p *Proxy) DelHeader(key string) {
+	key = http.CanonicalHeaderKey(key)
+	p._headerOps[key] = append(
+		p._headerOps[key],
+		headerOp{op: "del"},
+	)
+}
+
 func (p *Proxy) GetHeader(key string) string {
 	values := p.computeHeaderValues(key)
 	if len(values) == 0 {
@@ -75,15 +92,18 @@ func (p *Proxy) GetHeaders(key string) []string {
 }
 
 func (p *Proxy) computeHeaderValues(key string) []string {
-	ops := p._headerOps[key]
+	ops := p._headerOps[http.CanonicalHeaderKey(key)]
 	if len(ops) == 0 {
 		return nil
 	}
 	var values []string
 	for _, op := range ops {
-		if op.op == "set" {
+		switch op.op {
+		case "set":
 			values = []string{op.value}
-		} else {
+		case "del":
+			values = nil
+		default:
 			values = append(values, op.value)
 		}
 	}
@@ -195,14 +215,19 @@ func (p *Proxy) IsSuccess() bool {
 }
 
 func (p *Proxy) ApplyToResponseWriter(w http.ResponseWriter, r *http.Request) {
-	// Headers
-	for key, ops := range p._headerOps {
+	// Headers (applied in sorted key order for deterministic output)
+	for _, key := range slices.Sorted(maps.Keys(p._headerOps)) {
+		ops := p._headerOps[key]
 		currentValues := []string{}
 		for _, op := range ops {
-			if op.op == "set" {
+			switch op.op {
+			case "set":
 				w.Header().Del(key)
 				currentValues = []string{op.value}
-			} else {
+			case "del":
+				w.Header().Del(key)
+				currentValues = []string{}
+			default:
 				currentValues = append(currentValues, op.value)
 			}
 		}
@@ -241,6 +266,18 @@ type cookieWithIdx struct {
 	cookie *http.Cookie
 }
 
+// MergeProxyResponses merges proxies deterministically, in the order
+// given (for task middlewares, their registration order):
+//   - Headers: each proxy's operations are replayed in order, so a
+//     SetHeader (or DelHeader) from a later proxy replaces all earlier
+//     values for that key, while AddHeader values accumulate in proxy
+//     order.
+//   - Cookies: later cookies replace earlier ones with the same name.
+//   - Head elements: concatenated in order.
+//   - Status: the first error status wins; otherwise the first redirect
+//     (server or client) wins; otherwise the last success status wins.
+//     Client redirect headers from non-winning proxies are dropped.
+//
 // Consumers should deduplicate head els after calling MergeProxyResponses
 // by using headels.ToHeadEls(proxy.GetHeadElements())
 func MergeProxyResponses(proxies ...*Proxy) *Proxy {
@@ -294,6 +331,9 @@ func MergeProxyResponses(proxies ...*Proxy) *Proxy {
 		}
 	}
 
+	// Only the winning redirect (if any) may set the client redirect header
+	delete(merged._headerOps, ClientRedirectHeader)
+
 	// Redirect -- Assuming no error, FIRST REDIRECT WINS
 	if !isError(merged._status) {
 		for _, p := range proxies {
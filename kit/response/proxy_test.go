@@ -739,6 +739,127 @@ func TestMergeProxyResponses_HeaderOperations(t *testing.T) {
 	})
 }
 
+func TestMergeProxyResponses_DeterministicOrder(t *testing.T) {
+	t.Run("Merge_Canonicalizes_Header_Keys", func(t *testing.T) {
+		p1 := NewProxy()
+		p1.AddHeader("x-trace", "p1")
+
+		p2 := NewProxy()
+		p2.AddHeader("X-Trace", "p2")
+
+		p3 := NewProxy()
+		p3.AddHeader("X-TRACE", "p3")
+
+		merged := MergeProxyResponses(p1, p2, p3)
+
+		vals := merged.GetHeaders("x-Trace")
+		if strings.Join(vals, ",") != "p1,p2,p3" {
+			t.Errorf("Expected [p1 p2 p3], got %v", vals)
+		}
+	})
+
+	t.Run("Merge_Later_Set_Overrides_Regardless_Of_Key_Case", func(t *testing.T) {
+		p1 := NewProxy()
+		p1.AddHeader("cache-control", "no-cache")
+
+		p2 := NewProxy()
+		p2.SetHeader("Cache-Control", "max-age=60")
+
+		merged := MergeProxyResponses(p1, p2)
+
+		vals := merged.GetHeaders("Cache-Control")
+		if len(vals) != 1 || vals[0] != "max-age=60" {
+			t.Errorf("Expected [max-age=60], got %v", vals)
+		}
+	})
+
+	t.Run("Apply_Exact_Header_Sequence", func(t *testing.T) {
+		p1 := NewProxy()
+		p1.AddHeader("X-Trace", "a")
+		p1.SetHeader("X-Single", "p1")
+
+		p2 := NewProxy()
+		p2.AddHeader("X-Trace", "b")
+		p2.AddHeader("X-Trace", "c")
+
+		p3 := NewProxy()
+		p3.AddHeader("X-Trace", "d")
+		p3.SetHeader("X-Single", "p3")
+
+		for range 20 {
+			merged := MergeProxyResponses(p1, p2, p3)
+			w := httptest.NewRecorder()
+			merged.ApplyToResponseWriter(w, httptest.NewRequest("GET", "/", nil))
+
+			if got := strings.Join(w.Header().Values("X-Trace"), ","); got != "a,b,c,d" {
+				t.Fatalf("Expected X-Trace a,b,c,d, got %s", got)
+			}
+			if got := w.Header().Values("X-Single"); len(got) != 1 || got[0] != "p3" {
+				t.Fatalf("Expected X-Single [p3], got %v", got)
+			}
+		}
+	})
+
+	t.Run("Merge_Later_Del_Clears_Earlier_Values", func(t *testing.T) {
+		p1 := NewProxy()
+		p1.AddHeader("X-Internal", "a")
+
+		p2 := NewProxy()
+		p2.AddHeader("X-Internal", "b")
+
+		merged := MergeProxyResponses(p1, p2)
+		merged.DelHeader("x-internal")
+
+		if vals := merged.GetHeaders("X-Internal"); len(vals) != 0 {
+			t.Errorf("Expected no values, got %v", vals)
+		}
+
+		w := httptest.NewRecorder()
+		w.Header().Set("X-Internal", "preexisting")
+		merged.ApplyToResponseWriter(w, httptest.NewRequest("GET", "/", nil))
+		if vals := w.Header().Values("X-Internal"); len(vals) != 0 {
+			t.Errorf("Expected header to be removed from the writer, got %v", vals)
+		}
+	})
+
+	t.Run("Merge_Error_Drops_Client_Redirect_Header", func(t *testing.T) {
+		p1 := NewProxy()
+		p1.clientRedirect("/login")
+
+		p2 := NewProxy()
+		p2.SetStatus(http.StatusForbidden)
+
+		merged := MergeProxyResponses(p1, p2)
+
+		if status, _ := merged.GetStatus(); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+		if merged.IsRedirect() {
+			t.Error("Should not be a redirect when error is present")
+		}
+		if v := merged.GetHeader(ClientRedirectHeader); v != "" {
+			t.Errorf("Expected no client redirect header, got %q", v)
+		}
+	})
+
+	t.Run("Merge_Server_Redirect_Beats_Later_Client_Redirect", func(t *testing.T) {
+		p1 := NewProxy()
+		p1.serverRedirect("/first", http.StatusFound)
+
+		p2 := NewProxy()
+		p2.clientRedirect("/second")
+
+		merged := MergeProxyResponses(p1, p2)
+
+		if loc := merged.GetLocation(); loc != "/first" {
+			t.Errorf("Expected location '/first', got %q", loc)
+		}
+		if v := merged.GetHeader(ClientRedirectHeader); v != "" {
+			t.Errorf("Expected no client redirect header, got %q", v)
+		}
+	})
+}
+
 // Test complex scenarios
 func TestProxy_ComplexScenarios(t *testing.T) {
 	t.Run("Middleware_Chain_Simulation", func(t *testing.T) {
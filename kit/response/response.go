@@ -3,6 +3,7 @@ package response
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -10,8 +11,18 @@ import (
 )
 
 type Response struct {
-	Writer      http.ResponseWriter
-	isCommitted bool
+	Writer http.ResponseWriter
+	// Optional. Creates the encoder JSON uses to write values to Writer
+	// (e.g., to disable HTML escaping, or to stream large values without
+	// buffering them). Defaults to json.NewEncoder.
+	NewJSONEncoder func(w io.Writer) JSONEncoder
+	isCommitted    bool
+}
+
+// JSONEncoder encodes values as JSON to an underlying writer, as
+// *json.Encoder does.
+type JSONEncoder interface {
+	Encode(v any) error
 }
 
 func New(w http.ResponseWriter) Response {
@@ -64,7 +75,11 @@ func (res *Response) JSONBytes(bytes []byte) {
 // Pass in non-encoded JSON-marshallable data
 func (res *Response) JSON(v any) {
 	res.SetHeader("Content-Type", "application/json")
-	json.NewEncoder(res.Writer).Encode(v)
+	if res.NewJSONEncoder != nil {
+		res.NewJSONEncoder(res.Writer).Encode(v)
+	} else {
+		json.NewEncoder(res.Writer).Encode(v)
+	}
 	res.flagAsCommitted()
 }
 
@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -220,6 +221,40 @@ func TestResponse_SetHeader(t *testing.T) {
 	}
 }
 
+func TestResponse_NewJSONEncoder(t *testing.T) {
+	data := map[string]string{"html": "<b>&</b>"}
+
+	t.Run("Default escapes HTML", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		res := New(rr)
+		res.JSON(data)
+		if got := rr.Body.String(); got != `{"html":"\u003cb\u003e\u0026\u003c/b\u003e"}`+"\n" {
+			t.Errorf("unexpected default encoding: %q", got)
+		}
+	})
+
+	t.Run("Custom encoder", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		res := New(rr)
+		res.NewJSONEncoder = func(w io.Writer) JSONEncoder {
+			enc := json.NewEncoder(w)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			return enc
+		}
+		res.JSON(data)
+		if got := rr.Body.String(); got != "{\n  \"html\": \"<b>&</b>\"\n}\n" {
+			t.Errorf("unexpected custom encoding: %q", got)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json content type, got %q", ct)
+		}
+		if !res.IsCommitted() {
+			t.Error("expected response to be committed")
+		}
+	})
+}
+
 func compareJSON(t *testing.T, expected, actual string) {
 	var expectedObj, actualObj map[string]any
 
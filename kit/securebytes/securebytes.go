@@ -6,6 +6,9 @@
 package securebytes
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 
 	"github.com/river-now/river/kit/bytesutil"
@@ -17,6 +20,29 @@ const current_pkg_version byte = 1
 
 const MaxSize = 1 << 20 // 1MB in bytes
 
+// Serialized values are prefixed with an unencrypted envelope header
+// (magic bytes, envelope version, and a short fingerprint of the key
+// used), so that Parse can try the matching key first instead of every
+// key in turn. Values serialized before the header existed have none,
+// and are parsed by trying each key, as before.
+var envelope_magic = [2]byte{0xB5, 0x5E}
+
+const (
+	envelope_version byte = 1
+	key_id_len            = 4
+)
+
+// EnvelopeHeaderSize is the size of the unencrypted header that prefixes
+// the ciphertext in serialized values.
+const EnvelopeHeaderSize = len(envelope_magic) + 1 + key_id_len
+
+func key_id(k cryptoutil.Key32) []byte {
+	h := sha256.New()
+	h.Write([]byte("river_kit_securebytes_key_id"))
+	h.Write(k[:])
+	return h.Sum(nil)[:key_id_len]
+}
+
 type SecureBytes []byte // Encrypted value
 type RawValue any       // Any pre-serialization value
 
@@ -40,10 +66,15 @@ func Serialize(ks *keyset.Keyset, rv RawValue) (SecureBytes, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error encrypting value: %w", err)
 	}
-	if len(ciphertext) > MaxSize {
+	out := make([]byte, 0, EnvelopeHeaderSize+len(ciphertext))
+	out = append(out, envelope_magic[:]...)
+	out = append(out, envelope_version)
+	out = append(out, key_id(firstKey)...)
+	out = append(out, ciphertext...)
+	if len(out) > MaxSize {
 		return nil, fmt.Errorf("ciphertext too large (over 1MB)")
 	}
-	return SecureBytes(ciphertext), nil
+	return SecureBytes(out), nil
 }
 
 func Parse[T any](ks *keyset.Keyset, sb SecureBytes) (T, error) {
@@ -57,9 +88,7 @@ func Parse[T any](ks *keyset.Keyset, sb SecureBytes) (T, error) {
 	if err := ks.Validate(); err != nil {
 		return zeroT, fmt.Errorf("invalid keyset: %w", err)
 	}
-	plaintext, err := keyset.Attempt(ks, func(k cryptoutil.Key32) ([]byte, error) {
-		return cryptoutil.DecryptSymmetricXChaCha20Poly1305(sb, k)
-	})
+	plaintext, err := decrypt(ks, sb)
 	if err != nil {
 		return zeroT, fmt.Errorf("error decrypting value: %w", err)
 	}
@@ -73,3 +102,35 @@ func Parse[T any](ks *keyset.Keyset, sb SecureBytes) (T, error) {
 	}
 	return out, nil
 }
+
+// Tries the key matching the envelope's key ID first, falling back to
+// trying every key (both against the enveloped ciphertext, in case of a
+// key ID collision, and against the whole value, in case it predates the
+// envelope and merely happens to start with the magic bytes).
+func decrypt(ks *keyset.Keyset, sb SecureBytes) ([]byte, error) {
+	if len(sb) > EnvelopeHeaderSize &&
+		bytes.Equal(sb[:len(envelope_magic)], envelope_magic[:]) &&
+		sb[len(envelope_magic)] == envelope_version {
+		id := sb[len(envelope_magic)+1 : EnvelopeHeaderSize]
+		ciphertext := sb[EnvelopeHeaderSize:]
+		for _, k := range ks.Unwrap() {
+			if bytes.Equal(key_id(k), id) {
+				if plaintext, err := cryptoutil.DecryptSymmetricXChaCha20Poly1305(ciphertext, k); err == nil {
+					return plaintext, nil
+				}
+			}
+		}
+		plaintext, err := keyset.Attempt(ks, func(k cryptoutil.Key32) ([]byte, error) {
+			if bytes.Equal(key_id(k), id) {
+				return nil, errors.New("already tried")
+			}
+			return cryptoutil.DecryptSymmetricXChaCha20Poly1305(ciphertext, k)
+		})
+		if err == nil {
+			return plaintext, nil
+		}
+	}
+	return keyset.Attempt(ks, func(k cryptoutil.Key32) ([]byte, error) {
+		return cryptoutil.DecryptSymmetricXChaCha20Poly1305(sb, k)
+	})
+}
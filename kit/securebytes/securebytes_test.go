@@ -313,8 +313,8 @@ func TestSecureBytes_Version(t *testing.T) {
 		t.Fatalf("Serialize failed: %v", err)
 	}
 
-	// Manually decrypt to access version byte
-	plaintext, err := cryptoutil.DecryptSymmetricXChaCha20Poly1305(sb, uks[0])
+	// Manually decrypt (past the envelope header) to access version byte
+	plaintext, err := cryptoutil.DecryptSymmetricXChaCha20Poly1305(sb[EnvelopeHeaderSize:], uks[0])
 	if err != nil {
 		t.Fatalf("Manual DecryptSymmetricXChaCha20Poly1305 failed: %v", err)
 	}
@@ -572,3 +572,51 @@ func TestSecureBytes_InvalidKeyset(t *testing.T) {
 		}
 	})
 }
+
+func TestSecureBytes_KeyID(t *testing.T) {
+	kcs := mustKeys(t, 20)
+	uks := kcs.Unwrap()
+	value := "key id test"
+
+	lastActive, _ := keyset.FromUnwrapped(keyset.UnwrappedKeyset{uks[len(uks)-1]})
+	sb, err := Serialize(lastActive, value)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	t.Run("header carries the key ID", func(t *testing.T) {
+		if !reflect.DeepEqual([]byte(sb[3:EnvelopeHeaderSize]), key_id(uks[len(uks)-1])) {
+			t.Errorf("expected key ID of the serializing key in header")
+		}
+	})
+
+	t.Run("matching key is found", func(t *testing.T) {
+		got, err := Parse[string](kcs, sb)
+		if err != nil || got != value {
+			t.Fatalf("Parse = %q, %v; want %q", got, err, value)
+		}
+	})
+
+	t.Run("mismatched key ID falls back to full scan", func(t *testing.T) {
+		wrongID := append(SecureBytes{}, sb...)
+		copy(wrongID[3:EnvelopeHeaderSize], key_id(uks[0]))
+		got, err := Parse[string](kcs, wrongID)
+		if err != nil || got != value {
+			t.Fatalf("Parse = %q, %v; want %q", got, err, value)
+		}
+	})
+
+	t.Run("legacy values without a header still parse", func(t *testing.T) {
+		legacy := sb[EnvelopeHeaderSize:]
+		got, err := Parse[string](kcs, legacy)
+		if err != nil || got != value {
+			t.Fatalf("Parse = %q, %v; want %q", got, err, value)
+		}
+	})
+
+	t.Run("unknown key fails", func(t *testing.T) {
+		if _, err := Parse[string](mustKeys(t, 3), sb); err == nil {
+			t.Fatal("expected error parsing with unrelated keys")
+		}
+	})
+}
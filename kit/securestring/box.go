@@ -0,0 +1,85 @@
+package securestring
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/river-now/river/kit/keyset"
+)
+
+var (
+	// ErrExpired is returned by Box.Open for values past their TTL.
+	ErrExpired = errors.New("securestring: value expired")
+	// ErrBindingMismatch is returned by Box.Open when the aad passed in
+	// differs from the one the value was sealed with.
+	ErrBindingMismatch = errors.New("securestring: binding mismatch")
+)
+
+// Box seals values of type T together with an expiry timestamp and an
+// optional binding (additional authenticated data, e.g., a session ID),
+// and opens them again, rejecting values that have expired or that are
+// opened with a different binding. The zero value is ready to use.
+type Box[T any] struct {
+	// Optional. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Only a hash of the binding is stored, so sealing it does not reveal it
+// to anyone holding the keys, nor grow the value with it.
+type box_payload[T any] struct {
+	Value           T
+	ExpiresAtUnixMs int64
+	BindingHash     []byte
+}
+
+// Seal serializes value so that Open accepts it until ttl elapses, and
+// only when given the same aad (a nil or empty aad means unbound).
+func (b Box[T]) Seal(ks *keyset.Keyset, value T, ttl time.Duration, aad []byte) (SecureString, error) {
+	if ttl <= 0 {
+		return "", fmt.Errorf("invalid ttl: %v (must be positive)", ttl)
+	}
+	return Serialize(ks, box_payload[T]{
+		Value:           value,
+		ExpiresAtUnixMs: b.now().Add(ttl).UnixMilli(),
+		BindingHash:     binding_hash(aad),
+	})
+}
+
+// Open parses a value sealed by Seal, returning ErrBindingMismatch if aad
+// differs from the one it was sealed with, or ErrExpired if its TTL has
+// elapsed. Any other error means the value could not be decrypted or
+// decoded (e.g., it was tampered with, or sealed with unknown keys).
+func (b Box[T]) Open(ks *keyset.Keyset, ss SecureString, aad []byte) (T, error) {
+	var zeroT T
+	p, err := Parse[box_payload[T]](ks, ss)
+	if err != nil {
+		return zeroT, err
+	}
+	if subtle.ConstantTimeCompare(p.BindingHash, binding_hash(aad)) != 1 {
+		return zeroT, ErrBindingMismatch
+	}
+	if !b.now().Before(time.UnixMilli(p.ExpiresAtUnixMs)) {
+		return zeroT, ErrExpired
+	}
+	return p.Value, nil
+}
+
+func (b Box[T]) now() time.Time {
+	if b.Now != nil {
+		return b.Now()
+	}
+	return time.Now()
+}
+
+func binding_hash(aad []byte) []byte {
+	if len(aad) == 0 {
+		return nil
+	}
+	h := sha256.New()
+	h.Write([]byte("river_kit_securestring_box_binding"))
+	h.Write(aad)
+	return h.Sum(nil)
+}
@@ -3,6 +3,7 @@ package securestring
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -306,7 +307,7 @@ func TestSecureString_Version(t *testing.T) {
 		t.Fatalf("FromBase64 failed: %v", err)
 	}
 
-	plaintext, err := cryptoutil.DecryptSymmetricXChaCha20Poly1305(ciphertext, uks[0])
+	plaintext, err := cryptoutil.DecryptSymmetricXChaCha20Poly1305(ciphertext[securebytes.EnvelopeHeaderSize:], uks[0])
 	if err != nil {
 		t.Fatalf("Manual DecryptSymmetricXChaCha20Poly1305 failed: %v", err)
 	}
@@ -507,3 +508,94 @@ func TestSecureString_CornerCases(t *testing.T) {
 		}
 	})
 }
+
+func TestBox(t *testing.T) {
+	type session struct {
+		UserID string
+		Roles  []string
+	}
+	ks := mustKeys(t, 2)
+	now := time.Unix(1_700_000_000, 0)
+	box := Box[session]{Now: func() time.Time { return now }}
+	value := session{UserID: "u1", Roles: []string{"admin"}}
+
+	t.Run("round trip", func(t *testing.T) {
+		ss, err := box.Seal(ks, value, time.Minute, []byte("session-123"))
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		got, err := box.Open(ks, ss, []byte("session-123"))
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, value) {
+			t.Fatalf("round-trip mismatch: want %+v, got %+v", value, got)
+		}
+	})
+
+	t.Run("unbound", func(t *testing.T) {
+		ss, err := box.Seal(ks, value, time.Minute, nil)
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		if _, err := box.Open(ks, ss, []byte{}); err != nil {
+			t.Fatalf("Open with empty aad failed: %v", err)
+		}
+		if _, err := box.Open(ks, ss, []byte("session-123")); !errors.Is(err, ErrBindingMismatch) {
+			t.Fatalf("expected ErrBindingMismatch when binding an unbound value, got %v", err)
+		}
+	})
+
+	t.Run("binding mismatch", func(t *testing.T) {
+		ss, _ := box.Seal(ks, value, time.Minute, []byte("session-123"))
+		for _, aad := range [][]byte{nil, []byte("session-456")} {
+			if _, err := box.Open(ks, ss, aad); !errors.Is(err, ErrBindingMismatch) {
+				t.Errorf("aad %q: expected ErrBindingMismatch, got %v", aad, err)
+			}
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		ss, _ := box.Seal(ks, value, time.Minute, nil)
+		later := Box[session]{Now: func() time.Time { return now.Add(time.Minute - time.Millisecond) }}
+		if _, err := later.Open(ks, ss, nil); err != nil {
+			t.Fatalf("expected value to be valid just before expiry, got %v", err)
+		}
+		expired := Box[session]{Now: func() time.Time { return now.Add(time.Minute) }}
+		got, err := expired.Open(ks, ss, nil)
+		if !errors.Is(err, ErrExpired) {
+			t.Fatalf("expected ErrExpired, got %v", err)
+		}
+		if !reflect.DeepEqual(got, session{}) {
+			t.Errorf("expected zero value for an expired value, got %+v", got)
+		}
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		if _, err := box.Seal(ks, value, 0, nil); err == nil {
+			t.Fatal("expected error for a zero ttl")
+		}
+	})
+
+	t.Run("tampered or wrong keys", func(t *testing.T) {
+		ss, _ := box.Seal(ks, value, time.Minute, nil)
+		_, err := box.Open(mustKeys(t, 1), ss, nil)
+		if err == nil || errors.Is(err, ErrExpired) || errors.Is(err, ErrBindingMismatch) {
+			t.Fatalf("expected a decryption error for unknown keys, got %v", err)
+		}
+		if _, err := (Box[string]{}).Open(ks, ss, nil); err == nil {
+			t.Fatal("expected an error opening a value as the wrong type")
+		}
+	})
+
+	t.Run("default clock", func(t *testing.T) {
+		var b Box[string]
+		ss, err := b.Seal(ks, "hello", time.Hour, nil)
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		if got, err := b.Open(ks, ss, nil); err != nil || got != "hello" {
+			t.Fatalf("Open = %q, %v", got, err)
+		}
+	})
+}
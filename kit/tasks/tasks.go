@@ -9,13 +9,18 @@
 package tasks
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/river-now/river/kit/colorlog"
 	"github.com/river-now/river/kit/genericsutil"
 	"golang.org/x/sync/errgroup"
 )
@@ -24,15 +29,41 @@ type AnyTask interface {
 	RunWithAnyInput(ctx *Ctx, input any) (any, error)
 }
 
-type Task[I comparable, O any] struct {
-	fn func(ctx *Ctx, input I) (O, error)
+type Task[I any, O any] struct {
+	fn    func(ctx *Ctx, input I) (O, error)
+	keyFn func(input I) string
+	id    uint64 // monotonic, for identifying tasks in watchdog logs and graphs
+	deps  []graphTask
+	// If set, results are cached in the Ctx's SharedCache (if any)
+	// rather than per Ctx. See Global.
+	global bool
+	// If set, results are cached per input and vary value. See Vary.
+	varyFn func(c *Ctx) string
 }
 
+var taskCounter atomic.Uint64
+
+// NewTask creates a task whose results are cached per execution context
+// by input value, which therefore must be comparable.
 func NewTask[I comparable, O any](fn func(ctx *Ctx, input I) (O, error)) *Task[I, O] {
 	if fn == nil {
 		return nil
 	}
-	return &Task[I, O]{fn: fn}
+	return &Task[I, O]{fn: fn, id: taskCounter.Add(1)}
+}
+
+// NewTaskWithKey creates a task whose results are cached per execution
+// context by the string returned from keyFn rather than by the input
+// value itself. Use it when inputs are large (so comparing them is
+// expensive) or not comparable at all (e.g., they contain slices or
+// maps). Two inputs that produce the same key share a single execution,
+// so keyFn must capture everything about the input that affects the
+// task's result.
+func NewTaskWithKey[I any, O any](fn func(ctx *Ctx, input I) (O, error), keyFn func(input I) string) *Task[I, O] {
+	if fn == nil || keyFn == nil {
+		return nil
+	}
+	return &Task[I, O]{fn: fn, keyFn: keyFn, id: taskCounter.Add(1)}
 }
 
 func (t *Task[I, O]) RunWithAnyInput(ctx *Ctx, input any) (any, error) {
@@ -43,27 +74,135 @@ func (t *Task[I, O]) Run(ctx *Ctx, input I) (O, error) {
 	return runTask(ctx, t, input)
 }
 
+// RunEx is like Run, but also reports whether the result came from the
+// cache (including when it was deduplicated against a concurrent run of
+// the same task and input) rather than from running the task, e.g., to
+// set an X-Cache header. Cached errors also count as hits. Errors that
+// prevent the lookup (e.g., a canceled context) are reported as misses.
+func (t *Task[I, O]) RunEx(ctx *Ctx, input I) (O, bool, error) {
+	return runTaskEx(ctx, t, input)
+}
+
 func (t *Task[I, O]) Bind(input I, dest *O) BoundTask {
 	return bindTask(t, input, dest)
 }
 
+// BindFunc is like Bind, but rather than writing to a destination, it
+// calls fn with the task's result as soon as that task finishes (so,
+// within RunParallel, before the other tasks are necessarily done). fn
+// is called exactly once per run, including on errors and on cache or
+// deduplicated hits. Within RunParallel, callbacks for different bound
+// tasks may run concurrently, so fn must be safe for concurrent use.
+func (t *Task[I, O]) BindFunc(input I, fn func(O, error)) BoundTask {
+	bt := bindTask(t, input, nil).(*boundTask[O])
+	bt.callback = fn
+	return bt
+}
+
+// DependsOn records that the task runs the given tasks, for introspection
+// via Ctx.Graph only. It does not change how or when anything runs (the
+// task must still run its dependencies itself). Call it when defining the
+// task, before the task is run. Returns the task for chaining.
+func (t *Task[I, O]) DependsOn(deps ...AnyTask) *Task[I, O] {
+	for _, dep := range deps {
+		if gt, ok := dep.(graphTask); ok && gt.graphID() != 0 {
+			t.deps = append(t.deps, gt)
+		}
+	}
+	return t
+}
+
+// Global marks the task's results as process-wide rather than scoped to
+// an execution context: when run with a Ctx created via
+// NewCtxWithSharedCache, results are read from and written to the shared
+// cache, so the task runs once per input (per the cache's TTL) across all
+// such contexts. With any other Ctx, the task stays request-scoped. Only
+// mark tasks whose results do not depend on request data (e.g., feature
+// flags). Call it when defining the task, before the task is run. Returns
+// the task for chaining.
+func (t *Task[I, O]) Global() *Task[I, O] {
+	t.global = true
+	return t
+}
+
+// Vary makes the task cache its results per input and per the string fn
+// returns, for tasks whose results depend on Ctx-scoped state that is not
+// part of their input (e.g., the current tenant, user, or locale, read
+// via a Value). fn is called on every run with the Ctx the task is run
+// with (for Global tasks, the request's Ctx, not the shared cache's), so
+// it should be cheap. Call it when defining the task, before the task is
+// run. Returns the task for chaining.
+func (t *Task[I, O]) Vary(fn func(c *Ctx) string) *Task[I, O] {
+	t.varyFn = fn
+	return t
+}
+
+type graphTask interface {
+	graphID() uint64
+	graphName() string
+	graphDeps() []graphTask
+}
+
+func (t *Task[I, O]) graphID() uint64 {
+	if t == nil {
+		return 0
+	}
+	return t.id
+}
+func (t *Task[I, O]) graphName() string      { return taskFuncName(t.fn, t.id) }
+func (t *Task[I, O]) graphDeps() []graphTask { return t.deps }
+
 // taskKey is used for map lookups to avoid allocating anonymous structs
 type taskKey struct {
 	taskPtr uintptr
 	input   any
 }
 
+// The cache key of a task with a Vary func
+type variedKey struct {
+	key  any
+	vary string
+}
+
 type Ctx struct {
 	mu          *sync.RWMutex
 	results     map[taskKey]*cacheEntry
 	ctx         context.Context
 	ttl         time.Duration
 	lastCleanup *atomic.Int64 // Unix timestamp in nanoseconds (nil when TTL disabled)
+	values      *sync.Map     // Ctx-scoped values, keyed by *Value[T]
+	warnAfter   time.Duration // Watchdog threshold (0 when disabled)
+	stats       *ctxStats
+	shared      *SharedCache // Backing store for Global tasks (nil when not opted in)
+	isShared    bool         // Set on a SharedCache's own Ctx
+	bg          *background  // Work started via Go
+}
+
+// Tracks work started via Ctx.Go, for Ctx.WaitAll
+type background struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+type ctxStats struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// CtxStats is a snapshot of a Ctx's result cache activity.
+type CtxStats struct {
+	Hits      uint64 // task runs served from a cached (or in-flight) result
+	Misses    uint64 // task runs that executed the task function
+	Evictions uint64 // expired results removed or replaced (TTL only)
+	Entries   int    // results currently cached
 }
 
 type cacheEntry struct {
 	result    *TaskResult
 	expiresAt time.Time
+	task      graphTask
 }
 
 // NewCtx creates a new task execution context with no TTL.
@@ -86,6 +225,9 @@ func NewCtxWithTTL(parent context.Context, ttl time.Duration) *Ctx {
 		results: make(map[taskKey]*cacheEntry, 4),
 		ctx:     parent,
 		ttl:     ttl,
+		values:  &sync.Map{},
+		stats:   &ctxStats{},
+		bg:      &background{},
 	}
 
 	// Only initialize lastCleanup if TTL is enabled
@@ -97,29 +239,281 @@ func NewCtxWithTTL(parent context.Context, ttl time.Duration) *Ctx {
 	return c
 }
 
+// NewCtxWithWatchdog creates a new task execution context (with no TTL)
+// that logs a warning, naming the task function and where it is defined,
+// whenever a task runs for longer than warnAfter. This is meant to help
+// surface tasks that are stuck (e.g., blocked on an un-cancellable call)
+// during development. It does not cancel anything (use the parent
+// context for that).
+//
+// If getIsDev is nil or returns false, the watchdog is disabled and this
+// is equivalent to NewCtx, so it is safe to leave in production code.
+func NewCtxWithWatchdog(parent context.Context, warnAfter time.Duration, getIsDev func() bool) *Ctx {
+	c := NewCtxWithTTL(parent, 0)
+	if warnAfter > 0 && getIsDev != nil && getIsDev() {
+		c.warnAfter = warnAfter
+	}
+	return c
+}
+
+// SharedCache is a process-wide result cache for tasks marked Global,
+// shared by every Ctx created with NewCtxWithSharedCache. Create one per
+// process (or per group of tasks that should share results).
+type SharedCache struct {
+	ctx *Ctx
+}
+
+// NewSharedCache creates a SharedCache whose results expire after ttl
+// (or never, when ttl is 0). Unlike a Ctx, failed results are not
+// cached, so a transient error is retried on the next run rather than
+// served until it expires.
+func NewSharedCache(ttl time.Duration) *SharedCache {
+	ctx := NewCtxWithTTL(context.Background(), ttl)
+	ctx.isShared = true
+	return &SharedCache{ctx: ctx}
+}
+
+// Stats returns a snapshot of the shared cache's activity, across all
+// contexts using it.
+func (s *SharedCache) Stats() CtxStats {
+	return s.ctx.Stats()
+}
+
+// NewCtxWithSharedCache creates a new task execution context (with no
+// TTL) in which tasks marked Global use shared as their result cache.
+// All other tasks remain scoped to the returned Ctx. Global tasks are
+// run with the shared cache's own Ctx (not the returned one), so they
+// cannot see the returned Ctx's Values, and are not cancelled by parent
+// (callers still stop waiting on cancellation, once the run completes).
+func NewCtxWithSharedCache(parent context.Context, shared *SharedCache) *Ctx {
+	c := NewCtxWithTTL(parent, 0)
+	c.shared = shared
+	return c
+}
+
 func (c *Ctx) NativeContext() context.Context {
 	return c.ctx
 }
 
+// Stats returns a snapshot of the Ctx's cache hit, miss, and eviction
+// counts so far, along with the number of results currently cached.
+// Counts include tasks run via RunParallel.
+func (c *Ctx) Stats() CtxStats {
+	c.mu.RLock()
+	entries := len(c.results)
+	c.mu.RUnlock()
+	return CtxStats{
+		Hits:      c.stats.hits.Load(),
+		Misses:    c.stats.misses.Load(),
+		Evictions: c.stats.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+// Graph describes the tasks that have results cached in a Ctx, as nodes,
+// and the dependencies declared between them via DependsOn, as edges.
+type Graph struct {
+	Nodes []GraphNode // sorted by ID
+	Edges []GraphEdge // sorted by From, then To
+}
+
+type GraphNode struct {
+	ID   uint64
+	Name string // the task function's name
+	// Results currently cached for the task (one per distinct input). Zero
+	// for declared dependencies that did not run.
+	Runs int
+}
+
+// GraphEdge records that the task From declared a dependency on To.
+type GraphEdge struct {
+	From uint64
+	To   uint64
+}
+
+// Graph returns the task dependency graph for the Ctx so far (e.g., for
+// a dev endpoint to render after a request). Nodes are the tasks with
+// results currently cached (including tasks run via RunParallel), plus
+// any dependencies they declared that did not run.
+func (c *Ctx) Graph() Graph {
+	nodes := make(map[uint64]*GraphNode)
+	var ran []graphTask
+
+	c.mu.RLock()
+	for _, entry := range c.results {
+		if entry.task == nil {
+			continue
+		}
+		id := entry.task.graphID()
+		if node, ok := nodes[id]; ok {
+			node.Runs++
+			continue
+		}
+		nodes[id] = &GraphNode{ID: id, Name: entry.task.graphName(), Runs: 1}
+		ran = append(ran, entry.task)
+	}
+	c.mu.RUnlock()
+
+	var g Graph
+	for _, task := range ran {
+		for _, dep := range task.graphDeps() {
+			depID := dep.graphID()
+			if _, ok := nodes[depID]; !ok {
+				nodes[depID] = &GraphNode{ID: depID, Name: dep.graphName()}
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: task.graphID(), To: depID})
+		}
+	}
+	for _, node := range nodes {
+		g.Nodes = append(g.Nodes, *node)
+	}
+	slices.SortFunc(g.Nodes, func(a, b GraphNode) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(g.Edges, func(a, b GraphEdge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+	g.Edges = slices.Compact(g.Edges)
+	return g
+}
+
 func (c *Ctx) RunParallel(tasks ...BoundTask) error {
 	return runTasks(c, tasks...)
 }
 
-func runTask[I comparable, O any](c *Ctx, task *Task[I, O], input I) (result O, err error) {
+// Go runs fn in a new goroutine without waiting for it, for work whose
+// result the caller does not need right away (e.g., warming a cache or
+// recording an audit log). Unlike a bare goroutine, the work is tracked
+// by the Ctx, so WaitAll can block until it settles.
+//
+// fn is passed the Ctx, so it is cancelled along with the Ctx's context.
+// Within a request, that context is typically the request's, which
+// net/http cancels once the handler returns, so either call WaitAll
+// before returning or, for genuinely fire-and-forget work, call Go on
+// the Ctx returned by Detach instead. Work started within RunParallel is
+// tracked by the Ctx that RunParallel was called on, but is cancelled as
+// soon as RunParallel returns.
+func (c *Ctx) Go(fn func(ctx *Ctx) error) {
+	if fn == nil {
+		return
+	}
+	bg := c.bg
+	bg.wg.Add(1)
+	go func() {
+		defer bg.wg.Done()
+		if err := fn(c); err != nil {
+			bg.mu.Lock()
+			bg.errs = append(bg.errs, err)
+			bg.mu.Unlock()
+		}
+	}()
+}
+
+// WaitAll blocks until all work started via Go on the Ctx (including
+// work started from within that work) has completed, and returns the
+// errors it returned so far, joined (or nil). It does not wait for work
+// started on a Ctx returned by Detach.
+func (c *Ctx) WaitAll() error {
+	bg := c.bg
+	bg.wg.Wait()
+	bg.mu.Lock()
+	defer bg.mu.Unlock()
+	return errors.Join(bg.errs...)
+}
+
+// Detach returns a new Ctx, for fire-and-forget work that must outlive
+// the Ctx's context (e.g., a request). The returned Ctx's context keeps
+// the values of the original context but is never cancelled with it. It
+// shares the original's Values (and SharedCache, if any), but has its own
+// result cache (so it never sees results, such as cancellation errors,
+// cached by the original) and its own WaitAll.
+func (c *Ctx) Detach() *Ctx {
+	d := NewCtxWithTTL(context.WithoutCancel(c.ctx), c.ttl)
+	d.values = c.values
+	d.warnAfter = c.warnAfter
+	d.shared = c.shared
+	return d
+}
+
+// Value is a typed key for storing request-scoped data (e.g., the
+// current user or a trace ID) directly on a Ctx, as a type-safe
+// alternative to context.WithValue. Values are visible to every task
+// sharing the Ctx, including tasks run via RunParallel. Create Values
+// once, as package-level variables, and compare them by identity.
+type Value[T any] struct {
+	name string
+}
+
+// NewValue creates a new typed Ctx value key. The name is for
+// debugging only and does not need to be unique.
+func NewValue[T any](name string) *Value[T] {
+	return &Value[T]{name: name}
+}
+
+func (v *Value[T]) Name() string { return v.name }
+
+// Set stores val on the Ctx, replacing any previous value for this key.
+func (v *Value[T]) Set(c *Ctx, val T) {
+	c.values.Store(v, val)
+}
+
+// Get returns the value stored on the Ctx, or the zero value of T if
+// none has been set.
+func (v *Value[T]) Get(c *Ctx) T {
+	val, _ := v.Lookup(c)
+	return val
+}
+
+// Lookup returns the value stored on the Ctx and whether it was set.
+func (v *Value[T]) Lookup(c *Ctx) (T, bool) {
+	if c == nil || c.values == nil {
+		var zero T
+		return zero, false
+	}
+	val, ok := c.values.Load(v)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return val.(T), true
+}
+
+func runTask[I any, O any](c *Ctx, task *Task[I, O], input I) (result O, err error) {
+	result, _, err = runTaskEx(c, task, input)
+	return result, err
+}
+
+func runTaskEx[I any, O any](c *Ctx, task *Task[I, O], input I) (result O, cached bool, err error) {
 	if c == nil {
-		return result, errors.New("tasks: nil TasksCtx")
+		return result, false, errors.New("tasks: nil TasksCtx")
 	}
 	if task == nil || task.fn == nil {
-		return result, errors.New("tasks: invalid task")
+		return result, false, errors.New("tasks: invalid task")
 	}
 
 	// Check context only once at the beginning
 	if err := c.ctx.Err(); err != nil {
-		return result, err
+		return result, false, err
+	}
+
+	var cacheKey any = input
+	if task.keyFn != nil {
+		cacheKey = task.keyFn(input)
+	}
+	if task.varyFn != nil {
+		cacheKey = variedKey{key: cacheKey, vary: task.varyFn(c)}
 	}
 
-	r := c.getOrCreateResult(task, input)
+	requestCtx := c
+	if task.global && c.shared != nil {
+		c = c.shared.ctx
+	}
+
+	r := c.getOrCreateResult(task, cacheKey)
+	ran := false
 	r.once.Do(func() {
+		ran = true
+		if c.warnAfter > 0 {
+			defer startWatchdog(c.warnAfter, task.fn, task.id)()
+		}
 		val, err := task.fn(c, input)
 		if err != nil {
 			r.Err = err
@@ -132,14 +526,27 @@ func runTask[I comparable, O any](c *Ctx, task *Task[I, O], input I) (result O,
 		r.Data = val
 		r.Err = nil
 	})
+	if ran {
+		c.stats.misses.Add(1)
+	} else {
+		c.stats.hits.Add(1)
+	}
 
 	if r.Err != nil {
-		return result, r.Err
+		if ran && c.isShared {
+			c.evictResult(task, cacheKey, r)
+		}
+		return result, !ran, r.Err
+	}
+	if c != requestCtx {
+		if err := requestCtx.ctx.Err(); err != nil {
+			return result, !ran, err
+		}
 	}
 	if r.Data == nil {
-		return result, nil
+		return result, !ran, nil
 	}
-	return genericsutil.AssertOrZero[O](r.Data), nil
+	return genericsutil.AssertOrZero[O](r.Data), !ran, nil
 }
 
 func (c *Ctx) getOrCreateResult(taskPtr any, input any) *TaskResult {
@@ -186,17 +593,33 @@ func (c *Ctx) getOrCreateResult(taskPtr any, input any) *TaskResult {
 			return entry.result
 		}
 		// Still expired, will overwrite below
+		c.stats.evictions.Add(1)
 	}
 
 	// Create new result and cache entry
 	r := newTaskResult()
-	c.results[key] = &cacheEntry{result: r}
+	gt, _ := taskPtr.(graphTask)
+	c.results[key] = &cacheEntry{result: r, task: gt}
 	if c.ttl > 0 {
 		c.results[key].expiresAt = now.Add(c.ttl)
 	}
 	return r
 }
 
+// evictResult removes r from the cache, unless it has already been
+// replaced (e.g., by a concurrent refresh after expiry).
+func (c *Ctx) evictResult(taskPtr any, input any, r *TaskResult) {
+	key := taskKey{
+		taskPtr: reflect.ValueOf(taskPtr).Pointer(),
+		input:   input,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.results[key]; ok && entry.result == r {
+		delete(c.results, key)
+	}
+}
+
 // cleanupExpired removes all expired entries from the cache.
 // This is called lazily during getOrCreateResult, at most once per TTL period.
 func (c *Ctx) cleanupExpired(now time.Time) {
@@ -214,6 +637,7 @@ func (c *Ctx) cleanupExpired(now time.Time) {
 	for key, entry := range c.results {
 		if now.After(entry.expiresAt) {
 			delete(c.results, key)
+			c.stats.evictions.Add(1)
 		}
 	}
 
@@ -239,11 +663,12 @@ type BoundTask interface {
 }
 
 type boundTask[O any] struct {
-	runner func(ctx *Ctx) (O, error)
-	dest   *O
+	runner   func(ctx *Ctx) (O, error)
+	dest     *O
+	callback func(O, error)
 }
 
-func bindTask[I comparable, O any](task *Task[I, O], input I, dest *O) BoundTask {
+func bindTask[I any, O any](task *Task[I, O], input I, dest *O) BoundTask {
 	if task == nil || task.fn == nil {
 		return &boundTask[O]{
 			runner: func(ctx *Ctx) (O, error) {
@@ -269,6 +694,9 @@ func (bc *boundTask[O]) Run(ctx *Ctx) error {
 		return errors.New("tasks: boundTask runner is nil (task may have been invalid at Bind)")
 	}
 	res, err := bc.runner(ctx)
+	if bc.callback != nil {
+		bc.callback(res, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -304,6 +732,12 @@ func runTasks(ctx *Ctx, calls ...BoundTask) error {
 		ctx:         gCtx,
 		ttl:         ctx.ttl,
 		lastCleanup: ctx.lastCleanup,
+		values:      ctx.values,
+		warnAfter:   ctx.warnAfter,
+		stats:       ctx.stats,
+		shared:      ctx.shared,
+		isShared:    ctx.isShared,
+		bg:          ctx.bg,
 	}
 	for _, call := range valid {
 		c := call
@@ -316,3 +750,129 @@ func runTasks(ctx *Ctx, calls ...BoundTask) error {
 	}
 	return g.Wait()
 }
+
+// StreamTask is a task that emits any number of results incrementally
+// (e.g., tailing logs or sending progressive results to an SSE handler)
+// rather than returning a single value. Unlike Task, stream tasks bypass
+// the Ctx results cache entirely: every call to Run or Stream executes
+// the task function anew, and emitted values are never memoized. Once
+// the Ctx's context is canceled, further emissions are dropped (Run) or
+// abandoned (Stream), and the task function should return promptly
+// (check ctx.NativeContext().Err() in long-running loops).
+type StreamTask[I any, O any] struct {
+	fn func(ctx *Ctx, input I, emit func(O)) error
+	id uint64
+}
+
+// NewStreamTask creates a StreamTask. The fn may call emit any number
+// of times (including concurrently, as emissions are serialized) before
+// returning, but must not call it after returning.
+func NewStreamTask[I any, O any](fn func(ctx *Ctx, input I, emit func(O)) error) *StreamTask[I, O] {
+	if fn == nil {
+		return nil
+	}
+	return &StreamTask[I, O]{fn: fn, id: taskCounter.Add(1)}
+}
+
+// Run executes the stream task, synchronously calling onValue for each
+// emitted value, and returns once the task function returns. If the Ctx
+// is canceled, later emissions are dropped and the cancellation error is
+// returned.
+func (t *StreamTask[I, O]) Run(ctx *Ctx, input I, onValue func(O)) error {
+	if ctx == nil {
+		return errors.New("tasks: nil TasksCtx")
+	}
+	if t == nil || t.fn == nil {
+		return errors.New("tasks: invalid stream task")
+	}
+	if err := ctx.ctx.Err(); err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	emit := func(v O) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.ctx.Err() != nil || onValue == nil {
+			return
+		}
+		onValue(v)
+	}
+	if ctx.warnAfter > 0 {
+		defer startWatchdog(ctx.warnAfter, t.fn, t.id)()
+	}
+	if err := t.fn(ctx, input, emit); err != nil {
+		return err
+	}
+	return ctx.ctx.Err()
+}
+
+// Stream starts the stream task in a new goroutine and returns a channel
+// of emitted values, which is closed when the task function returns, and
+// a channel that then receives the task's final error (nil on success).
+// Emissions block until received, so a slow consumer applies
+// backpressure to the task. If the Ctx is canceled, blocked and later
+// emissions are abandoned and the cancellation error is reported.
+func (t *StreamTask[I, O]) Stream(ctx *Ctx, input I) (<-chan O, <-chan error) {
+	values := make(chan O)
+	errCh := make(chan error, 1)
+	if ctx == nil {
+		close(values)
+		errCh <- errors.New("tasks: nil TasksCtx")
+		close(errCh)
+		return values, errCh
+	}
+	go func() {
+		defer close(errCh)
+		err := t.Run(ctx, input, func(v O) {
+			select {
+			case values <- v:
+			case <-ctx.ctx.Done():
+			}
+		})
+		close(values)
+		errCh <- err
+	}()
+	return values, errCh
+}
+
+var watchdogLog = colorlog.New("tasks")
+
+// startWatchdog logs a warning if the returned stop func is not called
+// within warnAfter, and logs again once a task that was warned about
+// eventually completes.
+func startWatchdog(warnAfter time.Duration, fn any, id uint64) (stop func()) {
+	start := time.Now()
+	var warned atomic.Bool
+	timer := time.AfterFunc(warnAfter, func() {
+		warned.Store(true)
+		watchdogLog.Warn("Task still running after watchdog threshold (possibly stuck)",
+			"task", describeTask(fn, id),
+			"elapsed", time.Since(start),
+		)
+	})
+	return func() {
+		if !timer.Stop() && warned.Load() {
+			watchdogLog.Info("Previously reported slow task completed",
+				"task", describeTask(fn, id),
+				"duration", time.Since(start),
+			)
+		}
+	}
+}
+
+func describeTask(fn any, id uint64) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return fmt.Sprintf("task #%d", id)
+	}
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("task #%d (%s at %s:%d)", id, f.Name(), file, line)
+}
+
+func taskFuncName(fn any, id uint64) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return fmt.Sprintf("task #%d", id)
+	}
+	return f.Name()
+}
@@ -1,9 +1,14 @@
 package tasks
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -543,6 +548,144 @@ func TestTasksWithDifferentInputs(t *testing.T) {
 			t.Errorf("Expected 2 executions, got %d", execCount)
 		}
 	})
+
+	t.Run("Parallel_BindFunc", func(t *testing.T) {
+		var execCount int32
+		task := NewTask(func(ctx *Ctx, input time.Duration) (time.Duration, error) {
+			atomic.AddInt32(&execCount, 1)
+			time.Sleep(input)
+			if input == 0 {
+				return 0, errors.New("zero")
+			}
+			return input, nil
+		})
+
+		ctx := NewCtx(context.Background())
+
+		// Cache hit: the callback still fires
+		if _, err := task.Run(ctx, 10*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+
+		var mu sync.Mutex
+		var order []time.Duration
+		record := func(d time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			order = append(order, d)
+		}
+
+		err := ctx.RunParallel(
+			task.BindFunc(80*time.Millisecond, record),
+			task.BindFunc(10*time.Millisecond, record),
+			task.BindFunc(40*time.Millisecond, record),
+			task.BindFunc(40*time.Millisecond, record), // Duplicate
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []time.Duration{10 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond}
+		if !slices.Equal(order, want) {
+			t.Errorf("Expected callbacks in completion order %v, got %v", want, order)
+		}
+		if execCount != 3 {
+			t.Errorf("Expected 3 executions, got %d", execCount)
+		}
+
+		// Errors are passed to the callback, too
+		var gotErr error
+		if err := ctx.RunParallel(task.BindFunc(0, func(_ time.Duration, err error) { gotErr = err })); err == nil || gotErr == nil {
+			t.Errorf("Expected error from RunParallel and callback, got %v and %v", err, gotErr)
+		}
+	})
+}
+
+func TestTasksWithCustomKey(t *testing.T) {
+	type query struct {
+		UserID string
+		Fields []string // not comparable
+	}
+
+	t.Run("Uncomparable_Input_Deduped_By_Key", func(t *testing.T) {
+		var execCount int32
+		task := NewTaskWithKey(func(ctx *Ctx, input query) (string, error) {
+			atomic.AddInt32(&execCount, 1)
+			return "user-" + input.UserID, nil
+		}, func(input query) string { return input.UserID })
+
+		ctx := NewCtx(context.Background())
+
+		r1, err := task.Run(ctx, query{UserID: "1", Fields: []string{"a"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r2, _ := task.Run(ctx, query{UserID: "1", Fields: []string{"b"}})
+		r3, _ := task.Run(ctx, query{UserID: "2"})
+
+		if r1 != "user-1" || r2 != "user-1" || r3 != "user-2" {
+			t.Errorf("Unexpected results: %q, %q, %q", r1, r2, r3)
+		}
+		if execCount != 2 {
+			t.Errorf("Expected 2 executions, got %d", execCount)
+		}
+	})
+
+	t.Run("Same_Key_Different_Tasks_Do_Not_Collide", func(t *testing.T) {
+		keyFn := func(input query) string { return input.UserID }
+		taskA := NewTaskWithKey(func(ctx *Ctx, input query) (string, error) {
+			return "a", nil
+		}, keyFn)
+		taskB := NewTaskWithKey(func(ctx *Ctx, input query) (string, error) {
+			return "b", nil
+		}, keyFn)
+
+		ctx := NewCtx(context.Background())
+
+		a, _ := taskA.Run(ctx, query{UserID: "1"})
+		b, _ := taskB.Run(ctx, query{UserID: "1"})
+		if a != "a" || b != "b" {
+			t.Errorf("Expected per-task caching, got %q and %q", a, b)
+		}
+	})
+
+	t.Run("Bound_And_Parallel", func(t *testing.T) {
+		var execCount int32
+		task := NewTaskWithKey(func(ctx *Ctx, input query) (int, error) {
+			atomic.AddInt32(&execCount, 1)
+			time.Sleep(5 * time.Millisecond)
+			return len(input.UserID), nil
+		}, func(input query) string { return input.UserID })
+
+		ctx := NewCtx(context.Background())
+
+		var r1, r2 int
+		err := ctx.RunParallel(
+			task.Bind(query{UserID: "abc"}, &r1),
+			task.Bind(query{UserID: "abc", Fields: []string{"x"}}, &r2),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r1 != 3 || r2 != 3 {
+			t.Errorf("Expected both results to be 3, got %d and %d", r1, r2)
+		}
+		if execCount != 1 {
+			t.Errorf("Expected 1 execution, got %d", execCount)
+		}
+	})
+
+	t.Run("Nil_KeyFn_Returns_Nil_Task", func(t *testing.T) {
+		task := NewTaskWithKey[query, string](func(ctx *Ctx, input query) (string, error) {
+			return "", nil
+		}, nil)
+		if task != nil {
+			t.Error("Expected nil task when keyFn is nil")
+		}
+	})
 }
 
 func TestTTL_BasicExpiration(t *testing.T) {
@@ -589,6 +732,57 @@ func TestTTL_BasicExpiration(t *testing.T) {
 	}
 }
 
+func TestRunEx(t *testing.T) {
+	var execCount int32
+	task := NewTask(func(ctx *Ctx, input string) (string, error) {
+		atomic.AddInt32(&execCount, 1)
+		if input == "bad" {
+			return "", errors.New("bad input")
+		}
+		return input + "!", nil
+	})
+
+	ctx := NewCtxWithTTL(context.Background(), 50*time.Millisecond)
+
+	if res, cached, err := task.RunEx(ctx, "a"); err != nil || res != "a!" || cached {
+		t.Errorf("First run: got (%q, %v, %v), want (\"a!\", false, nil)", res, cached, err)
+	}
+	if res, cached, err := task.RunEx(ctx, "a"); err != nil || res != "a!" || !cached {
+		t.Errorf("Second run: got (%q, %v, %v), want (\"a!\", true, nil)", res, cached, err)
+	}
+	if _, cached, _ := task.RunEx(ctx, "b"); cached {
+		t.Error("Expected a miss for a different input")
+	}
+
+	// Cached errors are hits too
+	if _, cached, err := task.RunEx(ctx, "bad"); err == nil || cached {
+		t.Errorf("First bad run: got (%v, %v), want (error, false)", cached, err)
+	}
+	if _, cached, err := task.RunEx(ctx, "bad"); err == nil || !cached {
+		t.Errorf("Second bad run: got (%v, %v), want (error, true)", cached, err)
+	}
+
+	// Expired entries are misses
+	time.Sleep(60 * time.Millisecond)
+	if _, cached, _ := task.RunEx(ctx, "a"); cached {
+		t.Error("Expected a miss after TTL expiry")
+	}
+
+	// Run stays consistent with RunEx
+	if res, err := task.Run(ctx, "a"); err != nil || res != "a!" {
+		t.Errorf("Run: got (%q, %v)", res, err)
+	}
+	if got := atomic.LoadInt32(&execCount); got != 4 {
+		t.Errorf("Expected 4 executions, got %d", got)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, cached, err := task.RunEx(NewCtx(canceled), "a"); err == nil || cached {
+		t.Errorf("Canceled ctx: got (%v, %v), want (error, false)", cached, err)
+	}
+}
+
 func TestTTL_NoTTL_NeverExpires(t *testing.T) {
 	var execCount int32
 	task := NewTask(func(ctx *Ctx, input string) (string, error) {
@@ -719,6 +913,118 @@ func TestTTL_Cleanup_RemovesExpiredEntries(t *testing.T) {
 	}
 }
 
+func TestCtxStats(t *testing.T) {
+	task := NewTask(func(ctx *Ctx, input int) (int, error) {
+		return input * 2, nil
+	})
+
+	t.Run("HitsAndMisses", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		for range 3 {
+			if _, err := task.Run(ctx, 1); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var a, b int
+		if err := ctx.RunParallel(task.Bind(1, &a), task.Bind(2, &b)); err != nil {
+			t.Fatal(err)
+		}
+
+		got := ctx.Stats()
+		want := CtxStats{Hits: 3, Misses: 2, Evictions: 0, Entries: 2}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("Evictions", func(t *testing.T) {
+		ttl := 50 * time.Millisecond
+		ctx := NewCtxWithTTL(context.Background(), ttl)
+		for i := range 5 {
+			if _, err := task.Run(ctx, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		time.Sleep(ttl + 10*time.Millisecond)
+
+		// Triggers cleanup of the 5 expired entries
+		if _, err := task.Run(ctx, 100); err != nil {
+			t.Fatal(err)
+		}
+
+		got := ctx.Stats()
+		want := CtxStats{Hits: 0, Misses: 6, Evictions: 5, Entries: 1}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+}
+
+func TestCtxGraph(t *testing.T) {
+	var executions atomic.Int32
+	leaf := NewTask(func(ctx *Ctx, input int) (int, error) {
+		executions.Add(1)
+		return input, nil
+	})
+	unused := NewTask(func(ctx *Ctx, input int) (int, error) {
+		return input, nil
+	})
+	mid := NewTask(func(ctx *Ctx, input int) (int, error) {
+		return leaf.Run(ctx, input)
+	}).DependsOn(leaf)
+	root := NewTask(func(ctx *Ctx, input int) (int, error) {
+		var a, b int
+		if err := ctx.RunParallel(mid.Bind(input, &a), leaf.Bind(input+1, &b)); err != nil {
+			return 0, err
+		}
+		return a + b, nil
+	}).DependsOn(mid, leaf, unused, leaf)
+
+	ctx := NewCtx(context.Background())
+	if got, err := root.Run(ctx, 1); err != nil || got != 3 {
+		t.Fatalf("Expected 3, nil; got %d, %v", got, err)
+	}
+	if executions.Load() != 2 {
+		t.Errorf("Expected DependsOn not to change execution (2 leaf runs), got %d", executions.Load())
+	}
+
+	g := ctx.Graph()
+
+	wantRuns := map[uint64]int{root.id: 1, mid.id: 1, leaf.id: 2, unused.id: 0}
+	if len(g.Nodes) != len(wantRuns) {
+		t.Fatalf("Expected %d nodes, got %+v", len(wantRuns), g.Nodes)
+	}
+	for i, node := range g.Nodes {
+		if i > 0 && g.Nodes[i-1].ID >= node.ID {
+			t.Errorf("Expected nodes sorted by ID, got %+v", g.Nodes)
+		}
+		if runs, ok := wantRuns[node.ID]; !ok || runs != node.Runs {
+			t.Errorf("Unexpected node %+v", node)
+		}
+		if !strings.Contains(node.Name, "TestCtxGraph") {
+			t.Errorf("Expected node name to name the task func, got %q", node.Name)
+		}
+	}
+
+	wantEdges := []GraphEdge{
+		{From: mid.id, To: leaf.id},
+		{From: root.id, To: leaf.id},
+		{From: root.id, To: mid.id},
+		{From: root.id, To: unused.id},
+	}
+	slices.SortFunc(wantEdges, func(a, b GraphEdge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+	if !slices.Equal(g.Edges, wantEdges) {
+		t.Errorf("Expected edges %+v, got %+v", wantEdges, g.Edges)
+	}
+
+	if empty := NewCtx(context.Background()).Graph(); len(empty.Nodes) != 0 || len(empty.Edges) != 0 {
+		t.Errorf("Expected empty graph for unused Ctx, got %+v", empty)
+	}
+}
+
 func TestTTL_Cleanup_OnlyRunsOncePerTTLPeriod(t *testing.T) {
 	task := NewTask(func(ctx *Ctx, input int) (int, error) {
 		return input, nil
@@ -1028,3 +1334,562 @@ func TestTTL_MultipleContexts_IndependentCaches(t *testing.T) {
 		t.Errorf("Expected 2 executions, got %d", execCount)
 	}
 }
+
+func TestSharedCache(t *testing.T) {
+	t.Run("GlobalTaskRunsOnceAcrossContexts", func(t *testing.T) {
+		var globalCount, localCount int32
+		global := NewTask(func(ctx *Ctx, input string) (string, error) {
+			atomic.AddInt32(&globalCount, 1)
+			return "flags-" + input, nil
+		}).Global()
+		local := NewTask(func(ctx *Ctx, input string) (string, error) {
+			atomic.AddInt32(&localCount, 1)
+			return input, nil
+		})
+
+		shared := NewSharedCache(0)
+		ctx1 := NewCtxWithSharedCache(context.Background(), shared)
+		ctx2 := NewCtxWithSharedCache(context.Background(), shared)
+
+		for _, ctx := range []*Ctx{ctx1, ctx2} {
+			if got, err := global.Run(ctx, "a"); err != nil || got != "flags-a" {
+				t.Fatalf("expected 'flags-a', got %q, %v", got, err)
+			}
+			if _, err := local.Run(ctx, "a"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if n := atomic.LoadInt32(&globalCount); n != 1 {
+			t.Errorf("expected global task to run once, ran %d times", n)
+		}
+		if n := atomic.LoadInt32(&localCount); n != 2 {
+			t.Errorf("expected request-scoped task to run once per context, ran %d times", n)
+		}
+		if stats := shared.Stats(); stats.Misses != 1 || stats.Hits != 1 {
+			t.Errorf("expected 1 miss and 1 hit in shared cache, got %+v", stats)
+		}
+		if stats := ctx1.Stats(); stats.Entries != 1 {
+			t.Errorf("expected only the request-scoped result in ctx1, got %d entries", stats.Entries)
+		}
+	})
+
+	t.Run("GlobalTaskWithoutSharedCacheIsRequestScoped", func(t *testing.T) {
+		var count int32
+		global := NewTask(func(ctx *Ctx, input string) (string, error) {
+			atomic.AddInt32(&count, 1)
+			return input, nil
+		}).Global()
+
+		global.Run(NewCtx(context.Background()), "a")
+		global.Run(NewCtx(context.Background()), "a")
+
+		if n := atomic.LoadInt32(&count); n != 2 {
+			t.Errorf("expected 2 executions without a shared cache, got %d", n)
+		}
+	})
+
+	t.Run("ErrorsAreNotShared", func(t *testing.T) {
+		var count int32
+		global := NewTask(func(ctx *Ctx, input string) (string, error) {
+			if atomic.AddInt32(&count, 1) == 1 {
+				return "", errors.New("transient")
+			}
+			return "ok", nil
+		}).Global()
+
+		shared := NewSharedCache(0)
+		if _, err := global.Run(NewCtxWithSharedCache(context.Background(), shared), "a"); err == nil {
+			t.Fatal("expected first run to fail")
+		}
+		got, err := global.Run(NewCtxWithSharedCache(context.Background(), shared), "a")
+		if err != nil || got != "ok" {
+			t.Errorf("expected retry to succeed, got %q, %v", got, err)
+		}
+	})
+
+	t.Run("SharedTTL", func(t *testing.T) {
+		var count int32
+		global := NewTask(func(ctx *Ctx, input string) (int32, error) {
+			return atomic.AddInt32(&count, 1), nil
+		}).Global()
+
+		shared := NewSharedCache(50 * time.Millisecond)
+		first, _ := global.Run(NewCtxWithSharedCache(context.Background(), shared), "a")
+		time.Sleep(75 * time.Millisecond)
+		second, _ := global.Run(NewCtxWithSharedCache(context.Background(), shared), "a")
+
+		if first != 1 || second != 2 {
+			t.Errorf("expected re-execution after shared TTL, got %d then %d", first, second)
+		}
+	})
+
+	t.Run("CancelledCallerGetsContextError", func(t *testing.T) {
+		global := NewTask(func(ctx *Ctx, input string) (string, error) {
+			return input, nil
+		}).Global()
+
+		shared := NewSharedCache(0)
+		parent, cancel := context.WithCancel(context.Background())
+		ctx := NewCtxWithSharedCache(parent, shared)
+		cancel()
+
+		if _, err := global.Run(ctx, "a"); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("GlobalTaskRunsOnceViaRunParallel", func(t *testing.T) {
+		var globalCount int32
+		global := NewTask(func(ctx *Ctx, input string) (string, error) {
+			atomic.AddInt32(&globalCount, 1)
+			return "flags-" + input, nil
+		}).Global()
+		local := NewTask(func(ctx *Ctx, input string) (string, error) {
+			return input, nil
+		})
+
+		shared := NewSharedCache(0)
+		for i := range 3 {
+			ctx := NewCtxWithSharedCache(context.Background(), shared)
+			var flags, other string
+			if err := ctx.RunParallel(global.Bind("a", &flags), local.Bind("b", &other)); err != nil {
+				t.Fatalf("context %d: %v", i, err)
+			}
+			if flags != "flags-a" || other != "b" {
+				t.Fatalf("context %d: unexpected results %q, %q", i, flags, other)
+			}
+		}
+
+		if n := atomic.LoadInt32(&globalCount); n != 1 {
+			t.Errorf("expected global task to run once across contexts, ran %d times", n)
+		}
+	})
+}
+
+func TestTaskVary(t *testing.T) {
+	tenantValue := NewValue[string]("tenant")
+	varyByTenant := func(c *Ctx) string { return tenantValue.Get(c) }
+
+	t.Run("Same_Input_Caches_Per_Vary_Value", func(t *testing.T) {
+		var execCount int32
+		task := NewTask(func(ctx *Ctx, input string) (string, error) {
+			atomic.AddInt32(&execCount, 1)
+			return tenantValue.Get(ctx) + ":" + input, nil
+		}).Vary(varyByTenant)
+
+		ctx := NewCtx(context.Background())
+
+		tenantValue.Set(ctx, "acme")
+		a1, _ := task.Run(ctx, "settings")
+		a2, _ := task.Run(ctx, "settings")
+
+		tenantValue.Set(ctx, "globex")
+		b1, _ := task.Run(ctx, "settings")
+		b2, _ := task.Run(ctx, "settings")
+
+		if a1 != "acme:settings" || a2 != a1 {
+			t.Errorf("Unexpected acme results: %q, %q", a1, a2)
+		}
+		if b1 != "globex:settings" || b2 != b1 {
+			t.Errorf("Unexpected globex results: %q, %q", b1, b2)
+		}
+		if execCount != 2 {
+			t.Errorf("Expected 2 executions, got %d", execCount)
+		}
+	})
+
+	t.Run("Works_With_Custom_Key", func(t *testing.T) {
+		var execCount int32
+		task := NewTaskWithKey(func(ctx *Ctx, input []string) (int, error) {
+			atomic.AddInt32(&execCount, 1)
+			return len(input), nil
+		}, func(input []string) string { return strings.Join(input, ",") }).Vary(varyByTenant)
+
+		ctx := NewCtx(context.Background())
+		tenantValue.Set(ctx, "acme")
+		task.Run(ctx, []string{"a", "b"})
+		task.Run(ctx, []string{"a", "b"})
+		tenantValue.Set(ctx, "globex")
+		task.Run(ctx, []string{"a", "b"})
+
+		if execCount != 2 {
+			t.Errorf("Expected 2 executions, got %d", execCount)
+		}
+	})
+
+	t.Run("Global_Task_Varies_By_Request_Ctx", func(t *testing.T) {
+		var execCount int32
+		task := NewTask(func(ctx *Ctx, input string) (string, error) {
+			atomic.AddInt32(&execCount, 1)
+			return input, nil
+		}).Global().Vary(varyByTenant)
+
+		shared := NewSharedCache(time.Minute)
+		for _, tenant := range []string{"acme", "acme", "globex", "globex"} {
+			ctx := NewCtxWithSharedCache(context.Background(), shared)
+			tenantValue.Set(ctx, tenant)
+			if _, err := task.Run(ctx, "flags"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if execCount != 2 {
+			t.Errorf("Expected 2 executions across contexts, got %d", execCount)
+		}
+	})
+}
+
+func TestCtxValues(t *testing.T) {
+	type user struct{ ID string }
+	userValue := NewValue[*user]("user")
+	traceValue := NewValue[string]("trace")
+
+	t.Run("Get_Before_Set_Returns_Zero", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		if u := userValue.Get(ctx); u != nil {
+			t.Errorf("Expected nil, got %v", u)
+		}
+		if _, ok := traceValue.Lookup(ctx); ok {
+			t.Error("Expected Lookup to report unset value")
+		}
+	})
+
+	t.Run("Set_Then_Get", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		userValue.Set(ctx, &user{ID: "42"})
+		traceValue.Set(ctx, "abc")
+		if u := userValue.Get(ctx); u == nil || u.ID != "42" {
+			t.Errorf("Expected user 42, got %v", u)
+		}
+		if tr, ok := traceValue.Lookup(ctx); !ok || tr != "abc" {
+			t.Errorf("Expected trace abc, got %q (ok=%v)", tr, ok)
+		}
+	})
+
+	t.Run("Distinct_Keys_With_Same_Name_Do_Not_Collide", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		other := NewValue[string]("trace")
+		traceValue.Set(ctx, "one")
+		other.Set(ctx, "two")
+		if traceValue.Get(ctx) != "one" || other.Get(ctx) != "two" {
+			t.Error("Expected values to be keyed by identity, not name")
+		}
+	})
+
+	t.Run("Visible_Inside_Parallel_Tasks", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		userValue.Set(ctx, &user{ID: "7"})
+
+		task := NewTask(func(ctx *Ctx, suffix string) (string, error) {
+			return userValue.Get(ctx).ID + suffix, nil
+		})
+
+		var a, b string
+		if err := ctx.RunParallel(task.Bind("a", &a), task.Bind("b", &b)); err != nil {
+			t.Fatal(err)
+		}
+		if a != "7a" || b != "7b" {
+			t.Errorf("Expected 7a and 7b, got %q and %q", a, b)
+		}
+	})
+
+	t.Run("Set_Inside_Parallel_Task_Visible_To_Parent", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		task := NewTask(func(ctx *Ctx, input string) (struct{}, error) {
+			traceValue.Set(ctx, input)
+			return struct{}{}, nil
+		})
+		if err := ctx.RunParallel(task.Bind("x", nil), task.Bind("x", nil)); err != nil {
+			t.Fatal(err)
+		}
+		if traceValue.Get(ctx) != "x" {
+			t.Errorf("Expected x, got %q", traceValue.Get(ctx))
+		}
+	})
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestCtxWatchdog(t *testing.T) {
+	buf := &syncBuffer{}
+	originalLog := watchdogLog
+	watchdogLog = slog.New(slog.NewTextHandler(buf, nil))
+	defer func() { watchdogLog = originalLog }()
+
+	isDev := func() bool { return true }
+
+	slowTask := NewTask(func(c *Ctx, input int) (int, error) {
+		time.Sleep(50 * time.Millisecond)
+		return input, nil
+	})
+	fastTask := NewTask(func(c *Ctx, input int) (int, error) {
+		return input, nil
+	})
+
+	t.Run("WarnsAboutSlowTasks", func(t *testing.T) {
+		ctx := NewCtxWithWatchdog(context.Background(), 10*time.Millisecond, isDev)
+		var a, b int
+		if err := ctx.RunParallel(slowTask.Bind(1, &a), fastTask.Bind(2, &b)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := buf.String()
+		if strings.Count(out, "watchdog threshold") != 1 {
+			t.Errorf("expected exactly one watchdog warning, got:\n%s", out)
+		}
+		if !strings.Contains(out, fmt.Sprintf("task #%d", slowTask.id)) {
+			t.Errorf("expected warning to name the slow task, got:\n%s", out)
+		}
+		if !strings.Contains(out, "tasks_test.go") {
+			t.Errorf("expected warning to include the task's source location, got:\n%s", out)
+		}
+		if !strings.Contains(out, "Previously reported slow task completed") {
+			t.Errorf("expected completion to be logged, got:\n%s", out)
+		}
+	})
+
+	t.Run("DisabledOutsideDev", func(t *testing.T) {
+		buf.mu.Lock()
+		buf.buf.Reset()
+		buf.mu.Unlock()
+
+		for _, getIsDev := range []func() bool{nil, func() bool { return false }} {
+			ctx := NewCtxWithWatchdog(context.Background(), 10*time.Millisecond, getIsDev)
+			if _, err := slowTask.Run(ctx, 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if out := buf.String(); out != "" {
+			t.Errorf("expected no watchdog output, got:\n%s", out)
+		}
+	})
+}
+
+func TestStreamTask(t *testing.T) {
+	t.Run("RunEmitsEachValueWithoutCaching", func(t *testing.T) {
+		var calls atomic.Int32
+		task := NewStreamTask(func(c *Ctx, n int, emit func(int)) error {
+			calls.Add(1)
+			for i := range n {
+				emit(i)
+			}
+			return nil
+		})
+
+		ctx := NewCtx(context.Background())
+		for range 2 {
+			var got []int
+			if err := task.Run(ctx, 3, func(v int) { got = append(got, v) }); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, []int{0, 1, 2}) {
+				t.Errorf("expected [0 1 2], got %v", got)
+			}
+		}
+		if calls.Load() != 2 {
+			t.Errorf("expected stream task to run on every call, ran %d times", calls.Load())
+		}
+		if stats := ctx.Stats(); stats.Entries != 0 || stats.Misses != 0 {
+			t.Errorf("expected stream task to bypass the results cache, got %+v", stats)
+		}
+	})
+
+	t.Run("RunReturnsTaskError", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		task := NewStreamTask(func(c *Ctx, _ struct{}, emit func(string)) error {
+			emit("partial")
+			return wantErr
+		})
+		var got []string
+		err := task.Run(NewCtx(context.Background()), struct{}{}, func(v string) { got = append(got, v) })
+		if !errors.Is(err, wantErr) {
+			t.Errorf("expected %v, got %v", wantErr, err)
+		}
+		if !slices.Equal(got, []string{"partial"}) {
+			t.Errorf("expected partial result to be emitted, got %v", got)
+		}
+	})
+
+	t.Run("CancellationStopsEmission", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		task := NewStreamTask(func(c *Ctx, _ struct{}, emit func(int)) error {
+			emit(1)
+			cancel()
+			emit(2)
+			return nil
+		})
+		var got []int
+		err := task.Run(NewCtx(parent), struct{}{}, func(v int) { got = append(got, v) })
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if !slices.Equal(got, []int{1}) {
+			t.Errorf("expected only emissions before cancellation, got %v", got)
+		}
+	})
+
+	t.Run("StreamChannel", func(t *testing.T) {
+		task := NewStreamTask(func(c *Ctx, prefix string, emit func(string)) error {
+			for i := range 3 {
+				emit(fmt.Sprintf("%s%d", prefix, i))
+			}
+			return nil
+		})
+		values, errCh := task.Stream(NewCtx(context.Background()), "v")
+		var got []string
+		for v := range values {
+			got = append(got, v)
+		}
+		if err := <-errCh; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(got, []string{"v0", "v1", "v2"}) {
+			t.Errorf("expected [v0 v1 v2], got %v", got)
+		}
+	})
+
+	t.Run("StreamCancellationUnblocksTask", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		task := NewStreamTask(func(c *Ctx, _ struct{}, emit func(int)) error {
+			defer close(done)
+			for i := 0; c.NativeContext().Err() == nil; i++ {
+				emit(i)
+			}
+			return nil
+		})
+		values, errCh := task.Stream(NewCtx(parent), struct{}{})
+		if v := <-values; v != 0 {
+			t.Errorf("expected first value 0, got %d", v)
+		}
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stream task did not stop after cancellation")
+		}
+		for range values {
+		}
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("InvalidInputs", func(t *testing.T) {
+		if NewStreamTask[int, int](nil) != nil {
+			t.Error("expected nil stream task for nil fn")
+		}
+		var task *StreamTask[int, int]
+		if err := task.Run(NewCtx(context.Background()), 1, nil); err == nil {
+			t.Error("expected error for nil stream task")
+		}
+		valid := NewStreamTask(func(c *Ctx, _ int, emit func(int)) error { return nil })
+		if err := valid.Run(nil, 1, nil); err == nil {
+			t.Error("expected error for nil Ctx")
+		}
+		_, errCh := valid.Stream(nil, 1)
+		if err := <-errCh; err == nil {
+			t.Error("expected error for nil Ctx")
+		}
+	})
+}
+
+func TestCtxGoAndWaitAll(t *testing.T) {
+	t.Run("WaitAllBlocksUntilSettled", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		var done atomic.Int32
+		for range 5 {
+			ctx.Go(func(c *Ctx) error {
+				time.Sleep(10 * time.Millisecond)
+				done.Add(1)
+				return nil
+			})
+		}
+		if err := ctx.WaitAll(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := done.Load(); got != 5 {
+			t.Errorf("expected 5 completed, got %d", got)
+		}
+	})
+
+	t.Run("NestedAndErrors", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		errA, errB := errors.New("a"), errors.New("b")
+		ctx.Go(func(c *Ctx) error {
+			c.Go(func(c *Ctx) error {
+				time.Sleep(10 * time.Millisecond)
+				return errB
+			})
+			return errA
+		})
+		err := ctx.WaitAll()
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("expected both errors, got %v", err)
+		}
+	})
+
+	t.Run("StartedWithinRunParallel", func(t *testing.T) {
+		ctx := NewCtx(context.Background())
+		var ran atomic.Bool
+		task := NewTask(func(c *Ctx, _ int) (int, error) {
+			c.Go(func(c *Ctx) error {
+				ran.Store(true)
+				return nil
+			})
+			return 0, nil
+		})
+		var a, b int
+		if err := ctx.RunParallel(task.Bind(1, &a), task.Bind(2, &b)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := ctx.WaitAll(); err != nil || !ran.Load() {
+			t.Errorf("expected work started within RunParallel to be awaited, err=%v ran=%v", err, ran.Load())
+		}
+	})
+
+	t.Run("DetachSurvivesCancellation", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		ctx := NewCtx(parent)
+		user := NewValue[string]("user")
+		user.Set(ctx, "bob")
+
+		detached := ctx.Detach()
+		started := make(chan struct{})
+		detached.Go(func(c *Ctx) error {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			if user.Get(c) != "bob" {
+				return errors.New("expected shared values")
+			}
+			return c.NativeContext().Err()
+		})
+		<-started
+		cancel()
+
+		if err := ctx.WaitAll(); err != nil {
+			t.Errorf("original WaitAll should not track detached work, got %v", err)
+		}
+		if err := detached.WaitAll(); err != nil {
+			t.Errorf("expected detached work to survive cancellation, got %v", err)
+		}
+		if ctx.NativeContext().Err() == nil {
+			t.Error("expected original ctx to be cancelled")
+		}
+	})
+}
@@ -96,8 +96,10 @@ func optsToMerged(opts Opts) tsgencore.Results {
 
 	for _, adHocType := range opts.AdHocTypes {
 		adHocTypes = append(adHocTypes, &AdHocType{
-			TypeInstance: adHocType.TypeInstance,
-			TSTypeName:   adHocType.TSTypeName,
+			TypeInstance:      adHocType.TypeInstance,
+			TSTypeName:        adHocType.TSTypeName,
+			Description:       adHocType.Description,
+			FieldDescriptions: adHocType.FieldDescriptions,
 		})
 	}
 	for _, item := range opts.Collection {
@@ -231,6 +233,7 @@ func getCollectionStr(opts Opts, merged tsgencore.Results) (string, error) {
 
 func getExports(merged tsgencore.Results) string {
 	var exportsLines []string
+	docs := make(map[string]string)
 
 	for _, t := range merged.Types {
 		if t.ResolvedName != "" {
@@ -241,13 +244,16 @@ func getExports(merged tsgencore.Results) string {
 			write(sb, t.TSStr)
 			write(sb, ";")
 			exportsLines = append(exportsLines, sb.String())
+			docs[sb.String()] = tsgencore.JSDoc(t.Description, "")
 		}
 	}
 
+	// Sorted without their doc comments, so that docs don't affect order
 	slices.Sort(exportsLines)
 
 	exports := &strings.Builder{}
 	for _, line := range exportsLines {
+		exports.WriteString(docs[line])
 		exports.WriteString(line)
 		exports.WriteString("\n\n")
 	}
@@ -390,6 +390,58 @@ func TestGenerateTSContent_AdHocTypes(t *testing.T) {
 	assertContains(t, content, "export type CustomType = { Field: string; };")
 }
 
+func TestGenerateTSContent_AdHocTypeDescriptions(t *testing.T) {
+	type Account struct {
+		Plan string `json:"plan"`
+	}
+	type User struct {
+		ID      string  `json:"id"`
+		Name    string  `json:"name,omitempty"`
+		Account Account `json:"account"`
+	}
+
+	opts := Opts{
+		AdHocTypes: []*AdHocType{
+			{
+				TypeInstance: User{},
+				Description:  "A user of the app.\nIDs are stable across renames.",
+				FieldDescriptions: map[string]string{
+					"ID":   "The user's unique ID.",
+					"Name": "Display name. May contain */ safely.",
+				},
+			},
+			{TypeInstance: Account{}, Description: "A billing account."},
+		},
+	}
+
+	content, err := GenerateTSContent(opts)
+	if err != nil {
+		t.Fatalf("GenerateTSContent failed: %v", err)
+	}
+
+	expected := `/**
+ * A user of the app.
+ * IDs are stable across renames.
+ */
+export type User = {
+	/** The user's unique ID. */
+	id: string;
+	/** Display name. May contain *\/ safely. */
+	name?: string;
+	account: Account;
+};`
+	if !strings.Contains(content, expected) {
+		t.Errorf("Expected content to contain:\n%s\nGot:\n%s", expected, content)
+	}
+	if !strings.Contains(content, "/** A billing account. */\nexport type Account = {") {
+		t.Errorf("Expected Account to have a JSDoc comment, got:\n%s", content)
+	}
+	// Docs must not affect ordering
+	if strings.Index(content, "export type Account") > strings.Index(content, "export type User") {
+		t.Errorf("Expected Account before User, got:\n%s", content)
+	}
+}
+
 // TestGenerateTSContent_TypesWithTimeField tests handling of time.Time fields
 func TestGenerateTSContent_TypesWithTimeField(t *testing.T) {
 	type TypeWithTime struct {
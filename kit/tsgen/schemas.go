@@ -0,0 +1,282 @@
+package tsgen
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/river-now/river/kit/reflectutil"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// RUNTIME SCHEMAS
+/////////////////////////////////////////////////////////////////////
+
+type SchemaLibrary string
+
+var SchemaLibraries = struct {
+	Zod     SchemaLibrary
+	Valibot SchemaLibrary
+}{
+	Zod:     "zod",
+	Valibot: "valibot",
+}
+
+type schemaDialect struct {
+	importStmt string // %s is the import path
+	ns         string
+	optional   func(expr string) string
+	nullable   func(expr string) string
+	nullish    func(expr string) string
+}
+
+var schemaDialects = map[SchemaLibrary]schemaDialect{
+	SchemaLibraries.Zod: {
+		importStmt: `import { z } from "%s";`,
+		ns:         "z",
+		optional:   func(expr string) string { return expr + ".optional()" },
+		nullable:   func(expr string) string { return expr + ".nullable()" },
+		nullish:    func(expr string) string { return expr + ".nullish()" },
+	},
+	SchemaLibraries.Valibot: {
+		importStmt: `import * as v from "%s";`,
+		ns:         "v",
+		optional:   func(expr string) string { return "v.optional(" + expr + ")" },
+		nullable:   func(expr string) string { return "v.nullable(" + expr + ")" },
+		nullish:    func(expr string) string { return "v.nullish(" + expr + ")" },
+	},
+}
+
+// SchemaBuilder renders runtime validation schemas (Zod or Valibot) from
+// Go types, walking them the same way the TypeScript type generation
+// does (JSON field names, omitempty/pointer optionality, flattened
+// untagged embedded structs, etc.). Pointers, slices, and maps accept
+// null (which is how encoding/json serializes them when nil), unless
+// omitempty/omitzero means a nil value is omitted instead. Named struct
+// types are emitted once as exported consts and referenced lazily, so
+// recursive types are supported. Fields overridden via a ts_type tag
+// cannot be translated and fall back to an "unknown" schema.
+type SchemaBuilder struct {
+	importPath string
+	dialect    schemaDialect
+	names      map[reflect.Type]string
+	nameCounts map[string]int
+	defs       map[string]string
+}
+
+// NewSchemaBuilder returns a SchemaBuilder for the given library. If
+// importPath is empty, it defaults to the library's package name.
+func NewSchemaBuilder(library SchemaLibrary, importPath string) (*SchemaBuilder, error) {
+	dialect, ok := schemaDialects[library]
+	if !ok {
+		return nil, fmt.Errorf("tsgen: unsupported schema library %q", library)
+	}
+	if importPath == "" {
+		importPath = string(library)
+	}
+	return &SchemaBuilder{
+		importPath: importPath,
+		dialect:    dialect,
+		names:      make(map[reflect.Type]string),
+		nameCounts: make(map[string]int),
+		defs:       make(map[string]string),
+	}, nil
+}
+
+// Schema returns a schema expression for the type of the given
+// instance, registering any named struct types it depends on.
+func (b *SchemaBuilder) Schema(typeInstance any) string {
+	if _, isTSTyperRaw := typeInstance.(TSTyperRaw); isTSTyperRaw {
+		return b.call("unknown")
+	}
+	t := reflect.TypeOf(typeInstance)
+	if t == nil {
+		return b.call("null")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return b.baseSchemaFor(t)
+}
+
+// Definitions returns the import statement followed by the exported
+// schema consts for every named type registered so far.
+func (b *SchemaBuilder) Definitions() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(b.dialect.importStmt, b.importPath))
+	sb.WriteString("\n")
+
+	names := make([]string, 0, len(b.defs))
+	for name := range b.defs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		sb.WriteString("\nexport const ")
+		sb.WriteString(name)
+		sb.WriteString(" = ")
+		sb.WriteString(b.defs[name])
+		sb.WriteString(";\n")
+	}
+
+	return sb.String()
+}
+
+func (b *SchemaBuilder) call(fn string, args ...string) string {
+	return b.dialect.ns + "." + fn + "(" + strings.Join(args, ", ") + ")"
+}
+
+// schemaFor is baseSchemaFor, but also accepting null for types that
+// encoding/json serializes as null when nil.
+func (b *SchemaBuilder) schemaFor(t reflect.Type) string {
+	if isNullableSchemaType(t) {
+		return b.dialect.nullable(b.baseSchemaFor(t))
+	}
+	return b.baseSchemaFor(t)
+}
+
+func (b *SchemaBuilder) baseSchemaFor(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return b.call("string")
+	case reflect.TypeOf(time.Duration(0)):
+		return b.call("number")
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return b.call("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return b.call("number")
+	case reflect.String:
+		return b.call("string")
+	case reflect.Ptr:
+		return b.baseSchemaFor(t.Elem())
+	case reflect.Slice, reflect.Array:
+		// Byte slices are serialized as base64 by encoding/json
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return b.call("string")
+		}
+		return b.call("array", b.schemaFor(t.Elem()))
+	case reflect.Map:
+		// JSON object keys are always strings
+		return b.call("record", b.call("string"), b.schemaFor(t.Elem()))
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.objectFor(t)
+		}
+		return b.call("lazy", "() => "+b.registerNamed(t))
+	default:
+		return b.call("unknown")
+	}
+}
+
+var invalidSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_$]`)
+
+func (b *SchemaBuilder) registerNamed(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	base := strings.TrimRight(invalidSchemaNameChars.ReplaceAllString(t.Name(), "_"), "_") + "Schema"
+	b.nameCounts[base]++
+	name := base
+	if n := b.nameCounts[base]; n > 1 {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	// Register the name before rendering so recursive references resolve.
+	b.names[t] = name
+	b.defs[name] = b.objectFor(t)
+	return name
+}
+
+func (b *SchemaBuilder) objectFor(t reflect.Type) string {
+	var fields []string
+
+	var processFields func(currentType reflect.Type, isEmbeddedPtr bool)
+	processFields = func(currentType reflect.Type, isEmbeddedPtr bool) {
+		for i := range currentType.NumField() {
+			field := currentType.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			tag := field.Tag.Get("json")
+			if tag == "-" || strings.HasPrefix(tag, "-,") {
+				continue
+			}
+
+			if field.Anonymous && tag == "" {
+				embeddedType := field.Type
+				isPtr := embeddedType.Kind() == reflect.Ptr
+				if isPtr {
+					embeddedType = embeddedType.Elem()
+				}
+				if embeddedType.Kind() == reflect.Struct {
+					processFields(embeddedType, isPtr || isEmbeddedPtr)
+					continue
+				}
+			}
+
+			jsonFieldName := reflectutil.GetJSONFieldName(field)
+			if jsonFieldName == "" {
+				continue
+			}
+
+			var expr string
+			isNullable := false
+			if field.Tag.Get("ts_type") != "" {
+				expr = b.call("unknown")
+			} else {
+				expr = b.baseSchemaFor(field.Type)
+				isNullable = isNullableSchemaType(field.Type) && !hasOmitTag(field)
+			}
+			isOptional := isEmbeddedPtr || isOptionalSchemaField(field)
+			switch {
+			case isNullable && isOptional:
+				expr = b.dialect.nullish(expr)
+			case isNullable:
+				expr = b.dialect.nullable(expr)
+			case isOptional:
+				expr = b.dialect.optional(expr)
+			}
+
+			expr = strings.ReplaceAll(expr, "\n", "\n\t")
+			fields = append(fields, fmt.Sprintf("\t%q: %s,", jsonFieldName, expr))
+		}
+	}
+
+	processFields(t, false)
+
+	if len(fields) == 0 {
+		return b.call("object", "{}")
+	}
+	return b.call("object", "{\n"+strings.Join(fields, "\n")+"\n}")
+}
+
+func isOptionalSchemaField(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.Ptr || hasOmitTag(field)
+}
+
+// Nil pointers, slices, and maps are serialized as null by encoding/json.
+func isNullableSchemaType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func hasOmitTag(field reflect.StructField) bool {
+	parts := strings.Split(field.Tag.Get("json"), ",")
+	for _, part := range parts[1:] {
+		if part == "omitempty" || part == "omitzero" {
+			return true
+		}
+	}
+	return false
+}
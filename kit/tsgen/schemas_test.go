@@ -0,0 +1,198 @@
+package tsgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaTestNode struct {
+	Name     string            `json:"name"`
+	Children []*schemaTestNode `json:"children,omitempty"`
+}
+
+type SchemaTestBase struct {
+	ID int `json:"id"`
+}
+
+type schemaTestUser struct {
+	SchemaTestBase
+	Email     string            `json:"email"`
+	Nickname  *string           `json:"nickname"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Tags      []string          `json:"tags,omitempty"`
+	Meta      map[string]int    `json:"meta"`
+	Raw       []byte            `json:"raw"`
+	Custom    string            `json:"custom" ts_type:"'a' | 'b'"`
+	Ignored   string            `json:"-"`
+	Root      schemaTestNode    `json:"root"`
+	Inline    struct{ X bool }  `json:"inline"`
+	Lookup    map[string]string `json:"lookup,omitzero"`
+	private   string
+}
+
+func TestSchemaBuilderZod(t *testing.T) {
+	b, err := NewSchemaBuilder(SchemaLibraries.Zod, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expr := b.Schema(schemaTestUser{})
+	if expr != "z.lazy(() => schemaTestUserSchema)" {
+		t.Errorf("unexpected root expression: %s", expr)
+	}
+
+	defs := b.Definitions()
+
+	expected := []string{
+		`import { z } from "zod";`,
+		`export const schemaTestUserSchema = z.object({`,
+		`"id": z.number(),`,
+		`"email": z.string(),`,
+		`"nickname": z.string().nullish(),`,
+		`"createdAt": z.string(),`,
+		`"tags": z.array(z.string()).optional(),`,
+		`"meta": z.record(z.string(), z.number()).nullable(),`,
+		`"raw": z.string().nullable(),`,
+		`"custom": z.unknown(),`,
+		`"root": z.lazy(() => schemaTestNodeSchema),`,
+		"\"inline\": z.object({\n\t\t\"X\": z.boolean(),\n\t}),",
+		`"lookup": z.record(z.string(), z.string()).optional(),`,
+		`export const schemaTestNodeSchema = z.object({`,
+		`"children": z.array(z.lazy(() => schemaTestNodeSchema).nullable()).optional(),`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(defs, s) {
+			t.Errorf("expected definitions to contain %q, got:\n%s", s, defs)
+		}
+	}
+
+	for _, s := range []string{`"Ignored"`, `"private"`, `"-"`, `SchemaTestBaseSchema`} {
+		if strings.Contains(defs, s) {
+			t.Errorf("expected definitions not to contain %q, got:\n%s", s, defs)
+		}
+	}
+}
+
+func TestSchemaBuilderValibot(t *testing.T) {
+	b, err := NewSchemaBuilder(SchemaLibraries.Valibot, "valibot/custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expr := b.Schema([]int{}); expr != "v.array(v.number())" {
+		t.Errorf("unexpected expression: %s", expr)
+	}
+
+	b.Schema(&schemaTestNode{})
+	defs := b.Definitions()
+
+	for _, s := range []string{
+		`import * as v from "valibot/custom";`,
+		`export const schemaTestNodeSchema = v.object({`,
+		`"children": v.optional(v.array(v.nullable(v.lazy(() => schemaTestNodeSchema)))),`,
+	} {
+		if !strings.Contains(defs, s) {
+			t.Errorf("expected definitions to contain %q, got:\n%s", s, defs)
+		}
+	}
+}
+
+func TestSchemaBuilderUnsupportedLibrary(t *testing.T) {
+	if _, err := NewSchemaBuilder("yup", ""); err == nil {
+		t.Error("expected error for unsupported library")
+	}
+}
+
+func TestSchemaBuilderNil(t *testing.T) {
+	b, _ := NewSchemaBuilder(SchemaLibraries.Zod, "")
+	if expr := b.Schema(nil); expr != "z.null()" {
+		t.Errorf("unexpected expression: %s", expr)
+	}
+}
+
+type schemaTestNullable struct {
+	Name     string            `json:"name"`
+	Nickname *string           `json:"nickname"`
+	Tags     []string          `json:"tags"`
+	Meta     map[string]int    `json:"meta"`
+	Raw      []byte            `json:"raw"`
+	Children []*schemaTestNode `json:"children"`
+	Parent   *schemaTestNode   `json:"parent,omitempty"`
+}
+
+// Validates JSON-serialized Go values against the generated Zod schema
+// using Node. Skipped unless node is installed and can resolve zod (e.g.,
+// via NODE_PATH).
+func TestSchemaBuilderValidatesNullPayloads(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+	out, err := exec.Command("node", "-p", `require.resolve("zod")`).Output()
+	if err != nil {
+		t.Skip("zod not resolvable by node")
+	}
+	zodPath := strings.TrimSpace(string(out))
+
+	b, err := NewSchemaBuilder(SchemaLibraries.Zod, zodPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := b.Schema(schemaTestNullable{})
+
+	validate := func(payload any) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		script := b.Definitions() + "\nconst result = " + root + ".safeParse(" + string(data) + ");\n" +
+			"if (!result.success) { console.error(JSON.stringify(result.error.issues)); process.exit(1); }\n"
+		file := filepath.Join(t.TempDir(), "validate.mjs")
+		if err := os.WriteFile(file, []byte(script), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("node", file).CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return nil
+	}
+
+	t.Run("NilPointerSliceAndMap", func(t *testing.T) {
+		if err := validate(schemaTestNullable{Name: "a"}); err != nil {
+			t.Errorf("expected payload with nulls to validate: %v", err)
+		}
+	})
+
+	t.Run("NilElements", func(t *testing.T) {
+		payload := schemaTestNullable{Name: "a", Children: []*schemaTestNode{nil, {Name: "b"}}}
+		if err := validate(payload); err != nil {
+			t.Errorf("expected payload with null elements to validate: %v", err)
+		}
+	})
+
+	t.Run("Populated", func(t *testing.T) {
+		nickname := "nick"
+		payload := schemaTestNullable{
+			Name:     "a",
+			Nickname: &nickname,
+			Tags:     []string{"x"},
+			Meta:     map[string]int{"k": 1},
+			Raw:      []byte("raw"),
+			Parent:   &schemaTestNode{Name: "p"},
+		}
+		if err := validate(payload); err != nil {
+			t.Errorf("expected populated payload to validate: %v", err)
+		}
+	})
+
+	t.Run("NullForNonNullableField", func(t *testing.T) {
+		if err := validate(map[string]any{"name": nil}); err == nil {
+			t.Error("expected null for a string field to be rejected")
+		}
+	})
+}
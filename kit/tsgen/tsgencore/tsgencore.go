@@ -28,6 +28,12 @@ type Results struct {
 type AdHocType struct {
 	TypeInstance any
 	TSTypeName   string
+	// Optional. Rendered as a JSDoc comment above the exported type.
+	Description string
+	// Optional. Rendered as JSDoc comments above the type's fields. Keyed
+	// by Go field name (or by key, for fields added via TSTyper). Only
+	// applies to the fields of the type itself, not of nested types.
+	FieldDescriptions map[string]string
 }
 
 // TSTyper is an interface that a struct can implement to provide custom TypeScript type overrides.
@@ -69,6 +75,7 @@ type TypeInfo struct {
 	ResolvedName   string
 	ReflectType    reflect.Type
 	TSStr          string
+	Description    string
 	_id            IDStr
 	IsRoot         bool
 	IsReferenced   bool
@@ -79,6 +86,7 @@ type typeCollector struct {
 	types             map[reflect.Type]*typeEntry
 	rootType          reflect.Type
 	rootRequestedName string
+	rootFieldDescs    map[string]string
 }
 
 type typeEntry struct {
@@ -109,8 +117,13 @@ func traverseType(adHocType *AdHocType) (_results, IDStr) {
 	c := newTypeCollector()
 	c.rootType = t
 	c.rootRequestedName = effectiveRequestedName
+	c.rootFieldDescs = adHocType.FieldDescriptions
 	c.collectType(t, adHocType.TSTypeName)
-	return c.buildDefinitions()
+	results, rootID := c.buildDefinitions()
+	if root, ok := results[rootID]; ok {
+		root.Description = adHocType.Description
+	}
+	return results, rootID
 }
 
 func (c *typeCollector) buildDefinitions() (_results, IDStr) {
@@ -155,6 +168,14 @@ func mergeTypeResults(results ..._results) Results {
 	for _, result := range results {
 		for id, typeInfo := range result {
 			if existing, ok := flattened[id]; ok {
+				// Field descriptions only render when a type is processed as
+				// a root, so prefer the root's definition.
+				if typeInfo.IsRoot && !existing.IsRoot {
+					existing.TSStr = typeInfo.TSStr
+				}
+				if existing.Description == "" {
+					existing.Description = typeInfo.Description
+				}
 				existing.IsRoot = existing.IsRoot || typeInfo.IsRoot
 				existing.IsReferenced = existing.IsReferenced || typeInfo.IsReferenced
 				existing.UsedAsEmbedded = existing.UsedAsEmbedded || typeInfo.UsedAsEmbedded
@@ -287,7 +308,7 @@ func (c *typeCollector) collectType(t reflect.Type, userDefinedAlias ...string)
 func (c *typeCollector) collectStructFields(t reflect.Type) {
 	for i := range t.NumField() {
 		field := t.Field(i)
-		if isUnexported(field) || shouldOmitField(field) {
+		if isUnexported(field) || reflectutil.IsJSONFieldOmitted(field) {
 			continue
 		}
 		if field.Anonymous {
@@ -356,6 +377,11 @@ func (c *typeCollector) generateStructTypeFields(t reflect.Type) []string {
 	var fields []string
 	tsTypeMap := getTSTypeMap(t)
 
+	var fieldDescs map[string]string
+	if t == c.rootType {
+		fieldDescs = c.rootFieldDescs
+	}
+
 	// Keep track of which TSType() overrides have been applied.
 	usedOverrides := make(map[string]bool)
 
@@ -367,17 +393,12 @@ func (c *typeCollector) generateStructTypeFields(t reflect.Type) []string {
 
 		for i := range currentType.NumField() {
 			field := currentType.Field(i)
-			if isUnexported(field) || shouldOmitField(field) {
+			if isUnexported(field) || reflectutil.IsJSONFieldOmitted(field) {
 				continue
 			}
 
 			// First, handle untagged anonymous fields recursively to match `encoding/json` order.
-			if field.Anonymous && field.Tag.Get("json") == "" {
-				embeddedType := field.Type
-				isPtr := embeddedType.Kind() == reflect.Ptr
-				if isPtr {
-					embeddedType = embeddedType.Elem()
-				}
+			if embeddedType, isPtr, ok := reflectutil.GetJSONFlattenedStruct(field); ok {
 				processFields(embeddedType, isPtr || isEmbeddedPtr)
 				continue
 			}
@@ -399,10 +420,11 @@ func (c *typeCollector) generateStructTypeFields(t reflect.Type) []string {
 				fieldType = c.getTypeScriptType(field.Type)
 			}
 
-			if isEmbeddedPtr || isOptionalField(field) {
-				fields = append(fields, fmt.Sprintf("%s?: %s", jsonFieldName, fieldType))
+			doc := JSDoc(fieldDescs[field.Name], "\t")
+			if isEmbeddedPtr || reflectutil.IsJSONFieldOptional(field) {
+				fields = append(fields, fmt.Sprintf("%s%s?: %s", doc, jsonFieldName, fieldType))
 			} else {
-				fields = append(fields, fmt.Sprintf("%s: %s", jsonFieldName, fieldType))
+				fields = append(fields, fmt.Sprintf("%s%s: %s", doc, jsonFieldName, fieldType))
 			}
 		}
 	}
@@ -421,7 +443,7 @@ func (c *typeCollector) generateStructTypeFields(t reflect.Type) []string {
 
 		for _, key := range additiveKeys {
 			// Assume additive fields are required.
-			fields = append(fields, fmt.Sprintf("%s: %s", key, tsTypeMap[key]))
+			fields = append(fields, fmt.Sprintf("%s%s: %s", JSDoc(fieldDescs[key], "\t"), key, tsTypeMap[key]))
 		}
 	}
 
@@ -488,6 +510,32 @@ func getBasicTSType(t reflect.Type) string {
 	}
 }
 
+// JSDoc renders description as a JSDoc comment (followed by a newline and
+// indent, ready to prefix the documented line), or returns "" if description
+// is blank.
+func JSDoc(description string, indent string) string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return ""
+	}
+	description = strings.ReplaceAll(description, "*/", "*\\/")
+	lines := strings.Split(description, "\n")
+	if len(lines) == 1 {
+		return "/** " + lines[0] + " */\n" + indent
+	}
+	var sb strings.Builder
+	sb.WriteString("/**\n")
+	for _, line := range lines {
+		sb.WriteString(indent)
+		sb.WriteString(strings.TrimRight(" * "+strings.TrimSpace(line), " "))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(indent)
+	sb.WriteString(" */\n")
+	sb.WriteString(indent)
+	return sb.String()
+}
+
 func buildObj(fields []string) string {
 	if len(fields) == 0 {
 		return "Record<never, never>"
@@ -594,27 +642,6 @@ func isUnexported(field reflect.StructField) bool {
 	return field.PkgPath != ""
 }
 
-func isOptionalField(field reflect.StructField) bool {
-	if field.Type.Kind() == reflect.Ptr {
-		return true
-	}
-	tag := field.Tag.Get("json")
-	if tag != "" {
-		parts := strings.Split(tag, ",")
-		for _, part := range parts[1:] {
-			if part == "omitempty" || part == "omitzero" {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-func shouldOmitField(field reflect.StructField) bool {
-	tag := field.Tag.Get("json")
-	return tag == "-" || strings.HasPrefix(tag, "-,")
-}
-
 func get_ts_type_from_struct_tag(field reflect.StructField) string {
 	return field.Tag.Get("ts_type")
 }
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
+	"unicode/utf8"
 )
 
 type Validator interface{ Validate() error }
@@ -18,6 +21,101 @@ func IsValidationError(err error) bool {
 	return errors.As(err, &validationErr)
 }
 
+// Stable, machine-readable codes identifying which rule failed, so that
+// clients can branch on (or localize) failures without matching on
+// error messages.
+const (
+	CodeRequired          = "required"
+	CodeType              = "type"    // value is of the wrong kind for the rule
+	CodeInvalid           = "invalid" // misconfigured rule or unsupported target
+	CodeIn                = "in"
+	CodeNotIn             = "not_in"
+	CodeMutuallyExclusive = "mutually_exclusive"
+	CodeMutuallyRequired  = "mutually_required"
+	CodeOneOf             = "one_of"
+	CodeAnyOf             = "any_of"
+	CodeAllOf             = "all_of"
+	CodeUnique            = "unique"
+	CodePermittedChars    = "permitted_chars"
+	CodeEmail             = "email"
+	CodePattern           = "pattern"
+	CodeStartsWith        = "starts_with"
+	CodeEndsWith          = "ends_with"
+	CodeURL               = "url"
+	CodeCreditCard        = "credit_card"
+	CodePhone             = "phone"
+	CodeMin               = "min"
+	CodeMax               = "max"
+	CodeRange             = "range"
+	CodePasswordMinLen    = "password_min_len"
+	CodePasswordUpper     = "password_upper"
+	CodePasswordLower     = "password_lower"
+	CodePasswordDigit     = "password_digit"
+	CodePasswordSymbol    = "password_symbol"
+	CodePasswordEntropy   = "password_entropy"
+	CodeCustom            = "custom"
+)
+
+// RuleError is a single rule failure. Its Error method returns just the
+// human-readable message.
+type RuleError struct {
+	Label   string // the checker's label (for object fields, the field name)
+	Code    string // one of the Code* constants
+	Message string
+	Cause   error // the underlying error, if any (e.g., a parse failure)
+}
+
+func (e *RuleError) Error() string { return e.Message }
+func (e *RuleError) Unwrap() error { return e.Cause }
+
+// RuleErrors returns every rule failure within the error, including
+// those from nested validators, in order. Errors returned by custom
+// Validate methods that are not RuleErrors carry no code and are
+// skipped.
+func (e *ValidationError) RuleErrors() []*RuleError {
+	var ruleErrs []*RuleError
+	collectRuleErrors(e.Err, &ruleErrs)
+	return ruleErrs
+}
+
+// Codes returns the distinct codes of every rule failure within the
+// error, in order of first occurrence.
+func (e *ValidationError) Codes() []string {
+	var codes []string
+	for _, ruleErr := range e.RuleErrors() {
+		if !slices.Contains(codes, ruleErr.Code) {
+			codes = append(codes, ruleErr.Code)
+		}
+	}
+	return codes
+}
+
+// FieldCodes returns the codes of every rule failure within the error,
+// grouped by label.
+func (e *ValidationError) FieldCodes() map[string][]string {
+	fieldCodes := make(map[string][]string)
+	for _, ruleErr := range e.RuleErrors() {
+		if !slices.Contains(fieldCodes[ruleErr.Label], ruleErr.Code) {
+			fieldCodes[ruleErr.Label] = append(fieldCodes[ruleErr.Label], ruleErr.Code)
+		}
+	}
+	return fieldCodes
+}
+
+func collectRuleErrors(err error, ruleErrs *[]*RuleError) {
+	switch e := err.(type) {
+	case nil:
+	case *RuleError:
+		*ruleErrs = append(*ruleErrs, e)
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			collectRuleErrors(inner, ruleErrs)
+		}
+	case interface{ Unwrap() error }:
+		collectRuleErrors(e.Unwrap(), ruleErrs)
+	}
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// ANY CHECKER
 /////////////////////////////////////////////////////////////////////
@@ -30,6 +128,11 @@ type AnyChecker struct {
 
 	done   bool
 	errors []error
+	// Set when Optional found a zero value (see Default).
+	optionalZero bool
+	// See ShowValue and ObjectChecker.Redact.
+	showValue bool
+	redacted  bool
 }
 
 func newAnyChecker(label string, trueValue any, reflectValue reflect.Value) *AnyChecker {
@@ -53,13 +156,27 @@ func (c *AnyChecker) Error() error {
 
 func (c *AnyChecker) ok() { c.done = true }
 
-func (c *AnyChecker) fail(errMsg string) {
+func (c *AnyChecker) fail(code, errMsg string) {
 	c.done = true
-	c.errors = append(c.errors, errors.New(errMsg))
+	c.errors = append(c.errors, c.newRuleError(code, errMsg))
 }
 
-func (c *AnyChecker) failF(format string, args ...any) {
-	c.fail(fmt.Sprintf(format, args...))
+func (c *AnyChecker) failF(code, format string, args ...any) {
+	c.fail(code, fmt.Sprintf(format, args...))
+}
+
+func (c *AnyChecker) newRuleError(code, errMsg string) *RuleError {
+	if c.showValue {
+		errMsg += " (got " + c.displayValue() + ")"
+	}
+	return &RuleError{Label: c.label, Code: code, Message: errMsg}
+}
+
+// For messages that already include the value (via displayValue), so
+// that ShowValue does not repeat it.
+func (c *AnyChecker) failWithValueShown(code, errMsg string) {
+	c.done = true
+	c.errors = append(c.errors, &RuleError{Label: c.label, Code: code, Message: errMsg})
 }
 
 func (c *AnyChecker) init(required bool) *AnyChecker {
@@ -68,8 +185,9 @@ func (c *AnyChecker) init(required bool) *AnyChecker {
 	}
 	if isEffectivelyZero(c.reflectValue) {
 		if required {
-			c.fail(fmt.Sprintf("%s is required", c.label))
+			c.failF(CodeRequired, "%s is required", c.label)
 		} else {
+			c.optionalZero = true
 			c.ok()
 		}
 		return c
@@ -87,7 +205,10 @@ func (c *AnyChecker) init(required bool) *AnyChecker {
 
 type ObjectChecker struct {
 	AnyChecker
-	ChildCheckers []*AnyChecker
+	ChildCheckers  []*AnyChecker
+	matchedSchemas map[string][]int
+	showValues     bool
+	redactedFields []string
 }
 
 func (oc *ObjectChecker) Required(field string) *AnyChecker { return oc.validateField(field, true) }
@@ -113,6 +234,7 @@ func (oc *ObjectChecker) validateField(fieldName string, required bool) (c *AnyC
 	}
 	wrappedField := oc.getFieldValue(fieldName)
 	c = newAnyChecker(fieldName, wrappedField.trueValue, wrappedField.reflectValue)
+	oc.configureChild(c)
 	oc.ChildCheckers = append(oc.ChildCheckers, c)
 	if required {
 		c.Required()
@@ -144,6 +266,102 @@ func (oc *ObjectChecker) getFieldValue(fieldName string) (wrapped *fieldWrapper)
 	panic("this should never happen")
 }
 
+/////////////////////////////////////////////////////////////////////
+/////// VALUES IN ERRORS
+/////////////////////////////////////////////////////////////////////
+
+// Values longer than this (in runes, once formatted) are truncated in
+// error messages.
+const maxDisplayValueLen = 64
+
+const redactedDisplayValue = "[REDACTED]"
+
+// ShowValue opts the checker into appending the offending value to its
+// failure messages (e.g., "Age is required (got 0)"), to ease debugging.
+// Strings are quoted, and long values are truncated. Call it before any
+// rules (including Required and Optional), as it only affects failures
+// recorded after it. Messages are often returned to clients and logged,
+// so never use it on secrets; for objects, see WithValuesInErrors and
+// Redact.
+func (c *AnyChecker) ShowValue() *AnyChecker {
+	c.showValue = true
+	return c
+}
+
+// WithValuesInErrors is the object equivalent of ShowValue, applying to
+// every field checked after it is called. Fields named in Redact show
+// as "[REDACTED]" instead.
+func (oc *ObjectChecker) WithValuesInErrors() *ObjectChecker {
+	oc.showValues = true
+	return oc
+}
+
+// Redact masks the values of the given fields (e.g., "Password") in
+// error messages as "[REDACTED]", including in messages of rules that
+// always name the offending value (e.g., In), whether or not
+// WithValuesInErrors is set. Like WithValuesInErrors, it only applies to
+// fields checked after it is called.
+func (oc *ObjectChecker) Redact(fieldNames ...string) *ObjectChecker {
+	oc.redactedFields = append(oc.redactedFields, fieldNames...)
+	return oc
+}
+
+func (oc *ObjectChecker) configureChild(c *AnyChecker) {
+	c.showValue = oc.showValues
+	c.redacted = slices.Contains(oc.redactedFields, c.label)
+}
+
+func (c *AnyChecker) displayValue() string {
+	if c.redacted {
+		return redactedDisplayValue
+	}
+	return formatDisplayValue(c.reflectValue)
+}
+
+func formatDisplayValue(v reflect.Value) string {
+	v = safeDereference(v)
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || safeIsNil(v) || !v.CanInterface() {
+		return "<nil>"
+	}
+	if v.Kind() == reflect.String {
+		return strconv.Quote(truncateDisplayValue(v.String()))
+	}
+	return truncateDisplayValue(fmt.Sprintf("%v", v.Interface()))
+}
+
+func truncateDisplayValue(s string) string {
+	if utf8.RuneCountInString(s) <= maxDisplayValueLen {
+		return s
+	}
+	return string([]rune(s)[:maxDisplayValueLen]) + "..."
+}
+
+/////////////////////////////////////////////////////////////////////
+/////// STARTUP INVARIANTS
+/////////////////////////////////////////////////////////////////////
+
+// MustValid panics with the checker's *ValidationError if validation
+// failed. It is for startup invariants only (e.g., checking a config
+// struct in main or init), where an invalid value means the program
+// cannot run. Never use it on request input; use Error instead.
+func (c *AnyChecker) MustValid() {
+	if err := c.Error(); err != nil {
+		panic(err)
+	}
+}
+
+// MustValid panics with the checker's *ValidationError (including any
+// field errors) if validation failed. Like AnyChecker.MustValid, it is
+// for startup invariants only; never use it on request input.
+func (oc *ObjectChecker) MustValid() {
+	if err := oc.Error(); err != nil {
+		panic(err)
+	}
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// CORE ENTRY POINTS
 /////////////////////////////////////////////////////////////////////
@@ -164,13 +382,13 @@ func Any(label string, anything any) *AnyChecker {
 func Object(object any) *ObjectChecker {
 	oc := &ObjectChecker{}
 	if object == nil {
-		oc.fail("object cannot be nil")
+		oc.fail(CodeInvalid, "object cannot be nil")
 		return oc
 	}
 	reflectValue := reflect.ValueOf(object)
 	typeState := getTypeState(reflectValue)
 	if !typeState.isStructLike && !typeState.isMapWithStrKeysLike {
-		oc.failF("object must be a struct or a map with string keys (got %T)", object)
+		oc.failF(CodeInvalid, "object must be a struct or a map with string keys (got %T)", object)
 		return oc
 	}
 	oc.label = reflectValue.Type().String()
@@ -185,13 +403,53 @@ func Object(object any) *ObjectChecker {
 /////// UTILS
 /////////////////////////////////////////////////////////////////////
 
+// maxDepth is how deeply nested a value may be before the recursive walk
+// for nested Validators (run by Required and Optional, and on object
+// fields) stops descending and reports a CodeInvalid error instead. Values
+// that refer back to themselves (e.g., a child with a pointer to its
+// parent) are detected separately: each pointer, map, or slice is walked
+// at most once along any path, so cycles halt without an error.
+const maxDepth = 256
+
+type visitKey struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// Tracks the references on the current path of the walk, for cycle detection
+type recursiveWalker struct {
+	onPath map[visitKey]struct{}
+}
+
 func validateRecursive(label string, currentValue reflect.Value) []error {
+	w := &recursiveWalker{onPath: make(map[visitKey]struct{})}
+	return w.walk(label, currentValue, 0)
+}
+
+func (w *recursiveWalker) walk(label string, currentValue reflect.Value, depth int) []error {
 	var errs []error
 
 	if !currentValue.IsValid() || safeIsNil(currentValue) {
 		return errs
 	}
 
+	if depth > maxDepth {
+		return append(errs, &RuleError{
+			Label:   label,
+			Code:    CodeInvalid,
+			Message: fmt.Sprintf("%s exceeds the maximum validation depth of %d", label, maxDepth),
+		})
+	}
+
+	if key, ok := toVisitKey(currentValue); ok {
+		if _, seen := w.onPath[key]; seen {
+			return errs
+		}
+		w.onPath[key] = struct{}{}
+		defer delete(w.onPath, key)
+	}
+
 	validatedByDirectCall := false
 	validatorInterface := reflect.TypeOf((*Validator)(nil)).Elem()
 
@@ -240,7 +498,7 @@ func validateRecursive(label string, currentValue reflect.Value) []error {
 				continue
 			}
 			fieldLabel := fmt.Sprintf("%s.%s", label, field.Name)
-			if locErrs := validateRecursive(fieldLabel, fieldValue); len(locErrs) > 0 {
+			if locErrs := w.walk(fieldLabel, fieldValue, depth+1); len(locErrs) > 0 {
 				errs = append(errs, locErrs...)
 			}
 		}
@@ -262,10 +520,10 @@ func validateRecursive(label string, currentValue reflect.Value) []error {
 			}
 			mapLabel := fmt.Sprintf("%s[%s]", label, keyLabelPart)
 
-			if locErrs := validateRecursive(mapLabel+"(key)", key); len(locErrs) > 0 {
+			if locErrs := w.walk(mapLabel+"(key)", key, depth+1); len(locErrs) > 0 {
 				errs = append(errs, locErrs...)
 			}
-			if locErrs := validateRecursive(mapLabel+"(value)", val); len(locErrs) > 0 {
+			if locErrs := w.walk(mapLabel+"(value)", val, depth+1); len(locErrs) > 0 {
 				errs = append(errs, locErrs...)
 			}
 		}
@@ -276,7 +534,7 @@ func validateRecursive(label string, currentValue reflect.Value) []error {
 		for i := range baseValue.Len() {
 			elemValue := baseValue.Index(i)
 			elemLabel := fmt.Sprintf("%s[%d]", label, i)
-			if locErrs := validateRecursive(elemLabel, elemValue); len(locErrs) > 0 {
+			if locErrs := w.walk(elemLabel, elemValue, depth+1); len(locErrs) > 0 {
 				errs = append(errs, locErrs...)
 			}
 		}
@@ -285,6 +543,18 @@ func validateRecursive(label string, currentValue reflect.Value) []error {
 	return errs
 }
 
+// Only pointers, maps, and slices can form cycles. Slices are keyed by
+// length too, as a subslice shares its parent's data pointer.
+func toVisitKey(v reflect.Value) (visitKey, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return visitKey{typ: v.Type(), ptr: v.Pointer()}, true
+	case reflect.Slice:
+		return visitKey{typ: v.Type(), ptr: v.Pointer(), len: v.Len()}, true
+	}
+	return visitKey{}, false
+}
+
 func safeDereference(reflectValue reflect.Value) reflect.Value {
 	if reflectValue.Kind() == reflect.Ptr {
 		return reflectValue.Elem()
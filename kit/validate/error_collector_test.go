@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -681,6 +682,65 @@ func TestECEdgeCases(t *testing.T) {
 			t.Errorf("unexpected error for empty but non-required child field: %v", err)
 		}
 	})
+
+	t.Run("CyclicGraph", func(t *testing.T) {
+		a := &cyclicNode{Value: "A"}
+		b := &cyclicNode{Value: "B", Next: a}
+		a.Next = b
+		a.Self = []*cyclicNode{a, b}
+
+		if err := Any("node", a).Required().Error(); err != nil {
+			t.Errorf("unexpected error for valid cyclic graph: %v", err)
+		}
+
+		// Each invalid node is reported once per path, not forever
+		b.Value = ""
+		err := Any("node", a).Required().Error()
+		if err == nil {
+			t.Fatal("expected error for invalid node in cyclic graph")
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("expected *ValidationError, got %T", err)
+		}
+		if n := len(ve.RuleErrors()); n != 2 {
+			t.Errorf("expected 2 rule errors (via Next and Self), got %d: %v", n, err)
+		}
+	})
+
+	t.Run("MaxDepth", func(t *testing.T) {
+		head := &cyclicNode{Value: "0"}
+		cur := head
+		for i := 1; i <= maxDepth; i++ {
+			cur.Next = &cyclicNode{Value: fmt.Sprint(i)}
+			cur = cur.Next
+		}
+
+		err := Any("node", head).Required().Error()
+		if err == nil {
+			t.Fatal("expected error for value deeper than maxDepth")
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) || !slices.Equal(ve.Codes(), []string{CodeInvalid}) {
+			t.Errorf("expected a single %q code, got %v", CodeInvalid, err)
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("maximum validation depth of %d", maxDepth)) {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+}
+
+type cyclicNode struct {
+	Value string
+	Next  *cyclicNode
+	Self  []*cyclicNode
+}
+
+func (n *cyclicNode) Validate() error {
+	if n.Value == "" {
+		return &ValidationError{Err: &RuleError{Label: "Value", Code: CodeRequired, Message: "Value is required"}}
+	}
+	return nil
 }
 
 // Test custom validators
@@ -809,6 +869,63 @@ func TestValidationError(t *testing.T) {
 	})
 }
 
+func TestMustValid(t *testing.T) {
+	recoverValidationError := func(f func()) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err, _ = r.(error)
+				if err == nil {
+					t.Fatalf("expected panic value to be an error, got %v", r)
+				}
+			}
+		}()
+		f()
+		return nil
+	}
+
+	t.Run("AnyCheckerValid", func(t *testing.T) {
+		if err := recoverValidationError(func() { Any("port", 8080).Required().MustValid() }); err != nil {
+			t.Errorf("expected no panic, got %v", err)
+		}
+	})
+
+	t.Run("AnyCheckerInvalid", func(t *testing.T) {
+		err := recoverValidationError(func() { Any("port", 0).Required().MustValid() })
+		if !IsValidationError(err) {
+			t.Fatalf("expected panic with ValidationError, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "port is required") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("ObjectCheckerValid", func(t *testing.T) {
+		cfg := struct{ Name string }{Name: "app"}
+		if err := recoverValidationError(func() {
+			v := Object(cfg)
+			v.Required("Name")
+			v.MustValid()
+		}); err != nil {
+			t.Errorf("expected no panic, got %v", err)
+		}
+	})
+
+	t.Run("ObjectCheckerInvalidField", func(t *testing.T) {
+		cfg := struct{ Name string }{}
+		err := recoverValidationError(func() {
+			v := Object(cfg)
+			v.Required("Name")
+			v.MustValid()
+		})
+		if !IsValidationError(err) {
+			t.Fatalf("expected panic with ValidationError, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "Name is required") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+}
+
 type MyStruct struct {
 	Name string
 }
@@ -843,3 +960,85 @@ func TestSliceWithNilItems(t *testing.T) {
 		t.Error("expected no error for nil item in slice")
 	}
 }
+
+func TestValuesInErrors(t *testing.T) {
+	type Signup struct {
+		Name     string
+		Age      int
+		Role     string
+		Password string
+		PIN      int
+		Tags     []string
+	}
+	const secret = "hunter2-Secret"
+	const pin = 424242
+
+	t.Run("Off by default", func(t *testing.T) {
+		err := Any("Age", 0).Required().Error()
+		if err == nil || err.Error() != "Age is required" {
+			t.Errorf("Expected the plain message, got %v", err)
+		}
+	})
+
+	t.Run("ShowValue", func(t *testing.T) {
+		err := Any("Age", 5).ShowValue().Required().Min(18).Error()
+		if err == nil || err.Error() != "minimum permitted value for Age is 18, got 5" {
+			t.Errorf("Expected the value not to be repeated, got %v", err)
+		}
+		err = Any("Name", "ab").ShowValue().Required().Email().Error()
+		if err == nil || !strings.HasSuffix(err.Error(), `(got "ab")`) {
+			t.Errorf("Expected the quoted value, got %v", err)
+		}
+		long := strings.Repeat("x", 100)
+		err = Any("Name", long).ShowValue().In([]string{"a"}).Error()
+		if err == nil || strings.Contains(err.Error(), long) || strings.Contains(err.Error(), "(got") {
+			t.Errorf("Expected In to show the truncated value once, got %v", err)
+		}
+		err = Any("Name", long).ShowValue().StartsWith("a").Error()
+		if err == nil || strings.Contains(err.Error(), long) || !strings.Contains(err.Error(), "...") {
+			t.Errorf("Expected a truncated value, got %v", err)
+		}
+	})
+
+	t.Run("WithValuesInErrors", func(t *testing.T) {
+		s := Signup{Role: "root", Password: secret, PIN: pin, Tags: []string{secret, secret}}
+		oc := Object(&s).WithValuesInErrors().Redact("Password", "PIN", "Tags")
+		oc.Required("Name")
+		oc.Required("Age")
+		oc.Required("Role").In([]string{"user", "admin"})
+		oc.Required("Password").Min(20).In([]string{"x"}).PermittedChars("abc")
+		oc.Required("PIN").Max(1000)
+		oc.Required("Tags").Unique()
+		err := oc.Error()
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		msg := err.Error()
+		for _, want := range []string{`Name is required (got "")`, "Age is required (got 0)", `Role has an invalid value ("root")`} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("Expected %q in %q", want, msg)
+			}
+		}
+		if strings.Contains(msg, secret) || strings.Contains(msg, "424242") || strings.Contains(msg, "hunter") {
+			t.Errorf("Redacted value leaked: %q", msg)
+		}
+		if !strings.Contains(msg, "[REDACTED]") {
+			t.Errorf("Expected redacted placeholders in %q", msg)
+		}
+	})
+
+	t.Run("Redact without WithValuesInErrors", func(t *testing.T) {
+		oc := Object(map[string]any{"Password": secret}).Redact("Password")
+		oc.Required("Password").NotIn([]string{secret})
+		if err := oc.Error(); err == nil || strings.Contains(err.Error(), secret) {
+			t.Errorf("Expected NotIn to redact the value, got %v", err)
+		}
+	})
+
+	t.Run("Password is always redacted", func(t *testing.T) {
+		err := Any("Password", secret).ShowValue().Password(PasswordPolicy{MinLen: 30}).Error()
+		if err == nil || strings.Contains(err.Error(), secret) {
+			t.Errorf("Expected the password to be redacted, got %v", err)
+		}
+	})
+}
@@ -198,7 +198,7 @@ func TestValidatorChaining(t *testing.T) {
 	t.Run("ChainAfterFailure", func(t *testing.T) {
 		// Create an invalid ObjectChecker directly
 		oc := &ObjectChecker{}
-		oc.fail("preset error")
+		oc.fail(CodeInvalid, "preset error")
 
 		// Chains should not execute further validation
 		child := oc.Required("Field")
@@ -1,14 +1,19 @@
 package validate
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/mail"
 	"net/url"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/river-now/river/kit/set"
+	"golang.org/x/text/unicode/norm"
 )
 
 func (c *AnyChecker) If(condition bool, f func(*AnyChecker) *AnyChecker) *AnyChecker {
@@ -56,12 +61,12 @@ func (c *AnyChecker) validateAgainstSlice(valuesSlice any) bool {
 	}
 	base := safeDereference(reflect.ValueOf(valuesSlice))
 	if base.Kind() != reflect.Slice && base.Kind() != reflect.Array {
-		c.failF("%s is not a slice or array", c.label)
+		c.failF(CodeInvalid, "%s is not a slice or array", c.label)
 		c.done = true
 		return false
 	}
 	if base.Len() == 0 {
-		c.failF("%s is empty", c.label)
+		c.failF(CodeInvalid, "%s is empty", c.label)
 		c.done = true
 		return false
 	}
@@ -75,6 +80,15 @@ func (c *AnyChecker) validateAgainstSlice(valuesSlice any) bool {
 	return false
 }
 
+// The value as named by In and NotIn, which always include it in their
+// messages (formatted as by ShowValue if set, and masked if redacted).
+func (c *AnyChecker) inlineValue() string {
+	if c.redacted || c.showValue {
+		return c.displayValue()
+	}
+	return fmt.Sprintf("%v", c.trueValue)
+}
+
 // In validates that the value is in the permitted values slice
 func (c *AnyChecker) In(permittedValuesSlice any) *AnyChecker {
 	if c.done {
@@ -83,7 +97,7 @@ func (c *AnyChecker) In(permittedValuesSlice any) *AnyChecker {
 	if c.validateAgainstSlice(permittedValuesSlice) {
 		return c
 	}
-	c.failF("%s has an invalid value (%v)", c.label, c.trueValue)
+	c.failWithValueShown(CodeIn, fmt.Sprintf("%s has an invalid value (%s)", c.label, c.inlineValue()))
 	return c
 }
 
@@ -93,12 +107,37 @@ func (c *AnyChecker) NotIn(prohibitedValuesSlice any) *AnyChecker {
 		return c
 	}
 	if c.validateAgainstSlice(prohibitedValuesSlice) {
-		c.failF("%s has a prohibited value (%v)", c.label, c.trueValue)
+		c.failWithValueShown(CodeNotIn, fmt.Sprintf("%s has a prohibited value (%s)", c.label, c.inlineValue()))
 		return c
 	}
 	return c
 }
 
+// Custom runs an inline predicate against the value (for one-off checks
+// that don't warrant implementing Validator). If fn returns an error, it
+// is recorded as a failure with the label prefixed to its message, under
+// CodeCustom (or, if fn returns a *RuleError, under that error's code).
+func (c *AnyChecker) Custom(fn func(value any) error) *AnyChecker {
+	if c.done {
+		return c
+	}
+	if fn == nil {
+		c.failF(CodeInvalid, "custom validation function for %s is nil", c.label)
+		return c
+	}
+	err := fn(c.trueValue)
+	if err == nil {
+		return c
+	}
+	code := CodeCustom
+	var ruleErr *RuleError
+	if errors.As(err, &ruleErr) && ruleErr.Code != "" {
+		code = ruleErr.Code
+	}
+	c.failF(code, "%s: %v", c.label, err)
+	return c
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// RELATIONSHIPS BETWEEN OBJECT FIELDS
 /////////////////////////////////////////////////////////////////////
@@ -113,7 +152,7 @@ func (oc *ObjectChecker) MutuallyExclusive(label string, fields ...string) *Obje
 		}
 		return ""
 	}
-	return oc.validateFieldGroupConstraint(label, fields, f)
+	return oc.validateCodedFieldGroupConstraint(CodeMutuallyExclusive, label, fields, f)
 }
 
 func (oc *ObjectChecker) MutuallyRequired(label string, fields ...string) *ObjectChecker {
@@ -126,19 +165,23 @@ func (oc *ObjectChecker) MutuallyRequired(label string, fields ...string) *Objec
 		}
 		return ""
 	}
-	return oc.validateFieldGroupConstraint(label, fields, f)
+	return oc.validateCodedFieldGroupConstraint(CodeMutuallyRequired, label, fields, f)
 }
 
 type constraintFn func(truthyCount, totalFields int) string
 
 func (oc *ObjectChecker) validateFieldGroupConstraint(label string, fields []string, constraintFn constraintFn) *ObjectChecker {
+	return oc.validateCodedFieldGroupConstraint(CodeInvalid, label, fields, constraintFn)
+}
+
+func (oc *ObjectChecker) validateCodedFieldGroupConstraint(code, label string, fields []string, constraintFn constraintFn) *ObjectChecker {
 	if oc.done {
 		return oc
 	}
 	_, truthyCount := oc.validateFieldGroup(fields)
 	totalFields := len(fields)
 	if errMsgFExpectingLabel := constraintFn(truthyCount, totalFields); errMsgFExpectingLabel != "" {
-		oc.errors = append(oc.errors, fmt.Errorf(errMsgFExpectingLabel, label))
+		oc.errors = append(oc.errors, &RuleError{Label: label, Code: code, Message: fmt.Sprintf(errMsgFExpectingLabel, label)})
 	}
 	return oc
 }
@@ -155,6 +198,254 @@ func (oc *ObjectChecker) validateFieldGroup(fieldNames []string) (set.Set[string
 	return truthySet, truthyCount
 }
 
+/////////////////////////////////////////////////////////////////////
+/////// SCHEMA COMBINATORS
+/////////////////////////////////////////////////////////////////////
+
+// ObjectSchema is a candidate schema for OneOfSchemas, AnyOfSchemas, and
+// AllOfSchemas. It receives a fresh checker for the same object and
+// should chain the rules to apply (e.g., func(oc *ObjectChecker)
+// *ObjectChecker { oc.Required("Kind").In([]string{"card"}); return oc }).
+type ObjectSchema = func(*ObjectChecker) *ObjectChecker
+
+// OneOfSchemas passes only if the object satisfies exactly one of the
+// given schemas, like JSON Schema's oneOf. Use it for polymorphic
+// payloads, such as discriminated-union request bodies. On failure, the
+// error lists which schemas (by zero-based index) matched, if any. On
+// success, MatchedSchemas reports which one did.
+func (oc *ObjectChecker) OneOfSchemas(name string, schemas ...ObjectSchema) *ObjectChecker {
+	if oc.done {
+		return oc
+	}
+	matched, _ := oc.runSchemas(name, schemas)
+	if len(matched) != 1 {
+		oc.errors = append(oc.errors, &RuleError{Label: name, Code: CodeOneOf, Message: fmt.Sprintf(
+			"%s must match exactly one schema (matched %s)", name, describeMatchedSchemas(matched),
+		)})
+	}
+	return oc
+}
+
+// AnyOfSchemas passes if the object satisfies at least one of the given
+// schemas, like JSON Schema's anyOf. On success, MatchedSchemas reports
+// which ones did.
+func (oc *ObjectChecker) AnyOfSchemas(name string, schemas ...ObjectSchema) *ObjectChecker {
+	if oc.done {
+		return oc
+	}
+	if matched, _ := oc.runSchemas(name, schemas); len(matched) == 0 {
+		oc.errors = append(oc.errors, &RuleError{Label: name, Code: CodeAnyOf, Message: fmt.Sprintf(
+			"%s must match at least one schema (matched none)", name,
+		)})
+	}
+	return oc
+}
+
+// AllOfSchemas passes only if the object satisfies every given schema,
+// like JSON Schema's allOf. Unlike the other combinators, failures also
+// include the errors from each failing schema, so that clients can see
+// which rules were broken.
+func (oc *ObjectChecker) AllOfSchemas(name string, schemas ...ObjectSchema) *ObjectChecker {
+	if oc.done {
+		return oc
+	}
+	matched, errs := oc.runSchemas(name, schemas)
+	if len(matched) != len(schemas) {
+		failed := make([]int, 0, len(schemas)-len(matched))
+		for i := range schemas {
+			if !slices.Contains(matched, i) {
+				failed = append(failed, i)
+			}
+		}
+		oc.errors = append(oc.errors, &RuleError{Label: name, Code: CodeAllOf, Message: fmt.Sprintf(
+			"%s must match all schemas (failed %s)", name, describeMatchedSchemas(failed),
+		)})
+		oc.errors = append(oc.errors, errs...)
+	}
+	return oc
+}
+
+// MatchedSchemas returns the zero-based indexes of the schemas the object
+// satisfied in the combinator registered under name, or nil if there is
+// no such combinator.
+func (oc *ObjectChecker) MatchedSchemas(name string) []int {
+	return oc.matchedSchemas[name]
+}
+
+// Runs each schema against a fresh checker for the object, returning the
+// indexes of those that passed and the errors of those that failed
+func (oc *ObjectChecker) runSchemas(name string, schemas []ObjectSchema) (matched []int, errs []error) {
+	matched = []int{}
+	for i, schema := range schemas {
+		if err := schema(Object(oc.trueValue)).Error(); err != nil {
+			errs = append(errs, err)
+		} else {
+			matched = append(matched, i)
+		}
+	}
+	if oc.matchedSchemas == nil {
+		oc.matchedSchemas = make(map[string][]int)
+	}
+	oc.matchedSchemas[name] = matched
+	return matched, errs
+}
+
+func describeMatchedSchemas(indexes []int) string {
+	if len(indexes) == 0 {
+		return "none"
+	}
+	strs := make([]string, len(indexes))
+	for i, idx := range indexes {
+		strs[i] = fmt.Sprint(idx)
+	}
+	if len(strs) == 1 {
+		return "schema " + strs[0]
+	}
+	return "schemas " + strings.Join(strs, ", ")
+}
+
+/////////////////////////////////////////////////////////////////////
+/////// MAP KEYS AND VALUES
+/////////////////////////////////////////////////////////////////////
+
+// EachKey runs rule against every key of a map object (in sorted order),
+// for dynamic objects whose keys aren't known ahead of time. Each key
+// gets its own checker, labeled with the key itself, so failures are
+// reported per key. As keys are always present, rule should just chain
+// the rules to apply (e.g., func(c *AnyChecker) *AnyChecker { return
+// c.Regex(re) }).
+func (oc *ObjectChecker) EachKey(rule func(*AnyChecker) *AnyChecker) *ObjectChecker {
+	return oc.eachMapEntry("EachKey", rule, false)
+}
+
+// EachValue runs rule against every value of a map object (in sorted key
+// order). Each value gets its own checker, labeled with its key, so
+// failures are reported per key. Unlike with EachKey, the checker is not
+// yet initialized, so rule should start with Required (to also reject
+// zero values) or Optional (e.g., func(c *AnyChecker) *AnyChecker {
+// return c.Required().Max(10) }).
+func (oc *ObjectChecker) EachValue(rule func(*AnyChecker) *AnyChecker) *ObjectChecker {
+	return oc.eachMapEntry("EachValue", rule, true)
+}
+
+func (oc *ObjectChecker) eachMapEntry(
+	ruleName string,
+	rule func(*AnyChecker) *AnyChecker,
+	values bool,
+) *ObjectChecker {
+	if oc.done {
+		return oc
+	}
+	if !oc.isMapWithStrKeysLike {
+		oc.errors = append(oc.errors, oc.newRuleError(CodeInvalid, fmt.Sprintf("%s requires a map with string keys (got %s)", ruleName, oc.label)))
+		return oc
+	}
+	if rule == nil {
+		oc.errors = append(oc.errors, oc.newRuleError(CodeInvalid, fmt.Sprintf("%s rule for %s is nil", ruleName, oc.label)))
+		return oc
+	}
+	keys := oc.baseReflectValue.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	for _, key := range keys {
+		v := key
+		if values {
+			v = oc.baseReflectValue.MapIndex(key)
+		}
+		// Values of maps like map[string]any come wrapped in an interface
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			v = v.Elem()
+		}
+		var trueValue any
+		if v.IsValid() && v.CanInterface() {
+			trueValue = v.Interface()
+		}
+		c := newAnyChecker(key.String(), trueValue, v)
+		oc.configureChild(c)
+		oc.ChildCheckers = append(oc.ChildCheckers, c)
+		rule(c)
+	}
+	return oc
+}
+
+/////////////////////////////////////////////////////////////////////
+/////// UNIQUENESS
+/////////////////////////////////////////////////////////////////////
+
+// Unique validates that the value is a slice or array with no duplicate
+// elements (after dereferencing pointers), reporting each duplicate value
+// along with the indices at which it appears. Elements must be
+// comparable; for complex elements (e.g., structs containing slices, or
+// structs that should be compared by ID), use UniqueBy.
+func (c *AnyChecker) Unique() *AnyChecker {
+	return c.validateUnique("Unique", nil)
+}
+
+// UniqueBy validates that no two elements of the slice or array value
+// share a key, as returned by keyFn (which must return a comparable
+// value). keyFn receives each element as is (e.g., a struct, or a
+// pointer to one).
+func (c *AnyChecker) UniqueBy(keyFn func(elem any) any) *AnyChecker {
+	if keyFn == nil {
+		if !c.done {
+			c.failF(CodeInvalid, "UniqueBy key function for %s is nil", c.label)
+		}
+		return c
+	}
+	return c.validateUnique("UniqueBy", keyFn)
+}
+
+func (c *AnyChecker) validateUnique(ruleName string, keyFn func(elem any) any) *AnyChecker {
+	if c.done {
+		return c
+	}
+	base := c.baseReflectValue
+	if base.Kind() != reflect.Slice && base.Kind() != reflect.Array {
+		c.failF(CodeType, "cannot apply %s to type %s for %s", ruleName, base.Kind(), c.label)
+		return c
+	}
+	indicesByKey := make(map[any][]int, base.Len())
+	var keysInOrder []any
+	for i := range base.Len() {
+		elem := base.Index(i)
+		var key any
+		if keyFn != nil {
+			key = keyFn(elem.Interface())
+		} else if elem = safeDereference(elem); elem.IsValid() {
+			key = elem.Interface()
+		}
+		if key != nil && !reflect.ValueOf(key).Comparable() {
+			c.failF(CodeInvalid, "elements of %s are not comparable (%T); use UniqueBy", c.label, key)
+			return c
+		}
+		if _, seen := indicesByKey[key]; !seen {
+			keysInOrder = append(keysInOrder, key)
+		}
+		indicesByKey[key] = append(indicesByKey[key], i)
+	}
+	var duplicates []string
+	for _, key := range keysInOrder {
+		if indices := indicesByKey[key]; len(indices) > 1 {
+			var shownKey any = key
+			if c.redacted {
+				shownKey = redactedDisplayValue
+			}
+			duplicates = append(duplicates, fmt.Sprintf("%v at indices %s", shownKey, joinInts(indices)))
+		}
+	}
+	if len(duplicates) > 0 {
+		c.failWithValueShown(CodeUnique, fmt.Sprintf("%s has duplicate values (%s)", c.label, strings.Join(duplicates, "; ")))
+	}
+	return c
+}
+
+func joinInts(ints []int) string {
+	strs := make([]string, len(ints))
+	for i, n := range ints {
+		strs[i] = fmt.Sprint(n)
+	}
+	return strings.Join(strs, ", ")
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// STRINGS
 /////////////////////////////////////////////////////////////////////
@@ -165,7 +456,7 @@ func (c *AnyChecker) validateStr() (trueStr string, ok bool) {
 	}
 	base := safeDereference(c.reflectValue)
 	if base.Kind() != reflect.String {
-		c.failF("%s is not string-like", c.label)
+		c.failF(CodeType, "%s is not string-like", c.label)
 		return "", false
 	}
 	return base.String(), true
@@ -185,7 +476,11 @@ func (c *AnyChecker) PermittedChars(allowedChars string) *AnyChecker {
 	}
 	for _, char := range str {
 		if !allowedCharsSet.Contains(char) {
-			c.failF("%s contains invalid character: %q", c.label, char)
+			if c.redacted {
+				c.failF(CodePermittedChars, "%s contains an invalid character", c.label)
+			} else {
+				c.failF(CodePermittedChars, "%s contains invalid character: %q", c.label, char)
+			}
 			return c
 		}
 	}
@@ -201,11 +496,11 @@ func (c *AnyChecker) Email() *AnyChecker {
 		return c
 	}
 	if str == "" {
-		c.failF("%s is required", c.label)
+		c.failF(CodeRequired, "%s is required", c.label)
 		return c
 	}
 	if _, err := mail.ParseAddress(str); err != nil {
-		c.failF("%s must be a valid email address", c.label)
+		c.failF(CodeEmail, "%s must be a valid email address", c.label)
 	}
 	return c
 }
@@ -215,7 +510,7 @@ func (c *AnyChecker) Regex(regex *regexp.Regexp) *AnyChecker {
 		return c
 	}
 	if regex == nil {
-		c.failF("regexp pattern for %s validation is nil", c.label)
+		c.failF(CodeInvalid, "regexp pattern for %s validation is nil", c.label)
 		return c
 	}
 	str, ok := c.validateStr()
@@ -223,7 +518,7 @@ func (c *AnyChecker) Regex(regex *regexp.Regexp) *AnyChecker {
 		return c
 	}
 	if !regex.MatchString(str) {
-		c.failF("%s does not match required pattern", c.label)
+		c.failF(CodePattern, "%s does not match required pattern", c.label)
 	}
 	return c
 }
@@ -237,7 +532,7 @@ func (c *AnyChecker) StartsWith(prefix string) *AnyChecker {
 		return c
 	}
 	if !strings.HasPrefix(str, prefix) {
-		c.failF("%s must start with %s", c.label, prefix)
+		c.failF(CodeStartsWith, "%s must start with %s", c.label, prefix)
 	}
 	return c
 }
@@ -251,7 +546,7 @@ func (c *AnyChecker) EndsWith(suffix string) *AnyChecker {
 		return c
 	}
 	if !strings.HasSuffix(str, suffix) {
-		c.failF("%s must end with %s", c.label, suffix)
+		c.failF(CodeEndsWith, "%s must end with %s", c.label, suffix)
 	}
 	return c
 }
@@ -265,11 +560,441 @@ func (c *AnyChecker) URL() *AnyChecker {
 		return c
 	}
 	if _, err := url.ParseRequestURI(str); err != nil {
-		c.failF("%s must be a valid URL", c.label)
+		c.failF(CodeURL, "%s must be a valid URL", c.label)
+	}
+	return c
+}
+
+// CardNetwork identifies a payment card network, as detected from a
+// card number's issuer identification prefix.
+type CardNetwork string
+
+const (
+	CardVisa       CardNetwork = "visa"
+	CardMastercard CardNetwork = "mastercard"
+	CardAmex       CardNetwork = "amex"
+	CardDiscover   CardNetwork = "discover"
+	CardDinersClub CardNetwork = "diners_club"
+	CardJCB        CardNetwork = "jcb"
+	CardUnionPay   CardNetwork = "unionpay"
+)
+
+// CreditCard validates that the value is a payment card number that
+// passes the Luhn checksum and belongs to a known network. Spaces and
+// dashes are ignored. If any accepted networks are passed, the card
+// must belong to one of them.
+func (c *AnyChecker) CreditCard(accepted ...CardNetwork) *AnyChecker {
+	if c.done {
+		return c
+	}
+	str, ok := c.validateStr()
+	if !ok {
+		return c
+	}
+	digits, ok := stripSeparators(str, " -")
+	if !ok || !luhnValid(digits) {
+		c.failF(CodeCreditCard, "%s must be a valid card number", c.label)
+		return c
+	}
+	network := DetectCardNetwork(digits)
+	if network == "" {
+		c.failF(CodeCreditCard, "%s must be a valid card number", c.label)
+		return c
+	}
+	if len(accepted) > 0 && !slices.Contains(accepted, network) {
+		c.failF(CodeCreditCard, "%s card network is not accepted", c.label)
+	}
+	return c
+}
+
+// Phone validates that the value is a phone number in E.164 shape: a
+// country calling code and subscriber number totalling at most 15
+// digits. Spaces, dashes, dots, and parentheses are ignored. If region
+// is empty, the number must be in international format (leading "+").
+// Otherwise, region is an ISO 3166-1 alpha-2 code (e.g., "US"), national
+// format numbers are accepted for that region, and international format
+// numbers must use its calling code. This is a shape check only; it
+// does not know which number ranges are actually assigned.
+func (c *AnyChecker) Phone(region string) *AnyChecker {
+	if c.done {
+		return c
+	}
+	callingCode := ""
+	if region != "" {
+		var known bool
+		callingCode, known = regionCallingCodes[strings.ToUpper(region)]
+		if !known {
+			c.failF(CodeInvalid, "unknown phone region %q for %s validation", region, c.label)
+			return c
+		}
+	}
+	str, ok := c.validateStr()
+	if !ok {
+		return c
+	}
+	if !isE164Shaped(str, callingCode) {
+		c.failF(CodePhone, "%s must be a valid phone number", c.label)
+	}
+	return c
+}
+
+// DetectCardNetwork returns the network a card number belongs to based
+// on its prefix and length, or an empty string if it is not recognized.
+// The number must consist of digits only. It does not check the Luhn
+// checksum.
+func DetectCardNetwork(digits string) CardNetwork {
+	n := len(digits)
+	prefix := func(l int) int {
+		if n < l {
+			return -1
+		}
+		v := 0
+		for _, d := range digits[:l] {
+			v = v*10 + int(d-'0')
+		}
+		return v
+	}
+	switch p2, p3, p4 := prefix(2), prefix(3), prefix(4); {
+	case p2 == 34 || p2 == 37:
+		if n == 15 {
+			return CardAmex
+		}
+	case p2 >= 51 && p2 <= 55, p4 >= 2221 && p4 <= 2720:
+		if n == 16 {
+			return CardMastercard
+		}
+	case p4 == 6011, p2 == 65, p3 >= 644 && p3 <= 649:
+		if n >= 16 && n <= 19 {
+			return CardDiscover
+		}
+	case p4 >= 3528 && p4 <= 3589:
+		if n >= 16 && n <= 19 {
+			return CardJCB
+		}
+	case p3 >= 300 && p3 <= 305, p2 == 36, p2 == 38, p2 == 39:
+		if n >= 14 && n <= 19 {
+			return CardDinersClub
+		}
+	case p2 == 62:
+		if n >= 16 && n <= 19 {
+			return CardUnionPay
+		}
+	case digits != "" && digits[0] == '4':
+		if n == 13 || n == 16 || n == 19 {
+			return CardVisa
+		}
+	}
+	return ""
+}
+
+func luhnValid(digits string) bool {
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// stripSeparators removes any of the separator chars from str and
+// reports whether what remains is a non-empty string of ASCII digits.
+func stripSeparators(str, separators string) (string, bool) {
+	var b strings.Builder
+	b.Grow(len(str))
+	for _, r := range str {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(separators, r):
+		default:
+			return "", false
+		}
+	}
+	return b.String(), b.Len() > 0
+}
+
+func isE164Shaped(str, callingCode string) bool {
+	str = strings.TrimSpace(str)
+	international := strings.HasPrefix(str, "+")
+	if international {
+		str = str[1:]
+	} else if strings.HasPrefix(str, "00") && callingCode != "" {
+		international, str = true, str[2:]
+	}
+	digits, ok := stripSeparators(str, " -.()")
+	if !ok {
+		return false
+	}
+	switch {
+	case international:
+		if digits[0] == '0' {
+			return false
+		}
+		if callingCode != "" && !strings.HasPrefix(digits, callingCode) {
+			return false
+		}
+	case callingCode == "":
+		return false
+	case callingCode == "1":
+		// NANP national numbers may carry the "1" trunk prefix
+		digits = "1" + strings.TrimPrefix(digits, "1")
+	default:
+		// drop the national trunk prefix, if any
+		digits = callingCode + strings.TrimPrefix(digits, "0")
+	}
+	if callingCode == "1" {
+		// NANP: 10-digit national numbers whose area code and exchange
+		// do not start with 0 or 1
+		return len(digits) == 11 && digits[1] >= '2' && digits[4] >= '2'
+	}
+	return len(digits) >= 8 && len(digits) <= 15
+}
+
+// Calling codes for commonly used regions. Regions sharing a calling
+// code (e.g., NANP) are validated identically.
+var regionCallingCodes = map[string]string{
+	"AE": "971", "AR": "54", "AT": "43", "AU": "61", "BE": "32",
+	"BR": "55", "CA": "1", "CH": "41", "CL": "56", "CN": "86",
+	"CO": "57", "CZ": "420", "DE": "49", "DK": "45", "EG": "20",
+	"ES": "34", "FI": "358", "FR": "33", "GB": "44", "GR": "30",
+	"HK": "852", "HU": "36", "ID": "62", "IE": "353", "IL": "972",
+	"IN": "91", "IT": "39", "JP": "81", "KE": "254", "KR": "82",
+	"MX": "52", "MY": "60", "NG": "234", "NL": "31", "NO": "47",
+	"NZ": "64", "PE": "51", "PH": "63", "PK": "92", "PL": "48",
+	"PT": "351", "RO": "40", "RU": "7", "SA": "966", "SE": "46",
+	"SG": "65", "TH": "66", "TR": "90", "TW": "886", "UA": "380",
+	"US": "1", "VN": "84", "ZA": "27",
+}
+
+/////////////////////////////////////////////////////////////////////
+/////// TRANSFORMERS
+/////////////////////////////////////////////////////////////////////
+
+// Transformers normalize a string value in place before subsequent
+// rules run. They only have an effect when the checker targets an
+// addressable value, such as a field of a struct passed to Object by
+// pointer (e.g., Object(&s)) or a value passed to Any by pointer. On
+// non-addressable values (including map values) they are no-ops. Note
+// that the presence check performed by Required/Optional runs before
+// any transformers you chain afterwards.
+
+func (c *AnyChecker) Trim() *AnyChecker {
+	return c.transformStr(strings.TrimSpace)
+}
+
+func (c *AnyChecker) ToLower() *AnyChecker {
+	return c.transformStr(strings.ToLower)
+}
+
+func (c *AnyChecker) ToUpper() *AnyChecker {
+	return c.transformStr(strings.ToUpper)
+}
+
+// NormalizeUnicode converts the value to Unicode Normalization Form C.
+func (c *AnyChecker) NormalizeUnicode() *AnyChecker {
+	return c.transformStr(norm.NFC.String)
+}
+
+func (c *AnyChecker) transformStr(fn func(string) string) *AnyChecker {
+	if c.done {
+		return c
+	}
+	base := safeDereference(c.reflectValue)
+	if base.Kind() != reflect.String {
+		c.failF(CodeType, "%s is not string-like", c.label)
+		return c
+	}
+	if !base.CanSet() {
+		return c
+	}
+	base.SetString(fn(base.String()))
+	if c.reflectValue.CanInterface() {
+		c.trueValue = c.reflectValue.Interface()
 	}
 	return c
 }
 
+/////////////////////////////////////////////////////////////////////
+/////// DEFAULTS
+/////////////////////////////////////////////////////////////////////
+
+// Default assigns v to the value if it is zero, so that subsequent rules
+// (and the caller) see v instead. It is meant to be chained directly
+// after Optional (e.g., Optional("Sort").Default("asc").In(sorts)), and
+// like the transformers, it only has an effect on addressable values. v
+// must be assignable to the value's type, or share its underlying kind
+// (e.g., a string for a named string type). For pointer values, v may be
+// either the pointer type or its element type.
+func (c *AnyChecker) Default(v any) *AnyChecker {
+	if c.done && !c.optionalZero {
+		return c
+	}
+	if !isEffectivelyZero(c.reflectValue) {
+		return c
+	}
+	target := c.reflectValue
+	if !target.IsValid() {
+		return c
+	}
+	val, ok := defaultValueFor(target.Type(), v)
+	if !ok {
+		c.optionalZero = false
+		c.failF(CodeType, "default value for %s must be of type %s (got %T)", c.label, target.Type(), v)
+		return c
+	}
+	if target.Kind() == reflect.Ptr && !target.IsNil() {
+		target = target.Elem()
+		val = val.Elem()
+	}
+	if !target.CanSet() {
+		return c
+	}
+	target.Set(val)
+	if c.reflectValue.CanInterface() {
+		c.trueValue = c.reflectValue.Interface()
+	}
+	c.typeState = getTypeState(c.reflectValue)
+	c.baseReflectValue = safeDereference(c.reflectValue)
+	c.done = false
+	c.optionalZero = false
+	return c.init(false)
+}
+
+// Returns v as a value of type t (allocating a pointer if t is a pointer
+// to v's type), or false if v is not compatible with t.
+func defaultValueFor(t reflect.Type, v any) (reflect.Value, bool) {
+	if v == nil {
+		return reflect.Value{}, false
+	}
+	val := reflect.ValueOf(v)
+	if converted, ok := convertDefault(t, val); ok {
+		if t.Kind() == reflect.Ptr && converted.IsNil() {
+			return reflect.Value{}, false
+		}
+		return converted, true
+	}
+	if t.Kind() == reflect.Ptr {
+		if elem, ok := convertDefault(t.Elem(), val); ok {
+			ptr := reflect.New(t.Elem())
+			ptr.Elem().Set(elem)
+			return ptr, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func convertDefault(t reflect.Type, val reflect.Value) (reflect.Value, bool) {
+	if val.Type().AssignableTo(t) {
+		return val, true
+	}
+	if val.Kind() == t.Kind() && val.Type().ConvertibleTo(t) {
+		return val.Convert(t), true
+	}
+	return reflect.Value{}, false
+}
+
+// PasswordPolicy describes the requirements enforced by Password. Zero
+// values disable the corresponding requirement.
+type PasswordPolicy struct {
+	MinLen         int // minimum length, in characters (runes)
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSymbol  bool    // any character that is not a letter or digit
+	MinEntropyBits float64 // estimated as length * log2(charset size)
+}
+
+// Password validates a string against a PasswordPolicy, reporting every
+// unmet requirement rather than just the first. The value is always
+// redacted from error messages (see ShowValue).
+func (c *AnyChecker) Password(policy PasswordPolicy) *AnyChecker {
+	if c.done {
+		return c
+	}
+	c.redacted = true
+	str, ok := c.validateStr()
+	if !ok {
+		return c
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	length := 0
+	for _, char := range str {
+		length++
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	var errs []error
+	addErr := func(code, format string, args ...any) {
+		errs = append(errs, c.newRuleError(code, fmt.Sprintf(format, args...)))
+	}
+	if length < policy.MinLen {
+		addErr(CodePasswordMinLen, "%s must be at least %d characters long", c.label, policy.MinLen)
+	}
+	if policy.RequireUpper && !hasUpper {
+		addErr(CodePasswordUpper, "%s must contain an uppercase letter", c.label)
+	}
+	if policy.RequireLower && !hasLower {
+		addErr(CodePasswordLower, "%s must contain a lowercase letter", c.label)
+	}
+	if policy.RequireDigit && !hasDigit {
+		addErr(CodePasswordDigit, "%s must contain a digit", c.label)
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		addErr(CodePasswordSymbol, "%s must contain a symbol", c.label)
+	}
+	if policy.MinEntropyBits > 0 {
+		if bits := estimatePasswordEntropy(length, hasUpper, hasLower, hasDigit, hasSymbol); bits < policy.MinEntropyBits {
+			addErr(CodePasswordEntropy, "%s is too weak (estimated entropy %.1f bits, need %.1f)", c.label, bits, policy.MinEntropyBits)
+		}
+	}
+	if len(errs) > 0 {
+		c.done = true
+		c.errors = append(c.errors, errs...)
+	}
+	return c
+}
+
+// Simple charset-size heuristic: each character is assumed to be drawn
+// uniformly from the union of the character classes present.
+func estimatePasswordEntropy(length int, hasUpper, hasLower, hasDigit, hasSymbol bool) float64 {
+	charsetSize := 0
+	if hasUpper {
+		charsetSize += 26
+	}
+	if hasLower {
+		charsetSize += 26
+	}
+	if hasDigit {
+		charsetSize += 10
+	}
+	if hasSymbol {
+		charsetSize += 33 // printable ASCII punctuation plus space
+	}
+	if charsetSize == 0 {
+		return 0
+	}
+	return float64(length) * math.Log2(float64(charsetSize))
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// NUMERIC
 /////////////////////////////////////////////////////////////////////
@@ -281,10 +1006,10 @@ func (c *AnyChecker) Min(min float64) *AnyChecker {
 	f1 := func(val float64) bool {
 		return val >= min
 	}
-	f2 := func(typeName string, val float64) string {
+	f2 := func(typeName string, val any) string {
 		return fmt.Sprintf("minimum permitted %s for %s is %v, got %v", typeName, c.label, min, val)
 	}
-	return c.validateNumeric(f1, f2)
+	return c.validateNumeric(CodeMin, f1, f2)
 }
 
 func (c *AnyChecker) Max(max float64) *AnyChecker {
@@ -294,10 +1019,10 @@ func (c *AnyChecker) Max(max float64) *AnyChecker {
 	f1 := func(val float64) bool {
 		return val <= max
 	}
-	f2 := func(typeName string, val float64) string {
+	f2 := func(typeName string, val any) string {
 		return fmt.Sprintf("maximum permitted %s for %s is %v, got %v", typeName, c.label, max, val)
 	}
-	return c.validateNumeric(f1, f2)
+	return c.validateNumeric(CodeMax, f1, f2)
 }
 
 func (c *AnyChecker) RangeInclusive(min, max float64) *AnyChecker {
@@ -307,10 +1032,10 @@ func (c *AnyChecker) RangeInclusive(min, max float64) *AnyChecker {
 	f1 := func(val float64) bool {
 		return val >= min && val <= max
 	}
-	f2 := func(typeName string, val float64) string {
+	f2 := func(typeName string, val any) string {
 		return fmt.Sprintf("permitted %s range for %s is [%v, %v], got %v", typeName, c.label, min, max, val)
 	}
-	return c.validateNumeric(f1, f2)
+	return c.validateNumeric(CodeRange, f1, f2)
 }
 
 func (c *AnyChecker) RangeExclusive(min, max float64) *AnyChecker {
@@ -320,29 +1045,33 @@ func (c *AnyChecker) RangeExclusive(min, max float64) *AnyChecker {
 	f1 := func(val float64) bool {
 		return val > min && val < max
 	}
-	f2 := func(typeName string, val float64) string {
+	f2 := func(typeName string, val any) string {
 		return fmt.Sprintf("permitted %s range for %s is (%v, %v), got %v", typeName, c.label, min, max, val)
 	}
-	return c.validateNumeric(f1, f2)
+	return c.validateNumeric(CodeRange, f1, f2)
 }
 
 type checkFn func(float64) bool
-type getErrorMsg func(typeName string, val float64) string
+type getErrorMsg func(typeName string, val any) string
 
-func (c *AnyChecker) validateNumeric(checkFn checkFn, getErrorMsg getErrorMsg) *AnyChecker {
+func (c *AnyChecker) validateNumeric(code string, checkFn checkFn, getErrorMsg getErrorMsg) *AnyChecker {
 	if c.done {
 		return c
 	}
 	trueValue, nature, ok := extractNumericFromReflectValue(c.baseReflectValue)
 	if !ok {
 		c.failF(
-			"cannot apply numeric check to type %s for %s",
+			CodeType, "cannot apply numeric check to type %s for %s",
 			c.baseReflectValue.Kind(), c.label,
 		)
 		return c
 	}
 	if ok = checkFn(trueValue); !ok {
-		c.fail(getErrorMsg(nature, trueValue))
+		var shownValue any = trueValue
+		if c.redacted && nature == "value" {
+			shownValue = redactedDisplayValue
+		}
+		c.failWithValueShown(code, getErrorMsg(nature, shownValue))
 	}
 	return c
 }
@@ -1,7 +1,11 @@
 package validate
 
 import (
+	"errors"
+	"reflect"
 	"regexp"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -335,6 +339,107 @@ func TestMutuallyExclusiveFields(t *testing.T) {
 	})
 }
 
+func TestSchemaCombinators(t *testing.T) {
+	type Payment struct {
+		Kind   string
+		Card   string
+		IBAN   string
+		Amount int
+	}
+
+	card := func(oc *ObjectChecker) *ObjectChecker {
+		oc.Required("Kind").In([]string{"card"})
+		oc.Required("Card")
+		return oc
+	}
+	bank := func(oc *ObjectChecker) *ObjectChecker {
+		oc.Required("IBAN")
+		return oc
+	}
+	positive := func(oc *ObjectChecker) *ObjectChecker {
+		oc.Required("Amount").Min(1)
+		return oc
+	}
+
+	t.Run("OneOf zero matches", func(t *testing.T) {
+		oc := Object(Payment{Kind: "cash"}).OneOfSchemas("payment", card, bank)
+		err := oc.Error()
+		if err == nil {
+			t.Fatal("expected error when no schema matches")
+		}
+		if !strings.Contains(err.Error(), "payment must match exactly one schema (matched none)") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+		if codes := err.(*ValidationError).Codes(); !slices.Equal(codes, []string{CodeOneOf}) {
+			t.Errorf("expected only %q, got %v", CodeOneOf, codes)
+		}
+		if got := oc.MatchedSchemas("payment"); len(got) != 0 {
+			t.Errorf("expected no matched schemas, got %v", got)
+		}
+	})
+
+	t.Run("OneOf exactly one match", func(t *testing.T) {
+		oc := Object(&Payment{Kind: "card", Card: "4242"}).OneOfSchemas("payment", bank, card)
+		if err := oc.Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := oc.MatchedSchemas("payment"); !slices.Equal(got, []int{1}) {
+			t.Errorf("expected schema 1 to match, got %v", got)
+		}
+	})
+
+	t.Run("OneOf multiple matches", func(t *testing.T) {
+		oc := Object(Payment{Kind: "card", Card: "4242", IBAN: "DE89"}).OneOfSchemas("payment", card, bank)
+		err := oc.Error()
+		if err == nil || !strings.Contains(err.Error(), "(matched schemas 0, 1)") {
+			t.Errorf("expected error listing both matches, got %v", err)
+		}
+	})
+
+	t.Run("OneOf with map objects", func(t *testing.T) {
+		err := Object(map[string]any{"IBAN": "DE89"}).OneOfSchemas("payment", card, bank).Error()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("AnyOf", func(t *testing.T) {
+		oc := Object(Payment{Kind: "card", Card: "4242", IBAN: "DE89"}).AnyOfSchemas("payment", card, bank)
+		if err := oc.Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := oc.MatchedSchemas("payment"); !slices.Equal(got, []int{0, 1}) {
+			t.Errorf("expected both schemas to match, got %v", got)
+		}
+
+		err := Object(Payment{}).AnyOfSchemas("payment", card, bank).Error()
+		if err == nil || !strings.Contains(err.Error(), "must match at least one schema") {
+			t.Errorf("expected AnyOf error, got %v", err)
+		}
+	})
+
+	t.Run("AllOf", func(t *testing.T) {
+		if err := Object(Payment{IBAN: "DE89", Amount: 5}).AllOfSchemas("payment", bank, positive).Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		err := Object(Payment{IBAN: "DE89"}).AllOfSchemas("payment", bank, positive).Error()
+		if err == nil || !strings.Contains(err.Error(), "payment must match all schemas (failed schema 1)") {
+			t.Fatalf("expected AllOf error, got %v", err)
+		}
+		codes := err.(*ValidationError).Codes()
+		if !slices.Contains(codes, CodeAllOf) || !slices.Contains(codes, CodeRequired) {
+			t.Errorf("expected the failing schema's codes to be included, got %v", codes)
+		}
+	})
+
+	t.Run("Unknown name", func(t *testing.T) {
+		if got := Object(Payment{}).MatchedSchemas("nope"); got != nil {
+			t.Errorf("expected nil, got %v", got)
+		}
+	})
+}
+
 func TestMutuallyRequiredFields(t *testing.T) {
 	type TestStruct struct {
 		Field1 string
@@ -545,6 +650,150 @@ func TestURLValidation(t *testing.T) {
 	})
 }
 
+func TestCreditCardValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		accepted []CardNetwork
+		wantErr  bool
+	}{
+		{"Valid Visa", "4111111111111111", nil, false},
+		{"Valid Visa with spaces", "4111 1111 1111 1111", nil, false},
+		{"Valid Mastercard with dashes", "5555-5555-5555-4444", nil, false},
+		{"Valid Mastercard 2-series", "2223003122003222", nil, false},
+		{"Valid Amex", "378282246310005", nil, false},
+		{"Valid Discover", "6011111111111117", nil, false},
+		{"Valid JCB", "3530111333300000", nil, false},
+		{"Valid Diners Club", "30569309025904", nil, false},
+		{"Failed Luhn", "4111111111111112", nil, true},
+		{"Unknown network", "9999999999999995", nil, true},
+		{"Wrong length for network", "411111111111119", nil, true},
+		{"Letters", "4111-1111-1111-111a", nil, true},
+		{"Empty", "", nil, true},
+		{"Accepted network", "378282246310005", []CardNetwork{CardVisa, CardAmex}, false},
+		{"Unaccepted network", "378282246310005", []CardNetwork{CardVisa}, true},
+		{"Non-string value", 4111111111111111, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Any("card", tt.value).CreditCard(tt.accepted...).Error()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreditCard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("DetectCardNetwork", func(t *testing.T) {
+		if got := DetectCardNetwork("5555555555554444"); got != CardMastercard {
+			t.Errorf("expected mastercard, got %q", got)
+		}
+		if got := DetectCardNetwork("6200000000000005"); got != CardUnionPay {
+			t.Errorf("expected unionpay, got %q", got)
+		}
+		if got := DetectCardNetwork("1234"); got != "" {
+			t.Errorf("expected no network, got %q", got)
+		}
+	})
+}
+
+func TestPhoneValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		region  string
+		wantErr bool
+	}{
+		{"E.164", "+14155552671", "", false},
+		{"E.164 with separators", "+44 (20) 7946-0958", "", false},
+		{"National without region", "4155552671", "", true},
+		{"Leading zero country code", "+04155552671", "", true},
+		{"Too long", "+1234567890123456", "", true},
+		{"Too short", "+1234567", "", true},
+		{"Letters", "+1415555CALL", "", true},
+		{"US national", "(415) 555-2671", "US", false},
+		{"US national with trunk prefix", "1-415-555-2671", "US", false},
+		{"US invalid area code", "(115) 555-2671", "US", true},
+		{"US wrong digit count", "415-555-267", "US", true},
+		{"US international", "+1 415 555 2671", "us", false},
+		{"Region mismatch", "+44 20 7946 0958", "US", true},
+		{"GB national with trunk prefix", "020 7946 0958", "GB", false},
+		{"GB 00 international prefix", "0044 20 7946 0958", "GB", false},
+		{"Unknown region", "+14155552671", "ZZ", true},
+		{"Empty", "", "", true},
+		{"Non-string value", 14155552671, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Any("phone", tt.value).Phone(tt.region).Error()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Phone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordValidation(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLen:        10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	t.Run("Strong password", func(t *testing.T) {
+		err := Any("password", "Sup3r-Secret!").Password(policy).Error()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Reports every unmet requirement", func(t *testing.T) {
+		err := Any("password", "abc").Password(policy).Error()
+
+		if err == nil {
+			t.Fatal("expected error for weak password")
+		}
+		msg := err.Error()
+		for _, want := range []string{"at least 10 characters", "uppercase", "digit", "symbol"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("expected error to mention %q, got %q", want, msg)
+			}
+		}
+		if strings.Contains(msg, "lowercase") {
+			t.Errorf("did not expect lowercase requirement to be reported, got %q", msg)
+		}
+	})
+
+	t.Run("Minimum entropy", func(t *testing.T) {
+		entropyPolicy := PasswordPolicy{MinEntropyBits: 60}
+
+		if err := Any("password", "aaaaaaaa").Password(entropyPolicy).Error(); err == nil {
+			t.Error("expected error for low-entropy password")
+		}
+		if err := Any("password", "correct-horse-battery-staple").Password(entropyPolicy).Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Length counts characters not bytes", func(t *testing.T) {
+		err := Any("password", "ééééé").Password(PasswordPolicy{MinLen: 6}).Error()
+
+		if err == nil {
+			t.Error("expected error for 5-character password")
+		}
+	})
+
+	t.Run("Non-string value", func(t *testing.T) {
+		err := Any("password", 123).Password(policy).Error()
+
+		if err == nil {
+			t.Error("expected error for non-string value")
+		}
+	})
+}
+
 func TestNumericMin(t *testing.T) {
 	t.Run("Integer above minimum", func(t *testing.T) {
 		err := Any("number", 10).Min(5).Error()
@@ -874,3 +1123,513 @@ func TestChainValidations(t *testing.T) {
 		}
 	})
 }
+
+func TestCustomRule(t *testing.T) {
+	isCron := func(value any) error {
+		if str, _ := value.(string); len(strings.Fields(str)) != 5 {
+			return errors.New("must be a valid cron expression")
+		}
+		return nil
+	}
+
+	t.Run("Passing predicate", func(t *testing.T) {
+		if err := Any("schedule", "0 * * * *").Required().Custom(isCron).Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Failing predicate prefixes label", func(t *testing.T) {
+		err := Any("schedule", "hourly").Required().Custom(isCron).Error()
+		if err == nil || err.Error() != "schedule: must be a valid cron expression" {
+			t.Fatalf("expected labeled custom error, got %v", err)
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeCustom}) {
+			t.Errorf("expected code %q, got %v", CodeCustom, err)
+		}
+	})
+
+	t.Run("RuleError code is kept", func(t *testing.T) {
+		err := Any("schedule", "hourly").Custom(func(value any) error {
+			return &RuleError{Code: "cron", Message: "bad cron"}
+		}).Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{"cron"}) {
+			t.Errorf("expected code %q, got %v", "cron", err)
+		}
+	})
+
+	t.Run("Short-circuits", func(t *testing.T) {
+		executed := false
+		fn := func(value any) error {
+			executed = true
+			return nil
+		}
+		Any("schedule", "").Required().Custom(fn)
+		Any("schedule", "").Optional().Custom(fn)
+		Any("schedule", "x").If(false, func(c *AnyChecker) *AnyChecker { return c.Custom(fn) })
+		if executed {
+			t.Error("custom predicate should not run after short-circuit or in a false If")
+		}
+
+		err := Any("schedule", "hourly").Custom(isCron).Custom(func(value any) error {
+			executed = true
+			return nil
+		}).Error()
+		if err == nil || executed {
+			t.Error("rules after a failing custom predicate should not run")
+		}
+	})
+
+	t.Run("Collects across object fields", func(t *testing.T) {
+		type Job struct {
+			Schedule string
+			Backup   string
+		}
+		v := Object(Job{Schedule: "hourly", Backup: "daily"})
+		v.Required("Schedule").Custom(isCron)
+		v.Required("Backup").Custom(isCron)
+		err := v.Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.FieldCodes()) != 2 {
+			t.Errorf("expected failures for both fields, got %v", err)
+		}
+	})
+
+	t.Run("Nil predicate", func(t *testing.T) {
+		if err := Any("schedule", "x").Custom(nil).Error(); err == nil {
+			t.Error("expected error for nil predicate")
+		}
+	})
+}
+
+func TestStringTransformers(t *testing.T) {
+	type form struct {
+		Name  string
+		Email string
+		Nick  *string
+	}
+
+	t.Run("Trim then Min fails when trimmed value is too short", func(t *testing.T) {
+		f := form{Name: "  ab  "}
+		oc := Object(&f)
+		oc.Required("Name").Trim().Min(3)
+
+		if err := oc.Error(); err == nil {
+			t.Error("expected error for trimmed value shorter than 3")
+		}
+		if f.Name != "ab" {
+			t.Errorf("expected field to be trimmed in place, got %q", f.Name)
+		}
+	})
+
+	t.Run("Trim then Min passes when trimmed value is long enough", func(t *testing.T) {
+		f := form{Name: "  abcd  "}
+		oc := Object(&f)
+		oc.Required("Name").Trim().Min(3)
+
+		if err := oc.Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if f.Name != "abcd" {
+			t.Errorf("expected field to be trimmed in place, got %q", f.Name)
+		}
+	})
+
+	t.Run("ToLower before In", func(t *testing.T) {
+		f := form{Email: " Foo@Example.COM "}
+		oc := Object(&f)
+		oc.Required("Email").Trim().ToLower().In([]string{"foo@example.com"})
+
+		if err := oc.Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if f.Email != "foo@example.com" {
+			t.Errorf("expected normalized email, got %q", f.Email)
+		}
+	})
+
+	t.Run("NormalizeUnicode composes characters", func(t *testing.T) {
+		decomposed := "e\u0301" // "e" + combining acute accent
+		f := form{Name: decomposed}
+		Object(&f).Required("Name").NormalizeUnicode()
+
+		if f.Name != "\u00e9" {
+			t.Errorf("expected NFC-normalized value, got %q", f.Name)
+		}
+	})
+
+	t.Run("Pointer fields are transformed", func(t *testing.T) {
+		nick := "  Bob "
+		f := form{Nick: &nick}
+		Object(&f).Optional("Nick").Trim().ToUpper()
+
+		if nick != "BOB" {
+			t.Errorf("expected pointed-to value to be transformed, got %q", nick)
+		}
+	})
+
+	t.Run("Non-addressable values are left untouched", func(t *testing.T) {
+		f := form{Name: "  ab  "}
+		err := Object(f).Required("Name").Trim().Error()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if f.Name != "  ab  " {
+			t.Errorf("expected original value to be unchanged, got %q", f.Name)
+		}
+	})
+
+	t.Run("Non-string value", func(t *testing.T) {
+		err := Any("test", 123).Trim().Error()
+
+		if err == nil {
+			t.Error("expected error for non-string value")
+		}
+	})
+}
+
+func TestDefault(t *testing.T) {
+	type sortOrder string
+	type query struct {
+		Sort  string
+		Order sortOrder
+		Limit int
+		Page  *int
+	}
+
+	t.Run("Fills zero value and runs subsequent rules", func(t *testing.T) {
+		q := query{}
+		oc := Object(&q)
+		oc.Optional("Sort").Default("asc").In([]string{"asc", "desc"})
+		oc.Optional("Limit").Default(20).Max(100)
+
+		if err := oc.Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if q.Sort != "asc" || q.Limit != 20 {
+			t.Errorf("expected defaults to be applied, got %+v", q)
+		}
+	})
+
+	t.Run("Default is validated by subsequent rules", func(t *testing.T) {
+		q := query{}
+		err := Object(&q).Optional("Limit").Default(500).Max(100).Error()
+
+		if err == nil {
+			t.Error("expected error for default exceeding max")
+		}
+	})
+
+	t.Run("Non-zero value is left untouched", func(t *testing.T) {
+		q := query{Sort: "desc"}
+		Object(&q).Optional("Sort").Default("asc")
+
+		if q.Sort != "desc" {
+			t.Errorf("expected original value, got %q", q.Sort)
+		}
+	})
+
+	t.Run("Converts to named type of same kind", func(t *testing.T) {
+		q := query{}
+		err := Object(&q).Optional("Order").Default("asc").Error()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if q.Order != "asc" {
+			t.Errorf("expected default to be applied, got %q", q.Order)
+		}
+	})
+
+	t.Run("Allocates nil pointer fields", func(t *testing.T) {
+		q := query{}
+		err := Object(&q).Optional("Page").Default(1).Min(1).Error()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if q.Page == nil || *q.Page != 1 {
+			t.Errorf("expected page to default to 1, got %v", q.Page)
+		}
+	})
+
+	t.Run("Type mismatch", func(t *testing.T) {
+		q := query{}
+		err := Object(&q).Optional("Limit").Default("twenty").Error()
+
+		if err == nil {
+			t.Fatal("expected error for mismatched default type")
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeType}) {
+			t.Errorf("expected %q code, got %v", CodeType, err)
+		}
+	})
+
+	t.Run("Required failure is not overridden", func(t *testing.T) {
+		q := query{}
+		err := Object(&q).Required("Sort").Default("asc").Error()
+
+		if err == nil {
+			t.Error("expected required error")
+		}
+		if q.Sort != "" {
+			t.Errorf("expected value to be unchanged, got %q", q.Sort)
+		}
+	})
+
+	t.Run("Non-addressable values are left untouched", func(t *testing.T) {
+		q := query{}
+		err := Object(q).Optional("Sort").Default("asc").In([]string{"desc"}).Error()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if q.Sort != "" {
+			t.Errorf("expected original value to be unchanged, got %q", q.Sort)
+		}
+	})
+}
+
+type codedSignup struct {
+	Email    string
+	Password string
+	Age      int
+	Phone    string
+	Fax      string
+}
+
+func (s *codedSignup) Validate() error {
+	v := Object(s)
+	v.Required("Email").Email()
+	v.Required("Password").Password(PasswordPolicy{MinLen: 8, RequireDigit: true})
+	v.Required("Age").Min(18)
+	v.MutuallyExclusive("contact", "Phone", "Fax")
+	return v.Error()
+}
+
+func TestErrorCodes(t *testing.T) {
+	t.Run("Object", func(t *testing.T) {
+		err := (&codedSignup{Email: "nope", Password: "abc", Age: 12, Phone: "1", Fax: "2"}).Validate()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+
+		wantCodes := []string{CodeMutuallyExclusive, CodeEmail, CodePasswordMinLen, CodePasswordDigit, CodeMin}
+		if got := validationErr.Codes(); !reflect.DeepEqual(got, wantCodes) {
+			t.Errorf("Codes() = %v, want %v", got, wantCodes)
+		}
+
+		wantFieldCodes := map[string][]string{
+			"contact":  {CodeMutuallyExclusive},
+			"Email":    {CodeEmail},
+			"Password": {CodePasswordMinLen, CodePasswordDigit},
+			"Age":      {CodeMin},
+		}
+		if got := validationErr.FieldCodes(); !reflect.DeepEqual(got, wantFieldCodes) {
+			t.Errorf("FieldCodes() = %v, want %v", got, wantFieldCodes)
+		}
+
+		// Messages are unchanged
+		if !strings.Contains(err.Error(), "Email must be a valid email address") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("AnyChecker", func(t *testing.T) {
+		tests := []struct {
+			name string
+			err  error
+			code string
+		}{
+			{"Required", Any("x", "").Required().Error(), CodeRequired},
+			{"Type", Any("x", 5).Required().Email().Error(), CodeType},
+			{"In", Any("x", "c").Required().In([]string{"a", "b"}).Error(), CodeIn},
+			{"NotIn", Any("x", "a").Required().NotIn([]string{"a"}).Error(), CodeNotIn},
+			{"Pattern", Any("x", "abc").Required().Regex(regexp.MustCompile(`^\d+$`)).Error(), CodePattern},
+			{"URL", Any("x", "nope").Required().URL().Error(), CodeURL},
+			{"CreditCard", Any("x", "4111111111111112").Required().CreditCard().Error(), CodeCreditCard},
+			{"Phone", Any("x", "12345").Required().Phone("").Error(), CodePhone},
+			{"PhoneRegion", Any("x", "+14155552671").Required().Phone("ZZ").Error(), CodeInvalid},
+			{"Max", Any("x", 10).Required().Max(5).Error(), CodeMax},
+			{"Range", Any("x", 10).Required().RangeInclusive(1, 5).Error(), CodeRange},
+		}
+		for _, tt := range tests {
+			var validationErr *ValidationError
+			if !errors.As(tt.err, &validationErr) {
+				t.Errorf("%s: expected ValidationError, got %v", tt.name, tt.err)
+				continue
+			}
+			ruleErrs := validationErr.RuleErrors()
+			if len(ruleErrs) != 1 || ruleErrs[0].Code != tt.code || ruleErrs[0].Label != "x" {
+				t.Errorf("%s: unexpected rule errors %+v", tt.name, ruleErrs)
+			}
+		}
+	})
+
+	t.Run("NestedValidator", func(t *testing.T) {
+		type wrapper struct {
+			Signup codedSignup
+		}
+		err := Any("wrapper", &wrapper{Signup: codedSignup{Email: "a@b.co", Password: "abcdefg1"}}).Required().Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+		if got := validationErr.FieldCodes(); !reflect.DeepEqual(got, map[string][]string{"Age": {CodeRequired}}) {
+			t.Errorf("FieldCodes() = %v", got)
+		}
+	})
+}
+
+func TestEachKeyAndValue(t *testing.T) {
+	keyPattern := regexp.MustCompile(`^[a-z_]+$`)
+
+	t.Run("Valid map passes", func(t *testing.T) {
+		m := map[string]any{"env": "prod", "team": "core"}
+		err := Object(m).
+			EachKey(func(c *AnyChecker) *AnyChecker { return c.Regex(keyPattern) }).
+			EachValue(func(c *AnyChecker) *AnyChecker { return c.Required().Regex(keyPattern) }).
+			Error()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Errors keyed by map key", func(t *testing.T) {
+		m := map[string]any{"env": "", "Bad-Key": "ok", "count": 3}
+		err := Object(m).
+			EachKey(func(c *AnyChecker) *AnyChecker { return c.Regex(keyPattern) }).
+			EachValue(func(c *AnyChecker) *AnyChecker { return c.Required().StartsWith("o") }).
+			Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+		want := map[string][]string{
+			"Bad-Key": {CodePattern},
+			"count":   {CodeType},
+			"env":     {CodeRequired},
+		}
+		if got := validationErr.FieldCodes(); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected field codes %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Optional values skip zero values", func(t *testing.T) {
+		m := map[string]string{"a": "", "b": "x"}
+		err := Object(m).EachValue(func(c *AnyChecker) *AnyChecker { return c.Optional().StartsWith("x") }).Error()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Typed map values", func(t *testing.T) {
+		m := map[string]int{"low": 1, "high": 11}
+		err := Object(&m).EachValue(func(c *AnyChecker) *AnyChecker { return c.Required().Max(10) }).Error()
+		if err == nil || !strings.Contains(err.Error(), "high") || strings.Contains(err.Error(), "low") {
+			t.Errorf("expected only high to fail, got %v", err)
+		}
+	})
+
+	t.Run("Struct is rejected", func(t *testing.T) {
+		s := struct{ Name string }{"x"}
+		err := Object(s).EachKey(func(c *AnyChecker) *AnyChecker { return c }).Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeInvalid}) {
+			t.Errorf("expected code %q, got %v", CodeInvalid, err)
+		}
+	})
+
+	t.Run("Nil rule", func(t *testing.T) {
+		err := Object(map[string]any{"a": 1}).EachValue(nil).Error()
+		if err == nil {
+			t.Error("expected error for nil rule")
+		}
+	})
+}
+
+func TestUnique(t *testing.T) {
+	t.Run("All unique passes", func(t *testing.T) {
+		if err := Any("tags", []string{"a", "b", "c"}).Required().Unique().Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := Any("ids", [3]int{1, 2, 3}).Required().Unique().Error(); err != nil {
+			t.Errorf("unexpected error for array: %v", err)
+		}
+	})
+
+	t.Run("Duplicates are reported with indices", func(t *testing.T) {
+		err := Any("tags", []string{"a", "b", "a", "c", "b", "a"}).Required().Unique().Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeUnique}) {
+			t.Fatalf("expected code %q, got %v", CodeUnique, err)
+		}
+		want := "tags has duplicate values (a at indices 0, 2, 5; b at indices 1, 4)"
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %q", want, err.Error())
+		}
+	})
+
+	t.Run("Pointer elements are dereferenced", func(t *testing.T) {
+		one, otherOne := 1, 1
+		if err := Any("ids", []*int{&one, &otherOne}).Required().Unique().Error(); err == nil {
+			t.Error("expected duplicate error for pointers to equal values")
+		}
+	})
+
+	t.Run("Non-slice value", func(t *testing.T) {
+		err := Any("name", "abc").Required().Unique().Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeType}) {
+			t.Errorf("expected code %q, got %v", CodeType, err)
+		}
+	})
+
+	t.Run("Non-comparable elements", func(t *testing.T) {
+		err := Any("groups", [][]string{{"a"}, {"a"}}).Required().Unique().Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeInvalid}) {
+			t.Errorf("expected code %q, got %v", CodeInvalid, err)
+		}
+		// Comparable type holding a non-comparable dynamic value
+		err = Any("items", []struct{ X any }{{X: []int{1}}}).Required().Unique().Error()
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeInvalid}) {
+			t.Errorf("expected code %q, got %v", CodeInvalid, err)
+		}
+	})
+
+	type item struct {
+		ID   int
+		Tags []string
+	}
+
+	t.Run("UniqueBy", func(t *testing.T) {
+		byID := func(elem any) any { return elem.(item).ID }
+		items := []item{{ID: 1, Tags: []string{"x"}}, {ID: 2}, {ID: 1}}
+		err := Any("items", items).Required().UniqueBy(byID).Error()
+		if err == nil || !strings.Contains(err.Error(), "1 at indices 0, 2") {
+			t.Errorf("expected duplicate ID error, got %v", err)
+		}
+		if err := Any("items", items[:2]).Required().UniqueBy(byID).Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("UniqueBy nil key function", func(t *testing.T) {
+		err := Any("items", []int{1}).Required().UniqueBy(nil).Error()
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Codes(), []string{CodeInvalid}) {
+			t.Errorf("expected code %q, got %v", CodeInvalid, err)
+		}
+	})
+
+	t.Run("Optional empty slice is skipped", func(t *testing.T) {
+		if err := Any("tags", []string(nil)).Optional().Unique().Error(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -116,7 +116,12 @@ func setNestedField(v reflect.Value, values map[string][]string) error {
 
 		if value, ok := values[tag]; ok {
 			if err := setField(fieldValue, value); err != nil {
-				return fmt.Errorf("error setting field %s: %w", field.Name, err)
+				return &RuleError{
+					Label:   tag,
+					Code:    CodeType,
+					Message: fmt.Sprintf("error setting field %s: %v", field.Name, err),
+					Cause:   err,
+				}
 			}
 
 			continue
@@ -3,8 +3,11 @@ package validate
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 )
 
 // JSONBodyInto decodes an HTTP request body into a struct and validates it.
@@ -50,3 +53,37 @@ func URLSearchParamsInto(r *http.Request, destStructPtr any) error {
 	}
 	return nil
 }
+
+// FormInto parses form values (e.g., r.PostForm) into a struct and validates it.
+// Keys are matched against JSON field names, and values are coerced to each
+// field's kind. A value that cannot be coerced yields a ValidationError whose
+// RuleErrors carry CodeType.
+func FormInto(values url.Values, destStructPtr any) error {
+	if err := parseURLValues(values, destStructPtr); err != nil {
+		return &ValidationError{Err: fmt.Errorf("error parsing form values: %w", err)}
+	}
+	if err := attemptValidation("validate.FormInto", destStructPtr); err != nil {
+		return err
+	}
+	return nil
+}
+
+const formBodyMaxMemory = 32 << 20 // same default as http.Request.FormValue
+
+// FormBodyInto parses the form body of an HTTP request (whether
+// "application/x-www-form-urlencoded" or "multipart/form-data") into a
+// struct and validates it. URL query parameters are ignored. It can be
+// used directly as (or from) a mux ParseInput function for form posts.
+func FormBodyInto(r *http.Request, destStructPtr any) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var err error
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(formBodyMaxMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return &ValidationError{Err: fmt.Errorf("error parsing form body: %w", err)}
+	}
+	return FormInto(r.PostForm, destStructPtr)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -178,3 +179,78 @@ func TestEdgeCases(t *testing.T) {
 		t.Errorf("unexpected name value, got %s", dest.Name)
 	}
 }
+
+func TestFormInto(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		values := url.Values{"name": {"John"}, "email": {"john@example.com"}, "age": {"30"}}
+		dest := &TestStruct{}
+		if err := FormInto(values, dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Name != "John" || dest.Email != "john@example.com" || dest.Age != 30 {
+			t.Errorf("unexpected values in struct after parsing form: %+v", dest)
+		}
+	})
+
+	t.Run("FailedRules", func(t *testing.T) {
+		values := url.Values{"name": {"John"}, "email": {"john@example.com"}, "age": {"12"}}
+		err := FormInto(values, &TestStruct{})
+		if !IsValidationError(err) || !strings.Contains(err.Error(), "age must be at least 18") {
+			t.Errorf("expected validation error from Validate, got %v", err)
+		}
+	})
+
+	t.Run("BadCoercion", func(t *testing.T) {
+		values := url.Values{"name": {"John"}, "email": {"john@example.com"}, "age": {"thirty"}}
+		err := FormInto(values, &TestStruct{})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+		if codes := validationErr.FieldCodes()["age"]; len(codes) != 1 || codes[0] != CodeType {
+			t.Errorf("expected a %q code for age, got %v", CodeType, validationErr.FieldCodes())
+		}
+		var numErr *strconv.NumError
+		if !errors.As(err, &numErr) || !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("expected the underlying parse error to be reachable, got %v", err)
+		}
+	})
+}
+
+func TestFormBodyInto(t *testing.T) {
+	t.Run("URLEncoded", func(t *testing.T) {
+		body := url.Values{"name": {"John"}, "email": {"john@example.com"}, "age": {"30"}}.Encode()
+		r, _ := http.NewRequest(http.MethodPost, "/?name=Ignored", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		dest := &TestStruct{}
+		if err := FormBodyInto(r, dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Name != "John" || dest.Age != 30 {
+			t.Errorf("unexpected values in struct after parsing form body: %+v", dest)
+		}
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		body := "--b\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\nJane\r\n" +
+			"--b\r\nContent-Disposition: form-data; name=\"email\"\r\n\r\njane@example.com\r\n" +
+			"--b\r\nContent-Disposition: form-data; name=\"age\"\r\n\r\n40\r\n--b--\r\n"
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "multipart/form-data; boundary=b")
+		dest := &TestStruct{}
+		if err := FormBodyInto(r, dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Name != "Jane" || dest.Email != "jane@example.com" || dest.Age != 40 {
+			t.Errorf("unexpected values in struct after parsing multipart body: %+v", dest)
+		}
+	})
+
+	t.Run("MissingFields", func(t *testing.T) {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("name=John"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := FormBodyInto(r, &TestStruct{}); !IsValidationError(err) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+	})
+}
@@ -37,6 +37,8 @@ type BuildCtxOptions struct {
 	ManifestOut string
 	// optional -- default is 5173
 	DefaultPort int
+	// optional -- if true, fail instead of incrementing when DefaultPort is taken
+	StrictPort bool
 	// optional
 	ViteConfigFile string
 }
@@ -53,6 +55,13 @@ func NewBuildCtx(opts *BuildCtxOptions) *BuildCtx {
 	}
 }
 
+func (c *BuildCtx) requested_port() int {
+	if c.opts.DefaultPort != 0 {
+		return c.opts.DefaultPort
+	}
+	return 5173
+}
+
 func (c *BuildCtx) prep_cmd() {
 	split_cmd := strings.Fields(c.opts.JSPackageManagerBaseCmd)
 
@@ -79,12 +88,23 @@ func (c *BuildCtx) DevBuild() error {
 
 	c.prep_cmd()
 
+	requestedPort := c.requested_port()
+
+	var port int
 	var err error
-	c.port, err = InitPort(c.port)
+	if c.opts.StrictPort {
+		port, err = InitStrictPort(requestedPort)
+	} else {
+		port, err = InitPort(requestedPort)
+	}
 	if err != nil {
 		Log.Error(fmt.Sprintf("Error initializing vite port: %s", err))
 		return err
 	}
+	if port != requestedPort {
+		Log.Warn(fmt.Sprintf("Port %d is in use, using port %d for vite instead", requestedPort, port))
+	}
+	c.port = port
 
 	c.cmd.Args = append(c.cmd.Args, "vite",
 		"--port", fmt.Sprintf("%d", c.port),
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"os"
 	"path"
 	"slices"
@@ -30,12 +31,28 @@ type ManifestChunk struct {
 type Manifest map[string]ManifestChunk
 
 func ReadManifest(manifestPath string) (Manifest, error) {
-	manifest := make(Manifest)
 	contents, err := os.ReadFile(manifestPath)
 	if err != nil {
-		return manifest, err
+		return make(Manifest), err
+	}
+	return parseManifest(contents)
+}
+
+// ReadManifestFS is like ReadManifest, but reads the manifest from the
+// provided fs.FS (e.g., an embedded filesystem) instead of from disk.
+// The manifestPath arg must be a valid fs.FS path (slash-separated,
+// unrooted).
+func ReadManifestFS(fsys fs.FS, manifestPath string) (Manifest, error) {
+	contents, err := fs.ReadFile(fsys, manifestPath)
+	if err != nil {
+		return make(Manifest), err
 	}
-	err = json.Unmarshal(contents, &manifest)
+	return parseManifest(contents)
+}
+
+func parseManifest(contents []byte) (Manifest, error) {
+	manifest := make(Manifest)
+	err := json.Unmarshal(contents, &manifest)
 	return manifest, err
 }
 
@@ -160,20 +177,57 @@ func stripPrecedingSlash(s string) string {
 
 const PortEnvName = "__VITE_PORT"
 
+// ErrPortInUse is returned by InitStrictPort when the requested port
+// is already taken.
+var ErrPortInUse = errors.New("port already in use")
+
+// InitPort finds a free port for the Vite dev server, starting at
+// defaultPort (5173 if zero) and incrementing until one is available.
+// The chosen port is stored in the environment so that it can be read
+// back via GetVitePortStr.
 func InitPort(defaultPort int) (int, error) {
-	vitePort, err := netutil.GetFreePort(5199)
+	if defaultPort == 0 {
+		defaultPort = 5173
+	}
+
+	vitePort, err := netutil.GetFreePort(defaultPort)
 	if err != nil {
 		return 0, err
 	}
 
-	err = os.Setenv(PortEnvName, fmt.Sprintf("%d", vitePort))
-	if err != nil {
+	if err := setPortEnv(vitePort); err != nil {
 		return 0, err
 	}
 
 	return vitePort, nil
 }
 
+// InitStrictPort is like InitPort, except that it returns an error
+// wrapping ErrPortInUse instead of moving on to another port if port
+// is already taken.
+func InitStrictPort(port int) (int, error) {
+	if port == 0 {
+		port = 5173
+	}
+
+	if !netutil.CheckAvailability(port) {
+		return 0, fmt.Errorf(
+			"vite dev server port %d: %w (stop whatever is using it, choose a different Vite.DefaultPort, or disable Vite.StrictPort)",
+			port, ErrPortInUse,
+		)
+	}
+
+	if err := setPortEnv(port); err != nil {
+		return 0, err
+	}
+
+	return port, nil
+}
+
+func setPortEnv(port int) error {
+	return os.Setenv(PortEnvName, fmt.Sprintf("%d", port))
+}
+
 func GetVitePortStr() string {
 	return os.Getenv(PortEnvName)
 }
@@ -0,0 +1,81 @@
+package viteutil
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/river-now/river/kit/netutil"
+)
+
+func TestReadManifestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"river_out/manifest.json": &fstest.MapFile{Data: []byte(`{
+			"src/entry.ts": {"src": "src/entry.ts", "file": "assets/entry-abc.js", "isEntry": true, "imports": ["_shared.js"]},
+			"_shared.js": {"file": "assets/shared-def.js"}
+		}`)},
+		"bad.json": &fstest.MapFile{Data: []byte(`{`)},
+	}
+
+	manifest, err := ReadManifestFS(fsys, "river_out/manifest.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !manifest["src/entry.ts"].IsEntry {
+		t.Error("expected src/entry.ts to be an entry")
+	}
+
+	deps := FindAllDependencies(manifest, "src/entry.ts")
+	if want := []string{"entry-abc.js", "shared-def.js"}; !reflect.DeepEqual(deps, want) {
+		t.Errorf("FindAllDependencies = %v, want %v", deps, want)
+	}
+
+	if _, err := ReadManifestFS(fsys, "missing.json"); err == nil {
+		t.Error("expected error for missing manifest")
+	}
+	if _, err := ReadManifestFS(fsys, "bad.json"); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestInitPort(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	taken := ln.Addr().(*net.TCPAddr).Port
+
+	t.Setenv(PortEnvName, "")
+
+	port, err := InitPort(taken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port == taken {
+		t.Errorf("expected a port other than the taken port %d", taken)
+	}
+	if got, want := GetVitePortStr(), strconv.Itoa(port); got != want {
+		t.Errorf("GetVitePortStr() = %q, want %q", got, want)
+	}
+
+	if _, err := InitStrictPort(taken); !errors.Is(err, ErrPortInUse) {
+		t.Errorf("expected ErrPortInUse, got %v", err)
+	}
+
+	// Availability is checked across all local stacks, which may not
+	// all be usable in every environment.
+	if !netutil.CheckAvailability(port) {
+		t.Skipf("port %d not reported as available in this environment", port)
+	}
+	port, err = InitStrictPort(port)
+	if err != nil {
+		t.Fatalf("unexpected error for free port: %v", err)
+	}
+	if got, want := GetVitePortStr(), strconv.Itoa(port); got != want {
+		t.Errorf("GetVitePortStr() = %q, want %q", got, want)
+	}
+}
@@ -18,6 +18,7 @@ type (
 	River                             = rf.River
 	HeadEls                           = headels.HeadEls
 	AdHocType                         = rf.AdHocType
+	ActionErrorType                   = rf.ActionErrorType
 	RiverAppConfig                    = rf.RiverAppConfig
 	LoadersRouter                     = rf.LoadersRouter
 	LoaderReqData                     = rf.LoaderReqData
@@ -27,6 +28,8 @@ type (
 	Action[I any, O any]              = rf.TaskHandler[I, O]
 	Loader[O any]                     = rf.TaskHandler[None, O]
 	BuildOptions                      = rf.BuildOptions
+	RuntimeSchemaOptions              = rf.RuntimeSchemaOptions
+	SitemapOptions                    = rf.SitemapOptions
 	LoaderFunc[Ctx any, O any]        = func(*Ctx) (O, error)
 	ActionFunc[Ctx any, I any, O any] = func(*Ctx) (O, error)
 	LoadersRouterOptions              = rf.LoadersRouterOptions
@@ -50,6 +53,11 @@ var (
 	NewHeadEls             = headels.New
 	RiverBuildIDHeaderKey  = rf.RiverBuildIDHeaderKey
 	EnableThirdPartyRouter = mux.InjectTasksCtxMiddleware
+	SetLoaderDataVersion   = rf.SetLoaderDataVersion
+	SetDocumentHeader      = rf.SetDocumentHeader
+
+	SetLoaderRevalidationTags    = rf.SetLoaderRevalidationTags
+	RiverRevalidateTagsHeaderKey = rf.RiverRevalidateTagsHeaderKey
 )
 
 func NewRiverApp(o RiverAppConfig) *River { return rf.NewRiverApp(o) }
@@ -79,6 +87,12 @@ func NewAction[I any, O any, CtxPtr ~*Ctx, Ctx any](
 	return actionTask
 }
 
+// RevalidateTags tells the client which loader revalidation tags the
+// action affects (see SetLoaderRevalidationTags).
+func RevalidateTags[I any](rd *ActionReqData[I], tags ...string) {
+	rf.RevalidateTags(rd, tags...)
+}
+
 //go:embed package.json
 var packageJSON string
 
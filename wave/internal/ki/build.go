@@ -3,19 +3,21 @@ package ki
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	esbuild "github.com/evanw/esbuild/pkg/api"
 	"github.com/river-now/river/kit/errutil"
 	"github.com/river-now/river/kit/esbuildutil"
-	"github.com/river-now/river/kit/executil"
 	"github.com/river-now/river/kit/fsutil"
 	"github.com/river-now/river/kit/typed"
 	"github.com/river-now/river/wave/internal/ki/configschema"
@@ -35,14 +37,44 @@ const PrehashedDirname = "prehashed"
 var noHashPublicDirsByVersion = map[uint8]string{0: "__nohash", 1: PrehashedDirname}
 
 type BuildOptions struct {
-	IsDev                      bool
-	RecompileGoBinary          bool
+	IsDev             bool
+	RecompileGoBinary bool
+	// DiscardRetainedVersions drops any public asset versions retained
+	// via Core.RetainAssetVersions, rather than carrying them over into
+	// the new build (which otherwise starts from an empty dist/static
+	// directory anyway). Ignored for dev rebuilds.
+	DiscardRetainedVersions bool
+	// StrictWarnings makes the build return an error (wrapping
+	// ErrBuildWarnings) if it produced any warnings (e.g., a missing
+	// build report baseline, or unused CSS reported by the prod build
+	// hook), so that CI can gate on them. Warnings are still logged as
+	// they occur. Ignored for dev builds.
+	StrictWarnings             bool
 	just_run_simple_file_build bool
 	is_dev_rebuild             bool
+	skip_css_hooks             bool
 }
 
-func (c *Config) do_build_time_file_processing(shouldBeGranular bool) error {
-	if !shouldBeGranular {
+type build_time_file_processing_opts struct {
+	granular                  bool
+	discard_retained_versions bool
+	// Set for dev rebuilds triggered by a change to a CSS entry file (or
+	// one of its imports), as the CSS pre hook may be what wrote it.
+	skip_css_hooks bool
+}
+
+func (c *Config) do_build_time_file_processing(opts build_time_file_processing_opts) error {
+	should_retain_public_versions := c.should_retain_public_versions()
+
+	if !opts.granular {
+		var stash *stashed_public_versions
+		if should_retain_public_versions && !opts.discard_retained_versions {
+			var err error
+			if stash, err = c.stash_public_versions(); err != nil {
+				return fmt.Errorf("error stashing public asset versions: %w", err)
+			}
+		}
+
 		// nuke the dist/static directory
 		if err := os.RemoveAll(c._dist.S().Static.FullPath()); err != nil {
 			return fmt.Errorf("error removing dist/static directory: %w", err)
@@ -52,20 +84,32 @@ func (c *Config) do_build_time_file_processing(shouldBeGranular bool) error {
 		if err := c.SetupDistDir(); err != nil {
 			return fmt.Errorf("error making requisite directories: %w", err)
 		}
+
+		if stash != nil {
+			if err := c.restore_public_versions(stash); err != nil {
+				return fmt.Errorf("error restoring public asset versions: %w", err)
+			}
+		}
 	}
 
 	if c.is_using_browser() {
 		// Must be complete before BuildCSS in case the CSS references any public files
-		if err := c.handlePublicFiles(shouldBeGranular); err != nil {
+		if err := c.handlePublicFiles(opts.granular); err != nil {
 			return fmt.Errorf("error handling public files: %w", err)
 		}
 
+		if should_retain_public_versions {
+			if err := c.prune_public_versions(); err != nil {
+				return fmt.Errorf("error pruning public asset versions: %w", err)
+			}
+		}
+
 		var eg errgroup.Group
 		eg.Go(func() error {
-			return errutil.Maybe("error during precompile task (copyPrivateFiles)", c.copyPrivateFiles(shouldBeGranular))
+			return errutil.Maybe("error during precompile task (copyPrivateFiles)", c.copyPrivateFiles(opts.granular))
 		})
 		eg.Go(func() error {
-			return errutil.Maybe("error during precompile task (buildCSS)", c.buildCSS())
+			return errutil.Maybe("error during precompile task (buildCSS)", c.buildCSS(!opts.skip_css_hooks))
 		})
 		if err := eg.Wait(); err != nil {
 			return err
@@ -74,21 +118,43 @@ func (c *Config) do_build_time_file_processing(shouldBeGranular bool) error {
 	return nil
 }
 
-func (c *Config) BuildWave(opts BuildOptions) error {
+func (c *Config) BuildWave(opts BuildOptions) (err error) {
 	a := time.Now()
 
+	strict_warnings := opts.StrictWarnings && !opts.IsDev
+	if strict_warnings {
+		stop := c.collect_build_warnings()
+		defer func() {
+			if warnings_err := stop(); err == nil {
+				err = warnings_err
+			}
+		}()
+	}
+
 	if !opts.just_run_simple_file_build {
 		c.Logger.Info("START building Wave",
 			"recompile_go_binary", opts.RecompileGoBinary,
 			"is_dev_rebuild", opts.is_dev_rebuild,
+			"discard_retained_versions", opts.DiscardRetainedVersions,
+			"strict_warnings", strict_warnings,
 		)
 	}
 
-	err := c.do_build_time_file_processing(opts.is_dev_rebuild) // once before build hook
+	err = c.do_build_time_file_processing(build_time_file_processing_opts{ // once before build hook
+		granular:                  opts.is_dev_rebuild,
+		discard_retained_versions: opts.DiscardRetainedVersions,
+		skip_css_hooks:            opts.skip_css_hooks,
+	})
 	if err != nil {
 		return fmt.Errorf("error processing build time files: %w", err)
 	}
 
+	// Must exist before the build hook runs, as the hook may compile
+	// packages that import the generated embed file.
+	if err := c.maybeWriteEmbedGoFile(); err != nil {
+		return err
+	}
+
 	if opts.just_run_simple_file_build {
 		return nil
 	}
@@ -97,42 +163,104 @@ func (c *Config) BuildWave(opts BuildOptions) error {
 
 	with_dev_hook := opts.IsDev && c._uc.Core.DevBuildHook != ""
 	if with_dev_hook {
-		if err := executil.RunCmd(strings.Fields(c._uc.Core.DevBuildHook)...); err != nil {
+		fields := strings.Fields(c._uc.Core.DevBuildHook)
+		if len(fields) == 0 {
+			return errors.New("Core.DevBuildHook is set but contains no command")
+		}
+		if err := run_cmd_capturing_output(exec.Command(fields[0], fields[1:]...)); err != nil {
 			return fmt.Errorf("error running dev build command: %w", err)
 		}
 	}
 
 	with_prod_hook := !opts.IsDev && c._uc.Core.ProdBuildHook != ""
 	if with_prod_hook {
-		if err := executil.RunCmd(strings.Fields(c._uc.Core.ProdBuildHook)...); err != nil {
+		fields := strings.Fields(c._uc.Core.ProdBuildHook)
+		if len(fields) == 0 {
+			return errors.New("Core.ProdBuildHook is set but contains no command")
+		}
+		cmd := exec.Command(fields[0], fields[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if strict_warnings {
+			cmd.Env = append(os.Environ(), strict_warnings_env_var+"=1")
+		}
+		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("error running prod build command: %w", err)
 		}
 	}
 
 	hook_duration := time.Since(hook_start)
 
-	err = c.do_build_time_file_processing(true) // and once again after
-	if err != nil {
-		return fmt.Errorf("error processing build time files: %w", err)
+	// The binary only depends on the post-hook file processing if it
+	// embeds the processed static files, so otherwise (if opted in) the
+	// two can overlap.
+	concurrent_go_compile := opts.RecompileGoBinary && !opts.IsDev &&
+		c._uc.Core.ConcurrentGoCompile && !c._uc.Core.GenerateEmbedFile
+
+	var go_compile_eg errgroup.Group
+	var go_compile_duration time.Duration
+
+	compile := func() error {
+		go_compile_start := time.Now()
+		defer func() { go_compile_duration = time.Since(go_compile_start) }()
+		if err := c.compile_go_binary(opts.IsDev); err != nil {
+			return fmt.Errorf("error compiling binary: %w", err)
+		}
+		if c._uc.Core.PostCompileHook != "" {
+			if err := c.run_post_compile_hook(); err != nil {
+				return fmt.Errorf("error running post compile hook: %w", err)
+			}
+		}
+		return nil
 	}
 
-	err = configschema.Write(filepath.Join(
-		c._dist.S().Static.S().Internal.FullPath(),
-		"schema.json",
-	))
+	if concurrent_go_compile {
+		go_compile_eg.Go(compile)
+	}
+
+	err = c.do_build_time_file_processing(build_time_file_processing_opts{ // and once again after
+		granular: true,
+		// The CSS hooks already ran in the first pass. Running them
+		// again would double their cost and, in dev, re-trigger the
+		// watcher on anything the pre hook writes.
+		skip_css_hooks: true,
+	})
+	if err == nil {
+		err = configschema.Write(filepath.Join(
+			c._dist.S().Static.S().Internal.FullPath(),
+			"schema.json",
+		))
+		if err != nil {
+			err = fmt.Errorf("error writing config schema: %w", err)
+		}
+	} else {
+		err = fmt.Errorf("error processing build time files: %w", err)
+	}
+
+	go_compile_wait_start := time.Now()
+
+	if concurrent_go_compile {
+		err = errors.Join(err, go_compile_eg.Wait())
+	}
 	if err != nil {
-		return fmt.Errorf("error writing config schema: %w", err)
+		return err
 	}
 
-	go_compile_start := time.Now()
+	if opts.RecompileGoBinary && !concurrent_go_compile {
+		if err := compile(); err != nil {
+			return err
+		}
+	}
 
-	if opts.RecompileGoBinary {
-		if err := c.compile_go_binary(opts.IsDev); err != nil {
-			return fmt.Errorf("error compiling binary: %w", err)
+	if !opts.IsDev && c._uc.Core.BuildReport != nil {
+		if err := c.write_build_report(); err != nil {
+			return fmt.Errorf("error writing build report: %w", err)
 		}
 	}
 
-	go_compile_duration := time.Since(go_compile_start)
+	// Only the time spent blocked on the compile counts against it, so
+	// that wave_build_duration stays accurate when the two overlap.
+	go_compile_wait_duration := time.Since(go_compile_wait_start)
 
 	total_duration := time.Since(a)
 
@@ -140,13 +268,22 @@ func (c *Config) BuildWave(opts BuildOptions) error {
 		"total_duration", total_duration,
 		"hook_duration", hook_duration,
 		"go_compile_duration", go_compile_duration,
-		"wave_build_duration", total_duration-hook_duration-go_compile_duration,
+		"concurrent_go_compile", concurrent_go_compile,
+		"wave_build_duration", total_duration-hook_duration-go_compile_wait_duration,
 	)
 
 	return nil
 }
 
-func (c *Config) buildCSS() error {
+// If runHooks is true, the CSS pre and post hooks (if any) run
+// immediately before and after bundling.
+func (c *Config) buildCSS(runHooks bool) error {
+	if runHooks {
+		if err := c.run_css_hook("PreHook", c._uc.Core.CSSEntryFiles.PreHook); err != nil {
+			return err
+		}
+	}
+
 	err := c.processCSSCritical()
 	if err != nil {
 		return fmt.Errorf("error processing critical CSS: %w", err)
@@ -157,6 +294,12 @@ func (c *Config) buildCSS() error {
 		return fmt.Errorf("error processing normal CSS: %w", err)
 	}
 
+	if runHooks {
+		if err := c.run_css_hook("PostHook", c._uc.Core.CSSEntryFiles.PostHook); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -187,11 +330,29 @@ func (c *Config) __processCSS(nature string) error {
 		return nil
 	}
 
+	if _, err := os.Stat(entryPoint); err != nil {
+		field := "Core.CSSEntryFiles.NonCritical"
+		if nature == "critical" {
+			field = "Core.CSSEntryFiles.Critical"
+		}
+		wrapped := fmt.Errorf("%s entry file not found at resolved path %q: %w", field, entryPoint, err)
+		c.Logger.Error(wrapped.Error())
+		return wrapped
+	}
+
 	isDev := GetIsDev()
 
+	buildOpts, err := c.getCSSBuildOpts()
+	if err != nil {
+		return err
+	}
+
 	ctx, ctxErr := esbuild.Context(esbuild.BuildOptions{
 		EntryPoints:       []string{entryPoint},
 		Bundle:            true,
+		Target:            buildOpts.target,
+		Engines:           buildOpts.engines,
+		Loader:            buildOpts.loaders,
 		MinifyWhitespace:  !isDev,
 		MinifyIdentifiers: !isDev,
 		MinifySyntax:      !isDev,
@@ -215,6 +376,11 @@ func (c *Config) __processCSS(nature string) error {
 									return esbuild.OnResolveResult{}, nil
 								}
 
+								// Let esbuild bundle files with a user-configured loader
+								if _, ok := buildOpts.loaders[filepath.Ext(args.Path)]; ok {
+									return esbuild.OnResolveResult{}, nil
+								}
+
 								return esbuild.OnResolveResult{
 									Path:     c.MustGetPublicURLBuildtime(args.Path),
 									External: true,
@@ -398,6 +564,9 @@ func (c *Config) processStaticFiles(opts *staticFileProcessorOpts) error {
 			if err != nil {
 				return fmt.Errorf("error saving empty public file map JSON: %w", err)
 			}
+			if err = c.savePublicAssetManifest(map[string]fileVal{}); err != nil {
+				return fmt.Errorf("error saving empty public asset manifest: %w", err)
+			}
 		}
 		return nil
 	}
@@ -451,8 +620,7 @@ func (c *Config) processStaticFiles(opts *staticFileProcessorOpts) error {
 	}()
 
 	// File processing goroutines
-	workerCount := 4
-	for range workerCount {
+	for range c.get_static_file_workers() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -513,6 +681,9 @@ func (c *Config) processStaticFiles(opts *staticFileProcessorOpts) error {
 		if err != nil {
 			return fmt.Errorf("error saving public file map JSON: %w", err)
 		}
+		if err = c.savePublicAssetManifest(to_std_map(&newFileMap)); err != nil {
+			return fmt.Errorf("error saving public asset manifest: %w", err)
+		}
 	}
 
 	return nil
@@ -540,7 +711,7 @@ func (c *Config) processFile(
 	var fileIdentifier fileVal
 	fileIdentifier.ContentHash = contentHash
 
-	if fi.isNoHashDir {
+	if fi.isNoHashDir || (opts.basename == PUBLIC && c.isNoHashGlobMatch(fi.relativePath)) {
 		fileIdentifier.DistName = fi.relativePath
 		fileIdentifier.IsPrehashed = true
 	} else if !opts.writeWithHash {
@@ -582,6 +753,18 @@ func (c *Config) processFile(
 	return nil
 }
 
+// isNoHashGlobMatch reports whether the public file at relativePath
+// (slash-separated, relative to the public static dir) matches any of
+// the user's Core.NoHashGlobs patterns.
+func (c *Config) isNoHashGlobMatch(relativePath string) bool {
+	for _, pattern := range c._uc.Core.NoHashGlobs {
+		if isMatch, _ := doublestar.Match(pattern, relativePath); isMatch {
+			return true
+		}
+	}
+	return false
+}
+
 func to_std_map(sm *typed.SyncMap[string, fileVal]) map[string]fileVal {
 	m := make(map[string]fileVal)
 	sm.Range(func(k string, v fileVal) bool {
@@ -0,0 +1,286 @@
+package ki
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/river-now/river/kit/fsutil"
+)
+
+const (
+	build_report_default_file_name          = "build-report.json"
+	build_report_default_threshold_percent  = 10
+	build_report_critical_css_key           = "critical.css"
+	build_report_normal_css_key             = "normal.css"
+	build_report_max_logged_non_regressions = 10
+)
+
+type buildReport struct {
+	// Keyed by original (pre-hash) path, relative to the public static dir
+	Assets map[string]int64 `json:"assets"`
+	// Keyed by "critical.css" and "normal.css"
+	CSS        map[string]int64       `json:"css"`
+	TotalBytes int64                  `json:"totalBytes"`
+	Comparison *buildReportComparison `json:"comparison,omitempty"`
+}
+
+type buildReportComparison struct {
+	Baseline           string              `json:"baseline"`
+	ThresholdPercent   float64             `json:"thresholdPercent"`
+	BaselineTotalBytes int64               `json:"baselineTotalBytes"`
+	Regressions        int                 `json:"regressions"`
+	Changes            []buildReportChange `json:"changes"`
+}
+
+// Before is 0 for added assets, and After is 0 for removed ones.
+type buildReportChange struct {
+	Name          string  `json:"name"`
+	Status        string  `json:"status"` // "added", "removed", or "changed"
+	Before        int64   `json:"before"`
+	After         int64   `json:"after"`
+	ChangePercent float64 `json:"changePercent,omitempty"`
+	Regression    bool    `json:"regression,omitempty"`
+}
+
+func (c *Config) write_build_report() error {
+	cfg := c._uc.Core.BuildReport
+
+	report, err := c.get_current_build_report()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Baseline != "" {
+		baseline, err := read_build_report(cfg.Baseline)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			c.BuildWarn("Build report baseline not found; skipping comparison", "baseline", cfg.Baseline)
+		case err != nil:
+			return err
+		default:
+			threshold := cfg.RegressionThresholdPercent
+			if threshold == 0 {
+				threshold = build_report_default_threshold_percent
+			}
+			report.Comparison = compare_build_reports(baseline, report, threshold)
+			report.Comparison.Baseline = cfg.Baseline
+		}
+	}
+
+	out := cfg.OutFile
+	if out == "" {
+		out = filepath.Join(c._dist.FullPath(), build_report_default_file_name)
+	}
+	reportJSON, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshalling build report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return fmt.Errorf("error creating build report directory: %w", err)
+	}
+	if err := os.WriteFile(out, reportJSON, 0644); err != nil {
+		return fmt.Errorf("error writing build report: %w", err)
+	}
+
+	c.log_build_report(report, out)
+	return nil
+}
+
+func (c *Config) get_current_build_report() (*buildReport, error) {
+	report := &buildReport{Assets: map[string]int64{}, CSS: map[string]int64{}}
+
+	internal := c._dist.S().Static.S().Internal
+	publicOutDir := c.GetStaticPublicOutDir()
+
+	file, err := os.Open(filepath.Join(internal.FullPath(), PublicFileMapGobName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error opening public file map: %w", err)
+	}
+	if err == nil {
+		defer file.Close()
+		fileMap, err := fsutil.FromGob[FileMap](file)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding public file map: %w", err)
+		}
+		for name, val := range fileMap {
+			size, err := get_file_size(filepath.Join(publicOutDir, val.DistName))
+			if err != nil {
+				return nil, err
+			}
+			report.Assets[name] = size
+		}
+	}
+
+	cssFiles := map[string]string{
+		build_report_critical_css_key: internal.S().CriticalDotCSS.FullPath(),
+	}
+	if ref, err := os.ReadFile(internal.S().NormalCSSFileRefDotTXT.FullPath()); err == nil && len(ref) > 0 {
+		cssFiles[build_report_normal_css_key] = filepath.Join(publicOutDir, string(ref))
+	}
+	for key, path := range cssFiles {
+		size, err := get_file_size(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.CSS[key] = size
+	}
+
+	report.TotalBytes = report.total_bytes()
+	return report, nil
+}
+
+func get_file_size(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("error getting size of %s: %w", path, err)
+	}
+	return info.Size(), nil
+}
+
+func read_build_report(path string) (*buildReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading build report %s: %w", path, err)
+	}
+	var report buildReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("error parsing build report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+func (r *buildReport) total_bytes() int64 {
+	var total int64
+	for _, size := range r.Assets {
+		total += size
+	}
+	for _, size := range r.CSS {
+		total += size
+	}
+	return total
+}
+
+// CSS bundles are prefixed with "css:" so they can't collide with assets.
+func (r *buildReport) sizes_by_name() map[string]int64 {
+	sizes := make(map[string]int64, len(r.Assets)+len(r.CSS))
+	for name, size := range r.Assets {
+		sizes[name] = size
+	}
+	for name, size := range r.CSS {
+		sizes["css:"+name] = size
+	}
+	return sizes
+}
+
+// compare_build_reports lists every asset whose size differs between
+// baseline and current (sorted by name), flagging as regressions any that
+// existed in the baseline and grew by more than thresholdPercent.
+func compare_build_reports(baseline, current *buildReport, thresholdPercent float64) *buildReportComparison {
+	comparison := &buildReportComparison{
+		ThresholdPercent:   thresholdPercent,
+		BaselineTotalBytes: baseline.total_bytes(),
+		Changes:            []buildReportChange{},
+	}
+
+	before := baseline.sizes_by_name()
+	after := current.sizes_by_name()
+
+	for name, afterSize := range after {
+		beforeSize, existed := before[name]
+		switch {
+		case !existed:
+			comparison.Changes = append(comparison.Changes, buildReportChange{
+				Name: name, Status: "added", After: afterSize,
+			})
+		case beforeSize != afterSize:
+			change := buildReportChange{
+				Name: name, Status: "changed", Before: beforeSize, After: afterSize,
+			}
+			if beforeSize > 0 {
+				change.ChangePercent = math.Round(float64(afterSize-beforeSize)/float64(beforeSize)*10000) / 100
+				change.Regression = change.ChangePercent > thresholdPercent
+			} else {
+				change.Regression = true
+			}
+			if change.Regression {
+				comparison.Regressions++
+			}
+			comparison.Changes = append(comparison.Changes, change)
+		}
+	}
+	for name, beforeSize := range before {
+		if _, exists := after[name]; !exists {
+			comparison.Changes = append(comparison.Changes, buildReportChange{
+				Name: name, Status: "removed", Before: beforeSize,
+			})
+		}
+	}
+
+	sort.Slice(comparison.Changes, func(i, j int) bool {
+		return comparison.Changes[i].Name < comparison.Changes[j].Name
+	})
+
+	return comparison
+}
+
+func (c *Config) log_build_report(report *buildReport, out string) {
+	cmp := report.Comparison
+	if cmp == nil {
+		c.Logger.Info("Wrote build report",
+			"file", out,
+			"assets", len(report.Assets)+len(report.CSS),
+			"total_bytes", report.TotalBytes,
+		)
+		return
+	}
+
+	c.Logger.Info("Wrote build report",
+		"file", out,
+		"assets", len(report.Assets)+len(report.CSS),
+		"total_bytes", report.TotalBytes,
+		"baseline_total_bytes", cmp.BaselineTotalBytes,
+		"total_change", format_byte_delta(report.TotalBytes-cmp.BaselineTotalBytes),
+		"changed", len(cmp.Changes),
+		"regressions", cmp.Regressions,
+	)
+
+	logged := 0
+	for _, change := range cmp.Changes {
+		if change.Regression {
+			c.BuildWarn("Asset size regression",
+				"asset", change.Name,
+				"before", change.Before,
+				"after", change.After,
+				"change", fmt.Sprintf("%+.2f%%", change.ChangePercent),
+			)
+			continue
+		}
+		if logged == build_report_max_logged_non_regressions {
+			continue
+		}
+		logged++
+		c.Logger.Info("Asset size change",
+			"asset", change.Name,
+			"status", change.Status,
+			"before", change.Before,
+			"after", change.After,
+			"change", format_byte_delta(change.After-change.Before),
+		)
+	}
+	if rest := len(cmp.Changes) - cmp.Regressions - logged; rest > 0 {
+		c.Logger.Info(fmt.Sprintf("... and %d more asset size changes (see report)", rest))
+	}
+}
+
+func format_byte_delta(delta int64) string {
+	return fmt.Sprintf("%+d bytes", delta)
+}
@@ -0,0 +1,432 @@
+package ki
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/sync/semaphore"
+)
+
+func TestNoHashGlobs(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.config._uc.Core.NoHashGlobs = []string{"sw.js", ".well-known/**"}
+
+	env.createTestFile(t, "public-static/sw.js", "self.addEventListener('fetch', () => {});")
+	env.createTestFile(t, "public-static/app.js", "console.log('app');")
+	env.createTestFile(t, "public-static/nested/sw.js", "console.log('not root');")
+	env.createTestFile(t, "public-static/.well-known/security.txt", "Contact: mailto:security@example.com")
+	env.createTestFile(t, "public-static/prehashed/robots.txt", "User-agent: *")
+
+	if err := env.config.handlePublicFiles(false); err != nil {
+		t.Fatalf("handlePublicFiles() error = %v", err)
+	}
+
+	fileMap, err := env.config.loadMapFromGob(PublicFileMapGobName, true)
+	if err != nil {
+		t.Fatalf("loadMapFromGob() error = %v", err)
+	}
+
+	publicDist := filepath.Join(testRootDir, "dist/static/assets/public")
+
+	for _, name := range []string{"sw.js", ".well-known/security.txt", "robots.txt"} {
+		v, ok := fileMap[name]
+		if !ok {
+			t.Fatalf("expected %s in public file map", name)
+		}
+		if v.DistName != name || !v.IsPrehashed {
+			t.Errorf("expected %s to keep its name, got %+v", name, v)
+		}
+		if _, err := os.Stat(filepath.Join(publicDist, name)); err != nil {
+			t.Errorf("expected %s to be written unhashed: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"app.js", "nested/sw.js"} {
+		v, ok := fileMap[name]
+		if !ok {
+			t.Fatalf("expected %s in public file map", name)
+		}
+		if v.DistName == name || v.IsPrehashed {
+			t.Errorf("expected %s to be hashed, got %+v", name, v)
+		}
+		if _, err := os.Stat(filepath.Join(publicDist, v.DistName)); err != nil {
+			t.Errorf("expected hashed %s to be written: %v", name, err)
+		}
+	}
+
+	url, err := env.config.PublicURL("sw.js")
+	if err != nil {
+		t.Fatalf("PublicURL() error = %v", err)
+	}
+	if url != "/bob/sw.js" {
+		t.Errorf("expected PublicURL to resolve to the original name, got %q", url)
+	}
+}
+
+func TestCompareBuildReports(t *testing.T) {
+	baseline := &buildReport{
+		Assets: map[string]int64{"app.js": 100, "logo.png": 200, "old.js": 50, "same.txt": 10},
+		CSS:    map[string]int64{"normal.css": 100},
+	}
+	current := &buildReport{
+		Assets: map[string]int64{"app.js": 150, "logo.png": 205, "new.js": 30, "same.txt": 10},
+		CSS:    map[string]int64{"normal.css": 80},
+	}
+
+	cmp := compare_build_reports(baseline, current, 10)
+
+	if cmp.BaselineTotalBytes != 460 {
+		t.Errorf("BaselineTotalBytes = %d, want 460", cmp.BaselineTotalBytes)
+	}
+	if cmp.Regressions != 1 {
+		t.Errorf("Regressions = %d, want 1", cmp.Regressions)
+	}
+
+	want := []buildReportChange{
+		{Name: "app.js", Status: "changed", Before: 100, After: 150, ChangePercent: 50, Regression: true},
+		{Name: "css:normal.css", Status: "changed", Before: 100, After: 80, ChangePercent: -20},
+		{Name: "logo.png", Status: "changed", Before: 200, After: 205, ChangePercent: 2.5},
+		{Name: "new.js", Status: "added", After: 30},
+		{Name: "old.js", Status: "removed", Before: 50},
+	}
+	if len(cmp.Changes) != len(want) {
+		t.Fatalf("Changes = %+v, want %+v", cmp.Changes, want)
+	}
+	for i := range want {
+		if cmp.Changes[i] != want[i] {
+			t.Errorf("Changes[%d] = %+v, want %+v", i, cmp.Changes[i], want[i])
+		}
+	}
+}
+
+func TestWriteBuildReport(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "public-static/app.js", "console.log('a much bigger app than before');")
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+	env.createTestFile(t, "main.css", "p { font-size: 16px; }")
+
+	if err := env.config.handlePublicFiles(false); err != nil {
+		t.Fatalf("handlePublicFiles() error = %v", err)
+	}
+	if err := env.config.buildCSS(true); err != nil {
+		t.Fatalf("buildCSS() error = %v", err)
+	}
+
+	baselinePath := filepath.Join(testRootDir, "baseline.json")
+	if err := os.WriteFile(baselinePath, []byte(`{"assets":{"app.js":10},"css":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(testRootDir, "reports/build-report.json")
+	env.config._uc.Core.BuildReport = &BuildReport{OutFile: outPath, Baseline: baselinePath}
+
+	if err := env.config.write_build_report(); err != nil {
+		t.Fatalf("write_build_report() error = %v", err)
+	}
+
+	report, err := read_build_report(outPath)
+	if err != nil {
+		t.Fatalf("read_build_report() error = %v", err)
+	}
+	if report.Assets["app.js"] != int64(len("console.log('a much bigger app than before');")) {
+		t.Errorf("unexpected app.js size in %+v", report.Assets)
+	}
+	if report.CSS[build_report_critical_css_key] == 0 || report.CSS[build_report_normal_css_key] == 0 {
+		t.Errorf("expected both CSS bundle sizes, got %+v", report.CSS)
+	}
+	if report.TotalBytes != report.total_bytes() {
+		t.Errorf("TotalBytes = %d, want %d", report.TotalBytes, report.total_bytes())
+	}
+	if report.Comparison == nil || report.Comparison.ThresholdPercent != build_report_default_threshold_percent {
+		t.Fatalf("expected a comparison using the default threshold, got %+v", report.Comparison)
+	}
+	if report.Comparison.Regressions != 1 {
+		t.Errorf("expected app.js to be flagged as a regression, got %+v", report.Comparison.Changes)
+	}
+
+	// A missing baseline is not an error
+	env.config._uc.Core.BuildReport.Baseline = filepath.Join(testRootDir, "nope.json")
+	if err := env.config.write_build_report(); err != nil {
+		t.Fatalf("write_build_report() with missing baseline error = %v", err)
+	}
+	if report, err = read_build_report(outPath); err != nil || report.Comparison != nil {
+		t.Errorf("expected a report without comparison, got %+v (err: %v)", report, err)
+	}
+}
+
+func TestStaticFileConcurrency(t *testing.T) {
+	c := &Config{_uc: &UserConfig{Core: &UserConfigCore{}}}
+	if got := c.get_static_file_workers(); got != 4 {
+		t.Errorf("default workers = %d, want 4", got)
+	}
+	if got := c.get_max_open_static_files(); got != 100 {
+		t.Errorf("default max open files = %d, want 100", got)
+	}
+
+	c._uc.Core.StaticFileWorkers = 12
+	c._uc.Core.MaxOpenStaticFiles = 7
+	if got := c.get_static_file_workers(); got != 12 {
+		t.Errorf("workers = %d, want 12", got)
+	}
+	if got := c.get_max_open_static_files(); got != 7 {
+		t.Errorf("max open files = %d, want 7", got)
+	}
+
+	c._uc.Core.StaticFileWorkers = -1
+	c._uc.Core.MaxOpenStaticFiles = -1
+	if got, want := c.get_static_file_workers(), runtime.GOMAXPROCS(0); got != want {
+		t.Errorf("auto workers = %d, want %d", got, want)
+	}
+	if got, want := c.get_max_open_static_files(), 4*runtime.GOMAXPROCS(0); got != want {
+		t.Errorf("auto max open files = %d, want %d", got, want)
+	}
+
+	// Processing still works with a single worker and file slot
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+	env.config._uc.Core.StaticFileWorkers = 1
+	env.config._uc.Core.MaxOpenStaticFiles = 1
+	for _, name := range []string{"a.js", "b.js", "nested/c.js"} {
+		env.createTestFile(t, filepath.Join("public-static", name), "console.log('"+name+"');")
+	}
+	env.config.fileSemaphore = semaphore.NewWeighted(int64(env.config.get_max_open_static_files()))
+	if err := env.config.handlePublicFiles(false); err != nil {
+		t.Fatalf("handlePublicFiles() error = %v", err)
+	}
+	fileMap, err := env.config.loadMapFromGob(PublicFileMapGobName, true)
+	if err != nil {
+		t.Fatalf("loadMapFromGob() error = %v", err)
+	}
+	if len(fileMap) != 3 {
+		t.Errorf("expected 3 processed files, got %d", len(fileMap))
+	}
+}
+
+func TestPublicAssetManifest(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "public-static/app.js", "console.log('app');")
+	env.createTestFile(t, "public-static/prehashed/robots.txt", "User-agent: *")
+
+	if err := env.config.handlePublicFiles(false); err != nil {
+		t.Fatalf("handlePublicFiles() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(testRootDir, "dist/static/assets/public", PublicAssetManifestName))
+	if err != nil {
+		t.Fatalf("expected public asset manifest: %v", err)
+	}
+	var manifest PublicAssetManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("invalid manifest JSON: %v", err)
+	}
+
+	if manifest.Version != 1 || manifest.PublicPathPrefix != env.config.GetPublicPathPrefix() {
+		t.Errorf("unexpected manifest header: %+v", manifest)
+	}
+	if len(manifest.Assets) != 2 {
+		t.Fatalf("expected 2 assets, got %+v", manifest.Assets)
+	}
+	app := manifest.Assets["app.js"]
+	if app.Prehashed || app.File == "app.js" || !strings.HasSuffix(app.File, ".js") {
+		t.Errorf("expected app.js to be hashed, got %+v", app)
+	}
+	if _, err := os.Stat(filepath.Join(testRootDir, "dist/static/assets/public", app.File)); err != nil {
+		t.Errorf("manifest file for app.js does not exist: %v", err)
+	}
+	if robots := manifest.Assets["robots.txt"]; !robots.Prehashed || robots.File != "robots.txt" {
+		t.Errorf("expected robots.txt to be prehashed, got %+v", robots)
+	}
+
+	// Stable across rebuilds of the same files
+	if err := env.config.handlePublicFiles(false); err != nil {
+		t.Fatalf("handlePublicFiles() error = %v", err)
+	}
+	again, err := os.ReadFile(filepath.Join(testRootDir, "dist/static/assets/public", PublicAssetManifestName))
+	if err != nil || string(again) != string(content) {
+		t.Errorf("expected identical manifest on rebuild (err: %v)", err)
+	}
+}
+
+func TestDiscardRetainedVersions(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	c := env.config
+	c._uc.Core.RetainAssetVersions = 1
+
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+	env.createTestFile(t, "main.css", "p { font-size: 16px; }")
+	env.createTestFile(t, "public-static/app.js", "console.log(1);")
+	if err := c.do_build_time_file_processing(build_time_file_processing_opts{}); err != nil {
+		t.Fatalf("do_build_time_file_processing() error = %v", err)
+	}
+	env.createTestFile(t, "public-static/app.js", "console.log(2);")
+	if err := c.do_build_time_file_processing(build_time_file_processing_opts{}); err != nil {
+		t.Fatalf("do_build_time_file_processing() error = %v", err)
+	}
+	if versions, _ := c.read_public_versions_buildtime(); len(versions) != 1 {
+		t.Fatalf("expected 1 retained version before discarding, got %v", versions)
+	}
+
+	// Without the option, an unchanged rebuild carries the version over
+	if err := c.do_build_time_file_processing(build_time_file_processing_opts{}); err != nil {
+		t.Fatalf("do_build_time_file_processing() error = %v", err)
+	}
+	if versions, _ := c.read_public_versions_buildtime(); len(versions) != 1 {
+		t.Fatalf("expected retained version to be carried over, got %v", versions)
+	}
+
+	if err := c.do_build_time_file_processing(build_time_file_processing_opts{discard_retained_versions: true}); err != nil {
+		t.Fatalf("do_build_time_file_processing() (discard) error = %v", err)
+	}
+	if versions, _ := c.read_public_versions_buildtime(); len(versions) != 0 {
+		t.Errorf("expected no retained versions after discarding, got %v", versions)
+	}
+	entries, err := os.ReadDir(c._dist.S().Static.S().Assets.S().PublicVersions.FullPath())
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("Failed to read public versions dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no retained versions on disk, got %v", entries)
+	}
+	if _, err := c.loadMapFromGob(PublicFileMapGobName, true); err != nil {
+		t.Errorf("expected a fresh public file map after discarding: %v", err)
+	}
+}
+
+func TestStrictWarnings(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	c := env.config
+
+	c.BuildWarn("before collecting")
+	stop := c.collect_build_warnings()
+	if err := stop(); err != nil {
+		t.Errorf("expected no error without warnings, got %v", err)
+	}
+
+	stop = c.collect_build_warnings()
+	c.BuildWarn("Build report baseline not found", "baseline", "base.json")
+	c.BuildWarn("unused CSS: a.css")
+	err := stop()
+	if !errors.Is(err, ErrBuildWarnings) {
+		t.Fatalf("expected ErrBuildWarnings, got %v", err)
+	}
+	for _, want := range []string{"baseline=base.json", "unused CSS: a.css"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "before collecting") {
+		t.Errorf("expected warnings outside collection to be ignored, got %q", err.Error())
+	}
+
+	c.BuildWarn("after collecting")
+	if c._build_warnings != nil {
+		t.Errorf("expected collection to stop")
+	}
+}
+
+func TestBuildWaveBlankHook(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+	env.createTestFile(t, "main.css", "p { color: blue; }")
+
+	for _, tt := range []struct {
+		name  string
+		isDev bool
+		set   func(hook string)
+	}{
+		{"ProdBuildHook", false, func(hook string) { env.config._uc.Core.ProdBuildHook = hook }},
+		{"DevBuildHook", true, func(hook string) { env.config._uc.Core.DevBuildHook = hook }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.set("   ")
+			defer tt.set("")
+			err := env.config.BuildWave(BuildOptions{IsDev: tt.isDev})
+			if err == nil || !strings.Contains(err.Error(), "Core."+tt.name) {
+				t.Errorf("expected an error naming Core.%s, got %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestBuildWaveConcurrentGoCompileJoinsErrors(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+	env.createTestFile(t, "main.css", "p { color: blue; }")
+
+	c := env.config
+	c._uc.Core.ConcurrentGoCompile = true
+	// Fails the second file processing pass (which overlaps the compile)
+	c._uc.Core.ProdBuildHook = "rm " + filepath.Join(testRootDir, "main.css")
+	// Fails the compile, as there is no such package
+	c._uc.Core.MainAppEntry = "cmd/missing"
+
+	err := c.BuildWave(BuildOptions{RecompileGoBinary: true})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"error processing build time files", "error compiling binary"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err.Error())
+		}
+	}
+}
+
+func TestBuildWavePostCompileHook(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+	env.createTestFile(t, "main.css", "p { color: blue; }")
+	env.createTestFile(t, "cmd/app/main.go", "package main\n\nfunc main() {}\n")
+
+	c := env.config
+	c._uc.Core.MainAppEntry = filepath.Join(testRootDir, "cmd/app")
+	envOut := filepath.Join(testRootDir, "post_compile_env.txt")
+	c._uc.Core.PostCompileHook = "sh -c env>" + envOut
+
+	if err := c.BuildWave(BuildOptions{}); err != nil {
+		t.Fatalf("BuildWave() error = %v", err)
+	}
+	if _, err := os.Stat(envOut); !os.IsNotExist(err) {
+		t.Fatalf("expected no post compile hook run without RecompileGoBinary, got %v", err)
+	}
+
+	if err := c.BuildWave(BuildOptions{RecompileGoBinary: true}); err != nil {
+		t.Fatalf("BuildWave() error = %v", err)
+	}
+	if _, err := os.Stat(c.get_binary_output_path()); err != nil {
+		t.Fatalf("expected a compiled binary: %v", err)
+	}
+	content, err := os.ReadFile(envOut)
+	if err != nil {
+		t.Fatalf("expected the post compile hook to run: %v", err)
+	}
+	want := post_compile_hook_binary_path_env_var + "=" + c.get_binary_output_path()
+	if !strings.Contains(string(content), want+"\n") {
+		t.Errorf("expected %q in the hook's environment", want)
+	}
+
+	c._uc.Core.PostCompileHook = "false"
+	err = c.BuildWave(BuildOptions{RecompileGoBinary: true})
+	if err == nil || !strings.Contains(err.Error(), "error running post compile hook") {
+		t.Errorf("expected a post compile hook error, got %v", err)
+	}
+}
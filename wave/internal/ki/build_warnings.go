@@ -0,0 +1,74 @@
+package ki
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrBuildWarnings is wrapped by the error BuildWave returns when
+// BuildOptions.StrictWarnings is set and the build produced warnings.
+var ErrBuildWarnings = errors.New("build produced warnings")
+
+// Set in the environment of the prod build hook when
+// BuildOptions.StrictWarnings is set, so that the warnings the hook
+// reports (via BuildWarn) fail it too.
+const strict_warnings_env_var = "WAVE_STRICT_WARNINGS"
+
+type build_warnings struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+// BuildWarn logs a warning about the build (with slog-style key-value
+// args). If the current build was started with StrictWarnings (including
+// a prod build hook run by such a build), the warning is also collected,
+// so that it fails the build. Outside of a build, it just logs.
+func (c *Config) BuildWarn(msg string, args ...any) {
+	c.Logger.Warn(msg, args...)
+
+	c._build_warnings_mu.Lock()
+	w := c._build_warnings
+	c._build_warnings_mu.Unlock()
+	if w == nil {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&sb, " %v=%v", args[i], args[i+1])
+	}
+	w.mu.Lock()
+	w.msgs = append(w.msgs, sb.String())
+	w.mu.Unlock()
+}
+
+// Starts collecting warnings reported via BuildWarn. The returned func
+// stops collecting, and returns an error wrapping ErrBuildWarnings that
+// lists them, if there were any.
+func (c *Config) collect_build_warnings() (stop func() error) {
+	w := &build_warnings{}
+	c._build_warnings_mu.Lock()
+	c._build_warnings = w
+	c._build_warnings_mu.Unlock()
+
+	return func() error {
+		c._build_warnings_mu.Lock()
+		c._build_warnings = nil
+		c._build_warnings_mu.Unlock()
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if len(w.msgs) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w (StrictWarnings is set): %s", ErrBuildWarnings, strings.Join(w.msgs, "; "))
+	}
+}
+
+func is_strict_warnings_hook() bool {
+	return os.Getenv(strict_warnings_env_var) == "1"
+}
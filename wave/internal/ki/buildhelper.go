@@ -5,9 +5,22 @@ import (
 )
 
 func (c *Config) BuildWaveWithHook(hook func(isDev bool) error) {
+	c.BuildWaveWithHooks(hook, nil)
+}
+
+// Flag passed to the dev build hook for watched files with
+// OnlyRegenerateTypes set.
+const types_only_flag = "types-only"
+
+func (c *Config) BuildWaveWithHooks(hook func(isDev bool) error, typesOnlyHook func() error) {
 	devModeFlag := flag.Bool("dev", false, "set dev mode")
 	hookModeFlag := flag.Bool("hook", false, "set hook mode")
+	typesOnlyFlag := flag.Bool(types_only_flag, false, "with -hook, only regenerate types")
 	noBinaryFlag := flag.Bool("no-binary", false, "skip go binary compilation")
+	discardRetainedVersionsFlag := flag.Bool("discard-retained-versions", false, "drop retained public asset versions before building")
+	strictWarningsFlag := flag.Bool("strict-warnings", false, "fail the build if it produces any warnings")
+	doctorFlag := flag.Bool("doctor", false, "validate config and exit")
+	portFlag := flag.Int("port", 0, "app server port in dev mode (overrides PORT and Watch.AppPort)")
 
 	flag.Parse()
 
@@ -15,19 +28,67 @@ func (c *Config) BuildWaveWithHook(hook func(isDev bool) error) {
 	isHook := *hookModeFlag
 	noBinary := *noBinaryFlag
 
+	if *doctorFlag {
+		c.runDoctor()
+		return
+	}
+
+	if isHook && *typesOnlyFlag {
+		if typesOnlyHook == nil {
+			panic("the -types-only flag is not supported by this build program")
+		}
+		if err := typesOnlyHook(); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	if isHook {
+		var stop func() error
+		if !isDev && is_strict_warnings_hook() {
+			stop = c.collect_build_warnings()
+		}
 		if err := hook(isDev); err != nil {
 			panic(err)
 		}
+		if stop != nil {
+			if err := stop(); err != nil {
+				panic(err)
+			}
+		}
 		return
 	}
 
 	if isDev {
+		if *portFlag != 0 {
+			setPort(*portFlag)
+		}
 		c.MustStartDev()
 		return
 	}
 
-	if err := c.BuildWave(BuildOptions{RecompileGoBinary: !noBinary}); err != nil {
+	if err := c.BuildWave(BuildOptions{
+		RecompileGoBinary:       !noBinary,
+		DiscardRetainedVersions: *discardRetainedVersionsFlag,
+		StrictWarnings:          *strictWarningsFlag,
+	}); err != nil {
 		panic(err)
 	}
 }
+
+// Logs every config diagnostic, and panics if any of them is an error so
+// that the process exits non-zero (e.g., to fail a CI step).
+func (c *Config) runDoctor() {
+	diagnostics := c.Validate()
+	for _, d := range diagnostics {
+		if d.Severity == DiagnosticSeverityError {
+			c.Logger.Error(d.String())
+		} else {
+			c.Logger.Warn(d.String())
+		}
+	}
+	if HasDiagnosticErrors(diagnostics) {
+		panic(ErrConfigValidation)
+	}
+	c.Logger.Info("Config OK", "warnings", len(diagnostics))
+}
@@ -5,6 +5,9 @@ import (
 	"io/fs"
 	"log/slog"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -60,6 +63,7 @@ type runtimeCache struct {
 	public_filemap_details  *safecache.Cache[*publicFileMapDetails]
 	public_urls             *safecache.CacheMap[string, string, string]
 	is_public_asset         *safecache.CacheMap[string, string, bool]
+	prehashed_dist_names    *safecache.Cache[map[string]struct{}]
 }
 
 func (c *Config) InitRuntimeCache() {
@@ -85,6 +89,7 @@ func (c *Config) InitRuntimeCache() {
 		is_public_asset: safecache.NewMap(c.getInitialIsPublicAsset, publicURLsKeyMaker, func(string) bool {
 			return GetIsDev()
 		}),
+		prehashed_dist_names: safecache.New(c.getInitialPrehashedDistNames, GetIsDev),
 	}
 }
 
@@ -118,6 +123,9 @@ type Config struct {
 
 	_rebuild_cleanup_chan chan struct{}
 	_vite_dev_ctx         *viteutil.BuildCtx
+
+	_build_warnings_mu sync.Mutex
+	_build_warnings    *build_warnings // non-nil while collecting (see BuildWarn)
 }
 
 type CleanSources struct {
@@ -145,6 +153,29 @@ func (c *Config) GetPublicPathPrefix() string {
 	return matcher.EnsureLeadingSlash(matcher.EnsureTrailingSlash(p))
 }
 
+// GetPublicAssetsURLPrefix returns the prefix to use when generating URLs
+// that reference public assets. This is the PublicPathPrefix, preceded by
+// the PublicAssetsOrigin (if set and not in dev mode). Use
+// GetPublicPathPrefix instead when matching incoming request paths.
+func (c *Config) GetPublicAssetsURLPrefix() string {
+	return c.getPublicAssetsOrigin() + c.GetPublicPathPrefix()
+}
+
+func (c *Config) getPublicAssetsOrigin() string {
+	if c._uc.Core.PublicAssetsOrigin == "" || GetIsDev() {
+		return ""
+	}
+	return strings.TrimRight(c._uc.Core.PublicAssetsOrigin, "/")
+}
+
+// toPublicAssetURL joins the given elements onto the PublicPathPrefix,
+// preceded by the PublicAssetsOrigin (if applicable).
+func (c *Config) toPublicAssetURL(elems ...string) string {
+	return c.getPublicAssetsOrigin() + matcher.EnsureLeadingSlash(
+		path.Join(append([]string{c._uc.Core.PublicPathPrefix}, elems...)...),
+	)
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// USER CONFIG
 /////////////////////////////////////////////////////////////////////
@@ -171,15 +202,49 @@ type UserConfig struct {
 }
 
 type UserConfigCore struct {
-	ConfigLocation   string
-	DevBuildHook     string
-	ProdBuildHook    string
-	MainAppEntry     string
-	DistDir          string
-	StaticAssetDirs  StaticAssetDirs
-	CSSEntryFiles    CSSEntryFiles
+	ConfigLocation string
+	DevBuildHook   string
+	ProdBuildHook  string
+	// Optional command run after Wave compiles the Go binary (e.g., to
+	// codesign or compress it). The binary's path is exposed to the
+	// command via the WAVE_BINARY_PATH env var.
+	PostCompileHook string
+	MainAppEntry    string
+	DistDir         string
+	StaticAssetDirs StaticAssetDirs
+	// Optional glob patterns (relative to StaticAssetDirs.Public) for
+	// public files that should keep their original names, wherever they
+	// live in the public tree (e.g., "sw.js", ".well-known/**").
+	NoHashGlobs   []string
+	CSSEntryFiles CSSEntryFiles
+	// Optional esbuild settings for bundling your CSS entry files.
+	CSSBuild         *CSSBuild
 	PublicPathPrefix string
-	ServerOnlyMode   bool
+	// Optional absolute origin (e.g., "https://cdn.example.com") that
+	// generated public asset URLs are prefixed with outside of dev mode.
+	PublicAssetsOrigin string
+	ServerOnlyMode     bool
+	// If true, Wave generates an embed.go file in DistDir that embeds
+	// the processed dist/static tree and exposes it via StaticFS().
+	GenerateEmbedFile bool
+	// If true, prod builds compile the Go binary concurrently with the
+	// post-hook asset processing. Only safe if your binary does not embed
+	// the processed dist/static tree (ignored if GenerateEmbedFile is set).
+	ConcurrentGoCompile bool
+	// If set, prod builds write a JSON report of public asset and CSS
+	// bundle sizes, compared against an optional baseline report.
+	BuildReport *BuildReport
+	// If greater than zero, prod builds keep the public assets of up to
+	// this many previous builds (under dist/static/assets/public_versions),
+	// and the static handler falls back to them for files missing from
+	// the current build, so that in-flight clients don't 404 mid-deploy.
+	RetainAssetVersions int
+	// Number of workers that hash and copy static files. Defaults to 4.
+	// Set to -1 to use GOMAXPROCS.
+	StaticFileWorkers int
+	// Max number of static files open at once during processing.
+	// Defaults to 100. Set to -1 to use 4x GOMAXPROCS.
+	MaxOpenStaticFiles int
 }
 
 func (c *Config) GetConfigFile() string {
@@ -194,12 +259,59 @@ type StaticAssetDirs struct {
 type CSSEntryFiles struct {
 	Critical    string
 	NonCritical string
+	// If true, Critical and NonCritical are resolved relative to
+	// Vite.JSPackageManagerCmdDir (as Vite.ViteConfigFile is) rather
+	// than the directory you run commands from.
+	RelativeToJSPackageManagerCmdDir bool
+	// Optional command run immediately before Wave bundles the CSS entry
+	// files (e.g., to generate them with the Tailwind or PostCSS CLI).
+	PreHook string
+	// Optional command run immediately after Wave bundles the CSS entry
+	// files.
+	PostHook string
+}
+
+// resolveCSSEntryPath resolves a CSS entry path per
+// CSSEntryFiles.RelativeToJSPackageManagerCmdDir.
+func resolveCSSEntryPath(uc *UserConfig, p string) string {
+	if p == "" {
+		return ""
+	}
+	if uc.Core.CSSEntryFiles.RelativeToJSPackageManagerCmdDir && uc.Vite != nil {
+		return filepath.Join(uc.Vite.JSPackageManagerCmdDir, p)
+	}
+	return filepath.Clean(p)
+}
+
+type CSSBuild struct {
+	// esbuild targets (e.g., "chrome58", "safari11", "es2020"). Newer CSS
+	// syntax is lowered (or prefixed) for these. Defaults to esbuild's
+	// default (i.e., no lowering).
+	Target []string
+	// Map of file extensions to esbuild loaders (e.g., ".svg": "dataurl")
+	// for files referenced from your CSS. Files with a loader set here
+	// are bundled by esbuild instead of being resolved to public URLs.
+	Loaders map[string]string
+}
+
+type BuildReport struct {
+	// Where to write the report. Defaults to "build-report.json" in
+	// Core.DistDir.
+	OutFile string
+	// Optional path to a previous build's report to compare against
+	// (e.g., one saved from your main branch in CI). If the file does not
+	// exist, the report is written without a comparison.
+	Baseline string
+	// Growth (in percent) above which an asset that exists in the
+	// baseline is flagged as a regression. Defaults to 10.
+	RegressionThresholdPercent float64
 }
 
 type UserConfigVite struct {
 	JSPackageManagerBaseCmd string
 	JSPackageManagerCmdDir  string
 	DefaultPort             int
+	StrictPort              bool
 	ViteConfigFile          string
 }
 
@@ -209,6 +321,7 @@ type UserConfigRiver struct {
 	HTMLTemplateLocation       string // Relative to your static private dir
 	ClientEntry                string
 	ClientRouteDefsFile        string
+	ClientRoutesDir            string // e.g., "frontend/src/routes"
 	TSGenOutPath               string // e.g., "frontend/src/river.gen.ts"
 	BuildtimePublicURLFuncName string // e.g., "waveURL", "withHash", etc.
 }
@@ -225,6 +338,9 @@ func (c *Config) GetRiverClientEntry() string {
 func (c *Config) GetRiverClientRouteDefsFile() string {
 	return c._uc.River.ClientRouteDefsFile
 }
+func (c *Config) GetRiverClientRoutesDir() string {
+	return c._uc.River.ClientRoutesDir
+}
 func (c *Config) GetRiverTSGenOutPath() string {
 	return c._uc.River.TSGenOutPath
 }
@@ -239,8 +355,18 @@ func (c *Config) GetRiverBuildtimePublicURLFuncName() string {
 type UserConfigWatch struct {
 	WatchRoot           string
 	HealthcheckEndpoint string
-	Include             []WatchedFile
-	Exclude             struct {
+	// Optional app server port for dev mode (default 8080). The PORT env
+	// var and the -port flag take precedence over this.
+	AppPort int
+	// If true, dev mode fails if the app port is taken instead of moving
+	// on to the next free port.
+	StrictAppPort bool
+	// If true, dev mode still rebuilds and processes assets on change,
+	// but the browser reload client is neither injected nor served, so
+	// open pages never reload (or show rebuild/test/error overlays).
+	DisableBrowserReload bool
+	Include              []WatchedFile
+	Exclude              struct {
 		Dirs  []string
 		Files []string
 	}
@@ -261,4 +387,18 @@ type WatchedFile struct {
 	RunOnChangeOnly                    bool
 	SkipRebuildingNotification         bool
 	TreatAsNonGo                       bool
+	// If set, this command runs (instead of a rebuild) when a matching
+	// file changes, and its result is shown in the browser's dev overlay.
+	// The browser is never reloaded.
+	TestCmd string
+	// If true, a change to a matching file only regenerates the
+	// framework's TypeScript types (by running Core.DevBuildHook with the
+	// -types-only flag), without rebuilding or restarting the app. The
+	// browser is not reloaded, unless OnlyRunClientDefinedRevalidateFunc
+	// is also set. Ignored if TestCmd is set.
+	OnlyRegenerateTypes bool
+
+	// Internal (defaults only): if true, writes to matching files are not
+	// considered a match, only creations, removals, and renames.
+	only_on_create_or_remove bool
 }
@@ -0,0 +1,152 @@
+package ki
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// CONFIG ENV VAR INTERPOLATION
+/////////////////////////////////////////////////////////////////////
+
+// String values in the config may reference environment variables as
+// ${VAR}, or ${VAR:-default} to fall back to default if VAR is unset or
+// empty (as in POSIX shells). "$${" is a literal "${". For number and
+// boolean fields (e.g., Watch.AppPort), the value may be given as a
+// string, which must expand to a valid number or boolean.
+
+var config_env_var_regex = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expand_config_env_vars returns the config JSON with all environment
+// variable references expanded, along with a diagnostic for each value
+// that could not be expanded. If the config has no references, it is
+// returned as is.
+func expand_config_env_vars(configJSON []byte) ([]byte, []Diagnostic) {
+	if !bytes.Contains(configJSON, []byte("${")) {
+		return configJSON, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(configJSON))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		// Leave reporting malformed JSON to the caller's own unmarshal
+		return configJSON, nil
+	}
+
+	var diagnostics []Diagnostic
+	tree = expand_config_env_vars_in(tree, reflect.TypeOf(UserConfig{}), "", &diagnostics)
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+
+	expanded, err := json.Marshal(tree)
+	if err != nil {
+		return nil, []Diagnostic{diagnosticError("", fmt.Sprintf("failed to re-encode config after expanding environment variables: %v", err))}
+	}
+	return expanded, nil
+}
+
+func expand_config_env_vars_in(value any, t reflect.Type, field string, diagnostics *[]Diagnostic) any {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for k, child := range v {
+			childField := k
+			if field != "" {
+				childField = field + "." + k
+			}
+			v[k] = expand_config_env_vars_in(child, config_env_child_type(t, k), childField, diagnostics)
+		}
+		return v
+	case []any:
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for i, child := range v {
+			v[i] = expand_config_env_vars_in(child, elemType, fmt.Sprintf("%s[%d]", field, i), diagnostics)
+		}
+		return v
+	case string:
+		expanded, err := expand_env_var_refs(v)
+		if err != nil {
+			*diagnostics = append(*diagnostics, diagnosticError(field, err.Error()))
+			return v
+		}
+		if t == nil || expanded == v {
+			return expanded
+		}
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if _, err := strconv.ParseFloat(expanded, 64); err != nil {
+				*diagnostics = append(*diagnostics, diagnosticError(field, fmt.Sprintf("%q (expanded from %q) is not a valid number.", expanded, v)))
+				return v
+			}
+			return json.Number(expanded)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(expanded)
+			if err != nil {
+				*diagnostics = append(*diagnostics, diagnosticError(field, fmt.Sprintf("%q (expanded from %q) is not a valid boolean.", expanded, v)))
+				return v
+			}
+			return b
+		}
+		return expanded
+	default:
+		return v
+	}
+}
+
+// Returns the type of the struct field (matched case-insensitively, as
+// encoding/json does) or map value that key decodes into, or nil if
+// unknown (in which case nested strings are still expanded).
+func config_env_child_type(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		if f, ok := t.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, key) }); ok {
+			return f.Type
+		}
+	}
+	return nil
+}
+
+func expand_env_var_refs(s string) (string, error) {
+	var missing []string
+	expanded := config_env_var_regex.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		m := config_env_var_regex.FindStringSubmatch(ref)
+		name, hasDefault, def := m[1], m[2] != "", m[3]
+		if val := os.Getenv(name); val != "" {
+			return val
+		}
+		if hasDefault {
+			return def
+		}
+		if _, isSet := os.LookupEnv(name); !isSet {
+			missing = append(missing, name)
+		}
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set and has no default (use ${%s:-default} to provide one).", strings.Join(missing, ", "), missing[0])
+	}
+	return expanded, nil
+}
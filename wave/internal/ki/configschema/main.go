@@ -23,6 +23,18 @@ func Write(target string) error {
 	return nil
 }
 
+// Number and boolean fields may also be given as strings referencing
+// environment variables (e.g., "${PORT:-8080}"), which are expanded and
+// parsed when the config is loaded.
+func optionalNumber(sd jsonschema.Def) jsonschema.Entry {
+	sd.AltTypes = []string{jsonschema.TypeString}
+	return jsonschema.OptionalNumber(sd)
+}
+func optionalBoolean(sd jsonschema.Def) jsonschema.Entry {
+	sd.AltTypes = []string{jsonschema.TypeString}
+	return jsonschema.OptionalBoolean(sd)
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// ROOT
 /////////////////////////////////////////////////////////////////////
@@ -65,25 +77,45 @@ var Core_Schema = jsonschema.RequiredObject(jsonschema.Def{
 		},
 	}},
 	Properties: struct {
-		ConfigLocation   jsonschema.Entry
-		DevBuildHook     jsonschema.Entry
-		ProdBuildHook    jsonschema.Entry
-		MainAppEntry     jsonschema.Entry
-		DistDir          jsonschema.Entry
-		StaticAssetDirs  jsonschema.Entry
-		CSSEntryFiles    jsonschema.Entry
-		PublicPathPrefix jsonschema.Entry
-		ServerOnlyMode   jsonschema.Entry
+		ConfigLocation      jsonschema.Entry
+		DevBuildHook        jsonschema.Entry
+		ProdBuildHook       jsonschema.Entry
+		PostCompileHook     jsonschema.Entry
+		MainAppEntry        jsonschema.Entry
+		DistDir             jsonschema.Entry
+		StaticAssetDirs     jsonschema.Entry
+		NoHashGlobs         jsonschema.Entry
+		CSSEntryFiles       jsonschema.Entry
+		CSSBuild            jsonschema.Entry
+		PublicPathPrefix    jsonschema.Entry
+		PublicAssetsOrigin  jsonschema.Entry
+		ServerOnlyMode      jsonschema.Entry
+		GenerateEmbedFile   jsonschema.Entry
+		ConcurrentGoCompile jsonschema.Entry
+		BuildReport         jsonschema.Entry
+		RetainAssetVersions jsonschema.Entry
+		StaticFileWorkers   jsonschema.Entry
+		MaxOpenStaticFiles  jsonschema.Entry
 	}{
-		ConfigLocation:   ConfigLocation_Schema,
-		DevBuildHook:     DevBuildHook_Schema,
-		ProdBuildHook:    ProdBuildHook_Schema,
-		MainAppEntry:     MainAppEntry_Schema,
-		DistDir:          DistDir_Schema,
-		StaticAssetDirs:  StaticAssetDirs_Schema,
-		CSSEntryFiles:    CSSEntryFiles_Schema,
-		PublicPathPrefix: PublicPathPrefix_Schema,
-		ServerOnlyMode:   ServerOnlyMode_Schema,
+		ConfigLocation:      ConfigLocation_Schema,
+		DevBuildHook:        DevBuildHook_Schema,
+		ProdBuildHook:       ProdBuildHook_Schema,
+		PostCompileHook:     PostCompileHook_Schema,
+		MainAppEntry:        MainAppEntry_Schema,
+		DistDir:             DistDir_Schema,
+		StaticAssetDirs:     StaticAssetDirs_Schema,
+		NoHashGlobs:         NoHashGlobs_Schema,
+		CSSEntryFiles:       CSSEntryFiles_Schema,
+		CSSBuild:            CSSBuild_Schema,
+		PublicPathPrefix:    PublicPathPrefix_Schema,
+		PublicAssetsOrigin:  PublicAssetsOrigin_Schema,
+		ServerOnlyMode:      ServerOnlyMode_Schema,
+		GenerateEmbedFile:   GenerateEmbedFile_Schema,
+		ConcurrentGoCompile: ConcurrentGoCompile_Schema,
+		BuildReport:         BuildReport_Schema,
+		RetainAssetVersions: RetainAssetVersions_Schema,
+		StaticFileWorkers:   StaticFileWorkers_Schema,
+		MaxOpenStaticFiles:  MaxOpenStaticFiles_Schema,
 	},
 })
 
@@ -114,6 +146,15 @@ var ProdBuildHook_Schema = jsonschema.OptionalString(jsonschema.Def{
 	Examples:    []string{"go run ./backend/cmd/build", "make prod-generate"},
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- POST COMPILE HOOK
+/////////////////////////////////////////////////////////////////////
+
+var PostCompileHook_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Command to run after Wave compiles your Go binary (e.g., to codesign or compress it). Only runs when a build actually compiles the binary. The binary's path is available to the command via the WAVE_BINARY_PATH environment variable.`,
+	Examples:    []string{"./scripts/codesign.sh", "upx --best dist/main"},
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// CORE SETTINGS -- APP ENTRY
 /////////////////////////////////////////////////////////////////////
@@ -157,6 +198,16 @@ var Public_Schema = jsonschema.RequiredString(jsonschema.Def{
 	Examples:    []string{"./static/public"},
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- NO HASH GLOBS
+/////////////////////////////////////////////////////////////////////
+
+var NoHashGlobs_Schema = jsonschema.OptionalArray(jsonschema.Def{
+	Description: `Glob patterns (set relative to Core.StaticAssetDirs.Public) for public files that should keep their original names instead of being content-hashed, wherever they live in the public tree. Useful for service workers, .well-known files, and the like.`,
+	Items:       jsonschema.OptionalString(jsonschema.Def{}),
+	Examples:    []string{"sw.js", "robots.txt", ".well-known/**", "**/*.webmanifest"},
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// CORE SETTINGS -- CSS ENTRY FILES
 /////////////////////////////////////////////////////////////////////
@@ -164,11 +215,17 @@ var Public_Schema = jsonschema.RequiredString(jsonschema.Def{
 var CSSEntryFiles_Schema = jsonschema.OptionalObject(jsonschema.Def{
 	Description: `Use this if you are using Wave's CSS features. Wave will bundle and optimize your CSS files.`,
 	Properties: struct {
-		Critical    jsonschema.Entry
-		NonCritical jsonschema.Entry
+		Critical                         jsonschema.Entry
+		NonCritical                      jsonschema.Entry
+		RelativeToJSPackageManagerCmdDir jsonschema.Entry
+		PreHook                          jsonschema.Entry
+		PostHook                         jsonschema.Entry
 	}{
-		Critical:    Critical_Schema,
-		NonCritical: NonCritical_Schema,
+		Critical:                         Critical_Schema,
+		NonCritical:                      NonCritical_Schema,
+		RelativeToJSPackageManagerCmdDir: RelativeToJSPackageManagerCmdDir_Schema,
+		PreHook:                          CSSPreHook_Schema,
+		PostHook:                         CSSPostHook_Schema,
 	},
 })
 
@@ -182,6 +239,47 @@ var NonCritical_Schema = jsonschema.OptionalString(jsonschema.Def{
 	Examples:    []string{"./styles/main.css"},
 })
 
+var RelativeToJSPackageManagerCmdDir_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, Critical and NonCritical are resolved relative to Vite.JSPackageManagerCmdDir (as Vite.ViteConfigFile is) rather than the directory from which you run commands. Useful when your CSS lives alongside your frontend code (e.g., in "./web").`,
+	Default:     false,
+})
+
+var CSSPreHook_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Command to run immediately before Wave bundles your CSS entry files (e.g., to generate them with the Tailwind or PostCSS CLI). Runs on every build, including dev rebuilds, except those triggered by changes to the CSS entry files themselves (or their imports), so that the files it writes don't retrigger it. The entry files it generates need not exist before the first build.`,
+	Examples:    []string{"npx @tailwindcss/cli -i ./styles/tailwind.css -o ./styles/main.css"},
+})
+
+var CSSPostHook_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Command to run immediately after Wave bundles your CSS entry files. Runs whenever PreHook does.`,
+	Examples:    []string{"./scripts/check-css-size.sh"},
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- CSS BUILD
+/////////////////////////////////////////////////////////////////////
+
+var CSSBuild_Schema = jsonschema.OptionalObject(jsonschema.Def{
+	Description: `esbuild settings for bundling your CSS entry files. If not set, Wave uses esbuild's defaults.`,
+	Properties: struct {
+		Target  jsonschema.Entry
+		Loaders jsonschema.Entry
+	}{
+		Target:  CSSBuildTarget_Schema,
+		Loaders: CSSBuildLoaders_Schema,
+	},
+})
+
+var CSSBuildTarget_Schema = jsonschema.OptionalArray(jsonschema.Def{
+	Description: `esbuild targets for your CSS (browser versions such as "chrome58" or "safari11.1", and optionally one ES version such as "es2020"). Newer CSS syntax is lowered (or prefixed) so that it works in these browsers. Defaults to esbuild's default (no lowering).`,
+	Items:       jsonschema.OptionalString(jsonschema.Def{}),
+	Examples:    []string{`["chrome58", "firefox57", "safari11"]`},
+})
+
+var CSSBuildLoaders_Schema = jsonschema.OptionalObject(jsonschema.Def{
+	Description: `Map of file extensions to esbuild loaders ("base64", "binary", "copy", "css", "dataurl", "empty", "file", "global-css", "local-css", or "text") for files referenced from your CSS. Files with a loader set here are bundled by esbuild (e.g., inlined as data URLs) instead of being resolved to public static asset URLs.`,
+	Examples:    []string{`{".svg": "dataurl", ".pcss": "css"}`},
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// CORE SETTINGS -- PUBLIC PATH PREFIX
 /////////////////////////////////////////////////////////////////////
@@ -192,15 +290,97 @@ var PublicPathPrefix_Schema = jsonschema.OptionalString(jsonschema.Def{
 	Default:     "/",
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- PUBLIC ASSETS ORIGIN
+/////////////////////////////////////////////////////////////////////
+
+var PublicAssetsOrigin_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Absolute origin (scheme and host) to prepend to generated public asset URLs, for when your public assets are served from a CDN. Asset URLs become this origin followed by your PublicPathPrefix. App routes and incoming request matching are unaffected, and it is ignored in dev mode.`,
+	Examples:    []string{"https://cdn.example.com"},
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// CORE SETTINGS -- SERVER ONLY
 /////////////////////////////////////////////////////////////////////
 
-var ServerOnlyMode_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var ServerOnlyMode_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, skips static asset processing/serving and browser reloading. Use this for API-only servers without frontend assets.`,
 	Default:     false,
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- GENERATE EMBED FILE
+/////////////////////////////////////////////////////////////////////
+
+var GenerateEmbedFile_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, Wave generates an "embed.go" file in your DistDir that embeds the processed "static" directory into your Go binary and exposes it via a "StaticFS()" function, which you can pass to wave.Config.DistStaticFS. The package name is derived from the DistDir name. Enables single-binary deploys without hand-writing a go:embed directive.`,
+	Default:     false,
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- CONCURRENT GO COMPILE
+/////////////////////////////////////////////////////////////////////
+
+var ConcurrentGoCompile_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, prod builds compile your Go binary concurrently with the asset processing that runs after your build hook, which can meaningfully cut build times. Only enable this if your binary does not go:embed the processed "static" directory, as it may otherwise embed a stale or partial tree. Ignored when GenerateEmbedFile is true.`,
+	Default:     false,
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- BUILD REPORT
+/////////////////////////////////////////////////////////////////////
+
+var BuildReport_Schema = jsonschema.OptionalObject(jsonschema.Def{
+	Description: `If set, prod builds write a JSON report of your public asset and CSS bundle sizes (keyed by original, pre-hash file name), optionally compared against a baseline report from a previous build. Assets that grew by more than RegressionThresholdPercent are flagged as regressions and logged.`,
+	Properties: struct {
+		OutFile                    jsonschema.Entry
+		Baseline                   jsonschema.Entry
+		RegressionThresholdPercent jsonschema.Entry
+	}{
+		OutFile:                    BuildReportOutFile_Schema,
+		Baseline:                   BuildReportBaseline_Schema,
+		RegressionThresholdPercent: BuildReportRegressionThresholdPercent_Schema,
+	},
+})
+
+var BuildReportOutFile_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Where to write the report. Defaults to "build-report.json" in Core.DistDir.`,
+	Examples:    []string{"./dist/build-report.json", "./build-report.json"},
+})
+
+var BuildReportBaseline_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Path to a previous build's report to compare against (e.g., one saved from your main branch in CI). If the file does not exist, the report is written without a comparison.`,
+	Examples:    []string{"./baseline/build-report.json"},
+})
+
+var BuildReportRegressionThresholdPercent_Schema = optionalNumber(jsonschema.Def{
+	Description: `Growth (in percent) above which an asset that exists in the baseline report is flagged as a regression.`,
+	Default:     10,
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- RETAIN ASSET VERSIONS
+/////////////////////////////////////////////////////////////////////
+
+var RetainAssetVersions_Schema = optionalNumber(jsonschema.Def{
+	Description: `If greater than zero, prod builds keep the public assets of up to this many previous builds (under "static/assets/public_versions", keyed by each build's public file map hash), and Wave's static handler falls back to them for files missing from the current build. This lets clients still running an older build load its hashed chunks during a blue/green or rolling deploy. Has no effect in dev mode.`,
+	Default:     0,
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// CORE SETTINGS -- STATIC FILE CONCURRENCY
+/////////////////////////////////////////////////////////////////////
+
+var StaticFileWorkers_Schema = optionalNumber(jsonschema.Def{
+	Description: `Number of workers that hash and copy your static files during builds. Raise it on many-core machines with fast disks and thousands of public assets; lower it on constrained CI runners. Set to -1 to use GOMAXPROCS. Leave unset (or 0) for the default.`,
+	Default:     4,
+})
+
+var MaxOpenStaticFiles_Schema = optionalNumber(jsonschema.Def{
+	Description: `Maximum number of static files open at once during builds (shared across the public and private static dirs). Set to -1 to use 4x GOMAXPROCS. Leave unset (or 0) for the default.`,
+	Default:     100,
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// RIVER SETTINGS
 /////////////////////////////////////////////////////////////////////
@@ -213,6 +393,7 @@ var River_Schema = jsonschema.OptionalObject(jsonschema.Def{
 		HTMLTemplateLocation       jsonschema.Entry
 		ClientEntry                jsonschema.Entry
 		ClientRouteDefsFile        jsonschema.Entry
+		ClientRoutesDir            jsonschema.Entry
 		TSGenOutPath               jsonschema.Entry
 		BuildtimePublicURLFuncName jsonschema.Entry
 	}{
@@ -221,12 +402,13 @@ var River_Schema = jsonschema.OptionalObject(jsonschema.Def{
 		HTMLTemplateLocation:       HTMLTemplateLocation_Schema,
 		ClientEntry:                ClientEntry_Schema,
 		ClientRouteDefsFile:        ClientRouteDefsFile_Schema,
+		ClientRoutesDir:            ClientRoutesDir_Schema,
 		TSGenOutPath:               TSGenOutPath_Schema,
 		BuildtimePublicURLFuncName: BuildtimePublicURLFuncName_Schema,
 	},
 })
 
-var IncludeDefaults_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var IncludeDefaults_Schema = optionalBoolean(jsonschema.Def{
 	Description: `Whether to include River's default watch patterns and build hooks. Set to false if you want full control over the watch configuration.`,
 	Default:     true,
 })
@@ -250,11 +432,15 @@ var ClientEntry_Schema = jsonschema.OptionalString(jsonschema.Def{
 })
 
 var ClientRouteDefsFile_Schema = jsonschema.OptionalString(jsonschema.Def{
-	Description: `Path to the file where River route definitions are written.`,
-	Required:    true,
+	Description: `Path to the file where River route definitions are written. Required unless ClientRoutesDir is set.`,
 	Examples:    []string{"frontend/src/river.routes.ts"},
 })
 
+var ClientRoutesDir_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Path to a directory that River should scan for route modules (file-system routing). Patterns are derived from each module's path relative to this directory: "_route" files map to their parent directory's pattern, "$param" segments map to dynamic params, and "$" maps to a splat segment. Routes explicitly defined in ClientRouteDefsFile take precedence.`,
+	Examples:    []string{"frontend/src/routes"},
+})
+
 var TSGenOutPath_Schema = jsonschema.OptionalString(jsonschema.Def{
 	Description: `Path where TypeScript type definitions should be generated.`,
 	Required:    true,
@@ -276,11 +462,13 @@ var Vite_Schema = jsonschema.OptionalObject(jsonschema.Def{
 		JSPackageManagerBaseCmd jsonschema.Entry
 		JSPackageManagerCmdDir  jsonschema.Entry
 		DefaultPort             jsonschema.Entry
+		StrictPort              jsonschema.Entry
 		ViteConfigFile          jsonschema.Entry
 	}{
 		JSPackageManagerBaseCmd: JSPackageManagerBaseCmd_Schema,
 		JSPackageManagerCmdDir:  JSPackageManagerCmdDir_Schema,
 		DefaultPort:             DefaultPort_Schema,
+		StrictPort:              StrictPort_Schema,
 		ViteConfigFile:          ViteConfigFile_Schema,
 	},
 	RequiredChildren: []string{"JSPackageManagerBaseCmd"},
@@ -309,11 +497,20 @@ var JSPackageManagerCmdDir_Schema = jsonschema.OptionalString(jsonschema.Def{
 /////// VITE SETTINGS -- DEFAULT PORT
 /////////////////////////////////////////////////////////////////////
 
-var DefaultPort_Schema = jsonschema.OptionalNumber(jsonschema.Def{
+var DefaultPort_Schema = optionalNumber(jsonschema.Def{
 	Description: `Default port to use for Vite dev server. This is used when you run "wave dev" without specifying a port.`,
 	Default:     5173,
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// VITE SETTINGS -- STRICT PORT
+/////////////////////////////////////////////////////////////////////
+
+var StrictPort_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, "wave dev" fails with an error when the DefaultPort is already in use, instead of automatically moving on to the next free port.`,
+	Default:     false,
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// VITE SETTINGS -- CONFIG FILE
 /////////////////////////////////////////////////////////////////////
@@ -330,15 +527,21 @@ var ViteConfigFile_Schema = jsonschema.OptionalString(jsonschema.Def{
 var Watch_Schema = jsonschema.OptionalObject(jsonschema.Def{
 	Description: `File watching configuration for development mode. Controls which files trigger rebuilds and how.`,
 	Properties: struct {
-		WatchRoot           jsonschema.Entry
-		HealthcheckEndpoint jsonschema.Entry
-		Include             jsonschema.Entry
-		Exclude             jsonschema.Entry
+		WatchRoot            jsonschema.Entry
+		HealthcheckEndpoint  jsonschema.Entry
+		AppPort              jsonschema.Entry
+		StrictAppPort        jsonschema.Entry
+		DisableBrowserReload jsonschema.Entry
+		Include              jsonschema.Entry
+		Exclude              jsonschema.Entry
 	}{
-		WatchRoot:           WatchRoot_Schema,
-		HealthcheckEndpoint: HealthcheckEndpoint_Schema,
-		Include:             Include_Schema,
-		Exclude:             Exclude_Schema,
+		WatchRoot:            WatchRoot_Schema,
+		HealthcheckEndpoint:  HealthcheckEndpoint_Schema,
+		AppPort:              AppPort_Schema,
+		StrictAppPort:        StrictAppPort_Schema,
+		DisableBrowserReload: DisableBrowserReload_Schema,
+		Include:              Include_Schema,
+		Exclude:              Exclude_Schema,
 	},
 })
 
@@ -361,6 +564,29 @@ var HealthcheckEndpoint_Schema = jsonschema.OptionalString(jsonschema.Def{
 	Default:     "/",
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// WATCH SETTINGS -- APP PORT
+/////////////////////////////////////////////////////////////////////
+
+var AppPort_Schema = optionalNumber(jsonschema.Def{
+	Description: `Port your app server listens on during development. Overridden by the PORT environment variable and the -port flag. If the port is taken, Wave moves on to the next free port (unless StrictAppPort is set).`,
+	Default:     8080,
+})
+
+var StrictAppPort_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, Wave fails to start dev mode when the app port is already in use, instead of moving on to the next free port.`,
+	Default:     false,
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// WATCH SETTINGS -- DISABLE BROWSER RELOAD
+/////////////////////////////////////////////////////////////////////
+
+var DisableBrowserReload_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, dev mode still rebuilds your app and processes your assets on change, but Wave neither injects nor serves its browser reload client, so open pages are never reloaded (and show no rebuild, test, or build error overlays). Unlike ServerOnlyMode, asset processing is unaffected.`,
+	Default:     false,
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// WATCH SETTINGS -- INCLUDE
 /////////////////////////////////////////////////////////////////////
@@ -381,6 +607,8 @@ var IncludeItems_Schema = jsonschema.OptionalObject(jsonschema.Def{
 		RunOnChangeOnly                    jsonschema.Entry
 		SkipRebuildingNotification         jsonschema.Entry
 		TreatAsNonGo                       jsonschema.Entry
+		TestCmd                            jsonschema.Entry
+		OnlyRegenerateTypes                jsonschema.Entry
 	}{
 		Pattern:                            Pattern_Schema,
 		OnChangeHooks:                      OnChangeHooks_Schema,
@@ -390,6 +618,8 @@ var IncludeItems_Schema = jsonschema.OptionalObject(jsonschema.Def{
 		RunOnChangeOnly:                    RunOnChangeOnly_Schema,
 		SkipRebuildingNotification:         SkipRebuildingNotification_Schema,
 		TreatAsNonGo:                       TreatAsNonGo_Schema,
+		TestCmd:                            TestCmd_Schema,
+		OnlyRegenerateTypes:                OnlyRegenerateTypes_Schema,
 	},
 })
 
@@ -445,7 +675,7 @@ var OnChangeHooksExclude_Schema = jsonschema.OptionalArray(jsonschema.Def{
 /////// WATCH SETTINGS -- INCLUDE -- RECOMPILE BINARY
 /////////////////////////////////////////////////////////////////////
 
-var RecompileGoBinary_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var RecompileGoBinary_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, the Go binary will be recompiled when this file changes. Use for non-Go files that affect the Go build (e.g., embedded files).`,
 	Default:     false,
 })
@@ -454,7 +684,7 @@ var RecompileGoBinary_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
 /////// WATCH SETTINGS -- INCLUDE -- RESTART APP
 /////////////////////////////////////////////////////////////////////
 
-var RestartApp_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var RestartApp_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, the app will be restarted when this file changes. Use for files that are cached on startup (e.g., templates that are parsed once).`,
 	Default:     false,
 })
@@ -463,7 +693,7 @@ var RestartApp_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
 /////// WATCH SETTINGS -- INCLUDE -- RUN CLIENT DEFINED REVALIDATE FUNC
 /////////////////////////////////////////////////////////////////////
 
-var OnlyRunClientDefinedRevalidateFunc_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var OnlyRunClientDefinedRevalidateFunc_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, Wave will call window.__waveRevalidate() instead of reloading the page. Use with frameworks that support hot module replacement or client-side revalidation.`,
 	Default:     false,
 })
@@ -472,7 +702,7 @@ var OnlyRunClientDefinedRevalidateFunc_Schema = jsonschema.OptionalBoolean(jsons
 /////// WATCH SETTINGS -- INCLUDE -- RUN ON CHANGE ONLY
 /////////////////////////////////////////////////////////////////////
 
-var RunOnChangeOnly_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var RunOnChangeOnly_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, only the OnChangeHooks will run - Wave won't reload the browser. Use when your onChange hook triggers its own reload process. Note: OnChangeHooks must use "pre" timing (the default) with this option.`,
 	Default:     false,
 })
@@ -481,7 +711,7 @@ var RunOnChangeOnly_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
 /////// WATCH SETTINGS -- INCLUDE -- SKIP REBUILDING NOTIFICATION
 /////////////////////////////////////////////////////////////////////
 
-var SkipRebuildingNotification_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var SkipRebuildingNotification_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, Wave won't show the "Rebuilding..." overlay in the browser. Use with RunOnChangeOnly if your onChange doesn't trigger a rebuild, or for changes that don't need user notification.`,
 	Default:     false,
 })
@@ -490,11 +720,29 @@ var SkipRebuildingNotification_Schema = jsonschema.OptionalBoolean(jsonschema.De
 /////// WATCH SETTINGS -- INCLUDE -- TREAT AS NON GO
 /////////////////////////////////////////////////////////////////////
 
-var TreatAsNonGo_Schema = jsonschema.OptionalBoolean(jsonschema.Def{
+var TreatAsNonGo_Schema = optionalBoolean(jsonschema.Def{
 	Description: `If true, .go files matching this pattern won't trigger Go recompilation. Use for Go files that are independent from your main app (e.g., WASM files with separate build processes).`,
 	Default:     false,
 })
 
+/////////////////////////////////////////////////////////////////////
+/////// WATCH SETTINGS -- INCLUDE -- TEST CMD
+/////////////////////////////////////////////////////////////////////
+
+var TestCmd_Schema = jsonschema.OptionalString(jsonschema.Def{
+	Description: `Command to run when a file matching this pattern changes, instead of rebuilding. While it runs, the browser shows a "Running tests..." badge, and once it exits, a pass or fail badge (with the tail of the command's output on failure) based on its exit code. The browser is never reloaded. Other options for this pattern (including OnChangeHooks) are ignored.`,
+	Examples:    []string{"go test ./...", "npm test"},
+})
+
+/////////////////////////////////////////////////////////////////////
+/////// WATCH SETTINGS -- INCLUDE -- ONLY REGENERATE TYPES
+/////////////////////////////////////////////////////////////////////
+
+var OnlyRegenerateTypes_Schema = optionalBoolean(jsonschema.Def{
+	Description: `If true, a change to a file matching this pattern only regenerates the framework's TypeScript types (by running Core.DevBuildHook with the -types-only flag), without rebuilding or restarting the app. The browser is not reloaded (Vite's HMR picks up the regenerated file), unless OnlyRunClientDefinedRevalidateFunc is also set. Requires Core.DevBuildHook. Ignored if TestCmd is set.`,
+	Default:     false,
+})
+
 /////////////////////////////////////////////////////////////////////
 /////// WATCH SETTINGS -- EXCLUDE
 /////////////////////////////////////////////////////////////////////
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/river-now/river/kit/htmlutil"
-	"github.com/river-now/river/kit/matcher"
 )
 
 const (
@@ -62,9 +61,7 @@ func (c *Config) getInitialStyleSheetURL() (string, error) {
 		return "", err
 	}
 
-	return matcher.EnsureLeadingSlash(
-		path.Join(c._uc.Core.PublicPathPrefix, string(content)),
-	), nil
+	return c.toPublicAssetURL(string(content)), nil
 }
 
 func (c *Config) GetStyleSheetLinkElement() template.HTML {
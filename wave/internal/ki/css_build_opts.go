@@ -0,0 +1,124 @@
+package ki
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	esbuild "github.com/evanw/esbuild/pkg/api"
+)
+
+var esbuildTargets = map[string]esbuild.Target{
+	"esnext": esbuild.ESNext,
+	"es5":    esbuild.ES5,
+	"es2015": esbuild.ES2015,
+	"es2016": esbuild.ES2016,
+	"es2017": esbuild.ES2017,
+	"es2018": esbuild.ES2018,
+	"es2019": esbuild.ES2019,
+	"es2020": esbuild.ES2020,
+	"es2021": esbuild.ES2021,
+	"es2022": esbuild.ES2022,
+	"es2023": esbuild.ES2023,
+	"es2024": esbuild.ES2024,
+}
+
+var esbuildEngines = map[string]esbuild.EngineName{
+	"chrome":  esbuild.EngineChrome,
+	"deno":    esbuild.EngineDeno,
+	"edge":    esbuild.EngineEdge,
+	"firefox": esbuild.EngineFirefox,
+	"hermes":  esbuild.EngineHermes,
+	"ie":      esbuild.EngineIE,
+	"ios":     esbuild.EngineIOS,
+	"node":    esbuild.EngineNode,
+	"opera":   esbuild.EngineOpera,
+	"rhino":   esbuild.EngineRhino,
+	"safari":  esbuild.EngineSafari,
+}
+
+var esbuildLoaders = map[string]esbuild.Loader{
+	"base64":     esbuild.LoaderBase64,
+	"binary":     esbuild.LoaderBinary,
+	"copy":       esbuild.LoaderCopy,
+	"css":        esbuild.LoaderCSS,
+	"dataurl":    esbuild.LoaderDataURL,
+	"empty":      esbuild.LoaderEmpty,
+	"file":       esbuild.LoaderFile,
+	"global-css": esbuild.LoaderGlobalCSS,
+	"local-css":  esbuild.LoaderLocalCSS,
+	"text":       esbuild.LoaderText,
+}
+
+var engineTargetRegex = regexp.MustCompile(`^([a-z]+)(\d+(?:\.\d+){0,2})$`)
+
+// parseCSSBuildTargets parses esbuild-style targets (e.g., "es2020",
+// "chrome58", "safari11.1") into an esbuild target and engine list.
+func parseCSSBuildTargets(targets []string) (esbuild.Target, []esbuild.Engine, error) {
+	target := esbuild.DefaultTarget
+	var engines []esbuild.Engine
+	for _, raw := range targets {
+		t := strings.ToLower(strings.TrimSpace(raw))
+		if esTarget, ok := esbuildTargets[t]; ok {
+			if target != esbuild.DefaultTarget {
+				return 0, nil, fmt.Errorf("only one ES version target may be set (got %q)", raw)
+			}
+			target = esTarget
+			continue
+		}
+		m := engineTargetRegex.FindStringSubmatch(t)
+		if m == nil {
+			return 0, nil, fmt.Errorf("invalid target %q", raw)
+		}
+		engine, ok := esbuildEngines[m[1]]
+		if !ok {
+			return 0, nil, fmt.Errorf("unknown target engine %q", m[1])
+		}
+		engines = append(engines, esbuild.Engine{Name: engine, Version: m[2]})
+	}
+	return target, engines, nil
+}
+
+// parseCSSBuildLoaders parses a map of file extensions (e.g., ".svg") to
+// esbuild loader names (e.g., "dataurl").
+func parseCSSBuildLoaders(loaders map[string]string) (map[string]esbuild.Loader, error) {
+	if len(loaders) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]esbuild.Loader, len(loaders))
+	for ext, name := range loaders {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+			return nil, fmt.Errorf("loader extension %q must start with a dot (e.g., \".svg\")", ext)
+		}
+		loader, ok := esbuildLoaders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown loader %q for %q", name, ext)
+		}
+		parsed[ext] = loader
+	}
+	return parsed, nil
+}
+
+type cssBuildOpts struct {
+	target  esbuild.Target
+	engines []esbuild.Engine
+	loaders map[string]esbuild.Loader
+}
+
+// Config shape is validated up front (see userConfigShapeDiagnostics),
+// so errors here are unexpected.
+func (c *Config) getCSSBuildOpts() (*cssBuildOpts, error) {
+	opts := &cssBuildOpts{target: esbuild.DefaultTarget}
+	cb := c._uc.Core.CSSBuild
+	if cb == nil {
+		return opts, nil
+	}
+	var err error
+	if opts.target, opts.engines, err = parseCSSBuildTargets(cb.Target); err != nil {
+		return nil, fmt.Errorf("Core.CSSBuild.Target: %w", err)
+	}
+	if opts.loaders, err = parseCSSBuildLoaders(cb.Loaders); err != nil {
+		return nil, fmt.Errorf("Core.CSSBuild.Loaders: %w", err)
+	}
+	return opts, nil
+}
@@ -1,12 +1,14 @@
 package ki
 
 import (
+	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	esbuild "github.com/evanw/esbuild/pkg/api"
 	"github.com/river-now/river/kit/htmltestutil"
 )
 
@@ -61,6 +63,34 @@ func TestGetStyleSheetURL(t *testing.T) {
 	}
 }
 
+func TestGetStyleSheetURLWithPublicAssetsOrigin(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.config._uc.Core.PublicAssetsOrigin = "https://cdn.example.com/"
+
+	normalCSSFile := "normal_1234567890.css"
+	env.createTestFile(t, "dist/static/internal/normal_css_file_ref.txt", normalCSSFile)
+
+	result := env.config.GetStyleSheetURL()
+	expected := "https://cdn.example.com/bob/" + normalCSSFile
+	if result != expected {
+		t.Errorf("GetStyleSheetURL() = %v, want: %v", result, expected)
+	}
+
+	if result := env.config.GetPublicAssetsURLPrefix(); result != "https://cdn.example.com/bob/" {
+		t.Errorf("GetPublicAssetsURLPrefix() = %v, want: %v", result, "https://cdn.example.com/bob/")
+	}
+	if result := env.config.GetPublicPathPrefix(); result != "/bob/" {
+		t.Errorf("GetPublicPathPrefix() = %v, want: %v", result, "/bob/")
+	}
+
+	SetModeToDev()
+	if result := env.config.GetPublicAssetsURLPrefix(); result != "/bob/" {
+		t.Errorf("GetPublicAssetsURLPrefix() in dev = %v, want: %v", result, "/bob/")
+	}
+}
+
 func TestGetStyleSheetLinkElement(t *testing.T) {
 	env := setupTestEnv(t)
 	defer teardownTestEnv(t)
@@ -88,7 +118,7 @@ func TestBuildCSS(t *testing.T) {
 	minimizedCriticalCSS := "body{color:red}\n"
 	minimizedNormalCSS := "p{font-size:16px}\n"
 
-	err := env.config.buildCSS()
+	err := env.config.buildCSS(true)
 	if err != nil {
 		t.Fatalf("buildCSS() error = %v", err)
 	}
@@ -126,3 +156,170 @@ func TestBuildCSS(t *testing.T) {
 		t.Errorf("Processed normal CSS = %v, want: %v", string(processedNormalCSS), minimizedNormalCSS)
 	}
 }
+
+func TestBuildCSSWithCSSBuild(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "critical.css", `a { inset: 0; background: url("./icon.svg"); }`)
+	env.createTestFile(t, "main.css", "p { font-size: 16px; }")
+	env.createTestFile(t, "icon.svg", `<svg xmlns="http://www.w3.org/2000/svg"/>`)
+
+	env.config._uc.Core.CSSBuild = &CSSBuild{
+		Target:  []string{"chrome58"},
+		Loaders: map[string]string{".svg": "dataurl"},
+	}
+
+	if err := env.config.buildCSS(true); err != nil {
+		t.Fatalf("buildCSS() error = %v", err)
+	}
+
+	processedCriticalCSS, err := os.ReadFile(filepath.Join(testRootDir, "dist/static/internal/critical.css"))
+	if err != nil {
+		t.Fatalf("Failed to read processed critical CSS: %v", err)
+	}
+	got := string(processedCriticalCSS)
+	if strings.Contains(got, "inset") || !strings.Contains(got, "top:0") {
+		t.Errorf("Expected inset to be lowered for chrome58, got: %v", got)
+	}
+	if !strings.Contains(got, "data:image/svg+xml") {
+		t.Errorf("Expected svg to be inlined as a data URL, got: %v", got)
+	}
+}
+
+func TestResolveCSSEntryPath(t *testing.T) {
+	uc := &UserConfig{
+		Core: &UserConfigCore{},
+		Vite: &UserConfigVite{JSPackageManagerCmdDir: "./web"},
+	}
+	if got := resolveCSSEntryPath(uc, "./styles/main.css"); got != filepath.Clean("styles/main.css") {
+		t.Errorf("without opt-in: got %q", got)
+	}
+	uc.Core.CSSEntryFiles.RelativeToJSPackageManagerCmdDir = true
+	if got := resolveCSSEntryPath(uc, "./styles/main.css"); got != filepath.Join("web", "styles", "main.css") {
+		t.Errorf("with opt-in: got %q", got)
+	}
+	if got := resolveCSSEntryPath(uc, ""); got != "" {
+		t.Errorf("empty path: got %q", got)
+	}
+	uc.Vite = nil
+	if got := resolveCSSEntryPath(uc, "./styles/main.css"); got != filepath.Clean("styles/main.css") {
+		t.Errorf("without Vite config: got %q", got)
+	}
+}
+
+func TestBuildCSSMissingEntry(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+
+	err := env.config.buildCSS(true)
+	if err == nil {
+		t.Fatal("Expected an error for a missing CSS entry file")
+	}
+	if !strings.Contains(err.Error(), "Core.CSSEntryFiles.NonCritical") ||
+		!strings.Contains(err.Error(), env.config.cleanSources.NonCriticalCSSEntry) {
+		t.Errorf("Expected error to name the field and resolved path, got: %v", err)
+	}
+}
+
+func TestParseCSSBuildOpts(t *testing.T) {
+	target, engines, err := parseCSSBuildTargets([]string{"es2020", "Chrome58", "safari11.1"})
+	if err != nil {
+		t.Fatalf("parseCSSBuildTargets() error = %v", err)
+	}
+	if target != esbuild.ES2020 || len(engines) != 2 ||
+		engines[0] != (esbuild.Engine{Name: esbuild.EngineChrome, Version: "58"}) ||
+		engines[1] != (esbuild.Engine{Name: esbuild.EngineSafari, Version: "11.1"}) {
+		t.Errorf("parseCSSBuildTargets() = %v, %v", target, engines)
+	}
+
+	for _, bad := range [][]string{{"netscape4"}, {"chrome"}, {"es2020", "es2022"}} {
+		if _, _, err := parseCSSBuildTargets(bad); err == nil {
+			t.Errorf("parseCSSBuildTargets(%v) expected error", bad)
+		}
+	}
+
+	loaders, err := parseCSSBuildLoaders(map[string]string{".svg": "dataurl"})
+	if err != nil || loaders[".svg"] != esbuild.LoaderDataURL {
+		t.Errorf("parseCSSBuildLoaders() = %v, %v", loaders, err)
+	}
+	for _, bad := range []map[string]string{{"svg": "dataurl"}, {".svg": "tsx"}} {
+		if _, err := parseCSSBuildLoaders(bad); err == nil {
+			t.Errorf("parseCSSBuildLoaders(%v) expected error", bad)
+		}
+	}
+}
+
+func TestBuildCSSHooks(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	// The pre hook generates the (not yet existing) entry files
+	env.createTestFile(t, "gen_css.sh", fmt.Sprintf(
+		"echo 'body { color: red; }' > %s\necho 'p { color: blue; }' > %s\n",
+		filepath.Join(testRootDir, "critical.css"), filepath.Join(testRootDir, "main.css"),
+	))
+	postMarker := filepath.Join(testRootDir, "post_hook_ran")
+	env.createTestFile(t, "post_css.sh", fmt.Sprintf("touch %s\n", postMarker))
+
+	env.config._uc.Core.CSSEntryFiles.PreHook = "sh " + filepath.Join(testRootDir, "gen_css.sh")
+	env.config._uc.Core.CSSEntryFiles.PostHook = "sh " + filepath.Join(testRootDir, "post_css.sh")
+
+	if err := env.config.buildCSS(true); err != nil {
+		t.Fatalf("buildCSS() error = %v", err)
+	}
+	processedCriticalCSS, err := os.ReadFile(filepath.Join(testRootDir, "dist/static/internal/critical.css"))
+	if err != nil || !strings.Contains(string(processedCriticalCSS), "red") {
+		t.Errorf("Expected critical CSS generated by the pre hook, got %q (err: %v)", processedCriticalCSS, err)
+	}
+	if _, err := os.Stat(postMarker); err != nil {
+		t.Errorf("Expected the post hook to run: %v", err)
+	}
+
+	// Hooks are skipped when not requested
+	if err := os.Remove(postMarker); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.config.buildCSS(false); err != nil {
+		t.Fatalf("buildCSS() error = %v", err)
+	}
+	if _, err := os.Stat(postMarker); !os.IsNotExist(err) {
+		t.Errorf("Expected the post hook not to run, got err = %v", err)
+	}
+
+	// A failing pre hook fails the build
+	env.config._uc.Core.CSSEntryFiles.PreHook = "false"
+	if err := env.config.buildCSS(true); err == nil || !strings.Contains(err.Error(), "Core.CSSEntryFiles.PreHook") {
+		t.Errorf("Expected pre hook error, got %v", err)
+	}
+}
+
+func TestBuildWaveRunsCSSHooksOnce(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	preCount := filepath.Join(testRootDir, "pre_hook_count")
+	postCount := filepath.Join(testRootDir, "post_hook_count")
+	env.createTestFile(t, "critical.css", "body { color: red; }")
+	env.createTestFile(t, "main.css", "p { color: blue; }")
+	env.createTestFile(t, "pre_css.sh", fmt.Sprintf("echo x >> %s\n", preCount))
+	env.createTestFile(t, "post_css.sh", fmt.Sprintf("echo x >> %s\n", postCount))
+	env.config._uc.Core.CSSEntryFiles.PreHook = "sh " + filepath.Join(testRootDir, "pre_css.sh")
+	env.config._uc.Core.CSSEntryFiles.PostHook = "sh " + filepath.Join(testRootDir, "post_css.sh")
+
+	if err := env.config.BuildWave(BuildOptions{}); err != nil {
+		t.Fatalf("BuildWave() error = %v", err)
+	}
+
+	for _, file := range []string{preCount, postCount} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Expected hook to run: %v", err)
+		}
+		if runs := strings.Count(string(content), "x"); runs != 1 {
+			t.Errorf("Expected %s hook to run once, ran %d times", filepath.Base(file), runs)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package ki
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Only the end of a failing build step's output is sent to the
+// browser, as compiler errors are printed as they are found.
+const build_output_tail_max_bytes = 8 * 1024
+
+// build_output_error carries the captured output of a failed build
+// step (Go compilation or the dev build hook) so it can be shown in
+// the browser's dev overlay.
+type build_output_error struct {
+	output []byte
+	err    error
+}
+
+func (e *build_output_error) Error() string { return e.err.Error() }
+func (e *build_output_error) Unwrap() error { return e.err }
+
+// run_cmd_capturing_output runs cmd, streaming its output to the
+// terminal, and on failure returns a *build_output_error holding the
+// tail of that output.
+func run_cmd_capturing_output(cmd *exec.Cmd) error {
+	tail := &tail_writer{max: build_output_tail_max_bytes}
+	cmd.Stdout = io.MultiWriter(os.Stdout, tail)
+	cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+	if err := cmd.Run(); err != nil {
+		return &build_output_error{output: tail.bytes(), err: err}
+	}
+	return nil
+}
+
+// broadcast_build_error shows err's captured output (if any) in the
+// browser's dev overlay. The overlay is cleared by the next successful
+// build's reload.
+func (c *Config) broadcast_build_error(err error) {
+	if !c.is_using_browser() {
+		return
+	}
+	rfp := refreshFilePayload{ChangeType: changeTypeBuildError}
+	var boe *build_output_error
+	if errors.As(err, &boe) && len(boe.output) > 0 {
+		rfp.BuildError = base64.StdEncoding.EncodeToString(boe.output)
+	} else {
+		rfp.BuildError = base64.StdEncoding.EncodeToString([]byte(err.Error()))
+	}
+	c.browserTabManager.broadcast <- rfp
+}
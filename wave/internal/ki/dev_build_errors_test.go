@@ -0,0 +1,52 @@
+package ki
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunCmdCapturingOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	t.Run("CapturesBothStreamsOnFailure", func(t *testing.T) {
+		script := `for i in 1 2 3 4 5 6 7 8 9 10; do echo "out $i"; echo "err $i" >&2; done; exit 1`
+		err := run_cmd_capturing_output(exec.Command("sh", "-c", script))
+		var boe *build_output_error
+		if !errors.As(err, &boe) {
+			t.Fatalf("expected *build_output_error, got %v", err)
+		}
+		output := string(boe.output)
+		if !strings.Contains(output, "out 10") || !strings.Contains(output, "err 10") {
+			t.Errorf("expected output from both streams, got %q", output)
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Errorf("expected wrapped *exec.ExitError, got %v", err)
+		}
+	})
+
+	t.Run("KeepsOnlyTheTail", func(t *testing.T) {
+		script := `i=0; while [ $i -lt 2000 ]; do echo "line $i"; echo "line $i" >&2; i=$((i+1)); done; exit 1`
+		err := run_cmd_capturing_output(exec.Command("sh", "-c", script))
+		var boe *build_output_error
+		if !errors.As(err, &boe) {
+			t.Fatalf("expected *build_output_error, got %v", err)
+		}
+		if len(boe.output) != build_output_tail_max_bytes {
+			t.Errorf("expected %d bytes of output, got %d", build_output_tail_max_bytes, len(boe.output))
+		}
+		if !strings.HasSuffix(string(boe.output), "line 1999\n") {
+			t.Errorf("expected output to end with the last line, got %q", boe.output[len(boe.output)-20:])
+		}
+	})
+
+	t.Run("NilOnSuccess", func(t *testing.T) {
+		if err := run_cmd_capturing_output(exec.Command("sh", "-c", "echo ok")); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+}
@@ -26,7 +26,10 @@ func (c *Config) MustStartDev(_opts ...must_start_dev_opts) {
 
 	c.MainInit(MainInitOptions{IsDev: true, IsRebuild: opts.is_rebuild}, "MustStartDev")
 
-	MustGetAppPort() // Warm port right away, in case default is unavailable. Also, env needs to be set in this scope.
+	// Warm port right away, in case default is unavailable. Also, env needs to be set in this scope.
+	if _, err := c.init_app_port(); err != nil {
+		c.panic("failed to get app port", err)
+	}
 
 	refresh_server_port, err := netutil.GetFreePort(default_refresh_server_port)
 	if err != nil {
@@ -63,7 +66,9 @@ func (c *Config) MustStartDev(_opts ...must_start_dev_opts) {
 	}
 
 	go c.run_go_binary()
-	go c.setup_browser_refresh_mux()
+	if !c.is_browser_reload_disabled() {
+		go c.setup_browser_refresh_mux()
+	}
 
 	if opts.is_rebuild {
 		c.must_reload_broadcast(
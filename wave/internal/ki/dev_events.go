@@ -2,7 +2,6 @@ package ki
 
 import (
 	"encoding/base64"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -67,7 +66,8 @@ func (c *Config) process_batched_events(events []fsnotify.Event) {
 
 		wfcsAlreadyHandled[wfc.Pattern] = true
 
-		if !isGoOrNeedsHardReloadEvenIfNonGo {
+		// Types-only changes never restart the app, even for Go files.
+		if !isGoOrNeedsHardReloadEvenIfNonGo && !is_types_only(wfc) {
 			isGoOrNeedsHardReloadEvenIfNonGo = evtDetails.isGo
 		}
 		if !isGoOrNeedsHardReloadEvenIfNonGo {
@@ -81,6 +81,28 @@ func (c *Config) process_batched_events(events []fsnotify.Event) {
 		return
 	}
 
+	// Test commands and types-only regenerations neither rebuild nor
+	// hard reload, so if nothing else changed, skip the batch's
+	// "Rebuilding..." signal and reload.
+	allLightweight := true
+	for _, evtDetails := range relevantFileChanges {
+		if !is_test_only(evtDetails.wfc) && !is_types_only(evtDetails.wfc) {
+			allLightweight = false
+			break
+		}
+	}
+	if allLightweight {
+		for _, evtDetails := range relevantFileChanges {
+			c.Logger.Info("[watcher]", "op", evtDetails.evt.Op.String(), "filename", evtDetails.evt.Name)
+			if is_test_only(evtDetails.wfc) {
+				c.run_test_cmd(evtDetails.wfc)
+			} else {
+				c.run_types_only_regen(evtDetails.wfc)
+			}
+		}
+		return
+	}
+
 	hasMultipleEvents := len(relevantFileChanges) > 1
 
 	if !hasMultipleEvents {
@@ -134,6 +156,7 @@ func (c *Config) process_batched_events(events []fsnotify.Event) {
 		err := c.mustHandleFileChange(evtDetails, hasMultipleEvents)
 		if err != nil {
 			c.Logger.Error(fmt.Sprintf("error: failed to handle file change: %v", err))
+			c.broadcast_build_error(err)
 			return
 		}
 	}
@@ -183,6 +206,20 @@ func (c *Config) mustHandleFileChange(
 		wfc = &WatchedFile{}
 	}
 
+	if is_test_only(wfc) {
+		c.run_test_cmd(wfc)
+		return nil
+	}
+
+	// As part of a batch with other changes, the full rebuild regenerates
+	// the types anyway.
+	if is_types_only(wfc) {
+		if !isPartOfBatch {
+			c.run_types_only_regen(wfc)
+		}
+		return nil
+	}
+
 	if c.is_using_browser() && !wfc.SkipRebuildingNotification && !evtDetails.isWaveCSS && !isPartOfBatch {
 		c.browserTabManager.broadcast <- refreshFilePayload{
 			ChangeType: changeTypeRebuilding,
@@ -339,11 +376,12 @@ func (c *Config) runOtherFileBuild(wfc *WatchedFile, evtDetails *EvtDetails) err
 		IsDev:                      true,
 		is_dev_rebuild:             true,
 		just_run_simple_file_build: evtDetails.isWaveCSS || wfc.OnlyRunClientDefinedRevalidateFunc,
+		skip_css_hooks:             evtDetails.isWaveCSS,
 	})
 	if err != nil {
-		msg := fmt.Sprintf("error: failed to build app: %v", err)
-		c.Logger.Error(msg)
-		return errors.New(msg)
+		err = fmt.Errorf("error: failed to build app: %w", err)
+		c.Logger.Error(err.Error())
+		return err
 	}
 	return nil
 }
@@ -26,6 +26,7 @@ func (c *Config) toViteCtx() *viteutil.BuildCtx {
 		ManifestOut:             c.GetViteManifestLocation(),
 		ViteConfigFile:          c._uc.Vite.ViteConfigFile,
 		DefaultPort:             c._uc.Vite.DefaultPort,
+		StrictPort:              c._uc.Vite.StrictPort,
 	})
 }
 
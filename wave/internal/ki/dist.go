@@ -7,8 +7,9 @@ import (
 )
 
 const (
-	PUBLIC  = "public"
-	PRIVATE = "private"
+	PUBLIC          = "public"
+	PRIVATE         = "private"
+	PUBLIC_VERSIONS = "public_versions"
 )
 
 type Dist struct {
@@ -25,12 +26,15 @@ type DistStatic struct {
 type DistStaticAssets struct {
 	Public  *dirs.DirEmpty
 	Private *dirs.DirEmpty
+	// Only populated if Core.RetainAssetVersions is set
+	PublicVersions *dirs.DirEmpty
 }
 
 type DistWaveInternal struct {
 	CriticalDotCSS             *dirs.File
 	NormalCSSFileRefDotTXT     *dirs.File
 	PublicFileMapFileRefDotTXT *dirs.File
+	PublicVersionsDotTXT       *dirs.File
 }
 
 func toDistLayout(cleanDistDir string) *dirs.Dir[Dist] {
@@ -42,13 +46,15 @@ func toDistLayout(cleanDistDir string) *dirs.Dir[Dist] {
 		Binary: dirs.ToFile(mainOut),
 		Static: dirs.ToDir("static", DistStatic{
 			Assets: dirs.ToDir("assets", DistStaticAssets{
-				Public:  dirs.ToDirEmpty(PUBLIC),
-				Private: dirs.ToDirEmpty(PRIVATE),
+				Public:         dirs.ToDirEmpty(PUBLIC),
+				Private:        dirs.ToDirEmpty(PRIVATE),
+				PublicVersions: dirs.ToDirEmpty(PUBLIC_VERSIONS),
 			}),
 			Internal: dirs.ToDir("internal", DistWaveInternal{
 				CriticalDotCSS:             dirs.ToFile("critical.css"),
 				NormalCSSFileRefDotTXT:     dirs.ToFile("normal_css_file_ref.txt"),
 				PublicFileMapFileRefDotTXT: dirs.ToFile("public_file_map_file_ref.txt"),
+				PublicVersionsDotTXT:       dirs.ToFile("public_versions.txt"),
 			}),
 			Keep: dirs.ToFile(".keep"),
 		}),
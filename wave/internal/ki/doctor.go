@@ -0,0 +1,381 @@
+package ki
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// CONFIG DIAGNOSTICS
+/////////////////////////////////////////////////////////////////////
+
+type DiagnosticSeverity string
+
+const (
+	DiagnosticSeverityError   DiagnosticSeverity = "error"
+	DiagnosticSeverityWarning DiagnosticSeverity = "warning"
+)
+
+type Diagnostic struct {
+	Severity DiagnosticSeverity
+	Field    string // e.g., "Core.DistDir" (empty if not field-specific)
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Field == "" {
+		return fmt.Sprintf("[%s] %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", d.Severity, d.Field, d.Message)
+}
+
+// HasDiagnosticErrors reports whether any of the diagnostics is an error
+// (as opposed to a warning).
+func HasDiagnosticErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == DiagnosticSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate parses WaveConfigJSON (expanding any environment variable
+// references) and checks it against the constraints that would otherwise
+// only surface once a build or dev server fails: required fields
+// (including those required by a present River or Vite block), that the
+// dist and static dirs are distinct and not nested within one another,
+// that referenced files and directories exist (relative to the current
+// working directory), and that the Vite package manager command resolves.
+// It does not require MainInit to have been called and never panics, so
+// it is suitable for running in CI before a build.
+func (c *Config) Validate() []Diagnostic {
+	if len(c.WaveConfigJSON) == 0 {
+		return []Diagnostic{diagnosticError("", "WaveConfigJSON cannot be nil or empty. A valid wave.config.json must be provided.")}
+	}
+	configJSON, envDiagnostics := expand_config_env_vars(c.WaveConfigJSON)
+	if len(envDiagnostics) > 0 {
+		return envDiagnostics
+	}
+	uc := new(UserConfig)
+	if err := json.Unmarshal(configJSON, uc); err != nil {
+		return []Diagnostic{diagnosticError("", fmt.Sprintf("failed to parse config JSON: %v", err))}
+	}
+	if uc.Core == nil {
+		return []Diagnostic{diagnosticError("Core", "the [Core] block is required.")}
+	}
+
+	diagnostics := userConfigShapeDiagnostics(uc)
+	diagnostics = append(diagnostics, userConfigDirDiagnostics(uc)...)
+	diagnostics = append(diagnostics, userConfigPathDiagnostics(uc)...)
+	diagnostics = append(diagnostics, userConfigViteDiagnostics(uc)...)
+	diagnostics = append(diagnostics, userConfigBuildDiagnostics(uc)...)
+	return diagnostics
+}
+
+func diagnosticError(field, msg string) Diagnostic {
+	return Diagnostic{Severity: DiagnosticSeverityError, Field: field, Message: msg}
+}
+
+func diagnosticWarning(field, msg string) Diagnostic {
+	return Diagnostic{Severity: DiagnosticSeverityWarning, Field: field, Message: msg}
+}
+
+// Checks that only depend on the config values themselves. These are
+// also enforced (as panics) by MainInit.
+func userConfigShapeDiagnostics(uc *UserConfig) []Diagnostic {
+	var diagnostics []Diagnostic
+	add := func(field, msg string) {
+		diagnostics = append(diagnostics, diagnosticError(field, msg))
+	}
+
+	// Validate top-level required fields in [Core].
+	if uc.Core.MainAppEntry == "" {
+		add("Core.MainAppEntry", "Core.MainAppEntry is required and cannot be an empty string.")
+	}
+	if uc.Core.DistDir == "" {
+		add("Core.DistDir", "Core.DistDir is required and cannot be an empty string.")
+	}
+
+	// Validate conditionally required fields.
+	if !uc.Core.ServerOnlyMode {
+		if uc.Core.StaticAssetDirs.Private == "" {
+			add("Core.StaticAssetDirs.Private", "Core.StaticAssetDirs.Private is required and cannot be empty when not in ServerOnlyMode.")
+		}
+		if uc.Core.StaticAssetDirs.Public == "" {
+			add("Core.StaticAssetDirs.Public", "Core.StaticAssetDirs.Public is required and cannot be empty when not in ServerOnlyMode.")
+		}
+	}
+
+	// Validate public asset URL settings.
+	if strings.Contains(uc.Core.PublicPathPrefix, "://") {
+		add("Core.PublicPathPrefix", "Core.PublicPathPrefix must be a path, not a full URL. To serve public assets from another origin (e.g., a CDN), set Core.PublicAssetsOrigin instead.")
+	}
+	if origin := uc.Core.PublicAssetsOrigin; origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("Core.PublicAssetsOrigin", "Core.PublicAssetsOrigin must be an absolute http(s) URL (e.g., \"https://cdn.example.com\").")
+		} else if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+			add("Core.PublicAssetsOrigin", "Core.PublicAssetsOrigin must not include a path, query, or fragment. Use Core.PublicPathPrefix for the path.")
+		}
+	}
+
+	for _, pattern := range uc.Core.NoHashGlobs {
+		if !doublestar.ValidatePattern(pattern) {
+			add("Core.NoHashGlobs", fmt.Sprintf("Core.NoHashGlobs contains an invalid glob pattern: %q", pattern))
+		}
+	}
+
+	if cb := uc.Core.CSSBuild; cb != nil {
+		if _, _, err := parseCSSBuildTargets(cb.Target); err != nil {
+			add("Core.CSSBuild.Target", fmt.Sprintf("Core.CSSBuild.Target is invalid: %v.", err))
+		}
+		if _, err := parseCSSBuildLoaders(cb.Loaders); err != nil {
+			add("Core.CSSBuild.Loaders", fmt.Sprintf("Core.CSSBuild.Loaders is invalid: %v.", err))
+		}
+	}
+
+	if br := uc.Core.BuildReport; br != nil && br.RegressionThresholdPercent < 0 {
+		add("Core.BuildReport.RegressionThresholdPercent", fmt.Sprintf("Core.BuildReport.RegressionThresholdPercent must not be negative (got %v).", br.RegressionThresholdPercent))
+	}
+
+	if uc.Core.RetainAssetVersions < 0 {
+		add("Core.RetainAssetVersions", fmt.Sprintf("Core.RetainAssetVersions must not be negative (got %d).", uc.Core.RetainAssetVersions))
+	}
+
+	if uc.Core.StaticFileWorkers < static_file_concurrency_auto {
+		add("Core.StaticFileWorkers", fmt.Sprintf("Core.StaticFileWorkers must be positive, 0 (default), or -1 (auto) (got %d).", uc.Core.StaticFileWorkers))
+	}
+	if uc.Core.MaxOpenStaticFiles < static_file_concurrency_auto {
+		add("Core.MaxOpenStaticFiles", fmt.Sprintf("Core.MaxOpenStaticFiles must be positive, 0 (default), or -1 (auto) (got %d).", uc.Core.MaxOpenStaticFiles))
+	}
+
+	if uc.Watch != nil && (uc.Watch.AppPort < 0 || uc.Watch.AppPort > 65535) {
+		add("Watch.AppPort", fmt.Sprintf("Watch.AppPort must be between 0 and 65535 (got %d).", uc.Watch.AppPort))
+	}
+	if uc.Watch != nil && uc.Core.DevBuildHook == "" {
+		for i, wf := range uc.Watch.Include {
+			if wf.OnlyRegenerateTypes && wf.TestCmd == "" {
+				add(fmt.Sprintf("Watch.Include[%d].OnlyRegenerateTypes", i), fmt.Sprintf("Watch.Include[%d].OnlyRegenerateTypes requires Core.DevBuildHook to be set.", i))
+			}
+		}
+	}
+
+	// Validate required fields within optional blocks.
+	if uc.River != nil {
+		if uc.River.UIVariant == "" {
+			add("River.UIVariant", "River.UIVariant is required when the [River] block is present.")
+		}
+		if uc.River.HTMLTemplateLocation == "" {
+			add("River.HTMLTemplateLocation", "River.HTMLTemplateLocation is required when the [River] block is present.")
+		}
+		if uc.River.ClientEntry == "" {
+			add("River.ClientEntry", "River.ClientEntry is required when the [River] block is present.")
+		}
+		if uc.River.ClientRouteDefsFile == "" && uc.River.ClientRoutesDir == "" {
+			add("River.ClientRouteDefsFile", "River.ClientRouteDefsFile is required when the [River] block is present (unless River.ClientRoutesDir is set).")
+		}
+		if uc.River.TSGenOutPath == "" {
+			add("River.TSGenOutPath", "River.TSGenOutPath is required when the [River] block is present.")
+		}
+	}
+
+	if uc.Vite != nil {
+		if uc.Vite.JSPackageManagerBaseCmd == "" {
+			add("Vite.JSPackageManagerBaseCmd", "Vite.JSPackageManagerBaseCmd is required when the [Vite] block is present.")
+		}
+	}
+
+	return diagnostics
+}
+
+// DistDir, StaticAssetDirs.Private, and StaticAssetDirs.Public must all
+// be distinct, and none may live inside another (Wave copies the static
+// dirs into DistDir, and treats the public and private trees differently).
+func userConfigDirDiagnostics(uc *UserConfig) []Diagnostic {
+	type namedDir struct{ field, dir string }
+	dirs := []namedDir{{"Core.DistDir", uc.Core.DistDir}}
+	if !uc.Core.ServerOnlyMode {
+		dirs = append(dirs,
+			namedDir{"Core.StaticAssetDirs.Private", uc.Core.StaticAssetDirs.Private},
+			namedDir{"Core.StaticAssetDirs.Public", uc.Core.StaticAssetDirs.Public},
+		)
+	}
+
+	var diagnostics []Diagnostic
+	for i, a := range dirs {
+		for _, b := range dirs[i+1:] {
+			if a.dir == "" || b.dir == "" {
+				continue
+			}
+			cleanA, cleanB := filepath.Clean(a.dir), filepath.Clean(b.dir)
+			switch {
+			case cleanA == cleanB:
+				diagnostics = append(diagnostics, diagnosticError(b.field,
+					fmt.Sprintf("must differ from %s (both are %q).", a.field, a.dir),
+				))
+			case isWithinDir(cleanA, cleanB):
+				diagnostics = append(diagnostics, diagnosticError(b.field,
+					fmt.Sprintf("must not be inside %s (%q is inside %q).", a.field, b.dir, a.dir),
+				))
+			case isWithinDir(cleanB, cleanA):
+				diagnostics = append(diagnostics, diagnosticError(a.field,
+					fmt.Sprintf("must not be inside %s (%q is inside %q).", b.field, a.dir, b.dir),
+				))
+			}
+		}
+	}
+	return diagnostics
+}
+
+func isWithinDir(parent, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func userConfigPathDiagnostics(uc *UserConfig) []Diagnostic {
+	var diagnostics []Diagnostic
+	check := func(field, p string, wantDir bool) {
+		if p == "" {
+			return
+		}
+		if d, ok := checkPathExists(field, p, wantDir); !ok {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	// The main app entry may be a file or a package directory
+	if uc.Core.MainAppEntry != "" {
+		if _, err := os.Stat(uc.Core.MainAppEntry); err != nil {
+			diagnostics = append(diagnostics, pathErrDiagnostic("Core.MainAppEntry", uc.Core.MainAppEntry, err))
+		}
+	}
+
+	if !uc.Core.ServerOnlyMode {
+		check("Core.StaticAssetDirs.Private", uc.Core.StaticAssetDirs.Private, true)
+		check("Core.StaticAssetDirs.Public", uc.Core.StaticAssetDirs.Public, true)
+	}
+
+	// Entry files generated by a CSS pre hook need not exist yet, but
+	// their dirs must
+	cssEntries := uc.Core.CSSEntryFiles
+	for _, entry := range [][2]string{
+		{"Core.CSSEntryFiles.Critical", cssEntries.Critical},
+		{"Core.CSSEntryFiles.NonCritical", cssEntries.NonCritical},
+	} {
+		if p := resolveCSSEntryPath(uc, entry[1]); p != "" && cssEntries.PreHook != "" {
+			check(entry[0], filepath.Dir(p), true)
+		} else {
+			check(entry[0], p, false)
+		}
+	}
+	for _, hook := range [][2]string{
+		{"Core.CSSEntryFiles.PreHook", cssEntries.PreHook},
+		{"Core.CSSEntryFiles.PostHook", cssEntries.PostHook},
+	} {
+		if fields := strings.Fields(hook[1]); len(fields) > 0 {
+			if _, err := exec.LookPath(fields[0]); err != nil {
+				diagnostics = append(diagnostics, diagnosticError(hook[0],
+					fmt.Sprintf("command %q could not be found on your PATH.", fields[0]),
+				))
+			}
+		}
+	}
+
+	if uc.River != nil {
+		if uc.River.HTMLTemplateLocation != "" && uc.Core.StaticAssetDirs.Private != "" {
+			check(
+				"River.HTMLTemplateLocation",
+				filepath.Join(uc.Core.StaticAssetDirs.Private, uc.River.HTMLTemplateLocation),
+				false,
+			)
+		}
+		check("River.ClientEntry", uc.River.ClientEntry, false)
+		check("River.ClientRouteDefsFile", uc.River.ClientRouteDefsFile, false)
+		check("River.ClientRoutesDir", uc.River.ClientRoutesDir, true)
+		// The TS gen output file itself is generated, but its dir must exist
+		if uc.River.TSGenOutPath != "" {
+			check("River.TSGenOutPath", filepath.Dir(uc.River.TSGenOutPath), true)
+		}
+	}
+
+	if uc.Vite != nil {
+		check("Vite.JSPackageManagerCmdDir", uc.Vite.JSPackageManagerCmdDir, true)
+		if uc.Vite.ViteConfigFile != "" {
+			check("Vite.ViteConfigFile", filepath.Join(uc.Vite.JSPackageManagerCmdDir, uc.Vite.ViteConfigFile), false)
+		}
+	}
+
+	if uc.Watch != nil {
+		check("Watch.WatchRoot", uc.Watch.WatchRoot, true)
+	}
+
+	return diagnostics
+}
+
+func checkPathExists(field, p string, wantDir bool) (Diagnostic, bool) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return pathErrDiagnostic(field, p, err), false
+	}
+	if wantDir && !info.IsDir() {
+		return diagnosticError(field, fmt.Sprintf("%q is not a directory.", p)), false
+	}
+	if !wantDir && info.IsDir() {
+		return diagnosticError(field, fmt.Sprintf("%q is a directory, expected a file.", p)), false
+	}
+	return Diagnostic{}, true
+}
+
+func pathErrDiagnostic(field, p string, err error) Diagnostic {
+	if errors.Is(err, fs.ErrNotExist) {
+		return diagnosticError(field, fmt.Sprintf("%q does not exist.", p))
+	}
+	return diagnosticError(field, fmt.Sprintf("could not stat %q: %v", p, err))
+}
+
+func userConfigViteDiagnostics(uc *UserConfig) []Diagnostic {
+	if uc.Vite == nil || uc.Vite.JSPackageManagerBaseCmd == "" {
+		return nil
+	}
+	var diagnostics []Diagnostic
+	splitCmd := strings.Fields(uc.Vite.JSPackageManagerBaseCmd)
+	if len(splitCmd) == 0 {
+		return []Diagnostic{diagnosticError("Vite.JSPackageManagerBaseCmd", "must not be blank.")}
+	}
+	if _, err := exec.LookPath(splitCmd[0]); err != nil {
+		diagnostics = append(diagnostics, diagnosticError("Vite.JSPackageManagerBaseCmd",
+			fmt.Sprintf("command %q could not be found on your PATH.", splitCmd[0]),
+		))
+	}
+	// Vite is run via the package manager (e.g., "npx vite"), so it is
+	// usually a local dependency. Only warn, as it may be installed globally.
+	viteBin := filepath.Join(uc.Vite.JSPackageManagerCmdDir, "node_modules", ".bin", "vite")
+	if _, err := os.Stat(viteBin); err != nil {
+		diagnostics = append(diagnostics, diagnosticWarning("Vite",
+			fmt.Sprintf("vite was not found at %q. Have you installed your JS dependencies?", viteBin),
+		))
+	}
+	return diagnostics
+}
+
+func userConfigBuildDiagnostics(uc *UserConfig) []Diagnostic {
+	if uc.Core.ConcurrentGoCompile && uc.Core.GenerateEmbedFile {
+		return []Diagnostic{diagnosticWarning("Core.ConcurrentGoCompile",
+			"Core.ConcurrentGoCompile is ignored when Core.GenerateEmbedFile is true, as the binary embeds the processed static files.",
+		)}
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package ki
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/river-now/river/wave/internal/ki/configschema"
+)
+
+func TestValidate(t *testing.T) {
+	root := t.TempDir()
+	mustMkdir := func(p string) string {
+		full := filepath.Join(root, p)
+		if err := os.MkdirAll(full, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return full
+	}
+	mustWrite := func(p string) string {
+		full := filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return full
+	}
+
+	private := mustMkdir("static/private")
+	public := mustMkdir("static/public")
+	mainEntry := mustWrite("cmd/app/main.go")
+	mustWrite("static/private/entry.go.html")
+	clientEntry := mustWrite("frontend/entry.tsx")
+	mustMkdir("frontend/src")
+
+	validConfig := func() *UserConfig {
+		return &UserConfig{
+			Core: &UserConfigCore{
+				MainAppEntry:    mainEntry,
+				DistDir:         filepath.Join(root, "dist"),
+				StaticAssetDirs: StaticAssetDirs{Private: private, Public: public},
+			},
+			River: &UserConfigRiver{
+				UIVariant:            "react",
+				HTMLTemplateLocation: "entry.go.html",
+				ClientEntry:          clientEntry,
+				ClientRoutesDir:      filepath.Join(root, "frontend/src"),
+				TSGenOutPath:         filepath.Join(root, "frontend/src/river.gen.ts"),
+			},
+		}
+	}
+
+	validateUC := func(t *testing.T, uc *UserConfig) []Diagnostic {
+		t.Helper()
+		b, err := json.Marshal(uc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return (&Config{WaveConfigJSON: b}).Validate()
+	}
+
+	fieldsWithErrors := func(diagnostics []Diagnostic) []string {
+		var fields []string
+		for _, d := range diagnostics {
+			if d.Severity == DiagnosticSeverityError {
+				fields = append(fields, d.Field)
+			}
+		}
+		return fields
+	}
+
+	t.Run("ValidConfig", func(t *testing.T) {
+		if diagnostics := validateUC(t, validConfig()); len(diagnostics) != 0 {
+			t.Errorf("Expected no diagnostics, got %v", diagnostics)
+		}
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		diagnostics := (&Config{WaveConfigJSON: []byte("{")}).Validate()
+		if !HasDiagnosticErrors(diagnostics) {
+			t.Errorf("Expected an error diagnostic, got %v", diagnostics)
+		}
+	})
+
+	t.Run("MissingPaths", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.StaticAssetDirs.Public = filepath.Join(root, "nope")
+		uc.River.HTMLTemplateLocation = "missing.go.html"
+		uc.Core.CSSEntryFiles.Critical = public // a dir, not a file
+		fields := fieldsWithErrors(validateUC(t, uc))
+		for _, want := range []string{
+			"Core.StaticAssetDirs.Public",
+			"River.HTMLTemplateLocation",
+			"Core.CSSEntryFiles.Critical",
+		} {
+			if !slices.Contains(fields, want) {
+				t.Errorf("Expected an error for %s, got %v", want, fields)
+			}
+		}
+	})
+
+	t.Run("OverlappingDirs", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.DistDir = filepath.Join(public, "dist")
+		uc.Core.StaticAssetDirs.Private = public + string(filepath.Separator)
+		fields := fieldsWithErrors(validateUC(t, uc))
+		if !slices.Contains(fields, "Core.DistDir") {
+			t.Errorf("Expected an error for Core.DistDir nested in a static dir, got %v", fields)
+		}
+		if !slices.Contains(fields, "Core.StaticAssetDirs.Public") {
+			t.Errorf("Expected an error for identical static dirs, got %v", fields)
+		}
+	})
+
+	t.Run("RiverRequiredFields", func(t *testing.T) {
+		uc := validConfig()
+		uc.River = &UserConfigRiver{}
+		fields := fieldsWithErrors(validateUC(t, uc))
+		for _, want := range []string{
+			"River.UIVariant",
+			"River.HTMLTemplateLocation",
+			"River.ClientEntry",
+			"River.ClientRouteDefsFile",
+			"River.TSGenOutPath",
+		} {
+			if !slices.Contains(fields, want) {
+				t.Errorf("Expected an error for %s, got %v", want, fields)
+			}
+		}
+	})
+
+	t.Run("ViteCommand", func(t *testing.T) {
+		uc := validConfig()
+		uc.Vite = &UserConfigVite{
+			JSPackageManagerBaseCmd: "definitely-not-a-real-package-manager-xyz",
+			JSPackageManagerCmdDir:  root,
+		}
+		diagnostics := validateUC(t, uc)
+		if !slices.Contains(fieldsWithErrors(diagnostics), "Vite.JSPackageManagerBaseCmd") {
+			t.Errorf("Expected an error for an unresolvable command, got %v", diagnostics)
+		}
+		hasViteWarning := slices.ContainsFunc(diagnostics, func(d Diagnostic) bool {
+			return d.Severity == DiagnosticSeverityWarning && d.Field == "Vite"
+		})
+		if !hasViteWarning {
+			t.Errorf("Expected a warning for the missing vite binary, got %v", diagnostics)
+		}
+	})
+
+	t.Run("CSSBuild", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.CSSBuild = &CSSBuild{
+			Target:  []string{"netscape4"},
+			Loaders: map[string]string{".svg": "not-a-loader"},
+		}
+		fields := fieldsWithErrors(validateUC(t, uc))
+		if !slices.Contains(fields, "Core.CSSBuild.Target") || !slices.Contains(fields, "Core.CSSBuild.Loaders") {
+			t.Errorf("Expected errors for an invalid target and loader, got %v", fields)
+		}
+
+		uc.Core.CSSBuild = &CSSBuild{Target: []string{"chrome58"}, Loaders: map[string]string{".svg": "dataurl"}}
+		if fields := fieldsWithErrors(validateUC(t, uc)); len(fields) > 0 {
+			t.Errorf("Expected no errors for a valid CSSBuild, got %v", fields)
+		}
+	})
+
+	t.Run("BuildReport", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.BuildReport = &BuildReport{RegressionThresholdPercent: -5}
+		if !slices.Contains(fieldsWithErrors(validateUC(t, uc)), "Core.BuildReport.RegressionThresholdPercent") {
+			t.Errorf("Expected an error for a negative threshold")
+		}
+	})
+
+	t.Run("RetainAssetVersions", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.RetainAssetVersions = -1
+		if !slices.Contains(fieldsWithErrors(validateUC(t, uc)), "Core.RetainAssetVersions") {
+			t.Errorf("Expected an error for a negative RetainAssetVersions")
+		}
+	})
+
+	t.Run("OnlyRegenerateTypes", func(t *testing.T) {
+		uc := validConfig()
+		uc.Watch = &UserConfigWatch{Include: []WatchedFile{
+			{Pattern: "**/*.css"},
+			{Pattern: "backend/**/*.go", OnlyRegenerateTypes: true},
+		}}
+		if fields := fieldsWithErrors(validateUC(t, uc)); !slices.Equal(fields, []string{"Watch.Include[1].OnlyRegenerateTypes"}) {
+			t.Errorf("Expected an error for OnlyRegenerateTypes without a DevBuildHook, got %v", fields)
+		}
+		uc.Core.DevBuildHook = "go run ./backend/cmd/build -dev -hook"
+		if fields := fieldsWithErrors(validateUC(t, uc)); len(fields) != 0 {
+			t.Errorf("Expected no errors with a DevBuildHook, got %v", fields)
+		}
+	})
+
+	t.Run("EnvVars", func(t *testing.T) {
+		t.Setenv("WAVE_TEST_DIST", filepath.Join(root, "dist"))
+		uc := validConfig()
+		uc.Core.DistDir = "${WAVE_TEST_DIST}"
+		uc.Core.DevBuildHook = "${WAVE_TEST_UNSET_HOOK:-go run ./cmd/build}"
+		if diagnostics := validateUC(t, uc); len(diagnostics) != 0 {
+			t.Errorf("Expected no diagnostics, got %v", diagnostics)
+		}
+
+		uc.Core.ProdBuildHook = "${WAVE_TEST_UNSET_HOOK}"
+		if !slices.Contains(fieldsWithErrors(validateUC(t, uc)), "Core.ProdBuildHook") {
+			t.Errorf("Expected an error for an unset env var without a default")
+		}
+	})
+
+	t.Run("StaticFileConcurrency", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.StaticFileWorkers = -1
+		uc.Core.MaxOpenStaticFiles = -1
+		if fields := fieldsWithErrors(validateUC(t, uc)); len(fields) != 0 {
+			t.Errorf("Expected -1 (auto) to be valid, got errors for %v", fields)
+		}
+
+		uc.Core.StaticFileWorkers = -2
+		uc.Core.MaxOpenStaticFiles = -2
+		fields := fieldsWithErrors(validateUC(t, uc))
+		if !slices.Contains(fields, "Core.StaticFileWorkers") || !slices.Contains(fields, "Core.MaxOpenStaticFiles") {
+			t.Errorf("Expected errors for values below -1, got %v", fields)
+		}
+	})
+
+	t.Run("CSSHooks", func(t *testing.T) {
+		uc := validConfig()
+		uc.Core.CSSEntryFiles.NonCritical = filepath.Join(root, "static/generated.css")
+		if !slices.Contains(fieldsWithErrors(validateUC(t, uc)), "Core.CSSEntryFiles.NonCritical") {
+			t.Errorf("Expected an error for a missing CSS entry file without a pre hook")
+		}
+
+		// Generated by the pre hook, so only its dir must exist
+		uc.Core.CSSEntryFiles.PreHook = "go version"
+		if fields := fieldsWithErrors(validateUC(t, uc)); len(fields) != 0 {
+			t.Errorf("Expected no errors, got %v", fields)
+		}
+		uc.Core.CSSEntryFiles.NonCritical = filepath.Join(root, "missing-dir/generated.css")
+		if !slices.Contains(fieldsWithErrors(validateUC(t, uc)), "Core.CSSEntryFiles.NonCritical") {
+			t.Errorf("Expected an error for a missing CSS entry dir")
+		}
+
+		uc.Core.CSSEntryFiles.PostHook = "wave-test-definitely-not-a-command --flag"
+		if !slices.Contains(fieldsWithErrors(validateUC(t, uc)), "Core.CSSEntryFiles.PostHook") {
+			t.Errorf("Expected an error for a hook command not on the PATH")
+		}
+	})
+}
+
+func TestExpandConfigEnvVars(t *testing.T) {
+	t.Setenv("WAVE_TEST_PORT", "8080")
+	t.Setenv("WAVE_TEST_EMPTY", "")
+	t.Setenv("WAVE_TEST_BOOL", "true")
+
+	configJSON := []byte(`{
+		"Core": {
+			"DevBuildHook": "go run ./cmd/build --port=${WAVE_TEST_PORT}",
+			"ProdBuildHook": "${WAVE_TEST_EMPTY:-fallback} $${LITERAL}",
+			"ServerOnlyMode": "${WAVE_TEST_BOOL}",
+			"NoHashGlobs": ["${WAVE_TEST_UNSET:-sw.js}"]
+		},
+		"Watch": { "AppPort": "${WAVE_TEST_PORT}", "HealthcheckEndpoint": "${WAVE_TEST_EMPTY}" }
+	}`)
+
+	expanded, diagnostics := expand_config_env_vars(configJSON)
+	if len(diagnostics) != 0 {
+		t.Fatalf("Expected no diagnostics, got %v", diagnostics)
+	}
+	uc := new(UserConfig)
+	if err := json.Unmarshal(expanded, uc); err != nil {
+		t.Fatalf("Expanded config does not unmarshal: %v", err)
+	}
+	if uc.Core.DevBuildHook != "go run ./cmd/build --port=8080" {
+		t.Errorf("DevBuildHook = %q", uc.Core.DevBuildHook)
+	}
+	if uc.Core.ProdBuildHook != "fallback ${LITERAL}" {
+		t.Errorf("ProdBuildHook = %q", uc.Core.ProdBuildHook)
+	}
+	if !uc.Core.ServerOnlyMode {
+		t.Error("Expected ServerOnlyMode to expand to true")
+	}
+	if !slices.Equal(uc.Core.NoHashGlobs, []string{"sw.js"}) {
+		t.Errorf("NoHashGlobs = %v", uc.Core.NoHashGlobs)
+	}
+	if uc.Watch.AppPort != 8080 {
+		t.Errorf("Watch.AppPort = %d, want 8080", uc.Watch.AppPort)
+	}
+	if uc.Watch.HealthcheckEndpoint != "" {
+		t.Errorf("Expected a set but empty var to expand to empty, got %q", uc.Watch.HealthcheckEndpoint)
+	}
+
+	// No references: returned as is
+	plain := []byte(`{"Core": {"DistDir": "dist"}}`)
+	if got, _ := expand_config_env_vars(plain); string(got) != string(plain) {
+		t.Errorf("Expected config without references to be unchanged, got %s", got)
+	}
+
+	_, diagnostics = expand_config_env_vars([]byte(`{"Core": {"DistDir": "${WAVE_TEST_UNSET}"}, "Watch": {"AppPort": "x${WAVE_TEST_PORT}"}}`))
+	var fields []string
+	for _, d := range diagnostics {
+		fields = append(fields, d.Field)
+	}
+	slices.Sort(fields)
+	if !slices.Equal(fields, []string{"Core.DistDir", "Watch.AppPort"}) {
+		t.Errorf("Expected errors for Core.DistDir and Watch.AppPort, got %v", diagnostics)
+	}
+}
+
+func TestConfigSchemaAllowsEnvVarStrings(t *testing.T) {
+	for name, entry := range map[string]any{
+		"AppPort":        configschema.AppPort_Schema,
+		"ServerOnlyMode": configschema.ServerOnlyMode_Schema,
+	} {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema struct {
+			Type []string `json:"type"`
+		}
+		if err := json.Unmarshal(b, &schema); err != nil {
+			t.Fatalf("%s: expected a list of types, got %s", name, b)
+		}
+		if !slices.Contains(schema.Type, "string") || len(schema.Type) != 2 {
+			t.Errorf("%s: expected a string alternative, got %v", name, schema.Type)
+		}
+	}
+}
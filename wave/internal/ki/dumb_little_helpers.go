@@ -7,7 +7,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/river-now/river/kit/grace"
@@ -31,6 +33,48 @@ func (c *Config) is_using_browser() bool {
 	return !c._uc.Core.ServerOnlyMode
 }
 
+func (c *Config) is_browser_reload_disabled() bool {
+	return c._uc.Watch != nil && c._uc.Watch.DisableBrowserReload
+}
+
+// Whether to notify open browser tabs of rebuilds and reloads
+func (c *Config) is_notifying_browser() bool {
+	return c.is_using_browser() && !c.is_browser_reload_disabled()
+}
+
+/////////////////////////////////////////////////////////////////////
+/////// STATIC FILE CONCURRENCY
+/////////////////////////////////////////////////////////////////////
+
+const (
+	default_static_file_workers   = 4
+	default_max_open_static_files = 100
+	auto_max_open_files_per_proc  = 4
+	static_file_concurrency_auto  = -1
+)
+
+func (c *Config) get_static_file_workers() int {
+	switch n := c._uc.Core.StaticFileWorkers; {
+	case n == static_file_concurrency_auto:
+		return runtime.GOMAXPROCS(0)
+	case n > 0:
+		return n
+	default:
+		return default_static_file_workers
+	}
+}
+
+func (c *Config) get_max_open_static_files() int {
+	switch n := c._uc.Core.MaxOpenStaticFiles; {
+	case n == static_file_concurrency_auto:
+		return auto_max_open_files_per_proc * runtime.GOMAXPROCS(0)
+	case n > 0:
+		return n
+	default:
+		return default_max_open_static_files
+	}
+}
+
 /////////////////////////////////////////////////////////////////////
 /////// SETUP BROWSER REFRESH MUX
 /////////////////////////////////////////////////////////////////////
@@ -193,7 +237,7 @@ func (c *Config) run_go_binary() {
 /////////////////////////////////////////////////////////////////////
 
 func (c *Config) send_rebuilding_signal() {
-	if c.is_using_browser() {
+	if c.is_notifying_browser() {
 		c.browserTabManager.broadcast <- refreshFilePayload{
 			ChangeType: changeTypeRebuilding,
 		}
@@ -211,7 +255,7 @@ type must_reload_broadcast_opts struct {
 }
 
 func (c *Config) must_reload_broadcast(rfp refreshFilePayload, opts must_reload_broadcast_opts) {
-	if !c.is_using_browser() {
+	if !c.is_notifying_browser() {
 		return
 	}
 	if opts.wait_for_app {
@@ -249,12 +293,41 @@ func (c *Config) compile_go_binary(isDev bool) error {
 	if !isDev {
 		buildCmd = exec.Command("go", "build", "-tags=prod", "-o", buildDest, in)
 	}
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
-	err := buildCmd.Run()
+	err := run_cmd_capturing_output(buildCmd)
 	if err != nil {
 		return fmt.Errorf("error compiling binary: %w", err)
 	}
 	c.Logger.Info("DONE compiling Go binary", "duration", time.Since(a))
 	return nil
 }
+
+const post_compile_hook_binary_path_env_var = "WAVE_BINARY_PATH"
+
+// name is the hook's field name under Core.CSSEntryFiles
+func (c *Config) run_css_hook(name, hook string) error {
+	fields := strings.Fields(hook)
+	if len(fields) == 0 {
+		return nil
+	}
+	a := time.Now()
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running Core.CSSEntryFiles.%s: %w", name, err)
+	}
+	c.Logger.Info("DONE running CSS hook", "hook", name, "duration", time.Since(a))
+	return nil
+}
+
+func (c *Config) run_post_compile_hook() error {
+	fields := strings.Fields(c._uc.Core.PostCompileHook)
+	if len(fields) == 0 {
+		return nil
+	}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Env = append(os.Environ(), post_compile_hook_binary_path_env_var+"="+c.get_binary_output_path())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,75 @@
+package ki
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// EMBED FILE GENERATION
+/////////////////////////////////////////////////////////////////////
+
+const embedGoFileName = "embed.go"
+
+var invalidGoIdentChars = regexp.MustCompile(`[^a-z0-9_]`)
+
+const embedGoFileTmpl = `// Code generated by Wave. DO NOT EDIT.
+
+package %s
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:static
+var embedded embed.FS
+
+// StaticFS returns the embedded %s/static directory as the root of
+// an fs.FS. Pass it to wave.Config.DistStaticFS.
+func StaticFS() fs.FS {
+	sub, err := fs.Sub(embedded, "static")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+`
+
+// maybeWriteEmbedGoFile writes a Go source file into the dist directory
+// that embeds the processed dist/static tree and exposes it as an fs.FS,
+// so that apps can be deployed as a single binary without hand-writing
+// a go:embed directive. The file is only rewritten if its content would
+// change, so as not to needlessly trigger Go rebuilds in dev.
+func (c *Config) maybeWriteEmbedGoFile() error {
+	if !c._uc.Core.GenerateEmbedFile {
+		return nil
+	}
+	content := []byte(fmt.Sprintf(
+		embedGoFileTmpl, c.getEmbedPackageName(), filepath.Base(c.cleanSources.Dist),
+	))
+	target := filepath.Join(c.cleanSources.Dist, embedGoFileName)
+	if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		return fmt.Errorf("error writing embed file: %w", err)
+	}
+	return nil
+}
+
+// The package name is derived from the dist directory's name, falling
+// back to "dist" if that does not yield a valid identifier.
+func (c *Config) getEmbedPackageName() string {
+	name := invalidGoIdentChars.ReplaceAllString(
+		strings.ToLower(filepath.Base(c.cleanSources.Dist)), "_",
+	)
+	if name == "" || name == "_" || (name[0] >= '0' && name[0] <= '9') {
+		return "dist"
+	}
+	return name
+}
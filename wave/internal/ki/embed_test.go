@@ -0,0 +1,88 @@
+package ki
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const wantEmbedGoFile = `// Code generated by Wave. DO NOT EDIT.
+
+package dist
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:static
+var embedded embed.FS
+
+// StaticFS returns the embedded dist/static directory as the root of
+// an fs.FS. Pass it to wave.Config.DistStaticFS.
+func StaticFS() fs.FS {
+	sub, err := fs.Sub(embedded, "static")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+`
+
+func TestMaybeWriteEmbedGoFile(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	c := env.config
+	target := filepath.Join(testRootDir, "dist", embedGoFileName)
+
+	if err := c.maybeWriteEmbedGoFile(); err != nil {
+		t.Fatalf("maybeWriteEmbedGoFile() error = %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("expected no embed file without GenerateEmbedFile, got %v", err)
+	}
+
+	c._uc.Core.GenerateEmbedFile = true
+	if err := c.maybeWriteEmbedGoFile(); err != nil {
+		t.Fatalf("maybeWriteEmbedGoFile() error = %v", err)
+	}
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("expected embed file: %v", err)
+	}
+	if string(content) != wantEmbedGoFile {
+		t.Errorf("embed file mismatch\ngot:\n%s\nwant:\n%s", content, wantEmbedGoFile)
+	}
+
+	// Unchanged content is not rewritten
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(target, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	if err := c.maybeWriteEmbedGoFile(); err != nil {
+		t.Fatalf("maybeWriteEmbedGoFile() error = %v", err)
+	}
+	if info, err := os.Stat(target); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("expected the unchanged embed file not to be rewritten (err: %v)", err)
+	}
+}
+
+func TestGetEmbedPackageName(t *testing.T) {
+	tests := []struct {
+		distDir string
+		want    string
+	}{
+		{"dist", "dist"},
+		{"build/Out-Dir", "out_dir"},
+		{"9dist", "dist"},
+		{"-", "dist"},
+	}
+	for _, tt := range tests {
+		c := &Config{cleanSources: CleanSources{Dist: filepath.Clean(tt.distDir)}}
+		if got := c.getEmbedPackageName(); got != tt.want {
+			t.Errorf("getEmbedPackageName() for %q = %q, want %q", tt.distDir, got, tt.want)
+		}
+	}
+}
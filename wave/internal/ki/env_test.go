@@ -1,8 +1,13 @@
 package ki
 
 import (
+	"errors"
+	"log/slog"
+	"net"
 	"os"
 	"testing"
+
+	"github.com/river-now/river/kit/netutil"
 )
 
 func TestGetIsDev(t *testing.T) {
@@ -56,3 +61,119 @@ func TestRefreshServerPort(t *testing.T) {
 		t.Errorf("getRefreshServerPort() = %v, want %v", got, 3000)
 	}
 }
+
+func TestRefreshScriptDisableBrowserReload(t *testing.T) {
+	resetEnv()
+	defer resetEnv()
+	os.Setenv(modeKey, devModeVal)
+	set_refresh_server_port(3000)
+
+	c := &Config{_uc: &UserConfig{Core: &UserConfigCore{}, Watch: &UserConfigWatch{}}}
+	if c.GetRefreshScript() == "" || c.GetRefreshScriptSha256Hash() == "" {
+		t.Fatal("expected refresh script in dev mode")
+	}
+	if !c.is_notifying_browser() {
+		t.Error("expected browser notifications in dev mode")
+	}
+
+	c._uc.Watch.DisableBrowserReload = true
+	if got := c.GetRefreshScript(); got != "" {
+		t.Errorf("GetRefreshScript() = %q, want empty", got)
+	}
+	if got := c.GetRefreshScriptSha256Hash(); got != "" {
+		t.Errorf("GetRefreshScriptSha256Hash() = %q, want empty", got)
+	}
+	if c.is_notifying_browser() {
+		t.Error("expected no browser notifications with DisableBrowserReload")
+	}
+	if !c.is_using_browser() {
+		t.Error("expected asset processing to be unaffected by DisableBrowserReload")
+	}
+}
+
+func TestInitAppPort(t *testing.T) {
+	newConfig := func(watch *UserConfigWatch) *Config {
+		return &Config{Logger: slog.Default(), _uc: &UserConfig{Watch: watch}}
+	}
+	freePort := func(t *testing.T) int {
+		t.Helper()
+		port, err := netutil.GetRandomFreePort()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !netutil.CheckAvailability(port) {
+			t.Skip("port availability cannot be detected in this environment")
+		}
+		return port
+	}
+
+	t.Run("ConfigPort", func(t *testing.T) {
+		resetEnv()
+		SetModeToDev()
+		want := freePort(t)
+		got, err := newConfig(&UserConfigWatch{AppPort: want}).init_app_port()
+		if err != nil || got != want {
+			t.Errorf("init_app_port() = %d, %v, want %d, nil", got, err, want)
+		}
+		if getPort() != want || !getPortHasBeenSet() {
+			t.Errorf("expected PORT env to be set to %d", want)
+		}
+	})
+
+	t.Run("EnvOverridesConfig", func(t *testing.T) {
+		resetEnv()
+		SetModeToDev()
+		want := freePort(t)
+		setPort(want)
+		got, err := newConfig(&UserConfigWatch{AppPort: 1}).init_app_port()
+		if err != nil || got != want {
+			t.Errorf("init_app_port() = %d, %v, want %d, nil", got, err, want)
+		}
+	})
+
+	t.Run("StrictPortInUse", func(t *testing.T) {
+		resetEnv()
+		SetModeToDev()
+		ln, err := net.Listen("tcp", ":0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		taken := ln.Addr().(*net.TCPAddr).Port
+		_, err = newConfig(&UserConfigWatch{AppPort: taken, StrictAppPort: true}).init_app_port()
+		if !errors.Is(err, ErrAppPortInUse) {
+			t.Errorf("expected ErrAppPortInUse, got %v", err)
+		}
+		if getPortHasBeenSet() {
+			t.Errorf("expected port not to be marked as set")
+		}
+	})
+
+	t.Run("NonStrictPortInUse", func(t *testing.T) {
+		resetEnv()
+		SetModeToDev()
+		ln, err := net.Listen("tcp", ":0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		taken := ln.Addr().(*net.TCPAddr).Port
+		got, err := newConfig(&UserConfigWatch{AppPort: taken}).init_app_port()
+		if err != nil || got == taken {
+			t.Errorf("init_app_port() = %d, %v, want a port other than %d", got, err, taken)
+		}
+	})
+
+	t.Run("AlreadySet", func(t *testing.T) {
+		resetEnv()
+		SetModeToDev()
+		setPort(1234)
+		setPortHasBeenSet()
+		got, err := newConfig(&UserConfigWatch{AppPort: 5678, StrictAppPort: true}).init_app_port()
+		if err != nil || got != 1234 {
+			t.Errorf("init_app_port() = %d, %v, want 1234, nil", got, err)
+		}
+	})
+
+	resetEnv()
+}
@@ -47,7 +47,11 @@ func (c *Config) getEvtDetails(evt fsnotify.Event) *EvtDetails {
 	}
 
 	if matchingWatchedFile == nil {
+		isCreateOrRemove := evt.Has(fsnotify.Create) || evt.Has(fsnotify.Remove) || evt.Has(fsnotify.Rename)
 		for _, wfc := range c.defaultWatchedFiles {
+			if wfc.only_on_create_or_remove && !isCreateOrRemove {
+				continue
+			}
 			isMatch := c.get_is_match(potentialMatch{pattern: wfc.Pattern, path: evt.Name})
 			if isMatch {
 				matchingWatchedFile = &wfc
@@ -0,0 +1,33 @@
+package ki
+
+import (
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestGetEvtDetailsCreateOrRemoveOnly(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.config._uc.Watch = &UserConfigWatch{}
+	env.config.defaultWatchedFiles = []WatchedFile{{
+		Pattern:                  "frontend/routes/**/*",
+		OnChangeHooks:            []OnChangeHook{{Cmd: __internal_full_dev_reset_less_go_mrkr}},
+		only_on_create_or_remove: true,
+	}}
+
+	const name = "frontend/routes/users/$id.tsx"
+
+	for _, op := range []fsnotify.Op{fsnotify.Create, fsnotify.Remove, fsnotify.Rename} {
+		details := env.config.getEvtDetails(fsnotify.Event{Name: name, Op: op})
+		if details == nil || details.wfc == nil || !details.is_full_dev_reset {
+			t.Errorf("expected %s to trigger a full dev reset, got %+v", op, details)
+		}
+	}
+
+	details := env.config.getEvtDetails(fsnotify.Event{Name: name, Op: fsnotify.Write})
+	if details == nil || details.wfc != nil || details.is_full_dev_reset || !details.isIgnored {
+		t.Errorf("expected writes to be ignored, got %+v", details)
+	}
+}
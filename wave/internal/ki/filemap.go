@@ -15,13 +15,15 @@ import (
 
 	"github.com/river-now/river/kit/fsutil"
 	"github.com/river-now/river/kit/htmlutil"
-	"github.com/river-now/river/kit/matcher"
 )
 
 const (
 	PublicFileMapJSName   = "river_internal_public_filemap.js"
 	PublicFileMapGobName  = "public_filemap.gob"
 	PrivateFileMapGobName = "private_filemap.gob"
+	// Written to the root of the public assets dir, for non-River tools
+	// (see PublicAssetManifest)
+	PublicAssetManifestName = "wave_asset_manifest.json"
 )
 
 func (c *Config) loadMapFromGob(gobFileName string, isBuildTime bool) (FileMap, error) {
@@ -104,6 +106,54 @@ func (c *Config) savePublicFileMapJSToInternalPublicDir(mapToSave FileMap) error
 	), bytes, 0644)
 }
 
+// PublicAssetManifest is the format of the public asset manifest file
+// (PublicAssetManifestName). Its shape is stable and independent of
+// River internals, for tools such as CDN uploaders. Version is bumped
+// only on breaking changes.
+type PublicAssetManifest struct {
+	Version int `json:"version"`
+	// Prefix that the dist filenames are served under (see
+	// Core.PublicPathPrefix)
+	PublicPathPrefix string `json:"publicPathPrefix"`
+	// Keyed by original path, relative to the public static dir
+	Assets map[string]PublicAssetManifestEntry `json:"assets"`
+}
+
+type PublicAssetManifestEntry struct {
+	// Filename within the public assets dir (hashed, unless prehashed)
+	File string `json:"file"`
+	// True if the file kept its original name (e.g., it lives in a
+	// "prehashed" dir or matches Core.NoHashGlobs)
+	Prehashed bool `json:"prehashed"`
+}
+
+const public_asset_manifest_version = 1
+
+func (c *Config) savePublicAssetManifest(mapToSave FileMap) error {
+	manifest := PublicAssetManifest{
+		Version:          public_asset_manifest_version,
+		PublicPathPrefix: c.GetPublicPathPrefix(),
+		Assets:           make(map[string]PublicAssetManifestEntry, len(mapToSave)),
+	}
+	for k, v := range mapToSave {
+		if v.DistName == PublicAssetManifestName {
+			return fmt.Errorf("public file %s would be overwritten by the public asset manifest", k)
+		}
+		manifest.Assets[k] = PublicAssetManifestEntry{File: v.DistName, Prehashed: v.IsPrehashed}
+	}
+
+	// Map keys are sorted, so the output is stable across builds
+	manifestJSON, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshalling public asset manifest: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(
+		c._dist.S().Static.S().Assets.S().Public.FullPath(),
+		PublicAssetManifestName,
+	), append(manifestJSON, '\n'), 0644)
+}
+
 type publicFileMapDetails struct {
 	Elements   template.HTML
 	Sha256Hash string
@@ -129,7 +179,7 @@ func (c *Config) getInitialPublicFileMapDetails() (*publicFileMapDetails, error)
 	scriptEl := htmlutil.Element{
 		Tag:                "script",
 		Attributes:         map[string]string{"type": "module"},
-		DangerousInnerHTML: fmt.Sprintf(innerHTMLFormatStr, publicFileMapURL, c._uc.Core.PublicPathPrefix),
+		DangerousInnerHTML: fmt.Sprintf(innerHTMLFormatStr, publicFileMapURL, c.GetPublicAssetsURLPrefix()),
 	}
 
 	sha256Hash, err := htmlutil.AddSha256HashInline(&scriptEl)
@@ -174,12 +224,9 @@ func (c *Config) getInitialPublicFileMapURL() (string, error) {
 		return "", err
 	}
 
-	return matcher.EnsureLeadingSlash(
-		path.Join(
-			c._uc.Core.PublicPathPrefix,
-			c._dist.S().Static.S().Assets.S().Public.LastSegment(),
-			string(content),
-		),
+	return c.toPublicAssetURL(
+		c._dist.S().Static.S().Assets.S().Public.LastSegment(),
+		string(content),
 	), nil
 }
 
@@ -218,7 +265,7 @@ func (c *Config) GetSimplePublicFileMapBuildtime() (map[string]string, error) {
 	filemap, err := c.getInitialPublicFileMapFromGobBuildtime()
 
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
-		if err := c.do_build_time_file_processing(false); err != nil {
+		if err := c.do_build_time_file_processing(build_time_file_processing_opts{}); err != nil {
 			return nil, fmt.Errorf("error processing build time files: %w", err)
 		}
 
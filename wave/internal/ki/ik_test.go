@@ -89,6 +89,8 @@ func setupTestEnv(t *testing.T) *testEnv {
 		public_filemap_from_gob: safecache.New(c.getInitialPublicFileMapFromGobRuntime, nil),
 		public_filemap_url:      safecache.New(c.getInitialPublicFileMapURL, GetIsDev),
 		public_urls:             safecache.NewMap(c.getInitialPublicURL, publicURLsKeyMaker, nil),
+		is_public_asset:         safecache.NewMap(c.getInitialIsPublicAsset, publicURLsKeyMaker, nil),
+		prehashed_dist_names:    safecache.New(c.getInitialPrehashedDistNames, nil),
 	}
 
 	// Initialize dev cache if needed
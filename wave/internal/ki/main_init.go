@@ -45,32 +45,35 @@ func (c *Config) MainInit(opts MainInitOptions, calledFrom string) {
 		}
 	}
 
-	c.fileSemaphore = semaphore.NewWeighted(100)
-
 	if len(c.WaveConfigJSON) == 0 {
 		c.panic("Config Error: ConfigBytes cannot be nil or empty. A valid wave.config.json must be provided.", nil)
 	}
 
 	// USER CONFIG
+	configJSON, envDiagnostics := expand_config_env_vars(c.WaveConfigJSON)
+	if len(envDiagnostics) > 0 {
+		for _, d := range envDiagnostics {
+			c.Logger.Error(d.String())
+		}
+		c.panic("failed to expand environment variables in config", errors.New(envDiagnostics[0].String()))
+	}
 	c._uc = new(UserConfig)
-	if err := json.Unmarshal(c.WaveConfigJSON, c._uc); err != nil {
+	if err := json.Unmarshal(configJSON, c._uc); err != nil {
 		c.panic("failed to unmarshal user config", err)
 	}
 
 	c.validateUserConfig()
 
+	c.fileSemaphore = semaphore.NewWeighted(int64(c.get_max_open_static_files()))
+
 	// CLEAN SOURCES
 	c.cleanSources = CleanSources{
 		Dist:          filepath.Clean(c._uc.Core.DistDir),
 		PrivateStatic: filepath.Clean(c._uc.Core.StaticAssetDirs.Private),
 		PublicStatic:  filepath.Clean(c._uc.Core.StaticAssetDirs.Public),
 	}
-	if c._uc.Core.CSSEntryFiles.Critical != "" {
-		c.cleanSources.CriticalCSSEntry = filepath.Clean(c._uc.Core.CSSEntryFiles.Critical)
-	}
-	if c._uc.Core.CSSEntryFiles.NonCritical != "" {
-		c.cleanSources.NonCriticalCSSEntry = filepath.Clean(c._uc.Core.CSSEntryFiles.NonCritical)
-	}
+	c.cleanSources.CriticalCSSEntry = resolveCSSEntryPath(c._uc, c._uc.Core.CSSEntryFiles.Critical)
+	c.cleanSources.NonCriticalCSSEntry = resolveCSSEntryPath(c._uc, c._uc.Core.CSSEntryFiles.NonCritical)
 
 	// DIST LAYOUT
 	c._dist = toDistLayout(c.cleanSources.Dist)
@@ -136,20 +139,38 @@ func (c *Config) MainInit(opts MainInitOptions, calledFrom string) {
 	}
 
 	if includeDefaults {
-		relClientRouteDefsFile, err := filepath.Rel(c.cleanWatchRoot, c._uc.River.ClientRouteDefsFile)
-		if err != nil {
-			c.panic("failed to get relative path for ClientRouteDefsFile", err)
-		}
-
 		c.defaultWatchedFiles = append(c.defaultWatchedFiles, WatchedFile{
 			Pattern:       filepath.Join(c.cleanSources.PrivateStatic, c._uc.River.HTMLTemplateLocation),
 			OnChangeHooks: []OnChangeHook{{Cmd: __internal_full_dev_reset_less_go_mrkr}},
 		})
 
-		c.defaultWatchedFiles = append(c.defaultWatchedFiles, WatchedFile{
-			Pattern:       filepath.ToSlash(relClientRouteDefsFile),
-			OnChangeHooks: []OnChangeHook{{Cmd: __internal_full_dev_reset_less_go_mrkr}},
-		})
+		if c._uc.River.ClientRouteDefsFile != "" {
+			relClientRouteDefsFile, err := filepath.Rel(c.cleanWatchRoot, c._uc.River.ClientRouteDefsFile)
+			if err != nil {
+				c.panic("failed to get relative path for ClientRouteDefsFile", err)
+			}
+
+			c.defaultWatchedFiles = append(c.defaultWatchedFiles, WatchedFile{
+				Pattern:       filepath.ToSlash(relClientRouteDefsFile),
+				OnChangeHooks: []OnChangeHook{{Cmd: __internal_full_dev_reset_less_go_mrkr}},
+			})
+		}
+
+		// Adding, removing, or renaming a file-system route module changes
+		// the route set, so it warrants the same reset as editing the route
+		// defs file. Edits to existing modules are left to Vite.
+		if c._uc.River.ClientRoutesDir != "" {
+			relClientRoutesDir, err := filepath.Rel(c.cleanWatchRoot, c._uc.River.ClientRoutesDir)
+			if err != nil {
+				c.panic("failed to get relative path for ClientRoutesDir", err)
+			}
+
+			c.defaultWatchedFiles = append(c.defaultWatchedFiles, WatchedFile{
+				Pattern:                  filepath.ToSlash(filepath.Join(relClientRoutesDir, "**/*")),
+				OnChangeHooks:            []OnChangeHook{{Cmd: __internal_full_dev_reset_less_go_mrkr}},
+				only_on_create_or_remove: true,
+			})
+		}
 
 		c.defaultWatchedFiles = append(c.defaultWatchedFiles, WatchedFile{
 			Pattern:       "**/*.go",
@@ -214,46 +235,7 @@ func (c *Config) MainInit(opts MainInitOptions, calledFrom string) {
 var ErrConfigValidation = errors.New("config validation error")
 
 func (c *Config) validateUserConfig() {
-	// Validate top-level required fields in [Core].
-	if c._uc.Core.MainAppEntry == "" {
-		c.panic("Config Error: Core.MainAppEntry is required and cannot be an empty string.", ErrConfigValidation)
-	}
-	if c._uc.Core.DistDir == "" {
-		c.panic("Config Error: Core.DistDir is required and cannot be an empty string.", ErrConfigValidation)
-	}
-
-	// Validate conditionally required fields.
-	if !c._uc.Core.ServerOnlyMode {
-		if c._uc.Core.StaticAssetDirs.Private == "" {
-			c.panic("Config Error: Core.StaticAssetDirs.Private is required and cannot be empty when not in ServerOnlyMode.", ErrConfigValidation)
-		}
-		if c._uc.Core.StaticAssetDirs.Public == "" {
-			c.panic("Config Error: Core.StaticAssetDirs.Public is required and cannot be empty when not in ServerOnlyMode.", ErrConfigValidation)
-		}
-	}
-
-	// Validate required fields within optional blocks.
-	if c._uc.River != nil {
-		if c._uc.River.UIVariant == "" {
-			c.panic("Config Error: River.UIVariant is required when the [River] block is present.", ErrConfigValidation)
-		}
-		if c._uc.River.HTMLTemplateLocation == "" {
-			c.panic("Config Error: River.HTMLTemplateLocation is required when the [River] block is present.", ErrConfigValidation)
-		}
-		if c._uc.River.ClientEntry == "" {
-			c.panic("Config Error: River.ClientEntry is required when the [River] block is present.", ErrConfigValidation)
-		}
-		if c._uc.River.ClientRouteDefsFile == "" {
-			c.panic("Config Error: River.ClientRouteDefsFile is required when the [River] block is present.", ErrConfigValidation)
-		}
-		if c._uc.River.TSGenOutPath == "" {
-			c.panic("Config Error: River.TSGenOutPath is required when the [River] block is present.", ErrConfigValidation)
-		}
-	}
-
-	if c._uc.Vite != nil {
-		if c._uc.Vite.JSPackageManagerBaseCmd == "" {
-			c.panic("Config Error: Vite.JSPackageManagerBaseCmd is required when the [Vite] block is present.", ErrConfigValidation)
-		}
+	for _, d := range userConfigShapeDiagnostics(c._uc) {
+		c.panic("Config Error: "+d.Message, ErrConfigValidation)
 	}
 }
@@ -12,7 +12,7 @@ func (c *Config) FaviconRedirect() middleware.Middleware {
 
 	handlerFunc := func(w http.ResponseWriter, r *http.Request) {
 		faviconDotIcoURL := c.GetPublicURL("favicon.ico")
-		if faviconDotIcoURL == c.GetPublicPathPrefix()+"favicon.ico" {
+		if faviconDotIcoURL == c.GetPublicAssetsURLPrefix()+"favicon.ico" {
 			res := response.New(w)
 			res.NotFound()
 			return
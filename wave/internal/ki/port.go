@@ -1,6 +1,8 @@
 package ki
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/river-now/river/kit/netutil"
@@ -10,6 +12,10 @@ const (
 	default_refresh_server_port = 10_000
 )
 
+// ErrAppPortInUse is returned when Watch.StrictAppPort is set and the
+// requested app port is already taken.
+var ErrAppPortInUse = errors.New("app port already in use")
+
 func MustGetAppPort() int {
 	isDev := GetIsDev()
 	portHasBeenSet := getPortHasBeenSet()
@@ -29,3 +35,46 @@ func MustGetAppPort() int {
 
 	return port
 }
+
+// init_app_port resolves the dev-time app port and stores it in the env
+// (so that it is inherited by the app binary). Precedence is the -port
+// flag (which sets PORT), then the PORT env var, then Watch.AppPort, then
+// 8080. Unless Watch.StrictAppPort is set, a taken port is swapped for
+// the next free one. No-op once the port has been set (e.g., on rebuilds,
+// when the port is held by our own running app).
+func (c *Config) init_app_port() (int, error) {
+	if getPortHasBeenSet() {
+		return getPort(), nil
+	}
+
+	requested := getPort()
+	if requested == 0 && c._uc.Watch != nil {
+		requested = c._uc.Watch.AppPort
+	}
+
+	if c._uc.Watch == nil || !c._uc.Watch.StrictAppPort {
+		if requested != 0 {
+			setPort(requested)
+		}
+		port := MustGetAppPort()
+		if requested != 0 && port != requested {
+			c.Logger.Warn(fmt.Sprintf("app port %d is in use, using %d instead", requested, port))
+		}
+		return port, nil
+	}
+
+	if requested == 0 {
+		requested = 8080
+	}
+	if !netutil.CheckAvailability(requested) {
+		return 0, fmt.Errorf(
+			"app port %d: %w (stop whatever is using it, choose a different port, or disable Watch.StrictAppPort)",
+			requested, ErrAppPortInUse,
+		)
+	}
+
+	setPort(requested)
+	setPortHasBeenSet()
+
+	return requested, nil
+}
@@ -0,0 +1,235 @@
+package ki
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+/////// RETAINED PUBLIC ASSET VERSIONS
+/////////////////////////////////////////////////////////////////////
+
+// Retained versions live at dist/static/assets/public_versions/<version>,
+// where <version> is the content hash of that build's public file map. The
+// dist/static/internal/public_versions.txt file lists them, newest first.
+
+func (c *Config) should_retain_public_versions() bool {
+	return c._uc.Core.RetainAssetVersions > 0 && !GetIsDev()
+}
+
+// public_version_from_file_map_ref extracts the hash suffix from a public
+// file map ref (e.g., "river_out_river_internal_public_filemap_<hash>.js").
+func public_version_from_file_map_ref(ref string) string {
+	ref = strings.TrimSpace(ref)
+	ref = strings.TrimSuffix(ref, filepath.Ext(ref))
+	if idx := strings.LastIndex(ref, "_"); idx != -1 {
+		ref = ref[idx+1:]
+	}
+	return ref
+}
+
+func parse_public_versions(content []byte) []string {
+	var versions []string
+	for line := range strings.SplitSeq(string(content), "\n") {
+		if v := strings.TrimSpace(line); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+func (c *Config) read_public_versions_buildtime() ([]string, error) {
+	content, err := os.ReadFile(c._dist.S().Static.S().Internal.S().PublicVersionsDotTXT.FullPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading public versions file: %w", err)
+	}
+	return parse_public_versions(content), nil
+}
+
+func (c *Config) write_public_versions(versions []string) error {
+	content := strings.Join(versions, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := os.WriteFile(c._dist.S().Static.S().Internal.S().PublicVersionsDotTXT.FullPath(), []byte(content), 0644); err != nil {
+		return fmt.Errorf("error writing public versions file: %w", err)
+	}
+	return nil
+}
+
+type stashed_public_versions struct {
+	dir      string
+	versions []string
+}
+
+// stash_public_versions moves the current build's public assets (as the
+// newest retained version) and any previously retained versions out of
+// dist/static, so that they survive the pre-build nuke. The returned stash
+// must be passed to restore_public_versions once dist/static is re-made.
+func (c *Config) stash_public_versions() (*stashed_public_versions, error) {
+	assets := c._dist.S().Static.S().Assets
+
+	versions, err := c.read_public_versions_buildtime()
+	if err != nil {
+		return nil, err
+	}
+
+	// Inside DistDir (but outside dist/static), so that renames stay on
+	// the same device
+	dir, err := os.MkdirTemp(c.cleanSources.Dist, ".wave_public_versions_")
+	if err != nil {
+		return nil, fmt.Errorf("error making public versions stash dir: %w", err)
+	}
+	stash := &stashed_public_versions{dir: dir}
+
+	if _, err := os.Stat(assets.S().PublicVersions.FullPath()); err == nil {
+		if err := os.Rename(assets.S().PublicVersions.FullPath(), filepath.Join(dir, PUBLIC_VERSIONS)); err != nil {
+			return nil, fmt.Errorf("error stashing retained public versions: %w", err)
+		}
+	} else {
+		versions = nil
+		if err := os.Mkdir(filepath.Join(dir, PUBLIC_VERSIONS), 0755); err != nil {
+			return nil, fmt.Errorf("error making public versions stash dir: %w", err)
+		}
+	}
+
+	ref, err := os.ReadFile(c._dist.S().Static.S().Internal.S().PublicFileMapFileRefDotTXT.FullPath())
+	if err == nil {
+		current := public_version_from_file_map_ref(string(ref))
+		target := filepath.Join(dir, PUBLIC_VERSIONS, current)
+		if _, statErr := os.Stat(target); current != "" && errors.Is(statErr, fs.ErrNotExist) {
+			if err := os.Rename(assets.S().Public.FullPath(), target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("error stashing current public assets: %w", err)
+			}
+		}
+		if current != "" {
+			versions = slices.DeleteFunc(versions, func(v string) bool { return v == current })
+			versions = append([]string{current}, versions...)
+		}
+	}
+
+	stash.versions = versions
+	return stash, nil
+}
+
+func (c *Config) restore_public_versions(stash *stashed_public_versions) error {
+	defer os.RemoveAll(stash.dir)
+	if err := os.Rename(
+		filepath.Join(stash.dir, PUBLIC_VERSIONS),
+		c._dist.S().Static.S().Assets.S().PublicVersions.FullPath(),
+	); err != nil {
+		return fmt.Errorf("error restoring retained public versions: %w", err)
+	}
+	return c.write_public_versions(stash.versions)
+}
+
+// prune_public_versions drops the retained version matching the current
+// build (if any), as well as any beyond the Core.RetainAssetVersions most
+// recent, and removes any version directories not listed in the versions file.
+func (c *Config) prune_public_versions() error {
+	versionsDir := c._dist.S().Static.S().Assets.S().PublicVersions.FullPath()
+	if _, err := os.Stat(versionsDir); err != nil {
+		return nil
+	}
+
+	versions, err := c.read_public_versions_buildtime()
+	if err != nil {
+		return err
+	}
+
+	ref, err := os.ReadFile(c._dist.S().Static.S().Internal.S().PublicFileMapFileRefDotTXT.FullPath())
+	if err == nil {
+		current := public_version_from_file_map_ref(string(ref))
+		versions = slices.DeleteFunc(versions, func(v string) bool { return v == current })
+	}
+	if len(versions) > c._uc.Core.RetainAssetVersions {
+		versions = versions[:c._uc.Core.RetainAssetVersions]
+	}
+
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil {
+		return fmt.Errorf("error reading public versions dir: %w", err)
+	}
+	for _, entry := range entries {
+		if !slices.Contains(versions, entry.Name()) {
+			if err := os.RemoveAll(filepath.Join(versionsDir, entry.Name())); err != nil {
+				return fmt.Errorf("error removing public version %s: %w", entry.Name(), err)
+			}
+		}
+	}
+
+	if len(versions) > 0 {
+		c.Logger.Info("Retaining previous public asset versions", "versions", versions)
+	}
+
+	return c.write_public_versions(versions)
+}
+
+// getServePublicFS returns the current public FS, falling back (newest
+// first) to any retained public asset versions for files it lacks.
+func (c *Config) getServePublicFS() (fs.FS, error) {
+	publicFS, err := c.GetPublicFS()
+	if err != nil {
+		return nil, err
+	}
+	if c._uc.Core.RetainAssetVersions <= 0 {
+		return publicFS, nil
+	}
+
+	baseFS, err := c.GetBaseFS()
+	if err != nil {
+		return nil, fmt.Errorf("error getting base FS: %w", err)
+	}
+
+	distWaveInternal := c._dist.S().Static.S().Internal
+
+	// __LOCATION_ASSUMPTION: Inside "dist/static"
+	content, err := fs.ReadFile(baseFS, path.Join(
+		distWaveInternal.LastSegment(),
+		distWaveInternal.S().PublicVersionsDotTXT.LastSegment(),
+	))
+	if err != nil {
+		// No retained versions (e.g., first build)
+		return publicFS, nil
+	}
+
+	layered := versionedPublicFS{publicFS}
+	for _, v := range parse_public_versions(content) {
+		// __LOCATION_ASSUMPTION: Inside "dist/static"
+		subFS, err := fs.Sub(baseFS, path.Join(c._dist.S().Static.S().Assets.LastSegment(), PUBLIC_VERSIONS, v))
+		if err != nil {
+			return nil, fmt.Errorf("error getting public version %s FS: %w", v, err)
+		}
+		layered = append(layered, subFS)
+	}
+	return layered, nil
+}
+
+// versionedPublicFS opens files from the first FS that has them.
+type versionedPublicFS []fs.FS
+
+func (v versionedPublicFS) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, fsys := range v {
+		f, err := fsys.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	return nil, firstErr
+}
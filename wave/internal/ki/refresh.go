@@ -32,6 +32,9 @@ type refreshFilePayload struct {
 	ChangeType   changeType `json:"changeType"`
 	CriticalCSS  Base64     `json:"criticalCSS"`
 	NormalCSSURL string     `json:"normalCSSURL"`
+	TestPassed   bool       `json:"testPassed,omitempty"`
+	TestOutput   Base64     `json:"testOutput,omitempty"` // tail, on failure only
+	BuildError   Base64     `json:"buildError,omitempty"` // tail of failed build output
 }
 
 type changeType string
@@ -42,6 +45,9 @@ const (
 	changeTypeOther       changeType = "other"
 	changeTypeRebuilding  changeType = "rebuilding"
 	changeTypeRevalidate  changeType = "revalidate"
+	changeTypeTestRunning changeType = "test-running"
+	changeTypeTestResult  changeType = "test-result"
+	changeTypeBuildError  changeType = "build-error"
 )
 
 func newClientManager() *clientManager {
@@ -78,7 +84,7 @@ func (manager *clientManager) start() {
 }
 
 func (c *Config) GetRefreshScriptSha256Hash() string {
-	if !GetIsDev() {
+	if !GetIsDev() || c.is_browser_reload_disabled() {
 		return ""
 	}
 	hash := cryptoutil.Sha256Hash([]byte(GetRefreshScriptInner(getRefreshServerPort())))
@@ -86,7 +92,7 @@ func (c *Config) GetRefreshScriptSha256Hash() string {
 }
 
 func (c *Config) GetRefreshScript() template.HTML {
-	if !GetIsDev() {
+	if !GetIsDev() || c.is_browser_reload_disabled() {
 		return ""
 	}
 	result, _ := htmlutil.RenderElement(&htmlutil.Element{
@@ -100,8 +106,8 @@ func GetRefreshScriptInner(port int) string {
 	return fmt.Sprintf(refreshScriptFmt, port)
 }
 
-// changeTypes: "rebuilding", "other", "normal", "critical", "revalidate"
-// Element IDs: "wave-refreshscript-rebuilding", "wave-normal-css", "wave-critical-css"
+// changeTypes: "rebuilding", "other", "normal", "critical", "revalidate", "test-running", "test-result", "build-error"
+// Element IDs: "wave-refreshscript-rebuilding", "wave-refreshscript-tests", "wave-refreshscript-build-error", "wave-normal-css", "wave-critical-css"
 const refreshScriptFmt = `
 function base64ToUTF8(base64) {
 	const bytes = Uint8Array.from(atob(base64), (m) => m.codePointAt(0) || 0);
@@ -110,6 +116,70 @@ function base64ToUTF8(base64) {
 function getCurrentEl() {
 	return document.getElementById("wave-refreshscript-rebuilding");
 }
+function showTestsBadge(text, color, output) {
+	document.getElementById("wave-refreshscript-tests")?.remove();
+	const el = document.createElement("div");
+	el.id = "wave-refreshscript-tests";
+	el.style.position = "fixed";
+	el.style.right = "12px";
+	el.style.bottom = "12px";
+	el.style.maxWidth = "min(80ch, calc(100%% - 24px))";
+	el.style.maxHeight = "50vh";
+	el.style.overflow = "auto";
+	el.style.backgroundColor = color;
+	el.style.color = "white";
+	el.style.padding = "8px 12px";
+	el.style.borderRadius = "6px";
+	el.style.zIndex = "1001";
+	el.style.fontFamily = "monospace";
+	el.style.fontSize = "13px";
+	el.style.boxShadow = "0 2px 8px #0006";
+	el.style.cursor = "pointer";
+	el.title = "Click to dismiss";
+	el.onclick = () => el.remove();
+	const title = document.createElement("div");
+	title.style.fontWeight = "bold";
+	title.textContent = text;
+	el.appendChild(title);
+	if (output) {
+		const pre = document.createElement("pre");
+		pre.style.margin = "8px 0 0";
+		pre.style.whiteSpace = "pre-wrap";
+		pre.textContent = output;
+		el.appendChild(pre);
+	}
+	document.body.appendChild(el);
+	return el;
+}
+function removeBuildErrorPanel() {
+	document.getElementById("wave-refreshscript-build-error")?.remove();
+}
+function showBuildErrorPanel(output) {
+	removeBuildErrorPanel();
+	const el = document.createElement("div");
+	el.id = "wave-refreshscript-build-error";
+	el.style.position = "fixed";
+	el.style.inset = "0";
+	el.style.overflow = "auto";
+	el.style.backgroundColor = "#1b1b1bf2";
+	el.style.color = "#ff8a80";
+	el.style.padding = "24px";
+	el.style.zIndex = "1002";
+	el.style.fontFamily = "monospace";
+	el.style.fontSize = "14px";
+	const title = document.createElement("div");
+	title.style.fontWeight = "bold";
+	title.style.fontSize = "18px";
+	title.textContent = "Build failed";
+	el.appendChild(title);
+	const pre = document.createElement("pre");
+	pre.style.margin = "16px 0 0";
+	pre.style.whiteSpace = "pre-wrap";
+	pre.style.color = "white";
+	pre.textContent = output;
+	el.appendChild(pre);
+	document.body.appendChild(el);
+}
 const scrollYKey = "__wave_internal__devScrollY";
 const scrollY = sessionStorage.getItem(scrollYKey);
 if (scrollY) {
@@ -121,7 +191,10 @@ if (scrollY) {
 }
 const ws = new WebSocket("ws://localhost:%d/events");
 ws.onmessage = (e) => {
-	const { changeType, criticalCSS, normalCSSURL } = JSON.parse(e.data);
+	const { changeType, criticalCSS, normalCSSURL, testPassed, testOutput, buildError } = JSON.parse(e.data);
+	if (changeType == "normal" || changeType == "critical" || changeType == "revalidate") {
+		removeBuildErrorPanel();
+	}
 	if (changeType == "rebuilding") {
 		console.log("Wave: Rebuilding server...");
 		const currentEl = getCurrentEl();
@@ -175,6 +248,27 @@ ws.onmessage = (e) => {
 		newStyle.innerHTML = base64ToUTF8(criticalCSS);
 		document.head.replaceChild(newStyle, oldStyle);
 	}
+	if (changeType == "test-running") {
+		console.log("Wave: Running tests...");
+		showTestsBadge("Running tests...", "#555");
+	}
+	if (changeType == "test-result") {
+		if (testPassed) {
+			console.log("Wave: Tests passed");
+			const el = showTestsBadge("Tests passed", "#1a7f37");
+			setTimeout(() => el.remove(), 3000);
+		} else {
+			const output = testOutput ? base64ToUTF8(testOutput) : "";
+			console.error("Wave: Tests failed\n" + output);
+			showTestsBadge("Tests failed", "#b42318", output);
+		}
+	}
+	if (changeType == "build-error") {
+		getCurrentEl()?.remove();
+		const output = buildError ? base64ToUTF8(buildError) : "";
+		console.error("Wave: Build failed\n" + output);
+		showBuildErrorPanel(output);
+	}
 	if (changeType == "revalidate") {
 		console.log("Wave: Revalidating...");
 		const el = getCurrentEl();
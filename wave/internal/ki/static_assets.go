@@ -2,12 +2,11 @@ package ki
 
 import (
 	"fmt"
+	"html/template"
 	"io/fs"
 	"net/http"
 	"path"
 	"strings"
-
-	"github.com/river-now/river/kit/matcher"
 )
 
 type fileVal struct {
@@ -20,20 +19,70 @@ type fileVal struct {
 
 type FileMap map[string]fileVal
 
+const (
+	// For content-addressed (hashed) public files, which can be cached forever
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// For prehashed public files, which keep their original names and must
+	// therefore be revalidated (via the file server's Last-Modified handling)
+	revalidateCacheControl = "public, no-cache"
+)
+
+// If addImmutableCacheHeaders is true, hashed public files are served with
+// a long-lived immutable Cache-Control header, whereas prehashed files
+// (which keep their original names across builds) are served with a
+// revalidation policy instead. If Core.RetainAssetVersions is set, files
+// missing from the current build are served from retained previous builds.
 func (c *Config) GetServeStaticHandler(addImmutableCacheHeaders bool) (http.Handler, error) {
-	publicFS, err := c.GetPublicFS()
+	publicFS, err := c.getServePublicFS()
 	if err != nil {
 		wrapped := fmt.Errorf("error getting public FS: %w", err)
 		c.Logger.Error(wrapped.Error())
 		return nil, wrapped
 	}
+	fileServer := http.StripPrefix(c.GetPublicPathPrefix(), http.FileServer(http.FS(publicFS)))
 	if addImmutableCacheHeaders {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-			http.StripPrefix(c.GetPublicPathPrefix(), http.FileServer(http.FS(publicFS))).ServeHTTP(w, r)
+			if c.getIsPrehashedPublicAsset(r.URL.Path) {
+				w.Header().Set("Cache-Control", revalidateCacheControl)
+			} else {
+				w.Header().Set("Cache-Control", immutableCacheControl)
+			}
+			fileServer.ServeHTTP(w, r)
 		}), nil
 	}
-	return http.StripPrefix(c.GetPublicPathPrefix(), http.FileServer(http.FS(publicFS))), nil
+	return fileServer, nil
+}
+
+// getIsPrehashedPublicAsset reports whether the requested URL path refers
+// to a prehashed public file. If the public file map cannot be loaded, it
+// errs on the side of caution and reports true.
+func (c *Config) getIsPrehashedPublicAsset(urlPath string) bool {
+	// A failed initialization is only reported by the first Get, after
+	// which the cache holds a nil map
+	prehashed, err := c.runtime_cache.prehashed_dist_names.Get()
+	if err != nil || prehashed == nil {
+		return true
+	}
+	_, isPrehashed := prehashed[cleanURL(strings.TrimPrefix(urlPath, c.GetPublicPathPrefix()))]
+	return isPrehashed
+}
+
+// Loads the file map itself rather than going through
+// public_filemap_from_gob, whose load error (like this one's) is only
+// reported by the first Get.
+func (c *Config) getInitialPrehashedDistNames() (map[string]struct{}, error) {
+	fileMapFromGob, err := c.getInitialPublicFileMapFromGobRuntime()
+	if err != nil {
+		c.Logger.Error(fmt.Sprintf("error getting public file map from gob for cache headers: %v", err))
+		return nil, err
+	}
+	prehashed := make(map[string]struct{})
+	for _, v := range fileMapFromGob {
+		if v.IsPrehashed {
+			prehashed[v.DistName] = struct{}{}
+		}
+	}
+	return prehashed, nil
 }
 
 func (c *Config) getInitialPublicFileMapFromGobBuildtime() (FileMap, error) {
@@ -70,12 +119,7 @@ func (c *Config) getInitialPublicURL(originalPublicURL string) (string, error) {
 		c.Logger.Error(fmt.Sprintf(
 			"error getting public file map from gob for originalPublicURL %s: %v", originalPublicURL, err,
 		))
-		return matcher.EnsureLeadingSlash(
-			path.Join(
-				c._uc.Core.PublicPathPrefix,
-				originalPublicURL,
-			),
-		), err
+		return c.toPublicAssetURL(originalPublicURL), err
 	}
 
 	return c.getInitialPublicURLInner(originalPublicURL, fileMapFromGob)
@@ -87,20 +131,16 @@ func (c *Config) getInitialPublicURLInner(originalPublicURL string, fileMapFromG
 	}
 
 	if hashedURL, existsInFileMap := fileMapFromGob[cleanURL(originalPublicURL)]; existsInFileMap {
-		return matcher.EnsureLeadingSlash(
-			path.Join(c._uc.Core.PublicPathPrefix, hashedURL.DistName),
-		), nil
+		return c.toPublicAssetURL(hashedURL.DistName), nil
 	}
 
 	// If no hashed URL found, return the original URL
-	c.Logger.Warn(fmt.Sprintf(
+	c.BuildWarn(fmt.Sprintf(
 		"GetPublicURL: no hashed URL found for %s, returning original URL",
 		originalPublicURL,
 	))
 
-	return matcher.EnsureLeadingSlash(
-		path.Join(c._uc.Core.PublicPathPrefix, originalPublicURL),
-	), nil
+	return c.toPublicAssetURL(originalPublicURL), nil
 }
 
 func publicURLsKeyMaker(x string) string { return x }
@@ -110,6 +150,24 @@ func (c *Config) GetPublicURL(originalPublicURL string) string {
 	return url
 }
 
+// PublicURL is like GetPublicURL, but also returns any error encountered
+// loading the public file map (in which case the returned URL is the
+// unhashed original, with the PublicPathPrefix applied). As with
+// GetPublicURL, paths not found in the file map resolve to the unhashed
+// original (with a warning), and results are cached outside of dev mode.
+func (c *Config) PublicURL(originalPublicURL string) (string, error) {
+	return c.runtime_cache.public_urls.Get(originalPublicURL)
+}
+
+const PublicURLTemplateFuncName = "publicURL"
+
+// PublicURLFuncMap returns a template.FuncMap exposing PublicURL as
+// "publicURL", so that templates can reference public assets by their
+// original paths (e.g., {{ publicURL "img/logo.png" }}).
+func (c *Config) PublicURLFuncMap() template.FuncMap {
+	return template.FuncMap{PublicURLTemplateFuncName: c.PublicURL}
+}
+
 func cleanURL(url string) string {
 	return strings.TrimPrefix(path.Clean(url), "/")
 }
@@ -145,7 +203,7 @@ func (c *Config) getIsPublicAsset(hashedFileName string) bool {
 }
 
 func (c *Config) getInitialIsPublicAsset(hashedFileName string) (bool, error) {
-	publicFS, err := c.GetPublicFS()
+	publicFS, err := c.getServePublicFS()
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,192 @@
+package ki
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPublicURL(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	err := env.config.saveMapToGob(FileMap{
+		"img/logo.png":     {DistName: "img/logo_abc123.png"},
+		"vendor/lib_x1.js": {DistName: "vendor/lib_x1.js", IsPrehashed: true},
+	}, PublicFileMapGobName)
+	if err != nil {
+		t.Fatalf("Failed to save public file map: %v", err)
+	}
+
+	tests := []struct {
+		original string
+		want     string
+	}{
+		{"img/logo.png", "/bob/img/logo_abc123.png"},
+		{"/img/logo.png", "/bob/img/logo_abc123.png"},
+		{"vendor/lib_x1.js", "/bob/vendor/lib_x1.js"},
+		{"missing.png", "/bob/missing.png"},
+	}
+	for _, tt := range tests {
+		got, err := env.config.PublicURL(tt.original)
+		if err != nil {
+			t.Errorf("PublicURL(%q) error: %v", tt.original, err)
+		}
+		if got != tt.want {
+			t.Errorf("PublicURL(%q) = %q, want %q", tt.original, got, tt.want)
+		}
+	}
+
+	tmpl := template.Must(template.New("test").
+		Funcs(env.config.PublicURLFuncMap()).
+		Parse(`<img src="{{ publicURL "img/logo.png" }}">`))
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, nil); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if want := `<img src="/bob/img/logo_abc123.png">`; sb.String() != want {
+		t.Errorf("template output = %q, want %q", sb.String(), want)
+	}
+}
+
+func TestRetainAssetVersions(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	c := env.config
+	c._uc.Core.RetainAssetVersions = 1
+
+	build := func(content string) string {
+		t.Helper()
+		stash, err := c.stash_public_versions()
+		if err != nil {
+			t.Fatalf("stash_public_versions() error = %v", err)
+		}
+		if err := os.RemoveAll(c._dist.S().Static.FullPath()); err != nil {
+			t.Fatalf("Failed to remove dist/static: %v", err)
+		}
+		if err := c.SetupDistDir(); err != nil {
+			t.Fatalf("SetupDistDir() error = %v", err)
+		}
+		if err := c.restore_public_versions(stash); err != nil {
+			t.Fatalf("restore_public_versions() error = %v", err)
+		}
+		env.createTestFile(t, "public-static/app.js", content)
+		if err := c.handlePublicFiles(false); err != nil {
+			t.Fatalf("handlePublicFiles() error = %v", err)
+		}
+		if err := c.prune_public_versions(); err != nil {
+			t.Fatalf("prune_public_versions() error = %v", err)
+		}
+		fileMap, err := c.loadMapFromGob(PublicFileMapGobName, true)
+		if err != nil {
+			t.Fatalf("loadMapFromGob() error = %v", err)
+		}
+		return fileMap["app.js"].DistName
+	}
+
+	status := func(distName string) int {
+		t.Helper()
+		handler, err := c.GetServeStaticHandler(false)
+		if err != nil {
+			t.Fatalf("GetServeStaticHandler() error = %v", err)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bob/"+distName, nil))
+		return rec.Code
+	}
+
+	first := build("console.log(1);")
+	second := build("console.log(2);")
+	third := build("console.log(3);")
+
+	if code := status(third); code != http.StatusOK {
+		t.Errorf("current build asset: expected 200, got %d", code)
+	}
+	if code := status(second); code != http.StatusOK {
+		t.Errorf("retained build asset: expected 200, got %d", code)
+	}
+	if code := status(first); code != http.StatusNotFound {
+		t.Errorf("pruned build asset: expected 404, got %d", code)
+	}
+
+	versions, err := c.read_public_versions_buildtime()
+	if err != nil {
+		t.Fatalf("read_public_versions_buildtime() error = %v", err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 retained version, got %v", versions)
+	}
+	entries, err := os.ReadDir(c._dist.S().Static.S().Assets.S().PublicVersions.FullPath())
+	if err != nil {
+		t.Fatalf("Failed to read public versions dir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != versions[0] {
+		t.Errorf("expected only %v on disk, got %v", versions, entries)
+	}
+
+	// Rebuilding identical content must not retain a copy of itself
+	build("console.log(3);")
+	if versions, _ := c.read_public_versions_buildtime(); len(versions) != 1 {
+		t.Errorf("expected 1 retained version after identical rebuild, got %v", versions)
+	}
+	if code := status(second); code != http.StatusOK {
+		t.Errorf("retained build asset after identical rebuild: expected 200, got %d", code)
+	}
+}
+
+func TestServeStaticCacheControl(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "public-static/app.js", "console.log('app');")
+	env.createTestFile(t, "public-static/prehashed/robots.txt", "User-agent: *")
+	if err := env.config.handlePublicFiles(false); err != nil {
+		t.Fatalf("handlePublicFiles() error = %v", err)
+	}
+
+	handler, err := env.config.GetServeStaticHandler(true)
+	if err != nil {
+		t.Fatalf("GetServeStaticHandler() error = %v", err)
+	}
+	cacheControl := func(distName string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bob/"+distName, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", distName, rec.Code)
+		}
+		return rec.Header().Get("Cache-Control")
+	}
+
+	appDistName := env.config.GetPublicURL("app.js")[len("/bob/"):]
+	if appDistName == "app.js" {
+		t.Fatalf("expected app.js to be hashed")
+	}
+	if got := cacheControl(appDistName); got != immutableCacheControl {
+		t.Errorf("hashed file: Cache-Control = %q, want %q", got, immutableCacheControl)
+	}
+	if got := cacheControl("robots.txt"); got != revalidateCacheControl {
+		t.Errorf("prehashed file: Cache-Control = %q, want %q", got, revalidateCacheControl)
+	}
+}
+
+func TestServeStaticCacheControlWithoutFileMap(t *testing.T) {
+	env := setupTestEnv(t)
+	defer teardownTestEnv(t)
+
+	env.createTestFile(t, "dist/static/assets/public/app_abc123.js", "console.log('app');")
+
+	if _, err := env.config.getInitialPrehashedDistNames(); err == nil {
+		t.Error("expected the public file map error to be returned")
+	}
+	// Repeated to cover the cached result as well
+	for range 2 {
+		if !env.config.getIsPrehashedPublicAsset("/bob/app_abc123.js") {
+			t.Error("expected files to be treated as prehashed when the public file map cannot be loaded")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ki
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Only the end of a failing test command's output is sent to the
+// browser, as that is usually where the failures are summarized.
+const test_output_tail_max_bytes = 8 * 1024
+
+func is_test_only(wfc *WatchedFile) bool {
+	return wfc != nil && wfc.TestCmd != ""
+}
+
+// run_test_cmd runs wfc.TestCmd, streaming its output to the terminal,
+// and reports the result (by exit code) to the browser's dev overlay.
+func (c *Config) run_test_cmd(wfc *WatchedFile) {
+	fields := strings.Fields(c.resolveCmd(wfc.TestCmd))
+	if len(fields) == 0 {
+		return
+	}
+
+	c.Logger.Info("Running tests", "cmd", wfc.TestCmd)
+	if c.is_using_browser() {
+		c.browserTabManager.broadcast <- refreshFilePayload{ChangeType: changeTypeTestRunning}
+	}
+
+	tail := &tail_writer{max: test_output_tail_max_bytes}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdout = io.MultiWriter(os.Stdout, tail)
+	cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+	err := cmd.Run()
+
+	rfp := refreshFilePayload{ChangeType: changeTypeTestResult, TestPassed: err == nil}
+	if err != nil {
+		c.Logger.Error("Tests failed", "cmd", wfc.TestCmd, "error", err)
+		rfp.TestOutput = base64.StdEncoding.EncodeToString(tail.bytes())
+	} else {
+		c.Logger.Info("Tests passed", "cmd", wfc.TestCmd)
+	}
+	if c.is_using_browser() {
+		c.browserTabManager.broadcast <- rfp
+	}
+}
+
+// tail_writer keeps the last max bytes written to it. It is safe for
+// concurrent use, as exec.Cmd copies stdout and stderr from separate
+// goroutines when they are given different writers.
+type tail_writer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (w *tail_writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.max; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// bytes returns a copy of the retained tail.
+func (w *tail_writer) bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]byte(nil), w.buf...)
+}
@@ -0,0 +1,56 @@
+package ki
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTailWriter(t *testing.T) {
+	t.Run("KeepsEverythingUnderMax", func(t *testing.T) {
+		w := &tail_writer{max: 16}
+		w.Write([]byte("hello "))
+		w.Write([]byte("world"))
+		if got := string(w.bytes()); got != "hello world" {
+			t.Errorf("expected %q, got %q", "hello world", got)
+		}
+	})
+
+	t.Run("TruncatesToLastMaxBytes", func(t *testing.T) {
+		w := &tail_writer{max: 8}
+		w.Write([]byte("0123456789"))
+		if got := string(w.bytes()); got != "23456789" {
+			t.Errorf("expected %q, got %q", "23456789", got)
+		}
+		w.Write([]byte("abc"))
+		if got := string(w.bytes()); got != "56789abc" {
+			t.Errorf("expected %q, got %q", "56789abc", got)
+		}
+	})
+
+	t.Run("ReportsFullWriteLength", func(t *testing.T) {
+		w := &tail_writer{max: 4}
+		if n, err := w.Write([]byte("0123456789")); n != 10 || err != nil {
+			t.Errorf("expected (10, nil), got (%d, %v)", n, err)
+		}
+	})
+
+	t.Run("ConcurrentWrites", func(t *testing.T) {
+		w := &tail_writer{max: 64}
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					w.Write([]byte("x"))
+				}
+			}()
+		}
+		wg.Wait()
+		if got := w.bytes(); !bytes.Equal(got, []byte(strings.Repeat("x", 64))) {
+			t.Errorf("expected 64 retained bytes, got %d", len(got))
+		}
+	})
+}
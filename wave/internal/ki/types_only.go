@@ -0,0 +1,43 @@
+package ki
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func is_types_only(wfc *WatchedFile) bool {
+	return wfc != nil && wfc.OnlyRegenerateTypes && !is_test_only(wfc)
+}
+
+// run_types_only_regen runs the dev build hook with the -types-only flag
+// (so that only the framework's generated TypeScript types are rewritten),
+// without rebuilding or restarting the app. The browser is not reloaded
+// (Vite's HMR picks up the regenerated file), unless the watched file
+// opts into the client-defined revalidate function.
+func (c *Config) run_types_only_regen(wfc *WatchedFile) {
+	fields := strings.Fields(c._uc.Core.DevBuildHook)
+	if len(fields) == 0 {
+		c.Logger.Error("error: OnlyRegenerateTypes requires Core.DevBuildHook to be set")
+		return
+	}
+	fields = append(fields, "-"+types_only_flag)
+
+	c.Logger.Info("Regenerating types", "pattern", wfc.Pattern)
+	if err := run_cmd_capturing_output(exec.Command(fields[0], fields[1:]...)); err != nil {
+		err = fmt.Errorf("error regenerating types: %w", err)
+		c.Logger.Error(fmt.Sprintf("error: %v", err))
+		c.broadcast_build_error(err)
+		return
+	}
+
+	if c.is_using_browser() && wfc.OnlyRunClientDefinedRevalidateFunc {
+		c.must_reload_broadcast(
+			refreshFilePayload{ChangeType: changeTypeRevalidate},
+			must_reload_broadcast_opts{
+				wait_for_vite: c.isUsingVite(),
+				message:       "Running client-defined revalidate function",
+			},
+		)
+	}
+}
@@ -15,6 +15,10 @@ type (
 	FileMap     = ki.FileMap
 	WatchedFile = ki.WatchedFile
 	OnChangeCmd = ki.OnChangeHook
+	Diagnostic  = ki.Diagnostic
+
+	PublicAssetManifest      = ki.PublicAssetManifest
+	PublicAssetManifestEntry = ki.PublicAssetManifestEntry
 )
 
 const (
@@ -23,12 +27,16 @@ const (
 	OnChangeStrategyConcurrentNoWait = ki.OnChangeStrategyConcurrentNoWait
 	OnChangeStrategyPost             = ki.OnChangeStrategyPost
 	PrehashedDirname                 = ki.PrehashedDirname
+	DiagnosticSeverityError          = ki.DiagnosticSeverityError
+	DiagnosticSeverityWarning        = ki.DiagnosticSeverityWarning
+	PublicAssetManifestName          = ki.PublicAssetManifestName
 )
 
 var (
-	MustGetPort  = ki.MustGetAppPort
-	GetIsDev     = ki.GetIsDev
-	SetModeToDev = ki.SetModeToDev
+	MustGetPort         = ki.MustGetAppPort
+	GetIsDev            = ki.GetIsDev
+	SetModeToDev        = ki.SetModeToDev
+	HasDiagnosticErrors = ki.HasDiagnosticErrors
 )
 
 // Also add top-level funcs to Wave struct for convenience.
@@ -67,6 +75,16 @@ func New(config Config) *Wave {
 	return &Wave{cfg}
 }
 
+// ValidateConfig checks the given wave.config.json bytes for problems
+// that would otherwise only surface once a build or dev server fails
+// (missing paths, overlapping dirs, unresolvable commands, etc.). Paths
+// are resolved relative to the current working directory. Unlike New,
+// it never panics, so it can be run in CI before a build. The same
+// checks run when your build program is passed the -doctor flag.
+func ValidateConfig(waveConfigJSON []byte) []Diagnostic {
+	return (&ki.Config{WaveConfigJSON: waveConfigJSON}).Validate()
+}
+
 // If you want to do a custom build command, just use
 // Wave.BuildWaveWithoutCompilingGo() instead of Wave.BuildWave(),
 // and then you can control your build yourself afterwards.
@@ -101,6 +119,12 @@ func (k Wave) MustGetPrivateFS() fs.FS {
 func (k Wave) GetPublicURL(originalPublicURL string) string {
 	return k.c.GetPublicURL(originalPublicURL)
 }
+func (k Wave) PublicURL(originalPublicURL string) (string, error) {
+	return k.c.PublicURL(originalPublicURL)
+}
+func (k Wave) PublicURLFuncMap() template.FuncMap {
+	return k.c.PublicURLFuncMap()
+}
 func (k Wave) MustGetPublicURLBuildtime(originalPublicURL string) string {
 	return k.c.MustGetPublicURLBuildtime(originalPublicURL)
 }
@@ -181,6 +205,9 @@ func (k Wave) GetPublicStaticDir() string {
 func (k Wave) GetPublicPathPrefix() string {
 	return k.c.GetPublicPathPrefix()
 }
+func (k Wave) GetPublicAssetsURLPrefix() string {
+	return k.c.GetPublicAssetsURLPrefix()
+}
 func (k Wave) ViteProdBuildWave() error {
 	return k.c.ViteProdBuildWave()
 }
@@ -190,9 +217,24 @@ func (k Wave) GetViteManifestLocation() string {
 func (k Wave) GetViteOutDir() string {
 	return k.c.GetViteOutDir()
 }
+
+// BuildWarn logs a warning about the build (with slog-style key-value
+// args), which fails it if it was run with the -strict-warnings flag.
+// Call it from your build hook for problems that should not break a
+// normal build, but that CI may want to gate on.
+func (k Wave) BuildWarn(msg string, args ...any) {
+	k.c.BuildWarn(msg, args...)
+}
 func (k Wave) BuildWaveWithHook(hook func(isDev bool) error) {
 	k.c.BuildWaveWithHook(hook)
 }
+
+// BuildWaveWithHooks is like BuildWaveWithHook, except that when run
+// with both the -hook and -types-only flags (as watched files with
+// OnlyRegenerateTypes do), it runs typesOnlyHook instead of hook.
+func (k Wave) BuildWaveWithHooks(hook func(isDev bool) error, typesOnlyHook func() error) {
+	k.c.BuildWaveWithHooks(hook, typesOnlyHook)
+}
 func (k Wave) GetRiverUIVariant() string {
 	return k.c.GetRiverUIVariant()
 }
@@ -205,6 +247,9 @@ func (k Wave) GetRiverClientEntry() string {
 func (k Wave) GetRiverClientRouteDefsFile() string {
 	return k.c.GetRiverClientRouteDefsFile()
 }
+func (k Wave) GetRiverClientRoutesDir() string {
+	return k.c.GetRiverClientRoutesDir()
+}
 func (k Wave) GetRiverTSGenOutPath() string {
 	return k.c.GetRiverTSGenOutPath()
 }